@@ -0,0 +1,191 @@
+package authz
+
+import (
+	"LMS/models"
+	"errors"
+
+)
+
+// ErrForbidden dikembalikan ketika subjek tidak boleh melakukan aksi pada sumber daya
+var ErrForbidden = errors.New("you don't have permission to access this resource")
+
+// ErrNotFound dikenali dari error Can ketika sumber daya atau kursus induknya tidak ada
+var ErrNotFound = errors.New("resource not found")
+
+// Action adalah operasi yang ingin dilakukan subjek terhadap sumber daya
+type Action string
+
+const (
+	ActionView     Action = "view"
+	ActionCreate   Action = "create"
+	ActionUpdate   Action = "update"
+	ActionDelete   Action = "delete"
+	ActionGrade    Action = "grade"
+	ActionSubmit   Action = "submit"
+	ActionAccept   Action = "accept"
+	ActionModerate Action = "moderate"
+)
+
+// ResourceType adalah jenis sumber daya yang dilindungi kebijakan
+type ResourceType string
+
+const (
+	ResourceCourse      ResourceType = "course"
+	ResourceMaterial    ResourceType = "material"
+	ResourceAssignment  ResourceType = "assignment"
+	ResourceQuiz        ResourceType = "quiz"
+	ResourceSubmission  ResourceType = "submission"
+	ResourceAssessment  ResourceType = "assessment"
+	ResourceDiscussion  ResourceType = "discussion"
+	ResourceComment     ResourceType = "comment"
+	ResourceQuizAttempt ResourceType = "quiz_attempt"
+	ResourceProgress    ResourceType = "progress"
+	ResourceEnrollment  ResourceType = "enrollment"
+	ResourceExtension   ResourceType = "extension"
+	ResourceGradebook   ResourceType = "gradebook"
+	ResourceRubric      ResourceType = "rubric"
+	ResourceModule      ResourceType = "module"
+	ResourceCertificate ResourceType = "certificate"
+)
+
+// Subject adalah pengguna yang meminta akses
+type Subject struct {
+	UserID uint
+	Role   models.Role
+}
+
+// Resource menunjuk sumber daya yang diperiksa.
+// ID diisi untuk sumber daya yang sudah ada. Untuk sumber daya baru atau daftar,
+// ID dibiarkan nol dan CourseID/OwnerID menunjuk kursus induk dan pemiliknya
+// (siswa untuk kemajuan dan kiriman, mentor untuk kursus baru).
+type Resource struct {
+	Type     ResourceType
+	ID       uint
+	CourseID uint
+	OwnerID  uint
+}
+
+// Target adalah hubungan sumber daya yang sudah diselesaikan terhadap kursusnya
+type Target struct {
+	CourseID uint
+	MentorID uint
+	OwnerID  uint
+	Enrolled bool
+}
+
+// Decide menerapkan aturan kebijakan pada target yang sudah diselesaikan
+func Decide(subject Subject, action Action, resourceType ResourceType, target Target) bool {
+	if subject.Role == models.RoleAdmin {
+		return true
+	}
+
+	isMentor := subject.Role == models.RoleMentor && target.MentorID != 0 && target.MentorID == subject.UserID
+	isOwner := target.OwnerID != 0 && target.OwnerID == subject.UserID
+	isStudent := subject.Role == models.RoleStudent && target.Enrolled
+	isMember := isMentor || isStudent
+
+	switch resourceType {
+	case ResourceCourse:
+		switch action {
+		case ActionView:
+			return true
+		case ActionCreate, ActionUpdate, ActionDelete, ActionGrade:
+			return isMentor
+		}
+
+	case ResourceMaterial, ResourceRubric, ResourceModule:
+		switch action {
+		case ActionView:
+			return isMember
+		case ActionCreate, ActionUpdate, ActionDelete:
+			return isMentor
+		}
+
+	case ResourceAssignment, ResourceQuiz:
+		switch action {
+		case ActionView:
+			return isMember
+		case ActionCreate, ActionUpdate, ActionDelete, ActionGrade:
+			return isMentor
+		case ActionSubmit:
+			return isStudent
+		}
+
+	case ResourceSubmission, ResourceQuizAttempt:
+		switch action {
+		case ActionView:
+			return isOwner || isMentor
+		case ActionDelete:
+			return isOwner && isStudent
+		case ActionGrade:
+			return isMentor
+		}
+
+	case ResourceAssessment:
+		switch action {
+		case ActionView:
+			return isOwner || isMentor
+		case ActionCreate, ActionUpdate, ActionDelete, ActionGrade:
+			return isMentor
+		}
+
+	case ResourceDiscussion, ResourceComment:
+		switch action {
+		case ActionView, ActionCreate:
+			return isMember
+		case ActionUpdate:
+			return isOwner && isMember
+		case ActionDelete, ActionAccept:
+			return (isOwner && isMember) || isMentor
+		case ActionModerate:
+			return isMentor
+		}
+
+	case ResourceProgress:
+		switch action {
+		case ActionView:
+			return (isOwner && isStudent) || isMentor
+		case ActionCreate, ActionUpdate, ActionGrade:
+			return isMentor
+		}
+
+	case ResourceExtension:
+		switch action {
+		case ActionView:
+			return isOwner || isMentor
+		case ActionCreate, ActionUpdate, ActionDelete:
+			return isMentor
+		}
+
+	case ResourceGradebook:
+		switch action {
+		case ActionView:
+			return isMember
+		case ActionCreate, ActionUpdate, ActionDelete, ActionGrade:
+			return isMentor
+		}
+
+	case ResourceEnrollment:
+		switch action {
+		case ActionView:
+			return isOwner || isMentor
+		case ActionCreate:
+			return isOwner && subject.Role == models.RoleStudent
+		case ActionDelete:
+			return isOwner
+		}
+
+	// Pencabutan sertifikat (ActionDelete) hanya untuk admin
+	case ResourceCertificate:
+		switch action {
+		case ActionView:
+			return isOwner || isMentor
+		case ActionCreate:
+			return isOwner && isStudent
+		case ActionUpdate:
+			return isMentor
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,248 @@
+package authz
+
+import (
+	"LMS/models"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+
+)
+
+// Subjek uji terhadap satu kursus dengan mentor pengampu 10 dan sumber daya milik siswa 20
+const (
+	testCourseID = 1
+	testMentorID = 10
+	testOwnerID  = 20
+)
+
+// Nama subjek dipakai sebagai himpunan pihak yang diizinkan di tabel kebijakan
+const (
+	admin         = "admin"
+	owningMentor  = "owning mentor"
+	otherMentor   = "non-owning mentor"
+	enrolled      = "enrolled student"
+	unenrolled    = "unenrolled student"
+	owner         = "resource owner"
+	unenrolledOwn = "unenrolled resource owner"
+)
+
+type testSubject struct {
+	subject  Subject
+	enrolled bool
+}
+
+var testSubjects = map[string]testSubject{
+	admin:         {Subject{UserID: 1, Role: models.RoleAdmin}, false},
+	owningMentor:  {Subject{UserID: testMentorID, Role: models.RoleMentor}, false},
+	otherMentor:   {Subject{UserID: 11, Role: models.RoleMentor}, false},
+	enrolled:      {Subject{UserID: 30, Role: models.RoleStudent}, true},
+	unenrolled:    {Subject{UserID: 31, Role: models.RoleStudent}, false},
+	owner:         {Subject{UserID: testOwnerID, Role: models.RoleStudent}, true},
+	unenrolledOwn: {Subject{UserID: testOwnerID, Role: models.RoleStudent}, false},
+}
+
+var allResourceTypes = []ResourceType{
+	ResourceCourse, ResourceMaterial, ResourceAssignment, ResourceQuiz, ResourceSubmission,
+	ResourceAssessment, ResourceDiscussion, ResourceComment, ResourceQuizAttempt, ResourceProgress,
+	ResourceEnrollment, ResourceExtension, ResourceGradebook, ResourceRubric, ResourceModule,
+	ResourceCertificate,
+}
+
+var allActions = []Action{
+	ActionView, ActionCreate, ActionUpdate, ActionDelete, ActionGrade, ActionSubmit, ActionAccept, ActionModerate,
+}
+
+// allow mengembalikan himpunan subjek yang diizinkan
+func allow(names ...string) map[string]bool {
+	allowed := map[string]bool{admin: true}
+	for _, name := range names {
+		allowed[name] = true
+	}
+	return allowed
+}
+
+// policyTable adalah aturan yang diharapkan untuk setiap pasangan sumber daya dan aksi.
+// Pasangan yang tidak tercantum hanya boleh dilakukan admin.
+var policyTable = map[ResourceType]map[Action]map[string]bool{
+	ResourceCourse: {
+		ActionView:   allow(owningMentor, otherMentor, enrolled, unenrolled, owner, unenrolledOwn),
+		ActionCreate: allow(owningMentor),
+		ActionUpdate: allow(owningMentor),
+		ActionDelete: allow(owningMentor),
+		ActionGrade:  allow(owningMentor),
+	},
+	ResourceMaterial: memberContent(),
+	ResourceRubric:   memberContent(),
+	ResourceModule:   memberContent(),
+	ResourceAssignment: {
+		ActionView:   allow(owningMentor, enrolled, owner),
+		ActionCreate: allow(owningMentor),
+		ActionUpdate: allow(owningMentor),
+		ActionDelete: allow(owningMentor),
+		ActionGrade:  allow(owningMentor),
+		ActionSubmit: allow(enrolled, owner),
+	},
+	ResourceQuiz: {
+		ActionView:   allow(owningMentor, enrolled, owner),
+		ActionCreate: allow(owningMentor),
+		ActionUpdate: allow(owningMentor),
+		ActionDelete: allow(owningMentor),
+		ActionGrade:  allow(owningMentor),
+		ActionSubmit: allow(enrolled, owner),
+	},
+	// Pemilik kiriman dan percobaan kuis tetap dapat melihatnya setelah keluar dari kursus,
+	// tetapi hanya siswa terdaftar yang dapat menghapusnya
+	ResourceSubmission:  studentWork(),
+	ResourceQuizAttempt: studentWork(),
+	ResourceAssessment: {
+		ActionView:   allow(owningMentor, owner, unenrolledOwn),
+		ActionCreate: allow(owningMentor),
+		ActionUpdate: allow(owningMentor),
+		ActionDelete: allow(owningMentor),
+		ActionGrade:  allow(owningMentor),
+	},
+	ResourceDiscussion: forumPost(),
+	ResourceComment:    forumPost(),
+	ResourceProgress: {
+		ActionView:   allow(owningMentor, owner),
+		ActionCreate: allow(owningMentor),
+		ActionUpdate: allow(owningMentor),
+		ActionGrade:  allow(owningMentor),
+	},
+	ResourceExtension: {
+		ActionView:   allow(owningMentor, owner, unenrolledOwn),
+		ActionCreate: allow(owningMentor),
+		ActionUpdate: allow(owningMentor),
+		ActionDelete: allow(owningMentor),
+	},
+	ResourceGradebook: {
+		ActionView:   allow(owningMentor, enrolled, owner),
+		ActionCreate: allow(owningMentor),
+		ActionUpdate: allow(owningMentor),
+		ActionDelete: allow(owningMentor),
+		ActionGrade:  allow(owningMentor),
+	},
+	// Siswa mendaftarkan dirinya sendiri sebelum terdaftar
+	ResourceEnrollment: {
+		ActionView:   allow(owningMentor, owner, unenrolledOwn),
+		ActionCreate: allow(owner, unenrolledOwn),
+		ActionDelete: allow(owner, unenrolledOwn),
+	},
+	ResourceCertificate: {
+		ActionView:   allow(owningMentor, owner, unenrolledOwn),
+		ActionCreate: allow(owner),
+		ActionUpdate: allow(owningMentor),
+	},
+}
+
+// memberContent adalah aturan isi kursus yang dapat dilihat anggota dan dikelola mentor
+func memberContent() map[Action]map[string]bool {
+	return map[Action]map[string]bool{
+		ActionView:   allow(owningMentor, enrolled, owner),
+		ActionCreate: allow(owningMentor),
+		ActionUpdate: allow(owningMentor),
+		ActionDelete: allow(owningMentor),
+	}
+}
+
+// studentWork adalah aturan kiriman tugas dan percobaan kuis
+func studentWork() map[Action]map[string]bool {
+	return map[Action]map[string]bool{
+		ActionView:   allow(owningMentor, owner, unenrolledOwn),
+		ActionDelete: allow(owner),
+		ActionGrade:  allow(owningMentor),
+	}
+}
+
+// forumPost adalah aturan diskusi dan komentar
+func forumPost() map[Action]map[string]bool {
+	return map[Action]map[string]bool{
+		ActionView:     allow(owningMentor, enrolled, owner),
+		ActionCreate:   allow(owningMentor, enrolled, owner),
+		ActionUpdate:   allow(owner),
+		ActionDelete:   allow(owningMentor, owner),
+		ActionAccept:   allow(owningMentor, owner),
+		ActionModerate: allow(owningMentor),
+	}
+}
+
+func TestDecide(t *testing.T) {
+	for resourceType := range policyTable {
+		if !containsResource(resourceType) {
+			t.Fatalf("policy table has unknown resource type %q", resourceType)
+		}
+	}
+
+	for _, resourceType := range allResourceTypes {
+		rules, ok := policyTable[resourceType]
+		if !ok {
+			t.Fatalf("policy table is missing resource type %q", resourceType)
+		}
+
+		for _, action := range allActions {
+			allowed, ok := rules[action]
+			if !ok {
+				allowed = allow()
+			}
+
+			for name, subject := range testSubjects {
+				name, subject, want := name, subject, allowed[name]
+				t.Run(fmt.Sprintf("%s/%s/%s", resourceType, action, name), func(t *testing.T) {
+					target := Target{
+						CourseID: testCourseID,
+						MentorID: testMentorID,
+						OwnerID:  testOwnerID,
+						Enrolled: subject.enrolled,
+					}
+					if got := Decide(subject.subject, action, resourceType, target); got != want {
+						t.Errorf("Decide = %v, want %v", got, want)
+					}
+				})
+			}
+		}
+	}
+}
+
+// TestDecideMentorOwnsNothingWithoutCourse memastikan mentor tidak dianggap pengampu ketika target tidak punya mentor
+func TestDecideMentorOwnsNothingWithoutCourse(t *testing.T) {
+	mentor := Subject{UserID: 0, Role: models.RoleMentor}
+	if Decide(mentor, ActionUpdate, ResourceMaterial, Target{CourseID: testCourseID}) {
+		t.Error("mentor without a course must not be treated as the owning mentor")
+	}
+}
+
+func containsResource(resourceType ResourceType) bool {
+	for _, known := range allResourceTypes {
+		if known == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNotFound(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		notFound bool
+	}{
+		{"repository message", errors.New("material not found"), true},
+		{"gorm record not found", gorm.ErrRecordNotFound, true},
+		{"database failure", errors.New("driver: bad connection"), false},
+		{"unknown resource type", errors.New("unknown resource type"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := notFound(tt.err)
+			if got := errors.Is(err, ErrNotFound); got != tt.notFound {
+				t.Errorf("errors.Is(err, ErrNotFound) = %v, want %v", got, tt.notFound)
+			}
+			if err.Error() != tt.err.Error() {
+				t.Errorf("message = %q, want %q", err.Error(), tt.err.Error())
+			}
+		})
+	}
+}
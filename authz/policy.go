@@ -0,0 +1,263 @@
+package authz
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+)
+
+// Policy menyelesaikan kepemilikan kursus dan pendaftaran sebuah sumber daya lalu menerapkan Decide
+type Policy struct {
+	CourseRepo      *repositories.CourseRepository
+	EnrollmentRepo  *repositories.EnrollmentRepository
+	MaterialRepo    *repositories.MaterialRepository
+	AssignmentRepo  *repositories.AssignmentRepository
+	QuizRepo        *repositories.QuizRepository
+	SubmissionRepo  *repositories.SubmissionRepository
+	AssessmentRepo  *repositories.AssessmentRepository
+	DiscussionRepo  *repositories.DiscussionRepository
+	CommentRepo     *repositories.CommentRepository
+	ProgressRepo    *repositories.LearningProgressRepository
+	ExtensionRepo   *repositories.ExtensionRepository
+	RubricRepo      *repositories.RubricRepository
+	ModuleRepo      *repositories.ModuleRepository
+	CertificateRepo *repositories.CertificateRepository
+}
+
+// NewPolicy membuat kebijakan otorisasi baru
+func NewPolicy(
+	courseRepo *repositories.CourseRepository,
+	enrollmentRepo *repositories.EnrollmentRepository,
+	materialRepo *repositories.MaterialRepository,
+	assignmentRepo *repositories.AssignmentRepository,
+	quizRepo *repositories.QuizRepository,
+	submissionRepo *repositories.SubmissionRepository,
+	assessmentRepo *repositories.AssessmentRepository,
+	discussionRepo *repositories.DiscussionRepository,
+	commentRepo *repositories.CommentRepository,
+	progressRepo *repositories.LearningProgressRepository,
+	extensionRepo *repositories.ExtensionRepository,
+	rubricRepo *repositories.RubricRepository,
+	moduleRepo *repositories.ModuleRepository,
+	certificateRepo *repositories.CertificateRepository,
+) *Policy {
+	return &Policy{
+		CourseRepo:      courseRepo,
+		EnrollmentRepo:  enrollmentRepo,
+		MaterialRepo:    materialRepo,
+		AssignmentRepo:  assignmentRepo,
+		QuizRepo:        quizRepo,
+		SubmissionRepo:  submissionRepo,
+		AssessmentRepo:  assessmentRepo,
+		DiscussionRepo:  discussionRepo,
+		CommentRepo:     commentRepo,
+		ProgressRepo:    progressRepo,
+		ExtensionRepo:   extensionRepo,
+		RubricRepo:      rubricRepo,
+		ModuleRepo:      moduleRepo,
+		CertificateRepo: certificateRepo,
+	}
+}
+
+// Can mengembalikan nil jika subjek boleh melakukan aksi, ErrForbidden jika ditolak,
+// error yang dikenali sebagai ErrNotFound jika sumber daya tidak ditemukan, atau error basis data
+func (p *Policy) Can(subject Subject, action Action, resource Resource) error {
+	// Sumber daya tetap diselesaikan untuk admin agar ID yang tidak ada menghasilkan not found
+	target, err := p.resolve(resource)
+	if err != nil {
+		return notFound(err)
+	}
+
+	if subject.Role == models.RoleStudent && target.CourseID != 0 {
+		_, err := p.EnrollmentRepo.FindByUserAndCourse(subject.UserID, target.CourseID)
+		if err == nil {
+			target.Enrolled = true
+		} else if !errors.Is(notFound(err), ErrNotFound) {
+			return err
+		}
+	}
+
+	if !Decide(subject, action, resource.Type, *target) {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// resolve menemukan kursus, mentor, dan pemilik dari sebuah sumber daya
+func (p *Policy) resolve(resource Resource) (*Target, error) {
+	// Sumber daya baru atau daftar: gunakan kursus induk dan pemilik yang diberikan
+	if resource.ID == 0 {
+		if resource.Type == ResourceCourse {
+			return &Target{MentorID: resource.OwnerID}, nil
+		}
+		if resource.CourseID == 0 {
+			return &Target{OwnerID: resource.OwnerID}, nil
+		}
+		return p.courseTarget(resource.CourseID, resource.OwnerID)
+	}
+
+	switch resource.Type {
+	case ResourceCourse:
+		return p.courseTarget(resource.ID, 0)
+
+	case ResourceMaterial:
+		material, err := p.MaterialRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(material.CourseID, 0)
+
+	case ResourceAssignment:
+		assignment, err := p.AssignmentRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(assignment.CourseID, 0)
+
+	case ResourceQuiz:
+		quiz, err := p.QuizRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(quiz.CourseID, 0)
+
+	case ResourceSubmission:
+		submission, err := p.SubmissionRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.submissionTarget(submission)
+
+	case ResourceAssessment:
+		assessment, err := p.AssessmentRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		submission, err := p.SubmissionRepo.FindByID(assessment.SubmissionID)
+		if err != nil {
+			return nil, err
+		}
+		return p.submissionTarget(submission)
+
+	case ResourceDiscussion:
+		discussion, err := p.DiscussionRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(discussion.CourseID, discussion.UserID)
+
+	case ResourceComment:
+		comment, err := p.CommentRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		discussion, err := p.DiscussionRepo.FindByID(comment.DiscussionID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(discussion.CourseID, comment.UserID)
+
+	case ResourceQuizAttempt:
+		attempt, err := p.QuizRepo.FindAttemptByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		quiz, err := p.QuizRepo.FindByID(attempt.QuizID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(quiz.CourseID, attempt.StudentID)
+
+	case ResourceProgress:
+		progress, err := p.ProgressRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(progress.CourseID, progress.UserID)
+
+	case ResourceExtension:
+		extension, err := p.ExtensionRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(extension.CourseID, extension.StudentID)
+
+	case ResourceRubric:
+		rubric, err := p.RubricRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(rubric.CourseID, 0)
+
+	case ResourceModule:
+		module, err := p.ModuleRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(module.CourseID, 0)
+
+	case ResourceEnrollment:
+		enrollment, err := p.EnrollmentRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(enrollment.CourseID, enrollment.UserID)
+
+	case ResourceCertificate:
+		certificate, err := p.CertificateRepo.FindByID(resource.ID)
+		if err != nil {
+			return nil, err
+		}
+		return p.courseTarget(certificate.CourseID, certificate.UserID)
+	}
+
+	return nil, errors.New("unknown resource type")
+}
+
+// submissionTarget menyelesaikan kiriman ke kursus penugasannya dengan siswa sebagai pemilik
+func (p *Policy) submissionTarget(submission *models.Submission) (*Target, error) {
+	assignment, err := p.AssignmentRepo.FindByID(submission.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	return p.courseTarget(assignment.CourseID, submission.StudentID)
+}
+
+// courseTarget membuat target dari kursus dan pemilik sumber daya
+func (p *Policy) courseTarget(courseID, ownerID uint) (*Target, error) {
+	course, err := p.CourseRepo.FindByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	return &Target{
+		CourseID: course.ID,
+		MentorID: course.MentorID,
+		OwnerID:  ownerID,
+	}, nil
+}
+
+// notFoundError mempertahankan pesan repositori seperti "material not found"
+// sekaligus dapat dikenali dengan errors.Is(err, ErrNotFound)
+type notFoundError struct {
+	err error
+}
+
+func (e *notFoundError) Error() string { return e.err.Error() }
+
+func (e *notFoundError) Is(target error) bool { return target == ErrNotFound }
+
+func (e *notFoundError) Unwrap() error { return e.err }
+
+// notFound menandai error "x not found" dari repositori sebagai ErrNotFound;
+// error lain seperti kegagalan koneksi basis data dikembalikan apa adanya
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) || strings.HasSuffix(err.Error(), " not found") {
+		return &notFoundError{err: err}
+	}
+	return err
+}
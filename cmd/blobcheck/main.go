@@ -0,0 +1,64 @@
+package main
+
+import (
+	"LMS/config"
+	"LMS/repositories"
+	"LMS/services"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+)
+
+// blobcheck memeriksa konsistensi penyimpanan berkas: objek yatim, blob tanpa referensi,
+// blob yang hilang atau rusak, dan jumlah referensi yang tidak sesuai
+func main() {
+	verify := flag.Bool("verify", false, "read every blob and verify its SHA-256 checksum")
+	fix := flag.Bool("fix", false, "delete orphaned objects and unreferenced blobs and repair reference counts")
+	migrate := flag.Bool("migrate", false, "move files uploaded before content addressing into the blob store first")
+	flag.Parse()
+
+	db, err := config.InitDB()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	store, err := config.InitStorage()
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	blobService := services.NewBlobService(repositories.NewBlobRepository(db), store)
+
+	if *migrate {
+		migrated, err := blobService.MigrateLegacy()
+		if err != nil {
+			log.Fatalf("Failed to migrate legacy files: %v", err)
+		}
+		log.Printf("Migrated %d legacy files", migrated)
+	}
+
+	report, err := blobService.Check(*verify)
+	if err != nil {
+		log.Fatalf("Failed to check blobs: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	if report.Clean() {
+		return
+	}
+
+	if *fix {
+		if err := blobService.Repair(report); err != nil {
+			log.Fatalf("Failed to repair blobs: %v", err)
+		}
+		log.Println("Removed orphaned data and repaired reference counts; missing and corrupt blobs must be restored from backup")
+		if len(report.MissingBlobs) == 0 && len(report.CorruptBlobs) == 0 && len(report.DanglingReferences) == 0 {
+			return
+		}
+	}
+	os.Exit(1)
+}
@@ -10,6 +10,7 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
 )
 
 // DBConfig konfigurasi basis data
@@ -96,11 +97,45 @@ func InitDB() (*gorm.DB, error) {
 		&models.Assessment{},
 		&models.Discussion{},
 		&models.Comment{},
+		&models.CommentReaction{},
+		&models.ContentReport{},
+		&models.Notification{},
+		&models.EmailPreference{},
+		&models.EmailDelivery{},
 		&models.LearningProgress{},
+		&models.Quiz{},
+		&models.QuizQuestion{},
+		&models.QuizChoice{},
+		&models.QuizAttempt{},
+		&models.QuizAnswer{},
+		&models.RefreshToken{},
+		&models.RevokedAccessToken{},
+		&models.DueDateExtension{},
+		&models.GradeCategory{},
+		&models.LetterGrade{},
+		&models.Rubric{},
+		&models.RubricCriterion{},
+		&models.RubricLevel{},
+		&models.AssessmentCriterionScore{},
+		&models.Blob{},
+		&models.MaterialFile{},
+		&models.UploadSession{},
+		&models.UploadChunk{},
+		&models.Module{},
+		&models.ModuleItem{},
+		&models.CompletionRule{},
+		&models.CompletionActivity{},
+		&models.CertificateTemplate{},
+		&models.Certificate{},
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Jalur berkas lama disimpan relatif terhadap direktori kerja ("uploads/..."),
+	// sekarang disimpan sebagai kunci penyimpanan ("materials/...")
+	db.Exec("UPDATE materials SET file_path = SUBSTRING(file_path, 9) WHERE file_path LIKE 'uploads/%'")
+	db.Exec("UPDATE submissions SET file_path = SUBSTRING(file_path, 9) WHERE file_path LIKE 'uploads/%'")
+
 	return db, nil
 }
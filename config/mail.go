@@ -0,0 +1,73 @@
+package config
+
+import (
+	"LMS/mailer"
+	"LMS/services"
+	"fmt"
+	"strconv"
+	"time"
+
+)
+
+// InitMailer membuat pengirim email sesuai MAIL_DRIVER ("log" atau "smtp")
+func InitMailer() (mailer.Mailer, error) {
+	driver := getEnv("MAIL_DRIVER", "log")
+
+	switch driver {
+	case "log":
+		return mailer.NewLogMailer(), nil
+
+	case "smtp":
+		port, err := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+		}
+		timeout, err := time.ParseDuration(getEnv("SMTP_TIMEOUT", "30s"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_TIMEOUT: %w", err)
+		}
+		return mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     port,
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", "LMS <no-reply@localhost>"),
+			Security: getEnv("SMTP_SECURITY", mailer.SMTPSecuritySTARTTLS),
+			Timeout:  timeout,
+		})
+	}
+
+	return nil, fmt.Errorf("unknown mail driver %q", driver)
+}
+
+// BuildEmailSettings membuat pengaturan email pemberitahuan dari variabel lingkungan
+func BuildEmailSettings() (services.EmailSettings, error) {
+	settings := services.DefaultEmailSettings()
+	settings.AppURL = getEnv("MAIL_APP_URL", "")
+
+	if value := getEnv("MAIL_MAX_ATTEMPTS", ""); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return settings, fmt.Errorf("invalid MAIL_MAX_ATTEMPTS: must be a positive number")
+		}
+		settings.MaxAttempts = number
+	}
+
+	if value := getEnv("MAIL_RETRY_DELAY", ""); value != "" {
+		delay, err := time.ParseDuration(value)
+		if err != nil || delay <= 0 {
+			return settings, fmt.Errorf("invalid MAIL_RETRY_DELAY: must be a positive duration such as 1m")
+		}
+		settings.RetryDelay = delay
+	}
+
+	if value := getEnv("MAIL_DIGEST_HOUR", ""); value != "" {
+		hour, err := strconv.Atoi(value)
+		if err != nil || hour < 0 || hour > 23 {
+			return settings, fmt.Errorf("invalid MAIL_DIGEST_HOUR: must be between 0 and 23")
+		}
+		settings.DigestHour = hour
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"LMS/services"
+	"fmt"
+	"strconv"
+
+)
+
+// BuildMaterialCompletion membuat ambang penyelesaian materi otomatis dari variabel lingkungan
+func BuildMaterialCompletion() (services.MaterialCompletion, error) {
+	completion := services.DefaultMaterialCompletion()
+
+	if value := getEnv("MATERIAL_COMPLETION_MIN_SECONDS", ""); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			return completion, fmt.Errorf("invalid MATERIAL_COMPLETION_MIN_SECONDS: must be zero or a positive number")
+		}
+		completion.MinSeconds = number
+	}
+
+	if value := getEnv("MATERIAL_COMPLETION_WATCH_PERCENT", ""); value != "" {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || number <= 0 || number > 100 {
+			return completion, fmt.Errorf("invalid MATERIAL_COMPLETION_WATCH_PERCENT: must be between 0 and 100")
+		}
+		completion.MinWatchPercent = number
+	}
+
+	return completion, nil
+}
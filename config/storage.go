@@ -0,0 +1,38 @@
+package config
+
+import (
+	"LMS/storage"
+	"fmt"
+	"strconv"
+
+)
+
+// InitStorage membuat penyimpanan berkas sesuai STORAGE_DRIVER ("local" atau "s3")
+func InitStorage() (storage.Backend, error) {
+	driver := getEnv("STORAGE_DRIVER", "local")
+
+	switch driver {
+	case "local":
+		return storage.NewLocalBackend(
+			getEnv("STORAGE_LOCAL_ROOT", "uploads"),
+			getEnv("STORAGE_PUBLIC_URL", "http://localhost:8080"),
+			[]byte(getEnv("STORAGE_SIGNING_KEY", getEnv("JWT_SECRET_KEY", "your-256-bit-secret"))),
+		)
+
+	case "s3":
+		usePathStyle, err := strconv.ParseBool(getEnv("S3_USE_PATH_STYLE", "true"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3_USE_PATH_STYLE: %w", err)
+		}
+		return storage.NewS3Backend(storage.S3Config{
+			Endpoint:     getEnv("S3_ENDPOINT", ""),
+			Region:       getEnv("S3_REGION", "us-east-1"),
+			Bucket:       getEnv("S3_BUCKET", ""),
+			AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+			SecretKey:    getEnv("S3_SECRET_KEY", ""),
+			UsePathStyle: usePathStyle,
+		})
+	}
+
+	return nil, fmt.Errorf("unknown storage driver %q", driver)
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"LMS/services"
+	"fmt"
+	"strconv"
+	"time"
+
+)
+
+// BuildUploadLimits membuat batas unggahan berkas dari variabel lingkungan
+func BuildUploadLimits() (services.UploadLimits, error) {
+	limits := services.DefaultUploadLimits()
+
+	settings := []struct {
+		key    string
+		target *int64
+		unit   int64
+	}{
+		{"UPLOAD_MAX_MATERIAL_SIZE_MB", &limits.MaxMaterialSize, 1 << 20},
+		{"UPLOAD_MAX_SUBMISSION_SIZE_MB", &limits.MaxSubmissionSize, 1 << 20},
+		{"UPLOAD_MAX_ARCHIVE_UNCOMPRESSED_MB", &limits.MaxArchiveUncompressedSize, 1 << 20},
+		{"UPLOAD_MAX_ARCHIVE_RATIO", &limits.MaxArchiveCompressionRatio, 1},
+		{"UPLOAD_MAX_RESUMABLE_SIZE_MB", &limits.MaxResumableSize, 1 << 20},
+		{"UPLOAD_CHUNK_SIZE_MB", &limits.ResumableChunkSize, 1 << 20},
+	}
+	for _, setting := range settings {
+		value := getEnv(setting.key, "")
+		if value == "" {
+			continue
+		}
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || number < 1 {
+			return limits, fmt.Errorf("invalid %s: must be a positive number", setting.key)
+		}
+		*setting.target = number * setting.unit
+	}
+
+	if value := getEnv("UPLOAD_MAX_ARCHIVE_ENTRIES", ""); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return limits, fmt.Errorf("invalid UPLOAD_MAX_ARCHIVE_ENTRIES: must be a positive number")
+		}
+		limits.MaxArchiveEntries = number
+	}
+
+	if value := getEnv("UPLOAD_MAX_HLS_ENTRIES", ""); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return limits, fmt.Errorf("invalid UPLOAD_MAX_HLS_ENTRIES: must be a positive number")
+		}
+		limits.MaxHLSEntries = number
+	}
+
+	if value := getEnv("UPLOAD_SESSION_TTL_HOURS", ""); value != "" {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 1 {
+			return limits, fmt.Errorf("invalid UPLOAD_SESSION_TTL_HOURS: must be a positive number")
+		}
+		limits.UploadSessionTTL = time.Duration(number) * time.Hour
+	}
+	if value := getEnv("UPLOAD_STAGING_DIR", ""); value != "" {
+		limits.StagingDir = value
+	}
+	if limits.ResumableChunkSize > services.MaxResumableChunkSize {
+		return limits, fmt.Errorf("invalid UPLOAD_CHUNK_SIZE_MB: must be at most %d", services.MaxResumableChunkSize>>20)
+	}
+
+	return limits, nil
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"LMS/authz"
 	"LMS/models"
 	services "LMS/services"
 	"net/http"
@@ -12,26 +13,58 @@ import (
 // AssessmentController menangani permintaan penilaian
 type AssessmentController struct {
 	AssessmentService *services.AssessmentService
+	Policy            *authz.Policy
 }
 
 // NewAssessmentController untuk pengontrol penilaian baru
-func NewAssessmentController(assessmentService *services.AssessmentService) *AssessmentController {
+func NewAssessmentController(assessmentService *services.AssessmentService, policy *authz.Policy) *AssessmentController {
 	return &AssessmentController{
 		AssessmentService: assessmentService,
+		Policy:            policy,
 	}
 }
 
 // CreateAssessmentRequest  untuk membuat penilaian baru
+// Isi submission_id untuk menilai percobaan tertentu, atau assignment_id dan student_id
+// (dengan attempt_number opsional) untuk menilai percobaan terbaru siswa
 type CreateAssessmentRequest struct {
-	SubmissionID uint   `json:"submission_id" binding:"required"`
-	Score        *int   `json:"score"`
-	Feedback     string `json:"feedback"`
+	SubmissionID  uint                    `json:"submission_id"`
+	AssignmentID  uint                    `json:"assignment_id"`
+	StudentID     uint                    `json:"student_id"`
+	AttemptNumber *int                    `json:"attempt_number"`
+	Score         *int                    `json:"score"`
+	Feedback      string                  `json:"feedback"`
+	Criteria      []CriterionScoreRequest `json:"criteria" binding:"omitempty,dive"`
 }
 
 // UpdateAssessmentRequest  untuk memperbarui penilaian
 type UpdateAssessmentRequest struct {
-	Score    *int   `json:"score"`
-	Feedback string `json:"feedback"`
+	Score    *int                    `json:"score"`
+	Feedback string                  `json:"feedback"`
+	Criteria []CriterionScoreRequest `json:"criteria" binding:"omitempty,dive"`
+}
+
+// CriterionScoreRequest mewakili skor satu kriteria rubrik.
+// Isi level_id untuk memilih tingkat capaian, atau points untuk poin di antara tingkat.
+type CriterionScoreRequest struct {
+	CriterionID uint   `json:"criterion_id" binding:"required"`
+	LevelID     *uint  `json:"level_id"`
+	Points      *int   `json:"points"`
+	Comment     string `json:"comment"`
+}
+
+// criterionScoreInputs mengubah permintaan skor kriteria menjadi masukan layanan
+func criterionScoreInputs(requests []CriterionScoreRequest) []services.CriterionScoreInput {
+	inputs := make([]services.CriterionScoreInput, 0, len(requests))
+	for _, request := range requests {
+		inputs = append(inputs, services.CriterionScoreInput{
+			CriterionID: request.CriterionID,
+			LevelID:     request.LevelID,
+			Points:      request.Points,
+			Comment:     request.Comment,
+		})
+	}
+	return inputs
 }
 
 // CreateAssessment menangani pembuatan penilaian baru
@@ -42,13 +75,35 @@ func (c *AssessmentController) CreateAssessment(ctx *gin.Context) {
 		return
 	}
 
+	if request.SubmissionID == 0 {
+		if request.AssignmentID == 0 || request.StudentID == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "submission_id or assignment_id and student_id are required"})
+			return
+		}
+
+		if !authorize(ctx, c.Policy, authz.ActionGrade, authz.Resource{Type: authz.ResourceAssignment, ID: request.AssignmentID}) {
+			return
+		}
+
+		submission, err := c.AssessmentService.ResolveSubmission(request.AssignmentID, request.StudentID, request.AttemptNumber)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		request.SubmissionID = submission.ID
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionGrade, authz.Resource{Type: authz.ResourceSubmission, ID: request.SubmissionID}) {
+		return
+	}
+
 	assessment := &models.Assessment{
 		SubmissionID: request.SubmissionID,
 		Score:        request.Score,
 		Feedback:     request.Feedback,
 	}
 
-	if err := c.AssessmentService.CreateAssessment(assessment); err != nil {
+	if err := c.AssessmentService.CreateAssessment(assessment, criterionScoreInputs(request.Criteria)); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -67,6 +122,10 @@ func (c *AssessmentController) GetAssessmentByID(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceAssessment, ID: uint(id)}) {
+		return
+	}
+
 	assessment, err := c.AssessmentService.GetAssessmentByID(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Assessment not found"})
@@ -84,6 +143,10 @@ func (c *AssessmentController) GetAssessmentBySubmission(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceSubmission, ID: uint(submissionID)}) {
+		return
+	}
+
 	assessment, err := c.AssessmentService.GetAssessmentBySubmission(uint(submissionID))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Assessment not found"})
@@ -101,6 +164,10 @@ func (c *AssessmentController) UpdateAssessment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceAssessment, ID: uint(id)}) {
+		return
+	}
+
 	var request UpdateAssessmentRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -113,13 +180,14 @@ func (c *AssessmentController) UpdateAssessment(ctx *gin.Context) {
 		Feedback: request.Feedback,
 	}
 
-	if err := c.AssessmentService.UpdateAssessment(assessment); err != nil {
+	if err := c.AssessmentService.UpdateAssessment(assessment, criterionScoreInputs(request.Criteria)); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Assessment updated successfully",
+		"message":    "Assessment updated successfully",
+		"assessment": assessment,
 	})
 }
 
@@ -131,6 +199,10 @@ func (c *AssessmentController) DeleteAssessment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceAssessment, ID: uint(id)}) {
+		return
+	}
+
 	if err := c.AssessmentService.DeleteAssessment(uint(id)); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Assessment not found"})
 		return
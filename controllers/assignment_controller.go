@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"LMS/authz"
 	"LMS/models"
 	services "LMS/services"
 	"net/http"
@@ -14,30 +15,54 @@ import (
 // AssignmentController untuk permintaan penugasan
 type AssignmentController struct {
 	AssignmentService *services.AssignmentService
+	Policy            *authz.Policy
 }
 
 // NewAssignmentController membuat pengontrol penugasan baru
-func NewAssignmentController(assignmentService *services.AssignmentService) *AssignmentController {
+func NewAssignmentController(assignmentService *services.AssignmentService, policy *authz.Policy) *AssignmentController {
 	return &AssignmentController{
 		AssignmentService: assignmentService,
+		Policy:            policy,
 	}
 }
 
 // CreateAssignmentRequest mewakili permintaan untuk membuat tugas baru
 type CreateAssignmentRequest struct {
-	CourseID    uint       `json:"course_id" binding:"required"`
-	Title       string     `json:"title" binding:"required"`
-	Description string     `json:"description"`
-	DueDate     *time.Time `json:"due_date"`
-	MaxScore    *int       `json:"max_score"`
+	CourseID               uint       `json:"course_id" binding:"required"`
+	Title                  string     `json:"title" binding:"required"`
+	Description            string     `json:"description"`
+	DueDate                *time.Time `json:"due_date"`
+	MaxScore               *int       `json:"max_score"`
+	MaxAttempts            *int       `json:"max_attempts"`
+	AllowLateSubmissions   bool       `json:"allow_late_submissions"`
+	LateGracePeriodMinutes int        `json:"late_grace_period_minutes"`
+	LateCutoffDate         *time.Time `json:"late_cutoff_date"`
+	LatePenaltyPerDay      float64    `json:"late_penalty_per_day"`
+	RubricID               *uint      `json:"rubric_id"`
+	AllowedFileTypes       string     `json:"allowed_file_types"`
+	MaxFileSizeMB          *int       `json:"max_file_size_mb"`
+	PublishAt              *time.Time `json:"publish_at"`
+	UnpublishAt            *time.Time `json:"unpublish_at"`
+	ReleaseAfterDays       *int       `json:"release_after_days"`
 }
 
 // UpdateAssignmentRequest mewakili permintaan untuk memperbarui tugas
 type UpdateAssignmentRequest struct {
-	Title       string     `json:"title" binding:"required"`
-	Description string     `json:"description"`
-	DueDate     *time.Time `json:"due_date"`
-	MaxScore    *int       `json:"max_score"`
+	Title                  string     `json:"title" binding:"required"`
+	Description            string     `json:"description"`
+	DueDate                *time.Time `json:"due_date"`
+	MaxScore               *int       `json:"max_score"`
+	MaxAttempts            *int       `json:"max_attempts"`
+	AllowLateSubmissions   bool       `json:"allow_late_submissions"`
+	LateGracePeriodMinutes int        `json:"late_grace_period_minutes"`
+	LateCutoffDate         *time.Time `json:"late_cutoff_date"`
+	LatePenaltyPerDay      float64    `json:"late_penalty_per_day"`
+	RubricID               *uint      `json:"rubric_id"`
+	AllowedFileTypes       string     `json:"allowed_file_types"`
+	MaxFileSizeMB          *int       `json:"max_file_size_mb"`
+	PublishAt              *time.Time `json:"publish_at"`
+	UnpublishAt            *time.Time `json:"unpublish_at"`
+	ReleaseAfterDays       *int       `json:"release_after_days"`
 }
 
 // CreateAssignment menangani pembuatan tugas
@@ -48,12 +73,29 @@ func (c *AssignmentController) CreateAssignment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceAssignment, CourseID: request.CourseID}) {
+		return
+	}
+
 	assignment := &models.Assignment{
-		CourseID:    request.CourseID,
-		Title:       request.Title,
-		Description: request.Description,
-		DueDate:     request.DueDate,
-		MaxScore:    request.MaxScore,
+		Availability: models.Availability{
+			PublishAt:        request.PublishAt,
+			UnpublishAt:      request.UnpublishAt,
+			ReleaseAfterDays: request.ReleaseAfterDays,
+		},
+		CourseID:               request.CourseID,
+		Title:                  request.Title,
+		Description:            request.Description,
+		DueDate:                request.DueDate,
+		MaxScore:               request.MaxScore,
+		MaxAttempts:            request.MaxAttempts,
+		AllowLateSubmissions:   request.AllowLateSubmissions,
+		LateGracePeriodMinutes: request.LateGracePeriodMinutes,
+		LateCutoffDate:         request.LateCutoffDate,
+		LatePenaltyPerDay:      request.LatePenaltyPerDay,
+		RubricID:               request.RubricID,
+		AllowedFileTypes:       request.AllowedFileTypes,
+		MaxFileSizeMB:          request.MaxFileSizeMB,
 	}
 
 	if err := c.AssignmentService.CreateAssignment(assignment); err != nil {
@@ -75,7 +117,20 @@ func (c *AssignmentController) GetAssignmentByID(ctx *gin.Context) {
 		return
 	}
 
-	assignment, err := c.AssignmentService.GetAssignmentByID(uint(id))
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceAssignment, ID: uint(id)}) {
+		return
+	}
+
+	// Siswa melihat tenggat efektif beserta perpanjangan yang berlaku untuknya
+	var assignment *models.Assignment
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		assignment, err = c.AssignmentService.GetAssignmentForStudent(uint(id), subject.UserID)
+	} else {
+		assignment, err = c.AssignmentService.GetAssignmentByID(uint(id))
+	}
+	if contentLocked(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
 		return
@@ -92,7 +147,16 @@ func (c *AssignmentController) GetAssignmentsByCourse(ctx *gin.Context) {
 		return
 	}
 
-	assignments, err := c.AssignmentService.GetAssignmentsByCourse(uint(courseID))
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceAssignment, CourseID: uint(courseID)}) {
+		return
+	}
+
+	var assignments []models.Assignment
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		assignments, err = c.AssignmentService.GetAssignmentsByCourseForStudent(uint(courseID), subject.UserID)
+	} else {
+		assignments, err = c.AssignmentService.GetAssignmentsByCourse(uint(courseID))
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get assignments"})
 		return
@@ -109,6 +173,10 @@ func (c *AssignmentController) UpdateAssignment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceAssignment, ID: uint(id)}) {
+		return
+	}
+
 	var request UpdateAssignmentRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -116,11 +184,24 @@ func (c *AssignmentController) UpdateAssignment(ctx *gin.Context) {
 	}
 
 	assignment := &models.Assignment{
-		ID:          uint(id),
-		Title:       request.Title,
-		Description: request.Description,
-		DueDate:     request.DueDate,
-		MaxScore:    request.MaxScore,
+		Availability: models.Availability{
+			PublishAt:        request.PublishAt,
+			UnpublishAt:      request.UnpublishAt,
+			ReleaseAfterDays: request.ReleaseAfterDays,
+		},
+		ID:                     uint(id),
+		Title:                  request.Title,
+		Description:            request.Description,
+		DueDate:                request.DueDate,
+		MaxScore:               request.MaxScore,
+		MaxAttempts:            request.MaxAttempts,
+		AllowLateSubmissions:   request.AllowLateSubmissions,
+		LateGracePeriodMinutes: request.LateGracePeriodMinutes,
+		LateCutoffDate:         request.LateCutoffDate,
+		LatePenaltyPerDay:      request.LatePenaltyPerDay,
+		RubricID:               request.RubricID,
+		AllowedFileTypes:       request.AllowedFileTypes,
+		MaxFileSizeMB:          request.MaxFileSizeMB,
 	}
 
 	if err := c.AssignmentService.UpdateAssignment(assignment); err != nil {
@@ -141,6 +222,10 @@ func (c *AssignmentController) DeleteAssignment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceAssignment, ID: uint(id)}) {
+		return
+	}
+
 	if err := c.AssignmentService.DeleteAssignment(uint(id)); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
 		return
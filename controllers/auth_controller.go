@@ -3,7 +3,11 @@ package controllers
 import (
 	"LMS/models"
 	"LMS/services"
+	"LMS/utils"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -35,6 +39,17 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// RefreshRequest mewakili permintaan untuk merotasi refresh token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest mewakili permintaan untuk logout
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	AllDevices   bool   `json:"all_devices"`
+}
+
 // Register handles user registration
 func (c *AuthController) Register(ctx *gin.Context) {
 	var request RegisterRequest
@@ -74,15 +89,78 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	token, err := c.AuthService.Login(request.Email, request.Password)
+	tokens, err := c.AuthService.Login(request.Email, request.Password)
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   token,
+		"message":       "Login successful",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// Refresh menangani rotasi refresh token
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var request RefreshRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := c.AuthService.Refresh(request.RefreshToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       "Token refreshed successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// Logout menangani logout pengguna dan pencabutan token
+func (c *AuthController) Logout(ctx *gin.Context) {
+	var request LogoutRequest
+	// Body bersifat opsional
+	if err := ctx.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, _ := ctx.Get("claims")
+
+	if err := c.AuthService.Logout(claims.(*utils.JWTClaims), request.RefreshToken, request.AllDevices); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Logout successful",
+	})
+}
+
+// RevokeUserTokens menangani pencabutan semua token milik pengguna oleh admin
+func (c *AuthController) RevokeUserTokens(ctx *gin.Context) {
+	userID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := c.AuthService.RevokeAllUserTokens(uint(userID)); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User tokens revoked successfully",
 	})
 }
 
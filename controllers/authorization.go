@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+)
+
+// subjectFromContext membuat subjek otorisasi dari pengguna yang diautentikasi
+func subjectFromContext(ctx *gin.Context) authz.Subject {
+	userID, _ := ctx.Get("userID")
+	role, _ := ctx.Get("role")
+
+	subject := authz.Subject{}
+	if id, ok := userID.(uint); ok {
+		subject.UserID = id
+	}
+	if r, ok := role.(models.Role); ok {
+		subject.Role = r
+	}
+	return subject
+}
+
+// authorize memeriksa kebijakan akses dan menulis respons error jika ditolak
+func authorize(ctx *gin.Context, policy *authz.Policy, action authz.Action, resource authz.Resource) bool {
+	err := policy.Can(subjectFromContext(ctx), action, resource)
+	if err == nil {
+		return true
+	}
+
+	switch {
+	case errors.Is(err, authz.ErrForbidden):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to access this resource"})
+	case errors.Is(err, authz.ErrNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return false
+}
+
+// allowed memeriksa kebijakan akses tanpa menulis respons, untuk menyaring daftar
+func allowed(ctx *gin.Context, policy *authz.Policy, action authz.Action, resource authz.Resource) bool {
+	return policy.Can(subjectFromContext(ctx), action, resource) == nil
+}
+
+// contentLocked menanggapi konten yang masih terkunci bagi siswa beserta syarat untuk membukanya
+func contentLocked(ctx *gin.Context, err error) bool {
+	var lockedErr *services.ContentLockedError
+	if !errors.As(err, &lockedErr) {
+		return false
+	}
+	ctx.JSON(http.StatusForbidden, gin.H{
+		"error": "This content is not available yet",
+		"lock":  lockedErr.Lock,
+	})
+	return true
+}
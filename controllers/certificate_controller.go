@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// CertificateController menangani permintaan sertifikat penyelesaian kursus
+type CertificateController struct {
+	CertificateService *services.CertificateService
+	Policy             *authz.Policy
+}
+
+// NewCertificateController membuat pengontrol sertifikat baru
+func NewCertificateController(certificateService *services.CertificateService, policy *authz.Policy) *CertificateController {
+	return &CertificateController{
+		CertificateService: certificateService,
+		Policy:             policy,
+	}
+}
+
+// CertificateTemplateRequest mewakili permintaan untuk menetapkan templat sertifikat kursus
+type CertificateTemplateRequest struct {
+	Title string `json:"title" binding:"required"`
+	Body  string `json:"body" binding:"required"`
+}
+
+// RevokeCertificateRequest mewakili permintaan untuk mencabut sertifikat
+type RevokeCertificateRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ClaimCertificate menangani penerbitan sertifikat untuk siswa yang sudah menyelesaikan kursus
+func (c *CertificateController) ClaimCertificate(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceCertificate, CourseID: uint(courseID), OwnerID: userID.(uint)}) {
+		return
+	}
+
+	certificate, err := c.CertificateService.Issue(userID.(uint), uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, certificate)
+}
+
+// GetMyCertificates menangani pengambilan semua sertifikat milik pengguna yang masuk
+func (c *CertificateController) GetMyCertificates(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+
+	certificates, err := c.CertificateService.GetCertificatesByUser(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get certificates"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, certificates)
+}
+
+// GetCertificateByID menangani pengambilan sertifikat berdasarkan ID
+func (c *CertificateController) GetCertificateByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid certificate ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceCertificate, ID: uint(id)}) {
+		return
+	}
+
+	certificate, err := c.CertificateService.GetCertificateByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, certificate)
+}
+
+// DownloadCertificate menangani pengunduhan sertifikat sebagai PDF
+func (c *CertificateController) DownloadCertificate(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid certificate ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceCertificate, ID: uint(id)}) {
+		return
+	}
+
+	certificate, err := c.CertificateService.GetCertificateByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if certificate.RevokedAt != nil {
+		ctx.JSON(http.StatusGone, gin.H{"error": "Certificate has been revoked"})
+		return
+	}
+
+	var buffer bytes.Buffer
+	if err := c.CertificateService.WritePDF(&buffer, certificate); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate certificate"})
+		return
+	}
+
+	filename := fmt.Sprintf("certificate-%s.pdf", certificate.Code)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, "application/pdf", buffer.Bytes())
+}
+
+// GetCertificatesByCourse menangani pengambilan semua sertifikat yang diterbitkan untuk kursus
+func (c *CertificateController) GetCertificatesByCourse(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceCertificate, CourseID: uint(courseID)}) {
+		return
+	}
+
+	certificates, err := c.CertificateService.GetCertificatesByCourse(uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get certificates"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, certificates)
+}
+
+// RevokeCertificate menangani pencabutan sertifikat oleh admin
+func (c *CertificateController) RevokeCertificate(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid certificate ID"})
+		return
+	}
+
+	var request RevokeCertificateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceCertificate, ID: uint(id)}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	if err := c.CertificateService.Revoke(uint(id), userID.(uint), request.Reason); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Certificate revoked successfully",
+	})
+}
+
+// VerifyCertificate menangani pemeriksaan publik sebuah kode verifikasi sertifikat
+func (c *CertificateController) VerifyCertificate(ctx *gin.Context) {
+	verification, err := c.CertificateService.Verify(ctx.Param("code"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Certificate not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verification)
+}
+
+// GetCertificateTemplate menangani pengambilan templat sertifikat kursus
+func (c *CertificateController) GetCertificateTemplate(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceCertificate, CourseID: uint(courseID)}) {
+		return
+	}
+
+	template, err := c.CertificateService.GetTemplate(uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// SetCertificateTemplate menangani penetapan templat sertifikat kursus
+func (c *CertificateController) SetCertificateTemplate(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	var request CertificateTemplateRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceCertificate, CourseID: uint(courseID)}) {
+		return
+	}
+
+	template := &models.CertificateTemplate{
+		CourseID: uint(courseID),
+		Title:    request.Title,
+		Body:     request.Body,
+	}
+
+	if err := c.CertificateService.SetTemplate(template); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Certificate template updated successfully",
+		"template": template,
+	})
+}
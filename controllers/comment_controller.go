@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"LMS/authz"
 	"LMS/models"
 	"LMS/services"
 	"net/http"
@@ -13,21 +14,34 @@ import (
 // CommentController menangani permintaan komentar
 type CommentController struct {
 	CommentService *services.CommentService
+	Policy         *authz.Policy
 }
 
 // NewCommentController membuat pengontrol komentar baru
-func NewCommentController(commentService *services.CommentService) *CommentController {
+func NewCommentController(commentService *services.CommentService, policy *authz.Policy) *CommentController {
 	return &CommentController{
 		CommentService: commentService,
+		Policy:         policy,
 	}
 }
 
 // CreateCommentRequest mewakili permintaan untuk membuat komentar baru
 type CreateCommentRequest struct {
 	DiscussionID uint   `json:"discussion_id" binding:"required"`
+	ParentID     *uint  `json:"parent_id"`
 	Content      string `json:"content" binding:"required"`
 }
 
+// HideCommentRequest mewakili permintaan moderator untuk menyembunyikan komentar
+type HideCommentRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ReactionRequest mewakili permintaan untuk memberi reaksi pada komentar
+type ReactionRequest struct {
+	Type models.ReactionType `json:"type" binding:"required,oneof=upvote like thanks insightful confused"`
+}
+
 // UpdateCommentRequest mewakili permintaan untuk memperbarui komentar
 type UpdateCommentRequest struct {
 	Content string `json:"content" binding:"required"`
@@ -41,11 +55,16 @@ func (c *CommentController) CreateComment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceDiscussion, ID: request.DiscussionID}) {
+		return
+	}
+
 	userID, _ := ctx.Get("userID")
 
 	comment := &models.Comment{
 		DiscussionID: request.DiscussionID,
 		UserID:       userID.(uint),
+		ParentID:     request.ParentID,
 		Content:      request.Content,
 	}
 
@@ -68,7 +87,16 @@ func (c *CommentController) GetCommentByID(ctx *gin.Context) {
 		return
 	}
 
-	comment, err := c.CommentService.GetCommentByID(uint(id))
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceComment, ID: uint(id)}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+	// Kebijakan sudah memastikan mentor yang lolos adalah pengampu kursus ini
+	role, _ := ctx.Get("role")
+	isModerator := role == models.RoleAdmin || role == models.RoleMentor
+
+	comment, err := c.CommentService.GetVisibleComment(uint(id), userID.(uint), isModerator)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
 		return
@@ -85,7 +113,16 @@ func (c *CommentController) GetCommentsByDiscussion(ctx *gin.Context) {
 		return
 	}
 
-	comments, err := c.CommentService.GetCommentsByDiscussion(uint(discussionID))
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceDiscussion, ID: uint(discussionID)}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+	// Kebijakan sudah memastikan mentor yang lolos adalah pengampu kursus ini
+	role, _ := ctx.Get("role")
+	isModerator := role == models.RoleAdmin || role == models.RoleMentor
+
+	comments, err := c.CommentService.GetCommentsByDiscussion(uint(discussionID), userID.(uint), isModerator)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments"})
 		return
@@ -94,6 +131,199 @@ func (c *CommentController) GetCommentsByDiscussion(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, comments)
 }
 
+// GetCommentTree menangani pengambilan komentar diskusi sebagai pohon balasan dengan paginasi
+func (c *CommentController) GetCommentTree(ctx *gin.Context) {
+	discussionID, err := strconv.ParseUint(ctx.Param("discussion_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid discussion ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceDiscussion, ID: uint(discussionID)}) {
+		return
+	}
+
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(services.DefaultCommentPageSize)))
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	userID, _ := ctx.Get("userID")
+	// Kebijakan sudah memastikan mentor yang lolos adalah pengampu kursus ini
+	role, _ := ctx.Get("role")
+	isModerator := role == models.RoleAdmin || role == models.RoleMentor
+
+	tree, err := c.CommentService.GetCommentTree(uint(discussionID), userID.(uint), isModerator, ctx.Query("sort"), limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tree)
+}
+
+// AddReaction menangani pemberian reaksi pada komentar
+func (c *CommentController) AddReaction(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var request ReactionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceComment, ID: uint(id)}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	counts, err := c.CommentService.AddReaction(uint(id), userID.(uint), request.Type)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":         "Reaction added successfully",
+		"reaction_counts": counts,
+	})
+}
+
+// RemoveReaction menangani penghapusan reaksi pada komentar
+func (c *CommentController) RemoveReaction(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceComment, ID: uint(id)}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	counts, err := c.CommentService.RemoveReaction(uint(id), userID.(uint), models.ReactionType(ctx.Param("type")))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":         "Reaction removed successfully",
+		"reaction_counts": counts,
+	})
+}
+
+// AcceptAnswer menangani penandaan komentar sebagai jawaban diskusi
+func (c *CommentController) AcceptAnswer(ctx *gin.Context) {
+	comment, ok := c.answerComment(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.CommentService.AcceptAnswer(comment.ID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Answer accepted successfully",
+	})
+}
+
+// UnacceptAnswer menangani pelepasan tanda jawaban dari komentar
+func (c *CommentController) UnacceptAnswer(ctx *gin.Context) {
+	comment, ok := c.answerComment(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.CommentService.UnacceptAnswer(comment.ID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Answer unaccepted successfully",
+	})
+}
+
+// HideComment menangani penyembunyian komentar oleh moderator kursus
+func (c *CommentController) HideComment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	var request HideCommentRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionModerate, authz.Resource{Type: authz.ResourceComment, ID: uint(id)}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	if err := c.CommentService.HideComment(uint(id), userID.(uint), request.Reason); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Comment hidden successfully",
+	})
+}
+
+// UnhideComment menangani penampilan kembali komentar yang disembunyikan
+func (c *CommentController) UnhideComment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionModerate, authz.Resource{Type: authz.ResourceComment, ID: uint(id)}) {
+		return
+	}
+
+	if err := c.CommentService.UnhideComment(uint(id)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Comment unhidden successfully",
+	})
+}
+
+// answerComment memuat komentar dan memastikan pengguna adalah penulis diskusi atau mentor kursus
+func (c *CommentController) answerComment(ctx *gin.Context) (*models.Comment, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return nil, false
+	}
+
+	comment, err := c.CommentService.GetCommentByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return nil, false
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionAccept, authz.Resource{Type: authz.ResourceDiscussion, ID: comment.DiscussionID}) {
+		return nil, false
+	}
+
+	return comment, true
+}
+
 // UpdateComment menangani pembaruan komentar
 func (c *CommentController) UpdateComment(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
@@ -102,6 +332,10 @@ func (c *CommentController) UpdateComment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceComment, ID: uint(id)}) {
+		return
+	}
+
 	var request UpdateCommentRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -134,11 +368,16 @@ func (c *CommentController) DeleteComment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceComment, ID: uint(id)}) {
+		return
+	}
+
 	userID, _ := ctx.Get("userID")
+	// Kebijakan sudah memastikan mentor yang lolos adalah pengampu kursus ini
 	role, _ := ctx.Get("role")
-	isAdmin := role == models.RoleAdmin
+	isModerator := role == models.RoleAdmin || role == models.RoleMentor
 
-	if err := c.CommentService.DeleteComment(uint(id), userID.(uint), isAdmin); err != nil {
+	if err := c.CommentService.DeleteComment(uint(id), userID.(uint), isModerator); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
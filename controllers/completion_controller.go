@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// CompletionController menangani permintaan aturan penyelesaian kursus dan dasbor siswa
+type CompletionController struct {
+	CompletionService *services.CompletionService
+	Policy            *authz.Policy
+}
+
+// NewCompletionController membuat pengontrol penyelesaian kursus baru
+func NewCompletionController(completionService *services.CompletionService, policy *authz.Policy) *CompletionController {
+	return &CompletionController{
+		CompletionService: completionService,
+		Policy:            policy,
+	}
+}
+
+// CompletionActivityRequest mewakili satu aktivitas wajib pada aturan penyelesaian
+type CompletionActivityRequest struct {
+	ActivityType models.ProgressType `json:"activity_type" binding:"required,oneof=material assignment quiz discussion"`
+	ActivityID   uint                `json:"activity_id" binding:"required"`
+}
+
+// CompletionRuleRequest mewakili permintaan untuk menetapkan aturan penyelesaian kursus
+type CompletionRuleRequest struct {
+	MinPercentComplete float64                     `json:"min_percent_complete"`
+	MinFinalPercent    *float64                    `json:"min_final_percent"`
+	Activities         []CompletionActivityRequest `json:"activities" binding:"dive"`
+}
+
+// GetCompletionRule menangani pengambilan aturan penyelesaian kursus
+func (c *CompletionController) GetCompletionRule(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceGradebook, CourseID: uint(courseID)}) {
+		return
+	}
+
+	rule, err := c.CompletionService.GetRule(uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rule)
+}
+
+// SetCompletionRule menangani penetapan aturan penyelesaian kursus
+func (c *CompletionController) SetCompletionRule(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	var request CompletionRuleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceGradebook, CourseID: uint(courseID)}) {
+		return
+	}
+
+	rule := &models.CompletionRule{
+		CourseID:           uint(courseID),
+		MinPercentComplete: request.MinPercentComplete,
+		MinFinalPercent:    request.MinFinalPercent,
+		Activities:         make([]models.CompletionActivity, 0, len(request.Activities)),
+	}
+	for _, activity := range request.Activities {
+		rule.Activities = append(rule.Activities, models.CompletionActivity{
+			ActivityType: activity.ActivityType,
+			ActivityID:   activity.ActivityID,
+		})
+	}
+
+	if err := c.CompletionService.SetRule(rule); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Completion rule updated successfully",
+		"rule":    rule,
+	})
+}
+
+// GetStudentCompletion menangani pengambilan kemajuan penyelesaian kursus seorang siswa
+func (c *CompletionController) GetStudentCompletion(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	studentID, err := strconv.ParseUint(ctx.Param("student_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceProgress, CourseID: uint(courseID), OwnerID: uint(studentID)}) {
+		return
+	}
+
+	completion, err := c.CompletionService.GetStudentCompletion(uint(courseID), uint(studentID))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, completion)
+}
+
+// GetCourseCompletions menangani pengambilan kemajuan penyelesaian semua siswa dalam kursus
+func (c *CompletionController) GetCourseCompletions(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceProgress, CourseID: uint(courseID)}) {
+		return
+	}
+
+	completions, err := c.CompletionService.GetCourseCompletions(uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, completions)
+}
+
+// GetDashboard menangani pengambilan ringkasan kemajuan siswa di semua kursus yang diikutinya
+func (c *CompletionController) GetDashboard(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+
+	dashboard, err := c.CompletionService.GetDashboard(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get dashboard"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"courses": dashboard,
+	})
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"LMS/authz"
 	"LMS/models"
 	services "LMS/services"
 	"net/http"
@@ -13,26 +14,30 @@ import (
 // CourseController menangani permintaan kursus
 type CourseController struct {
 	CourseService *services.CourseService
+	Policy        *authz.Policy
 }
 
 // NewCourseController membuat pengontrol kursus baru
-func NewCourseController(courseService *services.CourseService) *CourseController {
+func NewCourseController(courseService *services.CourseService, policy *authz.Policy) *CourseController {
 	return &CourseController{
 		CourseService: courseService,
+		Policy:        policy,
 	}
 }
 
 // CreateCourseRequest mewakili permintaan untuk membuat kursus baru
 type CreateCourseRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description"`
-	MentorID    uint   `json:"mentor_id" binding:"required"`
+	Title            string `json:"title" binding:"required"`
+	Description      string `json:"description"`
+	MentorID         uint   `json:"mentor_id" binding:"required"`
+	AllowedFileTypes string `json:"allowed_file_types"`
 }
 
 // UpdateCourseRequest mewakili permintaan untuk memperbarui kursus
 type UpdateCourseRequest struct {
-	Title       string `json:"title" binding:"required"`
-	Description string `json:"description"`
+	Title            string `json:"title" binding:"required"`
+	Description      string `json:"description"`
+	AllowedFileTypes string `json:"allowed_file_types"`
 }
 
 // CreateCourse menangani pembuatan kursus
@@ -43,10 +48,15 @@ func (c *CourseController) CreateCourse(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceCourse, OwnerID: request.MentorID}) {
+		return
+	}
+
 	course := &models.Course{
-		Title:       request.Title,
-		Description: request.Description,
-		MentorID:    request.MentorID,
+		Title:            request.Title,
+		Description:      request.Description,
+		MentorID:         request.MentorID,
+		AllowedFileTypes: request.AllowedFileTypes,
 	}
 
 	if err := c.CourseService.CreateCourse(course); err != nil {
@@ -68,7 +78,12 @@ func (c *CourseController) GetCourseByID(ctx *gin.Context) {
 		return
 	}
 
-	course, err := c.CourseService.GetCourseByID(uint(id))
+	var course *models.Course
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		course, err = c.CourseService.GetCourseForStudent(uint(id), subject.UserID)
+	} else {
+		course, err = c.CourseService.GetCourseByID(uint(id))
+	}
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
 		return
@@ -85,6 +100,10 @@ func (c *CourseController) UpdateCourse(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceCourse, ID: uint(id)}) {
+		return
+	}
+
 	var request UpdateCourseRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -92,9 +111,10 @@ func (c *CourseController) UpdateCourse(ctx *gin.Context) {
 	}
 
 	course := &models.Course{
-		ID:          uint(id),
-		Title:       request.Title,
-		Description: request.Description,
+		ID:               uint(id),
+		Title:            request.Title,
+		Description:      request.Description,
+		AllowedFileTypes: request.AllowedFileTypes,
 	}
 
 	if err := c.CourseService.UpdateCourse(course); err != nil {
@@ -115,6 +135,10 @@ func (c *CourseController) DeleteCourse(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceCourse, ID: uint(id)}) {
+		return
+	}
+
 	if err := c.CourseService.DeleteCourse(uint(id)); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
 		return
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"LMS/authz"
 	"LMS/models"
 	services "LMS/services"
 	"net/http"
@@ -13,12 +14,14 @@ import (
 // DiscussionController menangani permintaan diskusi
 type DiscussionController struct {
 	DiscussionService *services.DiscussionService
+	Policy            *authz.Policy
 }
 
 // NewDiscussionController membuat pengontrol diskusi baru
-func NewDiscussionController(discussionService *services.DiscussionService) *DiscussionController {
+func NewDiscussionController(discussionService *services.DiscussionService, policy *authz.Policy) *DiscussionController {
 	return &DiscussionController{
 		DiscussionService: discussionService,
+		Policy:            policy,
 	}
 }
 
@@ -43,6 +46,10 @@ func (c *DiscussionController) CreateDiscussion(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceDiscussion, CourseID: request.CourseID}) {
+		return
+	}
+
 	userID, _ := ctx.Get("userID")
 
 	discussion := &models.Discussion{
@@ -71,6 +78,10 @@ func (c *DiscussionController) GetDiscussionByID(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceDiscussion, ID: uint(id)}) {
+		return
+	}
+
 	discussion, err := c.DiscussionService.GetDiscussionByID(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Discussion not found"})
@@ -88,6 +99,10 @@ func (c *DiscussionController) GetDiscussionsByCourse(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceDiscussion, CourseID: uint(courseID)}) {
+		return
+	}
+
 	discussions, err := c.DiscussionService.GetDiscussionsByCourse(uint(courseID))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get discussions"})
@@ -105,6 +120,10 @@ func (c *DiscussionController) UpdateDiscussion(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceDiscussion, ID: uint(id)}) {
+		return
+	}
+
 	var request UpdateDiscussionRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -130,6 +149,58 @@ func (c *DiscussionController) UpdateDiscussion(ctx *gin.Context) {
 	})
 }
 
+// PinDiscussion menangani penyematan diskusi di urutan teratas forum kursus
+func (c *DiscussionController) PinDiscussion(ctx *gin.Context) {
+	c.moderate(ctx, "Discussion pinned successfully", func(id, _ uint) error {
+		return c.DiscussionService.PinDiscussion(id, true)
+	})
+}
+
+// UnpinDiscussion menangani pelepasan sematan diskusi
+func (c *DiscussionController) UnpinDiscussion(ctx *gin.Context) {
+	c.moderate(ctx, "Discussion unpinned successfully", func(id, _ uint) error {
+		return c.DiscussionService.PinDiscussion(id, false)
+	})
+}
+
+// LockDiscussion menangani penguncian diskusi dari komentar baru
+func (c *DiscussionController) LockDiscussion(ctx *gin.Context) {
+	c.moderate(ctx, "Discussion locked successfully", func(id, moderatorID uint) error {
+		return c.DiscussionService.LockDiscussion(id, true, moderatorID)
+	})
+}
+
+// UnlockDiscussion menangani pembukaan kembali diskusi yang dikunci
+func (c *DiscussionController) UnlockDiscussion(ctx *gin.Context) {
+	c.moderate(ctx, "Discussion unlocked successfully", func(id, moderatorID uint) error {
+		return c.DiscussionService.LockDiscussion(id, false, moderatorID)
+	})
+}
+
+// moderate memeriksa hak moderasi atas diskusi lalu menjalankan tindakan moderator
+func (c *DiscussionController) moderate(ctx *gin.Context, message string, action func(id, moderatorID uint) error) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid discussion ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionModerate, authz.Resource{Type: authz.ResourceDiscussion, ID: uint(id)}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	if err := action(uint(id), userID.(uint)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+	})
+}
+
 // DeleteDiscussion menangani penghapusan diskusi
 func (c *DiscussionController) DeleteDiscussion(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
@@ -138,11 +209,16 @@ func (c *DiscussionController) DeleteDiscussion(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceDiscussion, ID: uint(id)}) {
+		return
+	}
+
 	userID, _ := ctx.Get("userID")
+	// Kebijakan sudah memastikan mentor yang lolos adalah pengampu kursus ini
 	role, _ := ctx.Get("role")
-	isAdmin := role == models.RoleAdmin
+	isModerator := role == models.RoleAdmin || role == models.RoleMentor
 
-	if err := c.DiscussionService.DeleteDiscussion(uint(id), userID.(uint), isAdmin); err != nil {
+	if err := c.DiscussionService.DeleteDiscussion(uint(id), userID.(uint), isModerator); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
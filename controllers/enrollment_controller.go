@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"LMS/authz"
 	"LMS/models"
 	services "LMS/services"
 	"net/http"
@@ -13,12 +14,14 @@ import (
 // EnrollmentController menangani permintaan pendaftaran
 type EnrollmentController struct {
 	EnrollmentService *services.EnrollmentService
+	Policy            *authz.Policy
 }
 
 // NewEnrollmentController membuat pengontrol pendaftaran baru
-func NewEnrollmentController(enrollmentService *services.EnrollmentService) *EnrollmentController {
+func NewEnrollmentController(enrollmentService *services.EnrollmentService, policy *authz.Policy) *EnrollmentController {
 	return &EnrollmentController{
 		EnrollmentService: enrollmentService,
+		Policy:            policy,
 	}
 }
 
@@ -36,6 +39,10 @@ func (c *EnrollmentController) CreateEnrollment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceEnrollment, CourseID: request.CourseID, OwnerID: request.UserID}) {
+		return
+	}
+
 	enrollment := &models.Enrollment{
 		UserID:   request.UserID,
 		CourseID: request.CourseID,
@@ -60,6 +67,10 @@ func (c *EnrollmentController) GetEnrollmentByID(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceEnrollment, ID: uint(id)}) {
+		return
+	}
+
 	enrollment, err := c.EnrollmentService.GetEnrollmentByID(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Enrollment not found"})
@@ -83,7 +94,15 @@ func (c *EnrollmentController) GetEnrollmentsByUser(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, enrollments)
+	// Hanya tampilkan pendaftaran yang boleh dilihat pemohon
+	visible := make([]models.Enrollment, 0, len(enrollments))
+	for _, enrollment := range enrollments {
+		if allowed(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceEnrollment, ID: enrollment.ID}) {
+			visible = append(visible, enrollment)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, visible)
 }
 
 // GetEnrollmentsByCourse menangani pendaftaran berdasarkan ID kursus
@@ -94,6 +113,10 @@ func (c *EnrollmentController) GetEnrollmentsByCourse(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceEnrollment, CourseID: uint(courseID)}) {
+		return
+	}
+
 	enrollments, err := c.EnrollmentService.GetEnrollmentsByCourse(uint(courseID))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get enrollments"})
@@ -111,6 +134,10 @@ func (c *EnrollmentController) DeleteEnrollment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceEnrollment, ID: uint(id)}) {
+		return
+	}
+
 	if err := c.EnrollmentService.DeleteEnrollment(uint(id)); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Enrollment not found"})
 		return
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// ExtensionController menangani permintaan perpanjangan tenggat
+type ExtensionController struct {
+	ExtensionService *services.ExtensionService
+	Policy           *authz.Policy
+}
+
+// NewExtensionController membuat pengontrol perpanjangan baru
+func NewExtensionController(extensionService *services.ExtensionService, policy *authz.Policy) *ExtensionController {
+	return &ExtensionController{
+		ExtensionService: extensionService,
+		Policy:           policy,
+	}
+}
+
+// GrantExtensionRequest mewakili permintaan untuk memberikan perpanjangan kepada satu atau beberapa siswa
+type GrantExtensionRequest struct {
+	ActivityType          models.ExtensionActivityType `json:"activity_type" binding:"required,oneof=assignment quiz"`
+	ActivityID            uint                         `json:"activity_id" binding:"required"`
+	StudentIDs            []uint                       `json:"student_ids" binding:"required,min=1"`
+	NewDueDate            *time.Time                   `json:"new_due_date"`
+	ExtraMinutes          int                          `json:"extra_minutes"`
+	ExtraTimeLimitMinutes int                          `json:"extra_time_limit_minutes"`
+	Reason                string                       `json:"reason" binding:"required"`
+}
+
+// GrantExtension menangani pemberian perpanjangan tenggat
+func (c *ExtensionController) GrantExtension(ctx *gin.Context) {
+	var request GrantExtensionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionGrade, activityResource(request.ActivityType, request.ActivityID)) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	extensions, err := c.ExtensionService.GrantExtensions(&models.DueDateExtension{
+		ActivityType:          request.ActivityType,
+		ActivityID:            request.ActivityID,
+		NewDueDate:            request.NewDueDate,
+		ExtraMinutes:          request.ExtraMinutes,
+		ExtraTimeLimitMinutes: request.ExtraTimeLimitMinutes,
+		Reason:                request.Reason,
+		GrantedByID:           userID.(uint),
+	}, request.StudentIDs)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":    "Extensions granted successfully",
+		"extensions": extensions,
+	})
+}
+
+// GetExtensionByID menangani pengambilan perpanjangan berdasarkan ID
+func (c *ExtensionController) GetExtensionByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid extension ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceExtension, ID: uint(id)}) {
+		return
+	}
+
+	extension, err := c.ExtensionService.GetExtensionByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Extension not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, extension)
+}
+
+// GetExtensionsByActivity menangani pengambilan riwayat perpanjangan sebuah tugas atau kuis
+func (c *ExtensionController) GetExtensionsByActivity(ctx *gin.Context) {
+	activityType := models.ExtensionActivityType(ctx.Param("activity_type"))
+	if activityType != models.ExtensionActivityAssignment && activityType != models.ExtensionActivityQuiz {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity type"})
+		return
+	}
+
+	activityID, err := strconv.ParseUint(ctx.Param("activity_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionGrade, activityResource(activityType, uint(activityID))) {
+		return
+	}
+
+	extensions, err := c.ExtensionService.GetExtensionsByActivity(activityType, uint(activityID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, extensions)
+}
+
+// GetStudentExtensions menangani pengambilan riwayat perpanjangan siswa dalam sebuah kursus
+func (c *ExtensionController) GetStudentExtensions(ctx *gin.Context) {
+	studentID, err := strconv.ParseUint(ctx.Param("student_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceExtension, CourseID: uint(courseID), OwnerID: uint(studentID)}) {
+		return
+	}
+
+	extensions, err := c.ExtensionService.GetStudentExtensions(uint(studentID), uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get extensions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, extensions)
+}
+
+// RevokeExtension menangani pencabutan perpanjangan
+func (c *ExtensionController) RevokeExtension(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid extension ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceExtension, ID: uint(id)}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	if err := c.ExtensionService.RevokeExtension(uint(id), userID.(uint)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Extension revoked successfully",
+	})
+}
+
+// activityResource memetakan aktivitas perpanjangan ke sumber daya kebijakan
+func activityResource(activityType models.ExtensionActivityType, activityID uint) authz.Resource {
+	if activityType == models.ExtensionActivityQuiz {
+		return authz.Resource{Type: authz.ResourceQuiz, ID: activityID}
+	}
+	return authz.Resource{Type: authz.ResourceAssignment, ID: activityID}
+}
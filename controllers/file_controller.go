@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"LMS/models"
+	services "LMS/services"
+	"LMS/storage"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// FileController melayani unduhan berkas lokal melalui URL bertanda tangan
+type FileController struct {
+	Storage         storage.Backend
+	BlobService     *services.BlobService
+	MaterialService *services.MaterialService
+}
+
+// NewFileController membuat pengontrol berkas baru
+func NewFileController(store storage.Backend, blobService *services.BlobService, materialService *services.MaterialService) *FileController {
+	return &FileController{
+		Storage:         store,
+		BlobService:     blobService,
+		MaterialService: materialService,
+	}
+}
+
+// DownloadSignedFile menangani unduhan berkas dari URL sementara penyimpanan lokal
+func (c *FileController) DownloadSignedFile(ctx *gin.Context) {
+	// URL bertanda tangan hanya diterbitkan oleh penyimpanan lokal; S3 melayani unduhannya sendiri
+	local, ok := c.Storage.(*storage.LocalBackend)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	query := ctx.Request.URL.Query()
+	if err := local.VerifySignature(key, query); err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Isi blob diverifikasi dan diberi jenis isi yang tercatat; URL ini juga dipakai sebagai sumber video
+	hash, _ := models.BlobHashFromKey(key)
+	content, object, err := c.BlobService.OpenSeekable(hash, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer content.Close()
+
+	// Unduhan materi oleh siswa dihitung saat URL dibuka; permintaan Range adalah lanjutan unduhan yang sama
+	if ctx.Request.Method == http.MethodGet && ctx.GetHeader("Range") == "" {
+		c.MaterialService.RecordSignedDownload(query)
+	}
+
+	streamFile(ctx, content, object, path.Base(key), "attachment")
+}
+
+// uploadFailed menanggapi kesalahan validasi unggahan dengan daftar kesalahan terstruktur;
+// mengembalikan false jika err bukan kesalahan unggahan
+func uploadFailed(ctx *gin.Context, err error) bool {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large"})
+		return true
+	}
+
+	var validationErr *services.UploadValidationError
+	if errors.As(err, &validationErr) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "File validation failed",
+			"details": validationErr.Errors,
+		})
+		return true
+	}
+	return false
+}
+
+// formFiles mengambil berkas unggahan dari kolom "file" dan "files" formulir multipart;
+// permintaan tanpa formulir multipart tidak memiliki berkas
+func formFiles(ctx *gin.Context) []*multipart.FileHeader {
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		return nil
+	}
+	return append(form.File["file"], form.File["files"]...)
+}
+
+// sendFile mengalirkan isi berkas dari awal sampai akhir dengan nama tertentu;
+// disposition bernilai "inline" atau "attachment"
+func sendFile(ctx *gin.Context, reader io.Reader, object *storage.Object, filename, disposition string) {
+	contentType := object.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, filename))
+	if object.Size >= 0 {
+		ctx.Header("Content-Length", strconv.FormatInt(object.Size, 10))
+	}
+	if object.ETag != "" {
+		ctx.Header("ETag", `"`+object.ETag+`"`)
+	}
+
+	ctx.Status(http.StatusOK)
+	if _, err := io.Copy(ctx.Writer, reader); err != nil {
+		// Respons sudah dikirim sebagian; penerima melihat isi yang terpotong
+		log.Printf("failed to send file %s: %v", object.Key, err)
+	}
+}
+
+// streamFile melayani isi berkas dengan dukungan Range, If-Range, ETag dan Last-Modified.
+// disposition bernilai "inline" agar diputar di peramban atau "attachment" agar diunduh.
+func streamFile(ctx *gin.Context, content io.ReadSeeker, object *storage.Object, filename, disposition string) {
+	contentType := object.ContentType
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+	}
+
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, filename))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Cache-Control", "private, no-cache")
+	if object.ETag != "" {
+		ctx.Header("ETag", `"`+object.ETag+`"`)
+	}
+
+	// ServeContent menangani Range, If-Range, If-None-Match dan If-Modified-Since
+	http.ServeContent(ctx.Writer, ctx.Request, filename, object.LastModified, content)
+}
+
+// downloadExtension mengambil ekstensi berkas dari nama unggahan asli, atau dari jalur untuk berkas lama
+func downloadExtension(fileName, filePath string) string {
+	if fileName != "" {
+		return filepath.Ext(fileName)
+	}
+	return filepath.Ext(filePath)
+}
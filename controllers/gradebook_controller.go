@@ -0,0 +1,369 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"LMS/utils"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// GradebookController menangani permintaan buku nilai
+type GradebookController struct {
+	GradebookService *services.GradebookService
+	Policy           *authz.Policy
+}
+
+// NewGradebookController membuat pengontrol buku nilai baru
+func NewGradebookController(gradebookService *services.GradebookService, policy *authz.Policy) *GradebookController {
+	return &GradebookController{
+		GradebookService: gradebookService,
+		Policy:           policy,
+	}
+}
+
+// GradeCategoryRequest mewakili permintaan untuk membuat atau memperbarui kategori nilai
+type GradeCategoryRequest struct {
+	Name         string              `json:"name" binding:"required"`
+	ActivityType models.ProgressType `json:"activity_type" binding:"required,oneof=material assignment quiz discussion"`
+	Weight       float64             `json:"weight" binding:"required"`
+	DropLowest   int                 `json:"drop_lowest"`
+}
+
+// LetterGradeRequest mewakili satu batas nilai huruf pada skala nilai
+type LetterGradeRequest struct {
+	Letter     string  `json:"letter" binding:"required"`
+	MinPercent float64 `json:"min_percent"`
+}
+
+// GetCategories menangani pengambilan kategori nilai kursus
+func (c *GradebookController) GetCategories(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceGradebook, CourseID: uint(courseID)}) {
+		return
+	}
+
+	categories, err := c.GradebookService.GetCategories(uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, categories)
+}
+
+// CreateCategory menangani pembuatan kategori nilai
+func (c *GradebookController) CreateCategory(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	var request GradeCategoryRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceGradebook, CourseID: uint(courseID)}) {
+		return
+	}
+
+	category := models.GradeCategory{
+		CourseID:     uint(courseID),
+		Name:         request.Name,
+		ActivityType: request.ActivityType,
+		Weight:       request.Weight,
+		DropLowest:   request.DropLowest,
+	}
+
+	if err := c.GradebookService.CreateCategory(&category); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":  "Grade category created successfully",
+		"category": category,
+	})
+}
+
+// UpdateCategory menangani pembaruan kategori nilai
+func (c *GradebookController) UpdateCategory(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	var request GradeCategoryRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	existingCategory, err := c.GradebookService.GetCategoryByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Grade category not found"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceGradebook, CourseID: existingCategory.CourseID}) {
+		return
+	}
+
+	category := models.GradeCategory{
+		ID:           uint(id),
+		Name:         request.Name,
+		ActivityType: request.ActivityType,
+		Weight:       request.Weight,
+		DropLowest:   request.DropLowest,
+	}
+
+	if err := c.GradebookService.UpdateCategory(&category); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Grade category updated successfully",
+	})
+}
+
+// DeleteCategory menangani penghapusan kategori nilai
+func (c *GradebookController) DeleteCategory(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	category, err := c.GradebookService.GetCategoryByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Grade category not found"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceGradebook, CourseID: category.CourseID}) {
+		return
+	}
+
+	if err := c.GradebookService.DeleteCategory(uint(id)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete grade category"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Grade category deleted successfully",
+	})
+}
+
+// GetLetterGrades menangani pengambilan skala nilai huruf kursus
+func (c *GradebookController) GetLetterGrades(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceGradebook, CourseID: uint(courseID)}) {
+		return
+	}
+
+	grades, isDefault, err := c.GradebookService.GetLetterGrades(uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"scale":      grades,
+		"is_default": isDefault,
+	})
+}
+
+// SetLetterGrades menangani penggantian skala nilai huruf kursus
+func (c *GradebookController) SetLetterGrades(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	var request []LetterGradeRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceGradebook, CourseID: uint(courseID)}) {
+		return
+	}
+
+	grades := make([]models.LetterGrade, 0, len(request))
+	for _, grade := range request {
+		grades = append(grades, models.LetterGrade{
+			Letter:     grade.Letter,
+			MinPercent: grade.MinPercent,
+		})
+	}
+
+	if err := c.GradebookService.SetLetterGrades(uint(courseID), grades); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Letter grade scale updated successfully",
+	})
+}
+
+// GetCourseGradebook menangani pengambilan matriks nilai seluruh siswa dalam kursus
+func (c *GradebookController) GetCourseGradebook(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionGrade, authz.Resource{Type: authz.ResourceGradebook, CourseID: uint(courseID)}) {
+		return
+	}
+
+	gradebook, err := c.GradebookService.GetCourseGradebook(uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gradebook)
+}
+
+// GetStudentGrade menangani pengambilan nilai akhir berbobot seorang siswa
+func (c *GradebookController) GetStudentGrade(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	studentID, err := strconv.ParseUint(ctx.Param("student_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceProgress, CourseID: uint(courseID), OwnerID: uint(studentID)}) {
+		return
+	}
+
+	grade, err := c.GradebookService.GetStudentGrade(uint(courseID), uint(studentID))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, grade)
+}
+
+// ExportGradebook menangani ekspor buku nilai kursus sebagai CSV atau XLSX
+func (c *GradebookController) ExportGradebook(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Format must be csv or xlsx"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionGrade, authz.Resource{Type: authz.ResourceGradebook, CourseID: uint(courseID)}) {
+		return
+	}
+
+	rows, err := c.GradebookService.ExportGradebook(uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var buffer bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = utils.WriteXLSX(&buffer, "Gradebook", rows)
+	} else {
+		err = utils.WriteCSV(&buffer, rows)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export gradebook"})
+		return
+	}
+
+	filename := fmt.Sprintf("gradebook-course-%d.%s", courseID, format)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(http.StatusOK, contentType, buffer.Bytes())
+}
+
+// ImportGradebook menangani impor nilai dan umpan balik dari berkas CSV
+func (c *GradebookController) ImportGradebook(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run value"})
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionGrade, authz.Resource{Type: authz.ResourceGradebook, CourseID: uint(courseID)}) {
+		return
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer reader.Close()
+
+	rows, err := utils.ReadCSV(reader)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV file: " + err.Error()})
+		return
+	}
+
+	graderID, _ := ctx.Get("userID")
+
+	result, err := c.GradebookService.ImportGradebook(uint(courseID), graderID.(uint), rows, dryRun)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -1,12 +1,18 @@
 package controllers
 
 import (
+	"LMS/authz"
 	"LMS/models"
 	services "LMS/services"
+	"LMS/storage"
+	"LMS/utils"
+	"archive/zip"
 	"fmt"
+	"io"
 	"net/http"
-	"path/filepath"
+	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -16,59 +22,82 @@ import (
 // MaterialController menangani permintaan material
 type MaterialController struct {
 	MaterialService *services.MaterialService
+	Policy          *authz.Policy
 }
 
 // NewMaterialController membuat pengontrol material baru
-func NewMaterialController(materialService *services.MaterialService) *MaterialController {
+func NewMaterialController(materialService *services.MaterialService, policy *authz.Policy) *MaterialController {
 	return &MaterialController{
 		MaterialService: materialService,
+		Policy:          policy,
 	}
 }
 
-// CreateMaterialRequest mewakili permintaan untuk membuat material baru
+// CreateMaterialRequest mewakili permintaan untuk membuat material baru, sebagai formulir multipart
+// untuk materi berkas dan bundel atau JSON untuk halaman, tautan dan video
 type CreateMaterialRequest struct {
-	CourseID uint   `form:"course_id" binding:"required"`
-	Title    string `form:"title" binding:"required"`
+	CourseID      uint   `form:"course_id" json:"course_id" binding:"required"`
+	Title         string `form:"title" json:"title" binding:"required"`
+	Type          string `form:"type" json:"type"`
+	ContentFormat string `form:"content_format" json:"content_format"`
+	Content       string `form:"content" json:"content"`
+	URL           string `form:"url" json:"url"`
+
+	PublishAt        *time.Time `form:"publish_at" json:"publish_at"`
+	UnpublishAt      *time.Time `form:"unpublish_at" json:"unpublish_at"`
+	ReleaseAfterDays *int       `form:"release_after_days" json:"release_after_days"`
 }
 
 // UpdateMaterialRequest mewakili permintaan untuk memperbarui material
 type UpdateMaterialRequest struct {
-	Title string `form:"title" binding:"required"`
+	Title         string `form:"title" json:"title" binding:"required"`
+	ContentFormat string `form:"content_format" json:"content_format"`
+	Content       string `form:"content" json:"content"`
+	URL           string `form:"url" json:"url"`
+
+	PublishAt        *time.Time `form:"publish_at" json:"publish_at"`
+	UnpublishAt      *time.Time `form:"unpublish_at" json:"unpublish_at"`
+	ReleaseAfterDays *int       `form:"release_after_days" json:"release_after_days"`
+}
+
+// MarkMaterialViewedRequest mewakili laporan tayangan materi dari klien
+type MarkMaterialViewedRequest struct {
+	SecondsSpent int      `json:"seconds_spent"`
+	WatchPercent *float64 `json:"watch_percent"`
 }
 
 // CreateMaterial menangani pembuatan material
 func (c *MaterialController) CreateMaterial(ctx *gin.Context) {
 	var request CreateMaterialRequest
 	if err := ctx.ShouldBind(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Dapatkan file dari formulir
-	file, err := ctx.FormFile("file")
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		if !uploadFailed(ctx, err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	// Hasilkan nama file yang unik
-	extension := filepath.Ext(file.Filename)
-	filename := fmt.Sprintf("material_%d_%s%s", request.CourseID, time.Now().Format("20060102150405"), extension)
-	filePath := filepath.Join("uploads/materials", filename)
-
-	// Simpan file ke disk
-	if err := ctx.SaveUploadedFile(file, filePath); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceMaterial, CourseID: request.CourseID}) {
 		return
 	}
 
 	material := &models.Material{
-		CourseID: request.CourseID,
-		Title:    request.Title,
+		Availability: models.Availability{
+			PublishAt:        request.PublishAt,
+			UnpublishAt:      request.UnpublishAt,
+			ReleaseAfterDays: request.ReleaseAfterDays,
+		},
+		CourseID:      request.CourseID,
+		Title:         request.Title,
+		Type:          request.Type,
+		ContentFormat: request.ContentFormat,
+		Content:       request.Content,
+		URL:           request.URL,
 	}
 
-	if err := c.MaterialService.CreateMaterial(material, filePath); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.MaterialService.CreateMaterial(material, formFiles(ctx)); err != nil {
+		if !uploadFailed(ctx, err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -86,7 +115,16 @@ func (c *MaterialController) GetMaterialByID(ctx *gin.Context) {
 		return
 	}
 
-	material, err := c.MaterialService.GetMaterialByID(uint(id))
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
+	var material *models.Material
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		material, err = c.MaterialService.GetMaterialForStudent(uint(id), subject.UserID)
+	} else {
+		material, err = c.MaterialService.GetMaterialByID(uint(id))
+	}
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Material not found"})
 		return
@@ -103,7 +141,16 @@ func (c *MaterialController) GetMaterialsByCourse(ctx *gin.Context) {
 		return
 	}
 
-	materials, err := c.MaterialService.GetMaterialsByCourse(uint(courseID))
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceMaterial, CourseID: uint(courseID)}) {
+		return
+	}
+
+	var materials []models.Material
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		materials, err = c.MaterialService.GetMaterialsByCourseForStudent(uint(courseID), subject.UserID)
+	} else {
+		materials, err = c.MaterialService.GetMaterialsByCourse(uint(courseID))
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get materials"})
 		return
@@ -120,34 +167,41 @@ func (c *MaterialController) UpdateMaterial(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
 	var request UpdateMaterialRequest
 	if err := ctx.ShouldBind(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !uploadFailed(ctx, err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
 	material := &models.Material{
-		ID:    uint(id),
-		Title: request.Title,
+		Availability: models.Availability{
+			PublishAt:        request.PublishAt,
+			UnpublishAt:      request.UnpublishAt,
+			ReleaseAfterDays: request.ReleaseAfterDays,
+		},
+		ID:            uint(id),
+		Title:         request.Title,
+		ContentFormat: request.ContentFormat,
+		Content:       request.Content,
+		URL:           request.URL,
 	}
 
-	filePath := ""
-	file, err := ctx.FormFile("file")
-	if err == nil {
-		// Hasilkan nama file yang unik
-		extension := filepath.Ext(file.Filename)
-		filename := fmt.Sprintf("material_%d_%s%s", id, time.Now().Format("20060102150405"), extension)
-		filePath = filepath.Join("uploads/materials", filename)
-
-		// Simpan file ke disk
-		if err := ctx.SaveUploadedFile(file, filePath); err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+	// Berkas bersifat opsional saat memperbarui
+	if err := c.MaterialService.UpdateMaterial(material, formFiles(ctx)); err != nil {
+		if uploadFailed(ctx, err) {
 			return
 		}
-	}
-
-	if err := c.MaterialService.UpdateMaterial(material, filePath); err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if err.Error() == "material not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -164,6 +218,10 @@ func (c *MaterialController) DeleteMaterial(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
 	if err := c.MaterialService.DeleteMaterial(uint(id)); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Material not found"})
 		return
@@ -174,6 +232,34 @@ func (c *MaterialController) DeleteMaterial(ctx *gin.Context) {
 	})
 }
 
+// openableMaterial memuat materi yang isinya akan dibuka; siswa ditolak jika materi masih terkunci
+func (c *MaterialController) openableMaterial(ctx *gin.Context, id uint) (*models.Material, bool) {
+	material, err := c.MaterialService.GetMaterialByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Material not found"})
+		return nil, false
+	}
+
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		if err := c.MaterialService.CheckAccess(material, subject.UserID); err != nil {
+			if !contentLocked(ctx, err) {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check material availability"})
+			}
+			return nil, false
+		}
+	}
+	return material, true
+}
+
+// recordDownload mencatat unduhan siswa di kemajuan belajarnya; permintaan Range lanjutan tidak dihitung ulang
+func (c *MaterialController) recordDownload(ctx *gin.Context, material *models.Material) {
+	subject := subjectFromContext(ctx)
+	if subject.Role != models.RoleStudent || ctx.GetHeader("Range") != "" {
+		return
+	}
+	c.MaterialService.RecordDownload(material, subject.UserID)
+}
+
 // DownloadMaterial menangani pengunduhan material
 func (c *MaterialController) DownloadMaterial(ctx *gin.Context) {
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
@@ -182,13 +268,270 @@ func (c *MaterialController) DownloadMaterial(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
+	material, ok := c.openableMaterial(ctx, uint(id))
+	if !ok {
+		return
+	}
+
+	content, object, err := c.MaterialService.OpenMaterialStream(material)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	defer content.Close()
+
+	c.recordDownload(ctx, material)
+
+	// Tetapkan nama file untuk diunduh; unduhan yang terputus dapat dilanjutkan dengan Range
+	filename := fmt.Sprintf("%s%s", material.Title, downloadExtension(material.FileName, material.FilePath))
+	streamFile(ctx, content, object, filename, "attachment")
+}
+
+// StreamMaterial menangani pemutaran berkas materi di peramban dengan dukungan Range agar video dapat dilompati
+func (c *MaterialController) StreamMaterial(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
+	material, ok := c.openableMaterial(ctx, uint(id))
+	if !ok {
+		return
+	}
+
+	content, object, err := c.MaterialService.OpenMaterialStream(material)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	defer content.Close()
+
+	filename := fmt.Sprintf("%s%s", material.Title, downloadExtension(material.FileName, material.FilePath))
+	streamFile(ctx, content, object, filename, "inline")
+}
+
+// DownloadMaterialFile menangani pengunduhan satu berkas materi bundel; ?inline=true untuk diputar di peramban
+func (c *MaterialController) DownloadMaterialFile(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+	fileID, err := strconv.ParseUint(ctx.Param("file_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
+	material, ok := c.openableMaterial(ctx, uint(id))
+	if !ok {
+		return
+	}
+
+	file, content, object, err := c.MaterialService.OpenMaterialFileStream(material, uint(fileID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	defer content.Close()
+
+	disposition := "attachment"
+	if ctx.Query("inline") == "true" {
+		disposition = "inline"
+	} else {
+		c.recordDownload(ctx, material)
+	}
+	streamFile(ctx, content, object, file.FileName, disposition)
+}
+
+// DeleteMaterialFile menangani penghapusan satu berkas dari materi bundel
+func (c *MaterialController) DeleteMaterialFile(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+	fileID, err := strconv.ParseUint(ctx.Param("file_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
+	if err := c.MaterialService.DeleteMaterialFile(uint(id), uint(fileID)); err != nil {
+		if err.Error() == "material file not found" || err.Error() == "material not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Material file deleted successfully",
+	})
+}
+
+// StreamMaterialHLS menangani playlist dan segmen materi video HLS yang diunggah sebagai ZIP
+func (c *MaterialController) StreamMaterialHLS(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
+	material, ok := c.openableMaterial(ctx, uint(id))
+	if !ok {
+		return
+	}
+	if material.StreamFormat != models.StreamFormatHLS {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Material is not an HLS video"})
+		return
+	}
+
+	content, object, err := c.MaterialService.OpenMaterialStream(material)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	defer content.Close()
+
+	archive, err := zip.NewReader(content, object.Size)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read HLS package"})
+		return
+	}
+
+	// Hanya berkas HLS yang dikenal yang dilayani dari dalam paket
+	name := strings.TrimPrefix(ctx.Param("path"), "/")
+	file := utils.FindHLSFile(archive, name)
+	contentType, known := utils.HLSContentType(name)
+	if file == nil || !known {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	entry := &storage.Object{
+		Key:          name,
+		Size:         int64(file.UncompressedSize64),
+		ContentType:  contentType,
+		LastModified: object.LastModified,
+		ETag:         fmt.Sprintf("%s-%08x", object.ETag, file.CRC32),
+	}
+
+	// Segmen yang disimpan tanpa kompresi dapat dibaca langsung sehingga mendukung Range
+	if file.Method == zip.Store {
+		offset, err := file.DataOffset()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read HLS package"})
+			return
+		}
+		streamFile(ctx, io.NewSectionReader(content, offset, entry.Size), entry, path.Base(name), "inline")
+		return
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read HLS package"})
+		return
+	}
+	defer reader.Close()
+	sendFile(ctx, reader, entry, path.Base(name), "inline")
+}
+
+// GetMaterialDownloadURL menangani pembuatan URL unduhan sementara untuk material
+func (c *MaterialController) GetMaterialDownloadURL(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
+	material, ok := c.openableMaterial(ctx, uint(id))
+	if !ok {
+		return
+	}
+
+	// Unduhan siswa dicatat saat URL dibuka
+	var studentID uint
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		studentID = subject.UserID
+	}
+
+	url, err := c.MaterialService.GetMaterialDownloadURL(material, studentID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"url":        url,
+		"expires_in": int(services.DownloadURLExpiry.Seconds()),
+	})
+}
+
+// MarkMaterialViewed menangani laporan siswa bahwa materi telah dilihat, beserta lama membuka dan persentase tontonan
+func (c *MaterialController) MarkMaterialViewed(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid material ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceMaterial, ID: uint(id)}) {
+		return
+	}
+
+	// Badan permintaan bersifat opsional
+	var request MarkMaterialViewedRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&request); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	material, err := c.MaterialService.GetMaterialByID(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Material not found"})
 		return
 	}
 
-	// Tetapkan nama file untuk diunduh
-	filename := fmt.Sprintf("%s%s", material.Title, filepath.Ext(material.FilePath))
-	ctx.FileAttachment(material.FilePath, filename)
+	userID, _ := ctx.Get("userID")
+	progress, err := c.MaterialService.RecordView(material, userID.(uint), request.SecondsSpent, request.WatchPercent)
+	if err != nil {
+		if !contentLocked(ctx, err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Material view recorded",
+		"progress": progress,
+	})
 }
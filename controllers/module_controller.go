@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// ModuleController menangani permintaan modul kursus dan kerangka kursus
+type ModuleController struct {
+	ModuleService *services.ModuleService
+	Policy        *authz.Policy
+}
+
+// NewModuleController membuat pengontrol modul baru
+func NewModuleController(moduleService *services.ModuleService, policy *authz.Policy) *ModuleController {
+	return &ModuleController{
+		ModuleService: moduleService,
+		Policy:        policy,
+	}
+}
+
+// CreateModuleRequest mewakili permintaan untuk membuat modul
+type CreateModuleRequest struct {
+	CourseID               uint       `json:"course_id" binding:"required"`
+	Title                  string     `json:"title" binding:"required"`
+	Description            string     `json:"description"`
+	PublishAt              *time.Time `json:"publish_at"`
+	UnpublishAt            *time.Time `json:"unpublish_at"`
+	ReleaseAfterDays       *int       `json:"release_after_days"`
+	PrerequisiteModuleID   *uint      `json:"prerequisite_module_id"`
+	PrerequisiteMinPercent float64    `json:"prerequisite_min_percent"`
+}
+
+// UpdateModuleRequest mewakili permintaan untuk memperbarui modul
+type UpdateModuleRequest struct {
+	Title                  string     `json:"title" binding:"required"`
+	Description            string     `json:"description"`
+	PublishAt              *time.Time `json:"publish_at"`
+	UnpublishAt            *time.Time `json:"unpublish_at"`
+	ReleaseAfterDays       *int       `json:"release_after_days"`
+	PrerequisiteModuleID   *uint      `json:"prerequisite_module_id"`
+	PrerequisiteMinPercent float64    `json:"prerequisite_min_percent"`
+}
+
+// AddModuleItemRequest mewakili permintaan untuk menempatkan aktivitas di modul
+type AddModuleItemRequest struct {
+	ItemType models.ProgressType `json:"item_type" binding:"required,oneof=material assignment quiz discussion"`
+	ItemID   uint                `json:"item_id" binding:"required"`
+}
+
+// ReorderModulesRequest mewakili urutan baru modul sebuah kursus
+type ReorderModulesRequest struct {
+	ModuleIDs []uint `json:"module_ids" binding:"required"`
+}
+
+// ReorderModuleItemsRequest mewakili urutan baru butir sebuah modul
+type ReorderModuleItemsRequest struct {
+	ItemIDs []uint `json:"item_ids" binding:"required"`
+}
+
+// CreateModule menangani pembuatan modul
+func (c *ModuleController) CreateModule(ctx *gin.Context) {
+	var request CreateModuleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceModule, CourseID: request.CourseID}) {
+		return
+	}
+
+	module := &models.Module{
+		Availability: models.Availability{
+			PublishAt:        request.PublishAt,
+			UnpublishAt:      request.UnpublishAt,
+			ReleaseAfterDays: request.ReleaseAfterDays,
+		},
+		CourseID:               request.CourseID,
+		Title:                  request.Title,
+		Description:            request.Description,
+		PrerequisiteModuleID:   request.PrerequisiteModuleID,
+		PrerequisiteMinPercent: request.PrerequisiteMinPercent,
+	}
+
+	if err := c.ModuleService.CreateModule(module); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Module created successfully",
+		"module":  module,
+	})
+}
+
+// GetModuleByID menangani pengambilan modul berdasarkan ID
+func (c *ModuleController) GetModuleByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceModule, ID: uint(id)}) {
+		return
+	}
+
+	var module *models.Module
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		module, err = c.ModuleService.GetModuleForStudent(uint(id), subject.UserID)
+	} else {
+		module, err = c.ModuleService.GetModuleByID(uint(id))
+	}
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Module not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, module)
+}
+
+// GetCourseOutline menangani pengambilan kerangka kursus lengkap
+func (c *ModuleController) GetCourseOutline(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceModule, CourseID: uint(courseID)}) {
+		return
+	}
+
+	var outline *services.CourseOutline
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		outline, err = c.ModuleService.GetCourseOutlineForStudent(uint(courseID), subject.UserID)
+	} else {
+		outline, err = c.ModuleService.GetCourseOutline(uint(courseID))
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get course outline"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, outline)
+}
+
+// UpdateModule menangani pembaruan modul
+func (c *ModuleController) UpdateModule(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceModule, ID: uint(id)}) {
+		return
+	}
+
+	var request UpdateModuleRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	module := &models.Module{
+		Availability: models.Availability{
+			PublishAt:        request.PublishAt,
+			UnpublishAt:      request.UnpublishAt,
+			ReleaseAfterDays: request.ReleaseAfterDays,
+		},
+		ID:                     uint(id),
+		Title:                  request.Title,
+		Description:            request.Description,
+		PrerequisiteModuleID:   request.PrerequisiteModuleID,
+		PrerequisiteMinPercent: request.PrerequisiteMinPercent,
+	}
+
+	if err := c.ModuleService.UpdateModule(module); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Module updated successfully",
+		"module":  module,
+	})
+}
+
+// DeleteModule menangani penghapusan modul
+func (c *ModuleController) DeleteModule(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceModule, ID: uint(id)}) {
+		return
+	}
+
+	if err := c.ModuleService.DeleteModule(uint(id)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Module deleted successfully",
+	})
+}
+
+// ReorderModules menangani pengaturan ulang urutan modul sebuah kursus
+func (c *ModuleController) ReorderModules(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceModule, CourseID: uint(courseID)}) {
+		return
+	}
+
+	var request ReorderModulesRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	outline, err := c.ModuleService.ReorderModules(uint(courseID), request.ModuleIDs)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, outline)
+}
+
+// AddModuleItem menangani penempatan aktivitas di modul
+func (c *ModuleController) AddModuleItem(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceModule, ID: uint(id)}) {
+		return
+	}
+
+	var request AddModuleItemRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	item, err := c.ModuleService.AddItem(uint(id), request.ItemType, request.ItemID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Item added to module successfully",
+		"item":    item,
+	})
+}
+
+// RemoveModuleItem menangani pengeluaran aktivitas dari modul
+func (c *ModuleController) RemoveModuleItem(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID"})
+		return
+	}
+
+	itemID, err := strconv.ParseUint(ctx.Param("item_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module item ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceModule, ID: uint(id)}) {
+		return
+	}
+
+	if err := c.ModuleService.RemoveItem(uint(id), uint(itemID)); err != nil {
+		if err.Error() == "module item not found" {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Module item not found"})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Item removed from module successfully",
+	})
+}
+
+// ReorderModuleItems menangani pengaturan ulang urutan aktivitas di modul
+func (c *ModuleController) ReorderModuleItems(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid module ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceModule, ID: uint(id)}) {
+		return
+	}
+
+	var request ReorderModuleItemsRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	module, err := c.ModuleService.ReorderItems(uint(id), request.ItemIDs)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, module)
+}
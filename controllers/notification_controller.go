@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"LMS/models"
+	services "LMS/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// NotificationController menangani permintaan kotak masuk pemberitahuan
+type NotificationController struct {
+	NotificationService *services.NotificationService
+	EmailService        *services.EmailService
+}
+
+// NewNotificationController membuat pengontrol pemberitahuan baru
+func NewNotificationController(notificationService *services.NotificationService, emailService *services.EmailService) *NotificationController {
+	return &NotificationController{
+		NotificationService: notificationService,
+		EmailService:        emailService,
+	}
+}
+
+// EmailPreferenceRequest mewakili permintaan untuk mengubah cara menerima email pemberitahuan
+type EmailPreferenceRequest struct {
+	Mode models.EmailMode `json:"mode" binding:"required"`
+}
+
+// GetNotifications menangani pengambilan pemberitahuan milik pengguna yang masuk
+func (c *NotificationController) GetNotifications(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+
+	limit, _ := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(services.DefaultNotificationPageSize)))
+	offset, _ := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	unreadOnly := ctx.Query("unread") == "true"
+
+	notifications, unread, err := c.NotificationService.GetNotifications(userID.(uint), unreadOnly, limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notifications"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"unread_count":  unread,
+	})
+}
+
+// GetUnreadCount menangani penghitungan pemberitahuan yang belum dibaca
+func (c *NotificationController) GetUnreadCount(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+
+	unread, err := c.NotificationService.CountUnread(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count notifications"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+// MarkRead menangani penandaan satu pemberitahuan sebagai sudah dibaca
+func (c *NotificationController) MarkRead(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	notification, err := c.NotificationService.MarkRead(uint(id), userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, notification)
+}
+
+// MarkAllRead menangani penandaan semua pemberitahuan pengguna sebagai sudah dibaca
+func (c *NotificationController) MarkAllRead(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+
+	updated, err := c.NotificationService.MarkAllRead(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Notifications marked as read",
+		"updated": updated,
+	})
+}
+
+// GetEmailPreference menangani pengambilan preferensi email pengguna yang masuk
+func (c *NotificationController) GetEmailPreference(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+
+	preference, err := c.EmailService.GetPreference(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get email preference"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preference)
+}
+
+// SetEmailPreference menangani perubahan preferensi email pengguna yang masuk
+func (c *NotificationController) SetEmailPreference(ctx *gin.Context) {
+	var request EmailPreferenceRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	preference, err := c.EmailService.SetPreference(userID.(uint), request.Mode)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":    "Email preference updated successfully",
+		"preference": preference,
+	})
+}
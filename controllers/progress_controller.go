@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"LMS/authz"
 	"LMS/models"
 	"LMS/services"
 	"net/http"
@@ -13,12 +14,14 @@ import (
 // ProgressController menangani permintaan kemajuan pembelajaran
 type ProgressController struct {
 	ProgressService *services.LearningProgressService
+	Policy          *authz.Policy
 }
 
 // NewProgressController membuat pengontrol progres baru
-func NewProgressController(progressService *services.LearningProgressService) *ProgressController {
+func NewProgressController(progressService *services.LearningProgressService, policy *authz.Policy) *ProgressController {
 	return &ProgressController{
 		ProgressService: progressService,
+		Policy:          policy,
 	}
 }
 
@@ -50,6 +53,10 @@ func (c *ProgressController) GradeActivity(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionGrade, authz.Resource{Type: authz.ResourceProgress, CourseID: request.CourseID, OwnerID: request.StudentID}) {
+		return
+	}
+
 	// Dapatkan ID grader dari konteks
 	graderID, _ := ctx.Get("userID")
 
@@ -83,6 +90,10 @@ func (c *ProgressController) GetProgressByID(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceProgress, ID: uint(id)}) {
+		return
+	}
+
 	progress, err := c.ProgressService.GetProgressByID(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Learning progress not found"})
@@ -106,6 +117,10 @@ func (c *ProgressController) GetStudentProgress(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceProgress, CourseID: uint(courseID), OwnerID: uint(studentID)}) {
+		return
+	}
+
 	progress, err := c.ProgressService.GetStudentProgress(uint(studentID), uint(courseID))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -123,6 +138,10 @@ func (c *ProgressController) GetCourseStudentsProgress(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceProgress, CourseID: uint(courseID)}) {
+		return
+	}
+
 	// Dapatkan ID pemohon dari konteks
 	requestorID, _ := ctx.Get("userID")
 
@@ -143,6 +162,10 @@ func (c *ProgressController) UpdateGradeByMentor(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceProgress, ID: uint(progressID)}) {
+		return
+	}
+
 	var request UpdateGradeRequest
 	if err := ctx.ShouldBindJSON(&request); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -0,0 +1,495 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// QuizController menangani permintaan kuis
+type QuizController struct {
+	QuizService *services.QuizService
+	Policy      *authz.Policy
+}
+
+// NewQuizController membuat pengontrol kuis baru
+func NewQuizController(quizService *services.QuizService, policy *authz.Policy) *QuizController {
+	return &QuizController{
+		QuizService: quizService,
+		Policy:      policy,
+	}
+}
+
+// ChoiceRequest mewakili satu pilihan jawaban pada pertanyaan pilihan ganda
+type ChoiceRequest struct {
+	Text      string `json:"text" binding:"required"`
+	IsCorrect bool   `json:"is_correct"`
+}
+
+// QuestionRequest mewakili permintaan untuk membuat atau memperbarui pertanyaan
+type QuestionRequest struct {
+	Type          models.QuestionType `json:"type" binding:"required,oneof=multiple_choice true_false numeric"`
+	Prompt        string              `json:"prompt" binding:"required"`
+	Points        float64             `json:"points" binding:"required"`
+	Position      int                 `json:"position"`
+	BoolAnswer    *bool               `json:"bool_answer"`
+	NumericAnswer *float64            `json:"numeric_answer"`
+	Tolerance     float64             `json:"tolerance"`
+	Choices       []ChoiceRequest     `json:"choices" binding:"dive"`
+}
+
+// CreateQuizRequest mewakili permintaan untuk membuat kuis baru
+type CreateQuizRequest struct {
+	CourseID         uint              `json:"course_id" binding:"required"`
+	Title            string            `json:"title" binding:"required"`
+	Description      string            `json:"description"`
+	TimeLimitMinutes *int              `json:"time_limit_minutes"`
+	MaxAttempts      *int              `json:"max_attempts"`
+	DueDate          *time.Time        `json:"due_date"`
+	PublishAt        *time.Time        `json:"publish_at"`
+	UnpublishAt      *time.Time        `json:"unpublish_at"`
+	ReleaseAfterDays *int              `json:"release_after_days"`
+	Questions        []QuestionRequest `json:"questions" binding:"dive"`
+}
+
+// UpdateQuizRequest mewakili permintaan untuk memperbarui kuis
+type UpdateQuizRequest struct {
+	Title            string     `json:"title" binding:"required"`
+	Description      string     `json:"description"`
+	TimeLimitMinutes *int       `json:"time_limit_minutes"`
+	MaxAttempts      *int       `json:"max_attempts"`
+	DueDate          *time.Time `json:"due_date"`
+	PublishAt        *time.Time `json:"publish_at"`
+	UnpublishAt      *time.Time `json:"unpublish_at"`
+	ReleaseAfterDays *int       `json:"release_after_days"`
+}
+
+// AnswerRequest mewakili jawaban siswa untuk satu pertanyaan
+type AnswerRequest struct {
+	QuestionID    uint     `json:"question_id" binding:"required"`
+	ChoiceID      *uint    `json:"choice_id"`
+	BoolAnswer    *bool    `json:"bool_answer"`
+	NumericAnswer *float64 `json:"numeric_answer"`
+}
+
+// SubmitAttemptRequest mewakili permintaan untuk mengirim jawaban kuis
+type SubmitAttemptRequest struct {
+	Answers []AnswerRequest `json:"answers" binding:"dive"`
+}
+
+// toQuestionModel mengubah permintaan pertanyaan menjadi model
+func (r QuestionRequest) toQuestionModel() models.QuizQuestion {
+	question := models.QuizQuestion{
+		Type:          r.Type,
+		Prompt:        r.Prompt,
+		Points:        r.Points,
+		Position:      r.Position,
+		BoolAnswer:    r.BoolAnswer,
+		NumericAnswer: r.NumericAnswer,
+		Tolerance:     r.Tolerance,
+	}
+	for i, choice := range r.Choices {
+		question.Choices = append(question.Choices, models.QuizChoice{
+			Text:      choice.Text,
+			IsCorrect: choice.IsCorrect,
+			Position:  i + 1,
+		})
+	}
+	return question
+}
+
+// CreateQuiz menangani pembuatan kuis
+func (c *QuizController) CreateQuiz(ctx *gin.Context) {
+	var request CreateQuizRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceQuiz, CourseID: request.CourseID}) {
+		return
+	}
+
+	quiz := &models.Quiz{
+		Availability: models.Availability{
+			PublishAt:        request.PublishAt,
+			UnpublishAt:      request.UnpublishAt,
+			ReleaseAfterDays: request.ReleaseAfterDays,
+		},
+		CourseID:         request.CourseID,
+		Title:            request.Title,
+		Description:      request.Description,
+		TimeLimitMinutes: request.TimeLimitMinutes,
+		MaxAttempts:      request.MaxAttempts,
+		DueDate:          request.DueDate,
+	}
+	for _, question := range request.Questions {
+		quiz.Questions = append(quiz.Questions, question.toQuestionModel())
+	}
+
+	if err := c.QuizService.CreateQuiz(quiz); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Quiz created successfully",
+		"quiz":    quiz,
+	})
+}
+
+// GetQuizByID menangani pengambilan kuis berdasarkan ID
+func (c *QuizController) GetQuizByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceQuiz, ID: uint(id)}) {
+		return
+	}
+
+	// Siswa tidak boleh melihat kunci jawaban maupun kuis yang masih terkunci
+	var quiz *models.Quiz
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		quiz, err = c.QuizService.GetQuizForStudent(uint(id), subject.UserID)
+	} else {
+		quiz, err = c.QuizService.GetQuizByID(uint(id))
+	}
+	if contentLocked(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quiz)
+}
+
+// GetQuizzesByCourse menangani pengambilan kuis berdasarkan ID kursus
+func (c *QuizController) GetQuizzesByCourse(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceQuiz, CourseID: uint(courseID)}) {
+		return
+	}
+
+	var quizzes []models.Quiz
+	if subject := subjectFromContext(ctx); subject.Role == models.RoleStudent {
+		quizzes, err = c.QuizService.GetQuizzesByCourseForStudent(uint(courseID), subject.UserID)
+	} else {
+		quizzes, err = c.QuizService.GetQuizzesByCourse(uint(courseID))
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get quizzes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quizzes)
+}
+
+// UpdateQuiz menangani pembaruan kuis
+func (c *QuizController) UpdateQuiz(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceQuiz, ID: uint(id)}) {
+		return
+	}
+
+	var request UpdateQuizRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quiz := &models.Quiz{
+		Availability: models.Availability{
+			PublishAt:        request.PublishAt,
+			UnpublishAt:      request.UnpublishAt,
+			ReleaseAfterDays: request.ReleaseAfterDays,
+		},
+		ID:               uint(id),
+		Title:            request.Title,
+		Description:      request.Description,
+		TimeLimitMinutes: request.TimeLimitMinutes,
+		MaxAttempts:      request.MaxAttempts,
+		DueDate:          request.DueDate,
+	}
+
+	if err := c.QuizService.UpdateQuiz(quiz); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Quiz updated successfully",
+	})
+}
+
+// DeleteQuiz menangani penghapusan kuis
+func (c *QuizController) DeleteQuiz(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceQuiz, ID: uint(id)}) {
+		return
+	}
+
+	if err := c.QuizService.DeleteQuiz(uint(id)); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Quiz not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Quiz deleted successfully",
+	})
+}
+
+// AddQuestion menangani penambahan pertanyaan ke kuis
+func (c *QuizController) AddQuestion(ctx *gin.Context) {
+	quizID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceQuiz, ID: uint(quizID)}) {
+		return
+	}
+
+	var request QuestionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question := request.toQuestionModel()
+	question.QuizID = uint(quizID)
+
+	if err := c.QuizService.AddQuestion(&question); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":  "Question created successfully",
+		"question": question,
+	})
+}
+
+// UpdateQuestion menangani pembaruan pertanyaan kuis
+func (c *QuizController) UpdateQuestion(ctx *gin.Context) {
+	questionID, err := strconv.ParseUint(ctx.Param("question_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	// Pertanyaan diotorisasi melalui kuis induknya
+	existing, err := c.QuizService.GetQuestionByID(uint(questionID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceQuiz, ID: existing.QuizID}) {
+		return
+	}
+
+	var request QuestionRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question := request.toQuestionModel()
+	question.ID = uint(questionID)
+
+	if err := c.QuizService.UpdateQuestion(&question); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Question updated successfully",
+	})
+}
+
+// DeleteQuestion menangani penghapusan pertanyaan kuis
+func (c *QuizController) DeleteQuestion(ctx *gin.Context) {
+	questionID, err := strconv.ParseUint(ctx.Param("question_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid question ID"})
+		return
+	}
+
+	// Pertanyaan diotorisasi melalui kuis induknya
+	existing, err := c.QuizService.GetQuestionByID(uint(questionID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceQuiz, ID: existing.QuizID}) {
+		return
+	}
+
+	if err := c.QuizService.DeleteQuestion(uint(questionID)); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Question deleted successfully",
+	})
+}
+
+// StartAttempt menangani dimulainya percobaan kuis oleh siswa
+func (c *QuizController) StartAttempt(ctx *gin.Context) {
+	quizID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionSubmit, authz.Resource{Type: authz.ResourceQuiz, ID: uint(quizID)}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	attempt, err := c.QuizService.StartAttempt(uint(quizID), userID.(uint))
+	if contentLocked(ctx, err) {
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Quiz attempt started",
+		"attempt": attempt,
+	})
+}
+
+// SubmitAttempt menangani pengiriman jawaban kuis oleh siswa
+func (c *QuizController) SubmitAttempt(ctx *gin.Context) {
+	attemptID, err := strconv.ParseUint(ctx.Param("attempt_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attempt ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceQuizAttempt, ID: uint(attemptID)}) {
+		return
+	}
+
+	var request SubmitAttemptRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	answers := make([]models.QuizAnswer, 0, len(request.Answers))
+	for _, answer := range request.Answers {
+		answers = append(answers, models.QuizAnswer{
+			QuestionID:    answer.QuestionID,
+			ChoiceID:      answer.ChoiceID,
+			BoolAnswer:    answer.BoolAnswer,
+			NumericAnswer: answer.NumericAnswer,
+		})
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	attempt, err := c.QuizService.SubmitAttempt(uint(attemptID), userID.(uint), answers)
+	if err != nil && attempt == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": "Quiz submitted, but recording progress failed: " + err.Error(),
+			"attempt": attempt,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Quiz submitted successfully",
+		"attempt": attempt,
+	})
+}
+
+// GetAttemptByID menangani pengambilan percobaan kuis berdasarkan ID
+func (c *QuizController) GetAttemptByID(ctx *gin.Context) {
+	attemptID, err := strconv.ParseUint(ctx.Param("attempt_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attempt ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceQuizAttempt, ID: uint(attemptID)}) {
+		return
+	}
+
+	attempt, err := c.QuizService.GetAttemptByID(uint(attemptID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Quiz attempt not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempt)
+}
+
+// GetQuizAttempts menangani pengambilan percobaan kuis; siswa hanya melihat miliknya sendiri
+func (c *QuizController) GetQuizAttempts(ctx *gin.Context) {
+	quizID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiz ID"})
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+	role, _ := ctx.Get("role")
+
+	// Siswa harus terdaftar di kursus; mentor hanya untuk kursus yang diampunya
+	action := authz.ActionGrade
+	if role == models.RoleStudent {
+		action = authz.ActionView
+	}
+	if !authorize(ctx, c.Policy, action, authz.Resource{Type: authz.ResourceQuiz, ID: uint(quizID)}) {
+		return
+	}
+
+	var attempts []models.QuizAttempt
+	if role == models.RoleStudent {
+		attempts, err = c.QuizService.GetStudentAttempts(uint(quizID), userID.(uint))
+	} else {
+		attempts, err = c.QuizService.GetQuizAttempts(uint(quizID))
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get quiz attempts"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempts)
+}
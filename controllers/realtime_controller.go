@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/realtime"
+	"LMS/services"
+	"LMS/utils"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// Batas aliran realtime
+const (
+	maxRealtimeTopics = 20
+	realtimeHeartbeat = 25 * time.Second
+)
+
+// RealtimeController menangani aliran pembaruan realtime lewat Server-Sent Events
+type RealtimeController struct {
+	Broker      realtime.Broker
+	Policy      *authz.Policy
+	AuthService *services.AuthService
+}
+
+// NewRealtimeController membuat pengontrol realtime baru
+func NewRealtimeController(broker realtime.Broker, policy *authz.Policy, authService *services.AuthService) *RealtimeController {
+	return &RealtimeController{
+		Broker:      broker,
+		Policy:      policy,
+		AuthService: authService,
+	}
+}
+
+// topicAccess adalah izin yang harus tetap dimiliki pengguna selama berlangganan suatu topik
+type topicAccess struct {
+	action   authz.Action
+	resource authz.Resource
+}
+
+// Stream menangani langganan topik realtime dan mengalirkan pesannya sebagai Server-Sent Events.
+// Topik pribadi pengguna selalu diikuti; topik lain dipilih lewat parameter topics, misalnya "course:1,discussion:5".
+// Aliran ditutup saat token akses kedaluwarsa, dan token serta izin setiap topik diperiksa ulang pada setiap heartbeat.
+func (c *RealtimeController) Stream(ctx *gin.Context) {
+	userID, _ := ctx.Get("userID")
+	claims, _ := ctx.Get("claims")
+	topics := []string{realtime.Topic(realtime.TopicUser, userID.(uint))}
+	var accesses []topicAccess
+
+	var requested []string
+	for _, value := range ctx.QueryArray("topics") {
+		for _, topic := range strings.Split(value, ",") {
+			if strings.TrimSpace(topic) != "" {
+				requested = append(requested, topic)
+			}
+		}
+	}
+	if len(requested) > maxRealtimeTopics {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Too many topics"})
+		return
+	}
+
+	for _, topic := range requested {
+		kind, id, err := realtime.ParseTopic(topic)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid topic: " + topic})
+			return
+		}
+
+		var access topicAccess
+		switch kind {
+		case realtime.TopicCourse:
+			// Pembaruan kursus hanya untuk anggotanya, sama seperti daftar diskusi kursus
+			access = topicAccess{authz.ActionView, authz.Resource{Type: authz.ResourceDiscussion, CourseID: id}}
+		case realtime.TopicDiscussion:
+			access = topicAccess{authz.ActionView, authz.Resource{Type: authz.ResourceDiscussion, ID: id}}
+		case realtime.TopicUser:
+			if id != userID.(uint) {
+				ctx.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to access this resource"})
+				return
+			}
+			continue
+		}
+		if !authorize(ctx, c.Policy, access.action, access.resource) {
+			return
+		}
+		topics = append(topics, realtime.Topic(kind, id))
+		accesses = append(accesses, access)
+	}
+
+	subscription := c.Broker.Subscribe(topics)
+	defer c.Broker.Unsubscribe(subscription)
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// Matikan penyanggaan proksi seperti nginx agar pesan langsung sampai
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.SSEvent("ready", gin.H{"topics": topics})
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(realtimeHeartbeat)
+	defer heartbeat.Stop()
+
+	// Tutup aliran tepat saat token akses kedaluwarsa; klien menyambung ulang dengan token baru
+	var expired <-chan time.Time
+	if accessClaims, ok := claims.(*utils.JWTClaims); ok && accessClaims.ExpiresAt != nil {
+		expiry := time.NewTimer(time.Until(accessClaims.ExpiresAt.Time))
+		defer expiry.Stop()
+		expired = expiry.C
+	}
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-expired:
+			ctx.SSEvent("close", gin.H{"error": "invalid or expired token"})
+			return false
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				// Langganan diputus hub karena klien terlalu lambat; klien perlu menyambung ulang
+				return false
+			}
+			ctx.SSEvent(message.Type, message)
+			return true
+		case <-heartbeat.C:
+			if err := c.recheck(ctx, claims, accesses); err != nil {
+				ctx.SSEvent("close", gin.H{"error": err.Error()})
+				return false
+			}
+			io.WriteString(w, ": ping\n\n")
+			return true
+		}
+	})
+}
+
+// recheck memastikan token akses belum dicabut dan pengguna masih berhak atas setiap topik yang diikuti
+func (c *RealtimeController) recheck(ctx *gin.Context, claims any, accesses []topicAccess) error {
+	accessClaims, ok := claims.(*utils.JWTClaims)
+	if !ok {
+		return errors.New("invalid or expired token")
+	}
+	if err := c.AuthService.CheckAccessClaims(accessClaims); err != nil {
+		return errors.New("invalid or expired token")
+	}
+
+	for _, access := range accesses {
+		if !allowed(ctx, c.Policy, access.action, access.resource) {
+			return errors.New("access to a subscribed topic was revoked")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// ReportController menangani permintaan laporan diskusi dan komentar
+type ReportController struct {
+	ReportService *services.ReportService
+	Policy        *authz.Policy
+}
+
+// NewReportController membuat pengontrol laporan baru
+func NewReportController(reportService *services.ReportService, policy *authz.Policy) *ReportController {
+	return &ReportController{
+		ReportService: reportService,
+		Policy:        policy,
+	}
+}
+
+// CreateReportRequest mewakili permintaan untuk melaporkan diskusi atau komentar
+type CreateReportRequest struct {
+	DiscussionID uint   `json:"discussion_id" binding:"required"`
+	CommentID    *uint  `json:"comment_id"`
+	Reason       string `json:"reason" binding:"required"`
+}
+
+// ResolveReportRequest mewakili permintaan moderator untuk menutup laporan
+type ResolveReportRequest struct {
+	Status      models.ReportStatus `json:"status" binding:"required,oneof=resolved dismissed"`
+	Resolution  string              `json:"resolution"`
+	HideComment bool                `json:"hide_comment"`
+}
+
+// CreateReport menangani pelaporan diskusi atau komentar ke moderator kursus
+func (c *ReportController) CreateReport(ctx *gin.Context) {
+	var request CreateReportRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resource := authz.Resource{Type: authz.ResourceDiscussion, ID: request.DiscussionID}
+	if request.CommentID != nil {
+		resource = authz.Resource{Type: authz.ResourceComment, ID: *request.CommentID}
+	}
+	if !authorize(ctx, c.Policy, authz.ActionView, resource) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	report := &models.ContentReport{
+		DiscussionID: request.DiscussionID,
+		CommentID:    request.CommentID,
+		ReporterID:   userID.(uint),
+		Reason:       request.Reason,
+	}
+
+	if err := c.ReportService.CreateReport(report); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Report submitted successfully",
+		"report":  report,
+	})
+}
+
+// GetReportsByCourse menangani pengambilan antrean laporan sebuah kursus
+func (c *ReportController) GetReportsByCourse(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionModerate, authz.Resource{Type: authz.ResourceDiscussion, CourseID: uint(courseID)}) {
+		return
+	}
+
+	status := models.ReportStatus(ctx.DefaultQuery("status", string(models.ReportOpen)))
+	if status == "all" {
+		status = ""
+	}
+
+	reports, err := c.ReportService.GetReportsByCourse(uint(courseID), status)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reports)
+}
+
+// GetReportByID menangani pengambilan laporan berdasarkan ID
+func (c *ReportController) GetReportByID(ctx *gin.Context) {
+	report, ok := c.moderatedReport(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// ResolveReport menangani penutupan laporan oleh moderator kursus
+func (c *ReportController) ResolveReport(ctx *gin.Context) {
+	var request ResolveReportRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, ok := c.moderatedReport(ctx)
+	if !ok {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	if err := c.ReportService.ResolveReport(report.ID, userID.(uint), request.Status, request.Resolution, request.HideComment); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Report resolved successfully",
+	})
+}
+
+// moderatedReport memuat laporan dan memastikan pengguna adalah moderator kursusnya
+func (c *ReportController) moderatedReport(ctx *gin.Context) (*models.ContentReport, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+		return nil, false
+	}
+
+	report, err := c.ReportService.GetReportByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionModerate, authz.Resource{Type: authz.ResourceDiscussion, CourseID: report.CourseID}) {
+		return nil, false
+	}
+
+	return report, true
+}
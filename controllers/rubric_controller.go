@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// RubricController menangani permintaan rubrik penilaian
+type RubricController struct {
+	RubricService *services.RubricService
+	Policy        *authz.Policy
+}
+
+// NewRubricController membuat pengontrol rubrik baru
+func NewRubricController(rubricService *services.RubricService, policy *authz.Policy) *RubricController {
+	return &RubricController{
+		RubricService: rubricService,
+		Policy:        policy,
+	}
+}
+
+// RubricLevelRequest mewakili satu tingkat capaian kriteria
+type RubricLevelRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description"`
+	Points      int    `json:"points"`
+}
+
+// RubricCriterionRequest mewakili satu kriteria rubrik beserta tingkat capaiannya
+type RubricCriterionRequest struct {
+	Title       string               `json:"title" binding:"required"`
+	Description string               `json:"description"`
+	Levels      []RubricLevelRequest `json:"levels" binding:"required,min=1,dive"`
+}
+
+// CreateRubricRequest mewakili permintaan untuk membuat rubrik
+type CreateRubricRequest struct {
+	CourseID    uint                     `json:"course_id" binding:"required"`
+	Title       string                   `json:"title" binding:"required"`
+	Description string                   `json:"description"`
+	Criteria    []RubricCriterionRequest `json:"criteria" binding:"required,min=1,dive"`
+}
+
+// UpdateRubricRequest mewakili permintaan untuk memperbarui rubrik
+type UpdateRubricRequest struct {
+	Title       string                   `json:"title" binding:"required"`
+	Description string                   `json:"description"`
+	Criteria    []RubricCriterionRequest `json:"criteria" binding:"required,min=1,dive"`
+}
+
+// DuplicateRubricRequest mewakili permintaan untuk menyalin rubrik ke sebuah kursus
+type DuplicateRubricRequest struct {
+	CourseID uint `json:"course_id" binding:"required"`
+}
+
+// rubricCriteria mengubah permintaan kriteria menjadi model
+func rubricCriteria(requests []RubricCriterionRequest) []models.RubricCriterion {
+	criteria := make([]models.RubricCriterion, 0, len(requests))
+	for _, request := range requests {
+		criterion := models.RubricCriterion{
+			Title:       request.Title,
+			Description: request.Description,
+		}
+		for _, level := range request.Levels {
+			criterion.Levels = append(criterion.Levels, models.RubricLevel{
+				Title:       level.Title,
+				Description: level.Description,
+				Points:      level.Points,
+			})
+		}
+		criteria = append(criteria, criterion)
+	}
+	return criteria
+}
+
+// CreateRubric menangani pembuatan rubrik
+func (c *RubricController) CreateRubric(ctx *gin.Context) {
+	var request CreateRubricRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceRubric, CourseID: request.CourseID}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	rubric := &models.Rubric{
+		CourseID:    request.CourseID,
+		Title:       request.Title,
+		Description: request.Description,
+		CreatedByID: userID.(uint),
+		Criteria:    rubricCriteria(request.Criteria),
+	}
+
+	if err := c.RubricService.CreateRubric(rubric); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Rubric created successfully",
+		"rubric":  rubric,
+	})
+}
+
+// GetRubricByID menangani pengambilan rubrik berdasarkan ID
+func (c *RubricController) GetRubricByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rubric ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceRubric, ID: uint(id)}) {
+		return
+	}
+
+	rubric, err := c.RubricService.GetRubricByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Rubric not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rubric)
+}
+
+// GetRubricsByCourse menangani pengambilan rubrik sebuah kursus
+func (c *RubricController) GetRubricsByCourse(ctx *gin.Context) {
+	courseID, err := strconv.ParseUint(ctx.Param("course_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid course ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceRubric, CourseID: uint(courseID)}) {
+		return
+	}
+
+	rubrics, err := c.RubricService.GetRubricsByCourse(uint(courseID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rubrics"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rubrics)
+}
+
+// UpdateRubric menangani pembaruan rubrik
+func (c *RubricController) UpdateRubric(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rubric ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceRubric, ID: uint(id)}) {
+		return
+	}
+
+	var request UpdateRubricRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rubric := &models.Rubric{
+		ID:          uint(id),
+		Title:       request.Title,
+		Description: request.Description,
+		Criteria:    rubricCriteria(request.Criteria),
+	}
+
+	if err := c.RubricService.UpdateRubric(rubric); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Rubric updated successfully",
+		"rubric":  rubric,
+	})
+}
+
+// DuplicateRubric menangani penyalinan rubrik ke sebuah kursus
+func (c *RubricController) DuplicateRubric(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rubric ID"})
+		return
+	}
+
+	var request DuplicateRubricRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Mentor harus dapat melihat rubrik sumber dan mengelola kursus tujuan
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceRubric, ID: uint(id)}) {
+		return
+	}
+	if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceRubric, CourseID: request.CourseID}) {
+		return
+	}
+
+	userID, _ := ctx.Get("userID")
+
+	rubric, err := c.RubricService.DuplicateRubric(uint(id), request.CourseID, userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Rubric duplicated successfully",
+		"rubric":  rubric,
+	})
+}
+
+// DeleteRubric menangani penghapusan rubrik
+func (c *RubricController) DeleteRubric(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rubric ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceRubric, ID: uint(id)}) {
+		return
+	}
+
+	if err := c.RubricService.DeleteRubric(uint(id)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Rubric deleted successfully",
+	})
+}
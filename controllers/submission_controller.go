@@ -1,13 +1,12 @@
 package controllers
 
 import (
+	"LMS/authz"
 	"LMS/models"
 	services "LMS/services"
 	"fmt"
 	"net/http"
-	"path/filepath"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -16,12 +15,14 @@ import (
 // SubmissionController menangani permintaan pengiriman
 type SubmissionController struct {
 	SubmissionService *services.SubmissionService
+	Policy            *authz.Policy
 }
 
 // NewSubmissionController membuat pengontrol pengiriman baru
-func NewSubmissionController(submissionService *services.SubmissionService) *SubmissionController {
+func NewSubmissionController(submissionService *services.SubmissionService, policy *authz.Policy) *SubmissionController {
 	return &SubmissionController{
 		SubmissionService: submissionService,
+		Policy:            policy,
 	}
 }
 
@@ -35,25 +36,26 @@ type CreateSubmissionRequest struct {
 func (c *SubmissionController) CreateSubmission(ctx *gin.Context) {
 	var request CreateSubmissionRequest
 	if err := ctx.ShouldBind(&request); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if !uploadFailed(ctx, err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
-	// Dapatkan file dari formulir
-	file, err := ctx.FormFile("file")
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+	if !authorize(ctx, c.Policy, authz.ActionSubmit, authz.Resource{Type: authz.ResourceAssignment, ID: request.AssignmentID}) {
 		return
 	}
 
-	// Hasilkan nama file yang unik
-	extension := filepath.Ext(file.Filename)
-	filename := fmt.Sprintf("submission_%d_%d_%s%s", request.AssignmentID, request.StudentID, time.Now().Format("20060102150405"), extension)
-	filePath := filepath.Join("uploads/submissions", filename)
+	// Siswa hanya boleh mengirim atas namanya sendiri
+	if subject := subjectFromContext(ctx); subject.UserID != request.StudentID {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You don't have permission to access this resource"})
+		return
+	}
 
-	// Menyimpan file ke disk
-	if err := ctx.SaveUploadedFile(file, filePath); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save file"})
+	// Dapatkan file dari formulir
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
 		return
 	}
 
@@ -62,8 +64,10 @@ func (c *SubmissionController) CreateSubmission(ctx *gin.Context) {
 		StudentID:    request.StudentID,
 	}
 
-	if err := c.SubmissionService.CreateSubmission(submission, filePath); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.SubmissionService.CreateSubmission(submission, file); err != nil {
+		if !uploadFailed(ctx, err) && !contentLocked(ctx, err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -81,6 +85,10 @@ func (c *SubmissionController) GetSubmissionByID(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceSubmission, ID: uint(id)}) {
+		return
+	}
+
 	submission, err := c.SubmissionService.GetSubmissionByID(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
@@ -98,6 +106,10 @@ func (c *SubmissionController) GetSubmissionsByAssignment(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionGrade, authz.Resource{Type: authz.ResourceAssignment, ID: uint(assignmentID)}) {
+		return
+	}
+
 	submissions, err := c.SubmissionService.GetSubmissionsByAssignment(uint(assignmentID))
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get submissions"})
@@ -121,7 +133,47 @@ func (c *SubmissionController) GetSubmissionsByStudent(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, submissions)
+	// Hanya tampilkan kiriman yang boleh dilihat pemohon (miliknya sendiri atau kursus yang diampu)
+	visible := make([]models.Submission, 0, len(submissions))
+	for _, submission := range submissions {
+		if allowed(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceSubmission, ID: submission.ID}) {
+			visible = append(visible, submission)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, visible)
+}
+
+// GetSubmissionAttempts menangani pengambilan semua percobaan siswa untuk sebuah tugas
+func (c *SubmissionController) GetSubmissionAttempts(ctx *gin.Context) {
+	assignmentID, err := strconv.ParseUint(ctx.Param("assignment_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid assignment ID"})
+		return
+	}
+
+	studentID, err := strconv.ParseUint(ctx.Param("student_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid student ID"})
+		return
+	}
+
+	// Siswa melihat riwayatnya sendiri; riwayat siswa lain hanya untuk mentor kursus
+	action := authz.ActionGrade
+	if subjectFromContext(ctx).UserID == uint(studentID) {
+		action = authz.ActionView
+	}
+	if !authorize(ctx, c.Policy, action, authz.Resource{Type: authz.ResourceAssignment, ID: uint(assignmentID)}) {
+		return
+	}
+
+	attempts, err := c.SubmissionService.GetSubmissionAttempts(uint(assignmentID), uint(studentID))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempts)
 }
 
 // DeleteSubmission menangani penghapusan kiriman
@@ -132,6 +184,10 @@ func (c *SubmissionController) DeleteSubmission(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionDelete, authz.Resource{Type: authz.ResourceSubmission, ID: uint(id)}) {
+		return
+	}
+
 	if err := c.SubmissionService.DeleteSubmission(uint(id)); err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
 		return
@@ -150,13 +206,54 @@ func (c *SubmissionController) DownloadSubmission(ctx *gin.Context) {
 		return
 	}
 
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceSubmission, ID: uint(id)}) {
+		return
+	}
+
 	submission, err := c.SubmissionService.GetSubmissionByID(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
 		return
 	}
 
+	reader, object, err := c.SubmissionService.OpenSubmissionFile(submission)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	defer reader.Close()
+
 	// Tetapkan nama file untuk diunduh
-	filename := fmt.Sprintf("submission_%d%s", submission.ID, filepath.Ext(submission.FilePath))
-	ctx.FileAttachment(submission.FilePath, filename)
+	filename := fmt.Sprintf("submission_%d%s", submission.ID, downloadExtension(submission.FileName, submission.FilePath))
+	sendFile(ctx, reader, object, filename, "attachment")
+}
+
+// GetSubmissionDownloadURL menangani pembuatan URL unduhan sementara untuk kiriman
+func (c *SubmissionController) GetSubmissionDownloadURL(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid submission ID"})
+		return
+	}
+
+	if !authorize(ctx, c.Policy, authz.ActionView, authz.Resource{Type: authz.ResourceSubmission, ID: uint(id)}) {
+		return
+	}
+
+	submission, err := c.SubmissionService.GetSubmissionByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Submission not found"})
+		return
+	}
+
+	url, err := c.SubmissionService.GetSubmissionDownloadURL(submission)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"url":        url,
+		"expires_in": int(services.DownloadURLExpiry.Seconds()),
+	})
 }
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"LMS/authz"
+	"LMS/models"
+	services "LMS/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// UploadController menangani unggahan materi berukuran besar yang dikirim per potongan
+type UploadController struct {
+	UploadService *services.ResumableUploadService
+	Policy        *authz.Policy
+}
+
+// NewUploadController membuat pengontrol unggahan bertahap baru
+func NewUploadController(uploadService *services.ResumableUploadService, policy *authz.Policy) *UploadController {
+	return &UploadController{
+		UploadService: uploadService,
+		Policy:        policy,
+	}
+}
+
+// InitUploadRequest mewakili permintaan untuk memulai unggahan bertahap.
+// MaterialID diisi untuk mengganti berkas materi yang sudah ada, selain itu CourseID dan Title wajib diisi.
+type InitUploadRequest struct {
+	CourseID   uint   `json:"course_id"`
+	MaterialID *uint  `json:"material_id"`
+	Title      string `json:"title"`
+	FileName   string `json:"file_name" binding:"required"`
+	FileSize   int64  `json:"file_size" binding:"required"`
+	ChunkSize  int64  `json:"chunk_size"`
+	Checksum   string `json:"checksum"`
+}
+
+// InitUpload menangani pembuatan sesi unggahan bertahap
+func (c *UploadController) InitUpload(ctx *gin.Context) {
+	var request InitUploadRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.MaterialID != nil {
+		if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceMaterial, ID: *request.MaterialID}) {
+			return
+		}
+	} else {
+		if request.CourseID == 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "course_id is required"})
+			return
+		}
+		if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceMaterial, CourseID: request.CourseID}) {
+			return
+		}
+	}
+
+	session := &models.UploadSession{
+		UserID:     subjectFromContext(ctx).UserID,
+		CourseID:   request.CourseID,
+		MaterialID: request.MaterialID,
+		Title:      request.Title,
+		FileName:   request.FileName,
+		FileSize:   request.FileSize,
+		ChunkSize:  request.ChunkSize,
+		Checksum:   request.Checksum,
+	}
+
+	if err := c.UploadService.InitUpload(session); err != nil {
+		if !uploadFailed(ctx, err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, session)
+}
+
+// GetUpload menangani pengambilan kemajuan unggahan bertahap
+func (c *UploadController) GetUpload(ctx *gin.Context) {
+	session, err := c.UploadService.GetUpload(ctx.Param("upload_id"), subjectFromContext(ctx).UserID)
+	if err != nil {
+		uploadSessionFailed(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// UploadChunk menangani penerimaan satu potongan; badan permintaan adalah isi mentah potongan
+func (c *UploadController) UploadChunk(ctx *gin.Context) {
+	index, err := strconv.Atoi(ctx.Param("index"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chunk index"})
+		return
+	}
+
+	session, err := c.UploadService.UploadChunk(
+		ctx.Param("upload_id"),
+		subjectFromContext(ctx).UserID,
+		index,
+		ctx.Request.Body,
+		ctx.GetHeader("X-Chunk-Checksum"),
+	)
+	if err != nil {
+		if errors.Is(err, services.ErrChunkChecksumMismatch) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		uploadSessionFailed(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// CompleteUpload menangani penyelesaian unggahan bertahap menjadi materi
+func (c *UploadController) CompleteUpload(ctx *gin.Context) {
+	userID := subjectFromContext(ctx).UserID
+	session, err := c.UploadService.GetUpload(ctx.Param("upload_id"), userID)
+	if err != nil {
+		uploadSessionFailed(ctx, err)
+		return
+	}
+
+	// Periksa ulang akses karena peran pengguna di kursus dapat berubah selama unggahan berlangsung
+	replacing := session.MaterialID != nil && session.Status != models.UploadStatusCompleted
+	if replacing {
+		if !authorize(ctx, c.Policy, authz.ActionUpdate, authz.Resource{Type: authz.ResourceMaterial, ID: *session.MaterialID}) {
+			return
+		}
+	} else if !authorize(ctx, c.Policy, authz.ActionCreate, authz.Resource{Type: authz.ResourceMaterial, CourseID: session.CourseID}) {
+		return
+	}
+
+	material, err := c.UploadService.CompleteUpload(session.Token, userID)
+	if err != nil {
+		uploadSessionFailed(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Upload completed successfully",
+		"material": material,
+	})
+}
+
+// AbortUpload menangani pembatalan unggahan bertahap
+func (c *UploadController) AbortUpload(ctx *gin.Context) {
+	if err := c.UploadService.AbortUpload(ctx.Param("upload_id"), subjectFromContext(ctx).UserID); err != nil {
+		uploadSessionFailed(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Upload cancelled successfully"})
+}
+
+// uploadSessionFailed menanggapi kesalahan sesi unggahan dengan kode status yang sesuai
+func uploadSessionFailed(ctx *gin.Context, err error) {
+	switch err.Error() {
+	case "upload session not found":
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Upload session not found"})
+	case "upload session has expired":
+		ctx.JSON(http.StatusGone, gin.H{"error": "Upload session has expired"})
+	case "upload is already being completed", "upload is no longer accepting chunks":
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		if !uploadFailed(ctx, err) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+)
+
+// Type adalah jenis kejadian yang dipublikasikan layanan
+type Type string
+
+const (
+	AssessmentCreated Type = "assessment.created"
+	AssessmentUpdated Type = "assessment.updated"
+	DiscussionCreated Type = "discussion.created"
+	DiscussionUpdated Type = "discussion.updated"
+	CommentCreated    Type = "comment.created"
+	AssignmentCreated Type = "assignment.created"
+	MaterialCreated   Type = "material.created"
+)
+
+// Event adalah kejadian yang terjadi setelah perubahan tersimpan.
+// ResourceID menunjuk sumber daya sesuai jenisnya, misalnya ID komentar untuk CommentCreated.
+// ActorID bernilai nol jika pelakunya tidak diketahui.
+type Event struct {
+	Type       Type
+	CourseID   uint
+	ResourceID uint
+	ActorID    uint
+	OccurredAt time.Time
+}
+
+// Handler memproses satu kejadian
+type Handler func(Event) error
+
+// Bus meneruskan kejadian ke semua pelanggannya di dalam proses
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus membuat bus kejadian baru tanpa pelanggan
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe mendaftarkan pelanggan yang menerima semua kejadian
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish meneruskan kejadian ke setiap pelanggan secara berurutan.
+// Kegagalan pelanggan hanya dicatat agar tidak menggagalkan permintaan yang memicunya.
+// Bus nil diabaikan sehingga layanan tetap dapat dipakai tanpa bus.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := append([]Handler(nil), b.handlers...)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		dispatch(handler, event)
+	}
+}
+
+// dispatch menjalankan satu pelanggan dan mencatat error atau panic-nya
+func dispatch(handler Handler, event Event) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("event handler panicked on %s %d: %v", event.Type, event.ResourceID, recovered)
+		}
+	}()
+
+	if err := handler(event); err != nil {
+		log.Printf("event handler failed on %s %d: %v", event.Type, event.ResourceID, err)
+	}
+}
@@ -10,6 +10,7 @@ CREATE TABLE users (
     email VARCHAR(255) NOT NULL UNIQUE,
     password VARCHAR(255) NOT NULL,
     role role NOT NULL,
+    token_version INTEGER NOT NULL DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP
@@ -20,6 +21,7 @@ CREATE TABLE courses (
     title VARCHAR(255) NOT NULL,
     description TEXT,
     mentor_id INTEGER NOT NULL,
+    allowed_file_types VARCHAR(255),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP,
@@ -31,6 +33,7 @@ CREATE TABLE enrollments (
     user_id INTEGER NOT NULL,
     course_id INTEGER NOT NULL,
     enrollment_date TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP,
@@ -42,8 +45,20 @@ CREATE TABLE enrollments (
 CREATE TABLE materials (
     id SERIAL PRIMARY KEY,
     course_id INTEGER NOT NULL,
+    type VARCHAR(20) NOT NULL DEFAULT 'file',
     title VARCHAR(255) NOT NULL,
     file_path TEXT NOT NULL,  -- Changed from VARCHAR(255) to TEXT
+    file_hash VARCHAR(64),
+    file_name VARCHAR(255),
+    stream_format VARCHAR(20),
+    content_format VARCHAR(20),
+    content TEXT,
+    content_html TEXT,
+    url VARCHAR(2048),
+    embed_url VARCHAR(2048),
+    publish_at TIMESTAMP,
+    unpublish_at TIMESTAMP,
+    release_after_days INTEGER,
     uploaded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -51,6 +66,44 @@ CREATE TABLE materials (
     FOREIGN KEY (course_id) REFERENCES courses(id)
 );
 
+CREATE TABLE rubrics (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_by_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (course_id) REFERENCES courses(id),
+    FOREIGN KEY (created_by_id) REFERENCES users(id)
+);
+
+CREATE TABLE rubric_criteria (
+    id SERIAL PRIMARY KEY,
+    rubric_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (rubric_id) REFERENCES rubrics(id)
+);
+
+CREATE TABLE rubric_levels (
+    id SERIAL PRIMARY KEY,
+    criterion_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    points INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (criterion_id) REFERENCES rubric_criteria(id)
+);
+
 CREATE TABLE assignments (
     id SERIAL PRIMARY KEY,
     course_id INTEGER NOT NULL,
@@ -58,10 +111,22 @@ CREATE TABLE assignments (
     description TEXT,
     due_date TIMESTAMP,
     max_score INTEGER,
+    max_attempts INTEGER,
+    allow_late_submissions BOOLEAN NOT NULL DEFAULT FALSE,
+    late_grace_period_minutes INTEGER NOT NULL DEFAULT 0,
+    late_cutoff_date TIMESTAMP,
+    late_penalty_per_day FLOAT NOT NULL DEFAULT 0,
+    rubric_id INTEGER,
+    allowed_file_types VARCHAR(255),
+    max_file_size_mb INTEGER,
+    publish_at TIMESTAMP,
+    unpublish_at TIMESTAMP,
+    release_after_days INTEGER,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP,
-    FOREIGN KEY (course_id) REFERENCES courses(id)
+    FOREIGN KEY (course_id) REFERENCES courses(id),
+    FOREIGN KEY (rubric_id) REFERENCES rubrics(id)
 );
 
 CREATE TABLE discussions (
@@ -73,6 +138,12 @@ CREATE TABLE discussions (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP,
+    accepted_comment_id INTEGER,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    pinned_at TIMESTAMP,
+    locked BOOLEAN NOT NULL DEFAULT FALSE,
+    locked_at TIMESTAMP,
+    locked_by_id INTEGER REFERENCES users(id),
     FOREIGN KEY (course_id) REFERENCES courses(id),
     FOREIGN KEY (user_id) REFERENCES users(id)
 );
@@ -85,19 +156,120 @@ CREATE TABLE comments (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP,
+    parent_id INTEGER REFERENCES comments(id),
+    depth INTEGER NOT NULL DEFAULT 0,
+    hidden_at TIMESTAMP,
+    hidden_by_id INTEGER REFERENCES users(id),
+    hidden_reason TEXT,
     FOREIGN KEY (discussion_id) REFERENCES discussions(id),
     FOREIGN KEY (user_id) REFERENCES users(id)
 );
 
+CREATE INDEX idx_comments_parent_id ON comments(parent_id);
+
+CREATE TYPE reaction_type AS ENUM ('upvote', 'like', 'thanks', 'insightful', 'confused');
+
+CREATE TABLE comment_reactions (
+    id SERIAL PRIMARY KEY,
+    comment_id INTEGER NOT NULL REFERENCES comments(id),
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    type reaction_type NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    UNIQUE (comment_id, user_id, type)
+);
+
+CREATE TYPE report_status AS ENUM ('open', 'resolved', 'dismissed');
+
+CREATE TABLE content_reports (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(id),
+    discussion_id INTEGER NOT NULL REFERENCES discussions(id),
+    comment_id INTEGER REFERENCES comments(id),
+    reporter_id INTEGER NOT NULL REFERENCES users(id),
+    reason TEXT NOT NULL,
+    status report_status NOT NULL DEFAULT 'open',
+    resolved_by_id INTEGER REFERENCES users(id),
+    resolved_at TIMESTAMP,
+    resolution TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_report_course_status ON content_reports(course_id, status);
+
+CREATE TYPE notification_type AS ENUM ('grade', 'reply', 'comment', 'mention', 'assignment', 'material', 'deadline');
+
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    type notification_type NOT NULL,
+    course_id INTEGER NOT NULL REFERENCES courses(id),
+    actor_id INTEGER REFERENCES users(id),
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_notification_user_read ON notifications(user_id, read_at);
+
+CREATE TYPE email_mode AS ENUM ('instant', 'daily', 'off');
+
+CREATE TABLE email_preferences (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id),
+    mode email_mode NOT NULL DEFAULT 'instant',
+    last_digest_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TYPE email_status AS ENUM ('pending', 'sent', 'failed');
+
+CREATE TABLE email_deliveries (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    notification_id INTEGER REFERENCES notifications(id),
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    text_body TEXT,
+    html_body TEXT,
+    status email_status NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_email_delivery_user ON email_deliveries(user_id);
+CREATE INDEX idx_email_delivery_due ON email_deliveries(status, next_attempt_at);
+
 CREATE TABLE submissions (
     id SERIAL PRIMARY KEY,
     assignment_id INTEGER NOT NULL,
     student_id INTEGER NOT NULL,
+    attempt_number INTEGER NOT NULL DEFAULT 1,
     file_path TEXT NOT NULL,  
+    file_hash VARCHAR(64),
+    file_name VARCHAR(255),
     submitted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    is_late BOOLEAN NOT NULL DEFAULT FALSE,
+    days_late INTEGER NOT NULL DEFAULT 0,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP,
+    UNIQUE (assignment_id, student_id, attempt_number),
     FOREIGN KEY (assignment_id) REFERENCES assignments(id),
     FOREIGN KEY (student_id) REFERENCES users(id)
 );
@@ -105,6 +277,8 @@ CREATE TABLE submissions (
 CREATE TABLE assessments (
     id SERIAL PRIMARY KEY,
     submission_id INTEGER NOT NULL UNIQUE,
+    raw_score INTEGER,
+    late_penalty_percent FLOAT NOT NULL DEFAULT 0,
     score INTEGER,
     feedback TEXT,
     assessed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -114,6 +288,22 @@ CREATE TABLE assessments (
     FOREIGN KEY (submission_id) REFERENCES submissions(id)
 );
 
+CREATE TABLE assessment_criterion_scores (
+    id SERIAL PRIMARY KEY,
+    assessment_id INTEGER NOT NULL,
+    criterion_id INTEGER NOT NULL,
+    level_id INTEGER,
+    points INTEGER NOT NULL,
+    max_points INTEGER NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (assessment_id) REFERENCES assessments(id),
+    FOREIGN KEY (criterion_id) REFERENCES rubric_criteria(id),
+    FOREIGN KEY (level_id) REFERENCES rubric_levels(id)
+);
+
 CREATE TABLE learning_progress (
     id SERIAL PRIMARY KEY,
     user_id INTEGER NOT NULL,
@@ -123,13 +313,332 @@ CREATE TABLE learning_progress (
     score FLOAT,
     max_score FLOAT,
     feedback TEXT,
-    graded_by INTEGER,
+    graded_by INTEGER NULL,
     completed BOOLEAN DEFAULT false,
     completed_at TIMESTAMP,
+    views INTEGER NOT NULL DEFAULT 0,
+    downloads INTEGER NOT NULL DEFAULT 0,
+    time_spent_seconds INTEGER NOT NULL DEFAULT 0,
+    watch_percent FLOAT NOT NULL DEFAULT 0,
+    last_accessed_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP,
     FOREIGN KEY (user_id) REFERENCES users(id),
     FOREIGN KEY (course_id) REFERENCES courses(id),
     FOREIGN KEY (graded_by) REFERENCES users(id)
-);
\ No newline at end of file
+);
+
+CREATE TYPE question_type AS ENUM ('multiple_choice', 'true_false', 'numeric');
+CREATE TYPE attempt_status AS ENUM ('in_progress', 'submitted', 'expired');
+
+CREATE TABLE quizzes (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    time_limit_minutes INTEGER,
+    max_attempts INTEGER,
+    due_date TIMESTAMP,
+    publish_at TIMESTAMP,
+    unpublish_at TIMESTAMP,
+    release_after_days INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (course_id) REFERENCES courses(id)
+);
+
+CREATE TABLE quiz_questions (
+    id SERIAL PRIMARY KEY,
+    quiz_id INTEGER NOT NULL,
+    type question_type NOT NULL,
+    prompt TEXT NOT NULL,
+    points FLOAT NOT NULL DEFAULT 1,
+    position INTEGER DEFAULT 0,
+    bool_answer BOOLEAN,
+    numeric_answer FLOAT,
+    tolerance FLOAT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (quiz_id) REFERENCES quizzes(id)
+);
+
+CREATE TABLE quiz_choices (
+    id SERIAL PRIMARY KEY,
+    question_id INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    is_correct BOOLEAN DEFAULT false,
+    position INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (question_id) REFERENCES quiz_questions(id)
+);
+
+CREATE TABLE quiz_attempts (
+    id SERIAL PRIMARY KEY,
+    quiz_id INTEGER NOT NULL,
+    student_id INTEGER NOT NULL,
+    attempt_number INTEGER NOT NULL,
+    status attempt_status NOT NULL DEFAULT 'in_progress',
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    submitted_at TIMESTAMP,
+    score FLOAT,
+    max_score FLOAT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    UNIQUE (quiz_id, student_id, attempt_number),
+    FOREIGN KEY (quiz_id) REFERENCES quizzes(id),
+    FOREIGN KEY (student_id) REFERENCES users(id)
+);
+
+CREATE TABLE quiz_answers (
+    id SERIAL PRIMARY KEY,
+    attempt_id INTEGER NOT NULL,
+    question_id INTEGER NOT NULL,
+    choice_id INTEGER,
+    bool_answer BOOLEAN,
+    numeric_answer FLOAT,
+    is_correct BOOLEAN DEFAULT false,
+    points_awarded FLOAT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (attempt_id) REFERENCES quiz_attempts(id),
+    FOREIGN KEY (question_id) REFERENCES quiz_questions(id)
+);
+
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    family_id VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE TABLE revoked_access_tokens (
+    id SERIAL PRIMARY KEY,
+    jti VARCHAR(64) NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TYPE extension_activity_type AS ENUM ('assignment', 'quiz');
+
+CREATE TABLE due_date_extensions (
+    id SERIAL PRIMARY KEY,
+    activity_type extension_activity_type NOT NULL,
+    activity_id INTEGER NOT NULL,
+    course_id INTEGER NOT NULL,
+    student_id INTEGER NOT NULL,
+    new_due_date TIMESTAMP,
+    extra_minutes INTEGER NOT NULL DEFAULT 0,
+    extra_time_limit_minutes INTEGER NOT NULL DEFAULT 0,
+    reason TEXT,
+    granted_by_id INTEGER NOT NULL,
+    revoked_at TIMESTAMP,
+    revoked_by_id INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (course_id) REFERENCES courses(id),
+    FOREIGN KEY (student_id) REFERENCES users(id),
+    FOREIGN KEY (granted_by_id) REFERENCES users(id),
+    FOREIGN KEY (revoked_by_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_extension_activity ON due_date_extensions (activity_type, activity_id, student_id);
+
+CREATE TABLE grade_categories (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    activity_type progress_type NOT NULL,
+    weight DOUBLE PRECISION NOT NULL,
+    drop_lowest INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (course_id) REFERENCES courses(id),
+    UNIQUE (course_id, activity_type)
+);
+
+CREATE TABLE letter_grades (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL,
+    letter VARCHAR(10) NOT NULL,
+    min_percent DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    FOREIGN KEY (course_id) REFERENCES courses(id)
+);
+
+CREATE INDEX idx_letter_grades_course_id ON letter_grades (course_id);
+
+CREATE INDEX idx_materials_file_hash ON materials (file_hash);
+CREATE INDEX idx_submissions_file_hash ON submissions (file_hash);
+
+CREATE TABLE blobs (
+    id SERIAL PRIMARY KEY,
+    hash VARCHAR(64) NOT NULL UNIQUE,
+    size BIGINT NOT NULL,
+    content_type VARCHAR(255),
+    ref_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE material_files (
+    id SERIAL PRIMARY KEY,
+    material_id INTEGER NOT NULL REFERENCES materials(id),
+    position INTEGER NOT NULL DEFAULT 0,
+    file_path TEXT NOT NULL,
+    file_hash VARCHAR(64),
+    file_name VARCHAR(255),
+    file_size BIGINT NOT NULL DEFAULT 0,
+    content_type VARCHAR(255),
+    stream_format VARCHAR(20),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_material_files_material_id ON material_files (material_id);
+CREATE INDEX idx_material_files_file_hash ON material_files (file_hash);
+
+CREATE TABLE upload_sessions (
+    id SERIAL PRIMARY KEY,
+    token VARCHAR(64) NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    course_id INTEGER NOT NULL REFERENCES courses(id),
+    material_id INTEGER REFERENCES materials(id),
+    title VARCHAR(255),
+    file_name VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL,
+    chunk_size BIGINT NOT NULL,
+    checksum VARCHAR(64),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_upload_sessions_user_id ON upload_sessions(user_id);
+CREATE INDEX idx_upload_sessions_expires_at ON upload_sessions(expires_at);
+
+CREATE TABLE upload_chunks (
+    id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL REFERENCES upload_sessions(id) ON DELETE CASCADE,
+    chunk_index INTEGER NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    UNIQUE (session_id, chunk_index)
+);
+
+CREATE TABLE modules (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL REFERENCES courses(id),
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    position INTEGER NOT NULL DEFAULT 0,
+    publish_at TIMESTAMP,
+    unpublish_at TIMESTAMP,
+    release_after_days INTEGER,
+    prerequisite_module_id INTEGER REFERENCES modules(id),
+    prerequisite_min_percent FLOAT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_modules_course_id ON modules(course_id);
+
+CREATE TABLE module_items (
+    id SERIAL PRIMARY KEY,
+    module_id INTEGER NOT NULL REFERENCES modules(id),
+    item_type progress_type NOT NULL,
+    item_id INTEGER NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    UNIQUE (item_type, item_id)
+);
+
+CREATE INDEX idx_module_items_module_id ON module_items(module_id);
+
+CREATE TABLE completion_rules (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL UNIQUE REFERENCES courses(id),
+    min_percent_complete FLOAT NOT NULL,
+    min_final_percent FLOAT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE completion_activities (
+    id SERIAL PRIMARY KEY,
+    rule_id INTEGER NOT NULL REFERENCES completion_rules(id),
+    activity_type progress_type NOT NULL,
+    activity_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_completion_activities_rule_id ON completion_activities(rule_id);
+
+CREATE TABLE certificate_templates (
+    id SERIAL PRIMARY KEY,
+    course_id INTEGER NOT NULL UNIQUE REFERENCES courses(id),
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE TABLE certificates (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    course_id INTEGER NOT NULL REFERENCES courses(id),
+    enrollment_id INTEGER NOT NULL REFERENCES enrollments(id),
+    student_name VARCHAR(255) NOT NULL,
+    course_title VARCHAR(255) NOT NULL,
+    mentor_name VARCHAR(255),
+    completed_at TIMESTAMP NOT NULL,
+    final_percent FLOAT,
+    letter_grade VARCHAR(10),
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    issued_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    revoked_by_id INTEGER REFERENCES users(id),
+    revocation_reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP,
+    UNIQUE (user_id, course_id)
+);
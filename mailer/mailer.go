@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"log"
+
+)
+
+// Message adalah satu email dengan isi teks biasa dan HTML
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer mengirim email. Implementasi harus aman dipakai dari beberapa goroutine.
+type Mailer interface {
+	// Send mengirim satu pesan; error berarti pesan boleh dicoba lagi nanti
+	Send(message Message) error
+}
+
+// LogMailer hanya mencatat email ke log; dipakai saat pengembangan tanpa server SMTP
+type LogMailer struct{}
+
+// NewLogMailer membuat mailer yang mencatat email ke log
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send mencatat penerima dan subjek email
+func (m *LogMailer) Send(message Message) error {
+	log.Printf("email to %s: %s", message.To, message.Subject)
+	return nil
+}
@@ -0,0 +1,199 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+)
+
+// Mode keamanan koneksi SMTP
+const (
+	SMTPSecurityNone     = "none"
+	SMTPSecuritySTARTTLS = "starttls"
+	SMTPSecurityTLS      = "tls"
+)
+
+// SMTPConfig adalah konfigurasi server SMTP keluar
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// Security adalah "none", "starttls" atau "tls" (TLS langsung, biasanya port 465)
+	Security string
+	Timeout  time.Duration
+}
+
+// SMTPMailer mengirim email lewat server SMTP, satu koneksi per pesan
+type SMTPMailer struct {
+	Config SMTPConfig
+	from   *mail.Address
+}
+
+// NewSMTPMailer membuat mailer SMTP
+func NewSMTPMailer(config SMTPConfig) (*SMTPMailer, error) {
+	if config.Host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	switch config.Security {
+	case "":
+		config.Security = SMTPSecuritySTARTTLS
+	case SMTPSecurityNone, SMTPSecuritySTARTTLS, SMTPSecurityTLS:
+	default:
+		return nil, fmt.Errorf("unknown SMTP security mode %q", config.Security)
+	}
+
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address: %w", err)
+	}
+
+	return &SMTPMailer{Config: config, from: from}, nil
+}
+
+// Send membuka koneksi ke server SMTP dan mengirim satu pesan
+func (m *SMTPMailer) Send(message Message) error {
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+	body, err := m.compose(to, message)
+	if err != nil {
+		return err
+	}
+
+	address := net.JoinHostPort(m.Config.Host, fmt.Sprint(m.Config.Port))
+	tlsConfig := &tls.Config{ServerName: m.Config.Host}
+
+	var conn net.Conn
+	dialer := &net.Dialer{Timeout: m.Config.Timeout}
+	if m.Config.Security == SMTPSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return err
+	}
+	// Batas waktu berlaku untuk seluruh percakapan agar server lambat tidak menahan pengiriman
+	conn.SetDeadline(time.Now().Add(m.Config.Timeout))
+
+	client, err := smtp.NewClient(conn, m.Config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.Config.Security == SMTPSecuritySTARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if m.Config.Username != "" {
+		auth := smtp.PlainAuth("", m.Config.Username, m.Config.Password, m.Config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(body); err != nil {
+		writer.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// compose menyusun pesan MIME multipart/alternative dengan bagian teks dan HTML
+func (m *SMTPMailer) compose(to *mail.Address, message Message) ([]byte, error) {
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	header := func(name, value string) {
+		fmt.Fprintf(&buffer, "%s: %s\r\n", name, value)
+	}
+	header("From", m.from.String())
+	header("To", to.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", boundary, m.Config.Host))
+	header("MIME-Version", "1.0")
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+	buffer.WriteString("\r\n")
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain", message.Text},
+		{"text/html", message.HTML},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+		fmt.Fprintf(&buffer, "--%s\r\n", boundary)
+		header("Content-Type", part.contentType+"; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buffer.WriteString("\r\n")
+
+		writer := quotedprintable.NewWriter(&buffer)
+		if _, err := writer.Write([]byte(strings.ReplaceAll(part.body, "\r\n", "\n"))); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		buffer.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buffer, "--%s--\r\n", boundary)
+
+	return buffer.Bytes(), nil
+}
+
+// randomBoundary membuat pembatas bagian MIME yang juga dipakai sebagai Message-ID
+func randomBoundary() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
@@ -0,0 +1,292 @@
+package mailer
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+)
+
+// smtpSink adalah server SMTP di dalam proses yang menerima satu koneksi dan mencatat percakapannya
+type smtpSink struct {
+	listener   net.Listener
+	extensions []string
+	rejectRcpt bool
+	commands   []string
+	data       string
+	done       chan struct{}
+}
+
+// startSMTPSink menjalankan smtpSink pada port acak di 127.0.0.1
+func startSMTPSink(t *testing.T, rejectRcpt bool, extensions ...string) *smtpSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	sink := &smtpSink{listener: listener, extensions: extensions, rejectRcpt: rejectRcpt, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		defer close(sink.done)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sink.serve(textproto.NewConn(conn))
+	}()
+	return sink
+}
+
+// serve menjawab perintah SMTP sampai QUIT atau koneksi ditutup
+func (s *smtpSink) serve(text *textproto.Conn) {
+	text.PrintfLine("220 sink ESMTP ready")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		s.commands = append(s.commands, line)
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO":
+			replies := append([]string{"sink greets you"}, s.extensions...)
+			for i, reply := range replies {
+				separator := "-"
+				if i == len(replies)-1 {
+					separator = " "
+				}
+				text.PrintfLine("250%s%s", separator, reply)
+			}
+		case "AUTH":
+			text.PrintfLine("235 2.7.0 authenticated")
+		case "MAIL":
+			text.PrintfLine("250 2.1.0 ok")
+		case "RCPT":
+			if s.rejectRcpt {
+				text.PrintfLine("550 5.1.1 mailbox unavailable")
+				continue
+			}
+			text.PrintfLine("250 2.1.5 ok")
+		case "DATA":
+			text.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data = string(data)
+			text.PrintfLine("250 2.0.0 queued")
+		case "QUIT":
+			text.PrintfLine("221 2.0.0 bye")
+			return
+		default:
+			text.PrintfLine("502 5.5.2 command not recognized")
+		}
+	}
+}
+
+// port mengembalikan port tempat sink mendengarkan
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// wait menunggu sink selesai melayani koneksi
+func (s *smtpSink) wait(t *testing.T) {
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP sink did not finish")
+	}
+}
+
+// hasCommand memeriksa apakah klien mengirim perintah dengan awalan tertentu
+func (s *smtpSink) hasCommand(prefix string) bool {
+	for _, command := range s.commands {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestMailer(t *testing.T, sink *smtpSink, security, username string) *SMTPMailer {
+	m, err := NewSMTPMailer(SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     sink.port(),
+		Username: username,
+		Password: "secret",
+		From:     "LMS <noreply@example.com>",
+		Security: security,
+		Timeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSMTPMailer: %v", err)
+	}
+	return m
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	sink := startSMTPSink(t, false, "AUTH PLAIN", "8BITMIME")
+	m := newTestMailer(t, sink, SMTPSecurityNone, "mailer")
+
+	message := Message{
+		To:      "Ana Putri <ana@example.com>",
+		Subject: "Nilai baru: Tugas 1 ✓",
+		Text:    "Halo Ana,\r\nNilai kamu 90. " + strings.Repeat("Catatan panjang dari mentor. ", 5),
+		HTML:    `<p>Halo Ana,</p><p>Nilai kamu <strong>90</strong> = lulus.</p>`,
+	}
+	if err := m.Send(message); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	sink.wait(t)
+
+	for _, command := range []string{"EHLO ", "AUTH PLAIN ", "MAIL FROM:<noreply@example.com>", "RCPT TO:<ana@example.com>", "DATA", "QUIT"} {
+		if !sink.hasCommand(command) {
+			t.Errorf("client did not send %q, commands: %q", command, sink.commands)
+		}
+	}
+	for _, command := range sink.commands {
+		if strings.HasPrefix(command, "AUTH PLAIN ") {
+			credentials, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(command, "AUTH PLAIN "))
+			if string(credentials) != "\x00mailer\x00secret" {
+				t.Errorf("AUTH PLAIN credentials = %q", credentials)
+			}
+		}
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(sink.data))
+	if err != nil {
+		t.Fatalf("parse message: %v\n%s", err, sink.data)
+	}
+	header := parsed.Header
+	if header.Get("From") != `"LMS" <noreply@example.com>` {
+		t.Errorf("From = %q", header.Get("From"))
+	}
+	if header.Get("To") != `"Ana Putri" <ana@example.com>` {
+		t.Errorf("To = %q", header.Get("To"))
+	}
+	if !strings.HasPrefix(header.Get("Subject"), "=?utf-8?q?") {
+		t.Errorf("Subject must be RFC 2047 encoded, got %q", header.Get("Subject"))
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if err != nil || subject != message.Subject {
+		t.Errorf("Subject = %q (%v), want %q", subject, err, message.Subject)
+	}
+	if _, err := header.Date(); err != nil {
+		t.Errorf("Date header: %v", err)
+	}
+	if id := header.Get("Message-ID"); !strings.HasPrefix(id, "<") || !strings.HasSuffix(id, "@127.0.0.1>") {
+		t.Errorf("Message-ID = %q", id)
+	}
+	if header.Get("MIME-Version") != "1.0" {
+		t.Errorf("MIME-Version = %q", header.Get("MIME-Version"))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" || params["boundary"] == "" {
+		t.Fatalf("Content-Type = %q (%v)", header.Get("Content-Type"), err)
+	}
+	if !strings.Contains(header.Get("Message-ID"), params["boundary"]) {
+		t.Errorf("Message-ID %q does not reuse the boundary %q", header.Get("Message-ID"), params["boundary"])
+	}
+
+	wantParts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", strings.ReplaceAll(message.Text, "\r\n", "\n")},
+		{"text/html; charset=utf-8", message.HTML},
+	}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for i, want := range wantParts {
+		part, err := reader.NextRawPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		if part.Header.Get("Content-Type") != want.contentType {
+			t.Errorf("part %d Content-Type = %q, want %q", i, part.Header.Get("Content-Type"), want.contentType)
+		}
+		if part.Header.Get("Content-Transfer-Encoding") != "quoted-printable" {
+			t.Errorf("part %d Content-Transfer-Encoding = %q", i, part.Header.Get("Content-Transfer-Encoding"))
+		}
+
+		raw, _ := io.ReadAll(part)
+		for _, line := range strings.Split(string(raw), "\n") {
+			if len(line) > 76 {
+				t.Errorf("part %d has a %d character line, quoted-printable allows 76", i, len(line))
+			}
+		}
+		decoded, _ := io.ReadAll(quotedprintable.NewReader(strings.NewReader(string(raw))))
+		if got := strings.TrimRight(strings.ReplaceAll(string(decoded), "\r\n", "\n"), "\n"); got != want.body {
+			t.Errorf("part %d body = %q, want %q", i, got, want.body)
+		}
+	}
+	if _, err := reader.NextRawPart(); err != io.EOF {
+		t.Errorf("expected exactly two parts, next part error = %v", err)
+	}
+}
+
+func TestSMTPMailerSendTextOnly(t *testing.T) {
+	sink := startSMTPSink(t, false)
+	m := newTestMailer(t, sink, SMTPSecurityNone, "")
+
+	if err := m.Send(Message{To: "ana@example.com", Subject: "Reminder", Text: "Quiz closes tomorrow."}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	sink.wait(t)
+
+	if sink.hasCommand("AUTH") {
+		t.Errorf("client authenticated without a username: %q", sink.commands)
+	}
+	if strings.Contains(sink.data, "text/html") || !strings.Contains(sink.data, "text/plain") {
+		t.Errorf("text-only message must have only a text/plain part:\n%s", sink.data)
+	}
+}
+
+func TestSMTPMailerSendErrors(t *testing.T) {
+	t.Run("STARTTLS not offered", func(t *testing.T) {
+		sink := startSMTPSink(t, false)
+		m := newTestMailer(t, sink, SMTPSecuritySTARTTLS, "")
+
+		err := m.Send(Message{To: "ana@example.com", Subject: "x", Text: "x"})
+		if err == nil || !strings.Contains(err.Error(), "does not support STARTTLS") {
+			t.Fatalf("error = %v, want STARTTLS to be required", err)
+		}
+		sink.wait(t)
+		if sink.hasCommand("MAIL") {
+			t.Error("message was sent without STARTTLS")
+		}
+	})
+
+	t.Run("recipient rejected", func(t *testing.T) {
+		sink := startSMTPSink(t, true)
+		m := newTestMailer(t, sink, SMTPSecurityNone, "")
+
+		err := m.Send(Message{To: "ana@example.com", Subject: "x", Text: "x"})
+		if err == nil || !strings.Contains(err.Error(), "550") {
+			t.Fatalf("error = %v, want the 550 reply", err)
+		}
+		sink.wait(t)
+		if sink.hasCommand("DATA") {
+			t.Error("DATA was sent after the recipient was rejected")
+		}
+	})
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		sink := startSMTPSink(t, false)
+		m := newTestMailer(t, sink, SMTPSecurityNone, "")
+
+		if err := m.Send(Message{To: "not an address", Subject: "x", Text: "x"}); err == nil {
+			t.Fatal("expected an invalid recipient error")
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Nama templat email bawaan
+const (
+	TemplateNotification = "notification"
+	TemplateDigest       = "digest"
+)
+
+// Templat disertakan di dalam biner sehingga kesalahan sintaks sudah ketahuan saat aplikasi mulai
+var (
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+)
+
+// Render mengisi templat name.txt dan name.html dengan data yang sama.
+// Hanya bagian HTML yang meloloskan karakter khusus.
+func Render(name string, data interface{}) (string, string, error) {
+	var text, html bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return "", "", err
+	}
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return "", "", err
+	}
+	return text.String(), html.String(), nil
+}
@@ -16,6 +16,34 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Inisialisasi penyimpanan berkas
+	store, err := config.InitStorage()
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+
+	// Batas unggahan berkas
+	uploadLimits, err := config.BuildUploadLimits()
+	if err != nil {
+		log.Fatalf("Failed to load upload limits: %v", err)
+	}
+
+	// Ambang penyelesaian materi otomatis
+	materialCompletion, err := config.BuildMaterialCompletion()
+	if err != nil {
+		log.Fatalf("Failed to load material completion settings: %v", err)
+	}
+
+	// Pengirim email pemberitahuan
+	mail, err := config.InitMailer()
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	emailSettings, err := config.BuildEmailSettings()
+	if err != nil {
+		log.Fatalf("Failed to load email settings: %v", err)
+	}
+
 	// Inisialisasi router dengan koneksi database
 	router := gin.Default()
 
@@ -34,7 +62,7 @@ func main() {
 		c.Next()
 	})
 
-	routes.SetupRoutes(router, db)
+	routes.SetupRoutes(router, db, store, uploadLimits, materialCompletion, mail, emailSettings)
 
 	// memulai server
 	log.Println("Server started on :8080")
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+)
+
+// BodySizeLimit menolak permintaan yang badannya melebihi limit byte sebelum berkas diterima seluruhnya
+func BodySizeLimit(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > limit {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large"})
+			c.Abort()
+			return
+		}
+
+		// Badan tanpa Content-Length tetap dibatasi saat dibaca
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
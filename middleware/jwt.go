@@ -2,7 +2,7 @@ package middleware
 
 import (
 	"LMS/models"
-	"LMS/utils"
+	"LMS/services"
 	"net/http"
 	"strings"
 
@@ -10,8 +10,8 @@ import (
 
 )
 
-// AuthMiddleware memverifikasi token JWT dan mengatur pengguna dalam konteks
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware memverifikasi token JWT, menolak token yang dicabut, dan mengatur pengguna dalam konteks
+func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -31,8 +31,8 @@ func AuthMiddleware() gin.HandlerFunc {
 		// dapatkan token
 		tokenString := parts[1]
 
-		// validasi token
-		claims, err := utils.ValidateToken(tokenString)
+		// validasi token dan periksa pencabutan
+		claims, err := authService.ValidateAccessToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
@@ -43,6 +43,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("claims", claims)
 
 		c.Next()
 	}
@@ -9,11 +9,14 @@ import (
 
 type Assessment struct {
 	gorm.Model
-	ID           uint       `gorm:"primaryKey" json:"id"`
-	SubmissionID uint       `gorm:"not null;uniqueIndex" json:"submission_id"`
-	Submission   Submission `gorm:"foreignKey:SubmissionID" json:"submission,omitempty"`
-	Score        *int       `json:"score"`
-	Feedback     string     `gorm:"type:text" json:"feedback"`
-	AssessedAt   time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"assessed_at"`
-	UpdatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+	ID                 uint                       `gorm:"primaryKey" json:"id"`
+	SubmissionID       uint                       `gorm:"not null;uniqueIndex" json:"submission_id"`
+	Submission         Submission                 `gorm:"foreignKey:SubmissionID" json:"submission,omitempty"`
+	RawScore           *int                       `json:"raw_score"`
+	LatePenaltyPercent float64                    `gorm:"not null;default:0" json:"late_penalty_percent"`
+	Score              *int                       `json:"score"`
+	Feedback           string                     `gorm:"type:text" json:"feedback"`
+	CriterionScores    []AssessmentCriterionScore `gorm:"foreignKey:AssessmentID" json:"criterion_scores,omitempty"`
+	AssessedAt         time.Time                  `gorm:"default:CURRENT_TIMESTAMP" json:"assessed_at"`
+	UpdatedAt          time.Time                  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
 }
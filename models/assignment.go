@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"gorm.io/gorm"
@@ -9,14 +10,36 @@ import (
 
 type Assignment struct {
 	gorm.Model
-	ID          uint         `gorm:"primaryKey" json:"id"`
-	CourseID    uint         `gorm:"not null" json:"course_id"`
-	Course      Course       `gorm:"foreignKey:CourseID" json:"course,omitempty"`
-	Title       string       `gorm:"size:255;not null" json:"title"`
-	Description string       `gorm:"type:text" json:"description"`
-	DueDate     *time.Time   `json:"due_date"`
-	MaxScore    *int         `json:"max_score"`
-	CreatedAt   time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
-	Submissions []Submission `gorm:"foreignKey:AssignmentID" json:"submissions,omitempty"`
-	UpdatedAt   time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+	Availability
+	ID                     uint         `gorm:"primaryKey" json:"id"`
+	CourseID               uint         `gorm:"not null" json:"course_id"`
+	Course                 Course       `gorm:"foreignKey:CourseID" json:"course,omitempty"`
+	Title                  string       `gorm:"size:255;not null" json:"title"`
+	Description            string       `gorm:"type:text" json:"description"`
+	DueDate                *time.Time   `json:"due_date"`
+	MaxScore               *int         `json:"max_score"`
+	MaxAttempts            *int         `json:"max_attempts"`
+	AllowLateSubmissions   bool         `gorm:"not null;default:false" json:"allow_late_submissions"`
+	LateGracePeriodMinutes int          `gorm:"not null;default:0" json:"late_grace_period_minutes"`
+	LateCutoffDate         *time.Time   `json:"late_cutoff_date"`
+	LatePenaltyPerDay      float64      `gorm:"not null;default:0" json:"late_penalty_per_day"`
+	RubricID               *uint        `json:"rubric_id"`
+	Rubric                 *Rubric      `gorm:"foreignKey:RubricID" json:"rubric,omitempty"`
+	AllowedFileTypes       string       `gorm:"size:255" json:"allowed_file_types"`
+	MaxFileSizeMB          *int         `json:"max_file_size_mb"`
+	CreatedAt              time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	Submissions            []Submission `gorm:"foreignKey:AssignmentID" json:"submissions,omitempty"`
+	UpdatedAt              time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+
+	// Diisi untuk tampilan siswa dari perpanjangan tenggat yang berlaku
+	EffectiveDueDate *time.Time        `gorm:"-" json:"effective_due_date,omitempty"`
+	Extension        *DueDateExtension `gorm:"-" json:"extension,omitempty"`
+}
+
+// LatePenaltyPercent menghitung persentase potongan nilai untuk jumlah hari keterlambatan, maksimal 100
+func (a *Assignment) LatePenaltyPercent(daysLate int) float64 {
+	if daysLate <= 0 || a.LatePenaltyPerDay <= 0 {
+		return 0
+	}
+	return math.Min(100, a.LatePenaltyPerDay*float64(daysLate))
 }
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+)
+
+// Alasan konten kursus terkunci bagi siswa
+const (
+	LockReasonScheduled    = "scheduled"
+	LockReasonUnpublished  = "unpublished"
+	LockReasonDrip         = "drip"
+	LockReasonPrerequisite = "prerequisite"
+)
+
+// Availability adalah aturan kapan konten kursus terbuka bagi siswa: tanggal terbit dan tanggal
+// ditarik, serta jadwal bertahap sejumlah hari setelah siswa mendaftar di kursus
+type Availability struct {
+	PublishAt        *time.Time        `json:"publish_at"`
+	UnpublishAt      *time.Time        `json:"unpublish_at"`
+	ReleaseAfterDays *int              `json:"release_after_days"`
+	Lock             *AvailabilityLock `gorm:"-" json:"lock,omitempty"`
+}
+
+// AvailabilityLock menjelaskan kenapa konten terkunci dan syarat untuk membukanya
+type AvailabilityLock struct {
+	Reason               string     `json:"reason"`
+	Message              string     `json:"message"`
+	UnlocksAt            *time.Time `json:"unlocks_at,omitempty"`
+	ModuleID             *uint      `json:"module_id,omitempty"`
+	PrerequisiteModuleID *uint      `json:"prerequisite_module_id,omitempty"`
+	RequiredPercent      float64    `json:"required_percent,omitempty"`
+	CurrentPercent       float64    `json:"current_percent,omitempty"`
+}
+
+// LockAt memeriksa aturan pada waktu now untuk siswa yang mendaftar pada enrolledAt.
+// enrolledAt bernilai nil jika siswa belum terdaftar. Mengembalikan nil jika konten terbuka.
+func (a Availability) LockAt(now time.Time, enrolledAt *time.Time) *AvailabilityLock {
+	if a.UnpublishAt != nil && !now.Before(*a.UnpublishAt) {
+		return &AvailabilityLock{
+			Reason:  LockReasonUnpublished,
+			Message: "no longer available since " + a.UnpublishAt.Format(time.RFC3339),
+		}
+	}
+
+	if a.PublishAt != nil && now.Before(*a.PublishAt) {
+		unlocksAt := *a.PublishAt
+		return &AvailabilityLock{
+			Reason:    LockReasonScheduled,
+			Message:   "available from " + unlocksAt.Format(time.RFC3339),
+			UnlocksAt: &unlocksAt,
+		}
+	}
+
+	if a.ReleaseAfterDays != nil && *a.ReleaseAfterDays > 0 {
+		message := fmt.Sprintf("available %d days after enrollment", *a.ReleaseAfterDays)
+		if enrolledAt == nil {
+			return &AvailabilityLock{Reason: LockReasonDrip, Message: message}
+		}
+		unlocksAt := enrolledAt.AddDate(0, 0, *a.ReleaseAfterDays)
+		if now.Before(unlocksAt) {
+			return &AvailabilityLock{
+				Reason:    LockReasonDrip,
+				Message:   message + ", from " + unlocksAt.Format(time.RFC3339),
+				UnlocksAt: &unlocksAt,
+			}
+		}
+	}
+
+	return nil
+}
+
+// HideContent mengosongkan isi materi yang terkunci sehingga hanya judul dan syarat bukanya yang tampil
+func (m *Material) HideContent() {
+	m.FilePath = ""
+	m.FileHash = ""
+	m.FileName = ""
+	m.Content = ""
+	m.ContentHTML = ""
+	m.URL = ""
+	m.EmbedURL = ""
+	m.Files = nil
+}
+
+// HideContent mengosongkan isi tugas yang terkunci sehingga hanya judul dan syarat bukanya yang tampil
+func (a *Assignment) HideContent() {
+	a.Description = ""
+	a.Rubric = nil
+}
+
+// HideContent mengosongkan isi kuis yang terkunci sehingga hanya judul dan syarat bukanya yang tampil
+func (q *Quiz) HideContent() {
+	q.Description = ""
+	q.Questions = nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// Blob adalah isi berkas yang disimpan sekali berdasarkan hash SHA-256-nya dan dipakai bersama
+type Blob struct {
+	gorm.Model
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Hash        string    `gorm:"size:64;not null;uniqueIndex" json:"hash"`
+	Size        int64     `gorm:"not null" json:"size"`
+	ContentType string    `gorm:"size:255" json:"content_type"`
+	RefCount    int       `gorm:"not null;default:0" json:"ref_count"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// BlobKey mengembalikan kunci penyimpanan untuk hash, misalnya "blobs/ab/cd/abcd..."
+func BlobKey(hash string) string {
+	return "blobs/" + hash[0:2] + "/" + hash[2:4] + "/" + hash
+}
+
+// BlobHashFromKey mengambil hash dari kunci penyimpanan blob; ok bernilai false jika kunci bukan kunci blob
+func BlobHashFromKey(key string) (string, bool) {
+	hash := key[strings.LastIndex(key, "/")+1:]
+	if len(hash) != 64 || BlobKey(hash) != key {
+		return "", false
+	}
+	return hash, true
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// CertificateTemplate adalah templat sertifikat penyelesaian sebuah kursus.
+// Judul dan isi boleh memuat placeholder seperti {{student_name}} dan {{course_title}}.
+type CertificateTemplate struct {
+	gorm.Model
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CourseID  uint      `gorm:"not null;uniqueIndex" json:"course_id"`
+	Title     string    `gorm:"size:255;not null" json:"title"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// Certificate adalah sertifikat penyelesaian yang diterbitkan untuk seorang siswa.
+// Data siswa, kursus dan teks templat disimpan saat penerbitan agar sertifikat tidak berubah kemudian.
+// Sertifikat tidak pernah dihapus; pencabutan dicatat beserta alasannya.
+type Certificate struct {
+	gorm.Model
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	Code             string     `gorm:"size:32;not null;uniqueIndex" json:"code"`
+	UserID           uint       `gorm:"not null;uniqueIndex:idx_certificate_user_course" json:"user_id"`
+	CourseID         uint       `gorm:"not null;uniqueIndex:idx_certificate_user_course" json:"course_id"`
+	EnrollmentID     uint       `gorm:"not null" json:"enrollment_id"`
+	StudentName      string     `gorm:"size:255;not null" json:"student_name"`
+	CourseTitle      string     `gorm:"size:255;not null" json:"course_title"`
+	MentorName       string     `gorm:"size:255" json:"mentor_name"`
+	CompletedAt      time.Time  `gorm:"not null" json:"completed_at"`
+	FinalPercent     *float64   `json:"final_percent"`
+	LetterGrade      string     `gorm:"size:10" json:"letter_grade"`
+	Title            string     `gorm:"size:255;not null" json:"title"`
+	Body             string     `gorm:"type:text;not null" json:"body"`
+	IssuedAt         time.Time  `gorm:"not null" json:"issued_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	RevokedByID      *uint      `json:"revoked_by_id"`
+	RevocationReason string     `gorm:"type:text" json:"revocation_reason"`
+	CreatedAt        time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
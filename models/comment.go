@@ -17,4 +17,42 @@ type Comment struct {
 	Content      string     `gorm:"type:text" json:"content"`
 	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+
+	// Balasan berantai: komentar teratas memiliki ParentID nil dan Depth 0
+	ParentID *uint `gorm:"index" json:"parent_id"`
+	Depth    int   `gorm:"not null;default:0" json:"depth"`
+
+	// Komentar yang disembunyikan moderator tetap ada di pohon, tetapi isinya hanya terlihat
+	// oleh moderator dan penulisnya
+	HiddenAt     *time.Time `json:"hidden_at"`
+	HiddenByID   *uint      `json:"hidden_by_id"`
+	HiddenReason string     `gorm:"type:text" json:"hidden_reason,omitempty"`
+
+	// Diisi saat komentar ditampilkan sebagai pohon
+	Replies        []Comment      `gorm:"-" json:"replies,omitempty"`
+	ReactionCounts map[string]int `gorm:"-" json:"reaction_counts,omitempty"`
+	MyReactions    []ReactionType `gorm:"-" json:"my_reactions,omitempty"`
+	IsAccepted     bool           `gorm:"-" json:"is_accepted"`
+}
+
+// ReactionType adalah jenis reaksi yang dapat diberikan pada komentar
+type ReactionType string
+
+const (
+	ReactionUpvote     ReactionType = "upvote"
+	ReactionLike       ReactionType = "like"
+	ReactionThanks     ReactionType = "thanks"
+	ReactionInsightful ReactionType = "insightful"
+	ReactionConfused   ReactionType = "confused"
+)
+
+// CommentReaction adalah reaksi seorang pengguna pada komentar; satu pengguna hanya sekali per jenis
+type CommentReaction struct {
+	gorm.Model
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	CommentID uint         `gorm:"not null;uniqueIndex:idx_comment_reaction" json:"comment_id"`
+	UserID    uint         `gorm:"not null;uniqueIndex:idx_comment_reaction" json:"user_id"`
+	Type      ReactionType `gorm:"type:enum('upvote','like','thanks','insightful','confused');not null;uniqueIndex:idx_comment_reaction" json:"type"`
+	CreatedAt time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// CompletionRule adalah kriteria penyelesaian sebuah kursus: persentase aktivitas wajib yang harus
+// diselesaikan dan nilai akhir minimum. Kursus tanpa aturan selesai setelah semua materi, tugas dan kuisnya selesai.
+type CompletionRule struct {
+	gorm.Model
+	ID                 uint                 `gorm:"primaryKey" json:"id"`
+	CourseID           uint                 `gorm:"not null;uniqueIndex" json:"course_id"`
+	MinPercentComplete float64              `gorm:"not null" json:"min_percent_complete"`
+	MinFinalPercent    *float64             `json:"min_final_percent"`
+	Activities         []CompletionActivity `gorm:"foreignKey:RuleID" json:"activities"`
+	CreatedAt          time.Time            `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt          time.Time            `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// CompletionActivity adalah aktivitas yang wajib diselesaikan. Jika aturan tidak memiliki aktivitas,
+// semua materi, tugas dan kuis kursus menjadi wajib.
+type CompletionActivity struct {
+	gorm.Model
+	ID           uint         `gorm:"primaryKey" json:"id"`
+	RuleID       uint         `gorm:"not null;index" json:"rule_id"`
+	ActivityType ProgressType `gorm:"type:enum('material','assignment','quiz','discussion');not null" json:"activity_type"`
+	ActivityID   uint         `gorm:"not null" json:"activity_id"`
+	Title        string       `gorm:"-" json:"title"`
+	CreatedAt    time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
@@ -9,15 +9,18 @@ import (
 
 type Course struct {
 	gorm.Model
-	ID          uint         `gorm:"primaryKey" json:"id"`
-	Title       string       `gorm:"size:255;not null" json:"title"`
-	Description string       `gorm:"type:text" json:"description"`
-	MentorID    uint         `gorm:"not null" json:"mentor_id"`
-	Mentor      User         `gorm:"foreignKey:MentorID" json:"mentor,omitempty"`
-	CreatedAt   time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt   time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
-	Materials   []Material   `gorm:"foreignKey:CourseID" json:"materials,omitempty"`
-	Assignments []Assignment `gorm:"foreignKey:CourseID" json:"assignments,omitempty"`
-	Enrollments []Enrollment `gorm:"foreignKey:CourseID" json:"enrollments,omitempty"`
-	Discussions []Discussion `gorm:"foreignKey:CourseID" json:"discussions,omitempty"`
+	ID               uint         `gorm:"primaryKey" json:"id"`
+	Title            string       `gorm:"size:255;not null" json:"title"`
+	Description      string       `gorm:"type:text" json:"description"`
+	MentorID         uint         `gorm:"not null" json:"mentor_id"`
+	AllowedFileTypes string       `gorm:"size:255" json:"allowed_file_types"`
+	Mentor           User         `gorm:"foreignKey:MentorID" json:"mentor,omitempty"`
+	CreatedAt        time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt        time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+	Materials        []Material   `gorm:"foreignKey:CourseID" json:"materials,omitempty"`
+	Assignments      []Assignment `gorm:"foreignKey:CourseID" json:"assignments,omitempty"`
+	Enrollments      []Enrollment `gorm:"foreignKey:CourseID" json:"enrollments,omitempty"`
+	Discussions      []Discussion `gorm:"foreignKey:CourseID" json:"discussions,omitempty"`
+	Modules          []Module     `gorm:"foreignKey:CourseID" json:"modules,omitempty"`
+	UngroupedItems   []ModuleItem `gorm:"-" json:"ungrouped_items,omitempty"`
 }
@@ -19,4 +19,15 @@ type Discussion struct {
 	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
 	Comments  []Comment `gorm:"foreignKey:DiscussionID" json:"comments,omitempty"`
+
+	// Komentar yang ditandai sebagai jawaban oleh penulis diskusi atau mentor kursus
+	AcceptedCommentID *uint `json:"accepted_comment_id"`
+
+	// Moderasi oleh mentor kursus atau admin: diskusi yang disematkan tampil paling atas,
+	// diskusi yang dikunci tidak menerima komentar baru
+	Pinned     bool       `gorm:"not null;default:false" json:"pinned"`
+	PinnedAt   *time.Time `json:"pinned_at"`
+	Locked     bool       `gorm:"not null;default:false" json:"locked"`
+	LockedAt   *time.Time `json:"locked_at"`
+	LockedByID *uint      `json:"locked_by_id"`
 }
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// ExtensionActivityType adalah jenis aktivitas yang dapat diberi perpanjangan
+type ExtensionActivityType string
+
+const (
+	ExtensionActivityAssignment ExtensionActivityType = "assignment"
+	ExtensionActivityQuiz       ExtensionActivityType = "quiz"
+)
+
+// DueDateExtension adalah perpanjangan tenggat atau akomodasi waktu untuk satu siswa.
+// Perpanjangan tidak pernah dihapus; pencabutan dan penggantian dicatat sebagai jejak audit.
+type DueDateExtension struct {
+	gorm.Model
+	ID                    uint                  `gorm:"primaryKey" json:"id"`
+	ActivityType          ExtensionActivityType `gorm:"type:enum('assignment','quiz');not null;index:idx_extension_activity" json:"activity_type"`
+	ActivityID            uint                  `gorm:"not null;index:idx_extension_activity" json:"activity_id"`
+	CourseID              uint                  `gorm:"not null" json:"course_id"`
+	StudentID             uint                  `gorm:"not null;index:idx_extension_activity" json:"student_id"`
+	Student               User                  `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	NewDueDate            *time.Time            `json:"new_due_date"`
+	ExtraMinutes          int                   `gorm:"not null;default:0" json:"extra_minutes"`
+	ExtraTimeLimitMinutes int                   `gorm:"not null;default:0" json:"extra_time_limit_minutes"`
+	Reason                string                `gorm:"type:text" json:"reason"`
+	GrantedByID           uint                  `gorm:"not null" json:"granted_by_id"`
+	GrantedBy             User                  `gorm:"foreignKey:GrantedByID" json:"granted_by,omitempty"`
+	RevokedAt             *time.Time            `json:"revoked_at"`
+	RevokedByID           *uint                 `json:"revoked_by_id"`
+	CreatedAt             time.Time             `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt             time.Time             `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// ApplyToDueDate menghitung tenggat efektif dari tenggat asli aktivitas
+func (e *DueDateExtension) ApplyToDueDate(dueDate *time.Time) *time.Time {
+	if e.NewDueDate != nil {
+		effective := *e.NewDueDate
+		return &effective
+	}
+	if dueDate == nil {
+		return nil
+	}
+	effective := dueDate.Add(time.Duration(e.ExtraMinutes) * time.Minute)
+	return &effective
+}
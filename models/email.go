@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// EmailMode menentukan cara pengguna menerima pemberitahuan lewat email
+type EmailMode string
+
+const (
+	EmailModeInstant EmailMode = "instant"
+	EmailModeDaily   EmailMode = "daily"
+	EmailModeOff     EmailMode = "off"
+)
+
+// EmailPreference adalah pilihan email pemberitahuan seorang pengguna.
+// Pengguna tanpa catatan preferensi menerima email seketika.
+type EmailPreference struct {
+	gorm.Model
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	Mode         EmailMode  `gorm:"type:enum('instant','daily','off');not null;default:'instant'" json:"mode"`
+	LastDigestAt *time.Time `json:"last_digest_at"`
+	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// EmailStatus adalah status pengiriman sebuah email di antrean
+type EmailStatus string
+
+const (
+	EmailStatusPending EmailStatus = "pending"
+	EmailStatusSent    EmailStatus = "sent"
+	EmailStatusFailed  EmailStatus = "failed"
+)
+
+// EmailDelivery adalah email yang sudah dirender dan menunggu dikirim oleh pekerja latar belakang.
+// Pengiriman yang gagal dicoba lagi pada NextAttemptAt sampai batas percobaan habis.
+type EmailDelivery struct {
+	gorm.Model
+	ID             uint        `gorm:"primaryKey" json:"id"`
+	UserID         uint        `gorm:"not null;index" json:"user_id"`
+	NotificationID *uint       `json:"notification_id"`
+	Recipient      string      `gorm:"size:255;not null" json:"recipient"`
+	Subject        string      `gorm:"size:255;not null" json:"subject"`
+	TextBody       string      `gorm:"type:text" json:"-"`
+	HTMLBody       string      `gorm:"type:mediumtext" json:"-"`
+	Status         EmailStatus `gorm:"type:enum('pending','sent','failed');not null;default:'pending';index:idx_email_delivery_due" json:"status"`
+	Attempts       int         `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time   `gorm:"not null;index:idx_email_delivery_due" json:"next_attempt_at"`
+	LastError      string      `gorm:"type:text" json:"last_error"`
+	SentAt         *time.Time  `json:"sent_at"`
+	CreatedAt      time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
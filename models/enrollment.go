@@ -16,4 +16,7 @@ type Enrollment struct {
 	Course         Course    `gorm:"foreignKey:CourseID" json:"course,omitempty"`
 	EnrollmentDate time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"enrollment_date"`
 	UpdatedAt      time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+
+	// Waktu siswa pertama kali memenuhi aturan penyelesaian kursus
+	CompletedAt *time.Time `json:"completed_at"`
 }
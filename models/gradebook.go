@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// GradeCategory adalah kategori penilaian berbobot dalam sebuah kursus.
+// Setiap kategori mengelompokkan satu jenis aktivitas kemajuan.
+type GradeCategory struct {
+	gorm.Model
+	ID           uint         `gorm:"primaryKey" json:"id"`
+	CourseID     uint         `gorm:"not null;uniqueIndex:idx_grade_category_type" json:"course_id"`
+	Name         string       `gorm:"size:100;not null" json:"name"`
+	ActivityType ProgressType `gorm:"type:enum('material','assignment','quiz','discussion');not null;uniqueIndex:idx_grade_category_type" json:"activity_type"`
+	Weight       float64      `gorm:"not null" json:"weight"`
+	DropLowest   int          `gorm:"not null;default:0" json:"drop_lowest"`
+	CreatedAt    time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// LetterGrade adalah satu batas nilai huruf pada skala nilai kursus
+type LetterGrade struct {
+	gorm.Model
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	CourseID   uint      `gorm:"not null;index" json:"course_id"`
+	Letter     string    `gorm:"size:10;not null" json:"letter"`
+	MinPercent float64   `gorm:"not null" json:"min_percent"`
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// DefaultLetterGrades adalah skala nilai yang dipakai jika kursus belum menentukan skalanya sendiri
+func DefaultLetterGrades() []LetterGrade {
+	return []LetterGrade{
+		{Letter: "A", MinPercent: 90},
+		{Letter: "B", MinPercent: 80},
+		{Letter: "C", MinPercent: 70},
+		{Letter: "D", MinPercent: 60},
+		{Letter: "E", MinPercent: 0},
+	}
+}
@@ -29,11 +29,18 @@ type LearningProgress struct {
 	Score         *float64     `json:"score"`
 	MaxScore      *float64     `json:"max_score"`
 	Feedback      string       `gorm:"type:text" json:"feedback"`
-	GradedBy      uint         `json:"graded_by"`
+	GradedBy      *uint        `json:"graded_by"`
 	Grader        User         `gorm:"foreignKey:GradedBy" json:"grader,omitempty"`
 	GraderName    string       `gorm:"-" json:"grader_name,omitempty"`
 	Completed     bool         `gorm:"default:false" json:"completed"`
 	CompletedAt   *time.Time   `json:"completed_at"`
 	CreatedAt     time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt     time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Akses siswa ke materi yang dicatat otomatis dari unduhan dan laporan tayangan
+	Views            int        `gorm:"not null;default:0" json:"views"`
+	Downloads        int        `gorm:"not null;default:0" json:"downloads"`
+	TimeSpentSeconds int        `gorm:"not null;default:0" json:"time_spent_seconds"`
+	WatchPercent     float64    `gorm:"not null;default:0" json:"watch_percent"`
+	LastAccessedAt   *time.Time `json:"last_accessed_at"`
 }
@@ -7,13 +7,61 @@ import (
 
 )
 
+// Jenis materi
+const (
+	MaterialTypeFile   = "file"
+	MaterialTypePage   = "page"
+	MaterialTypeLink   = "link"
+	MaterialTypeVideo  = "video"
+	MaterialTypeBundle = "bundle"
+)
+
+// Format isi materi halaman
+const (
+	ContentFormatMarkdown = "markdown"
+	ContentFormatHTML     = "html"
+)
+
+// Format pemutaran berkas materi
+const (
+	StreamFormatProgressive = "progressive"
+	StreamFormatHLS         = "hls"
+)
+
 type Material struct {
 	gorm.Model
-	ID         uint      `gorm:"primaryKey" json:"id"`
-	CourseID   uint      `gorm:"not null" json:"course_id"`
-	Course     Course    `gorm:"foreignKey:CourseID" json:"course,omitempty"`
-	Title      string    `gorm:"size:255;not null" json:"title"`
-	FilePath   string    `gorm:"size:255;not null" json:"file_path"`
-	UploadedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"uploaded_at"`
-	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+	Availability
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	CourseID      uint           `gorm:"not null" json:"course_id"`
+	Course        Course         `gorm:"foreignKey:CourseID" json:"course,omitempty"`
+	Type          string         `gorm:"size:20;not null;default:'file'" json:"type"`
+	Title         string         `gorm:"size:255;not null" json:"title"`
+	FilePath      string         `gorm:"size:255;not null" json:"file_path"`
+	FileHash      string         `gorm:"size:64;index" json:"file_hash"`
+	FileName      string         `gorm:"size:255" json:"file_name"`
+	StreamFormat  string         `gorm:"size:20" json:"stream_format"`
+	ContentFormat string         `gorm:"size:20" json:"content_format,omitempty"`
+	Content       string         `gorm:"type:mediumtext" json:"content,omitempty"`
+	ContentHTML   string         `gorm:"type:mediumtext" json:"content_html,omitempty"`
+	URL           string         `gorm:"size:2048" json:"url,omitempty"`
+	EmbedURL      string         `gorm:"size:2048" json:"embed_url,omitempty"`
+	Files         []MaterialFile `gorm:"foreignKey:MaterialID" json:"files,omitempty"`
+	UploadedAt    time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"uploaded_at"`
+	UpdatedAt     time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+}
+
+// MaterialFile adalah satu berkas di dalam materi bundel
+type MaterialFile struct {
+	gorm.Model
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	MaterialID   uint      `gorm:"not null;index" json:"material_id"`
+	Position     int       `gorm:"not null;default:0" json:"position"`
+	FilePath     string    `gorm:"size:255;not null" json:"file_path"`
+	FileHash     string    `gorm:"size:64;index" json:"file_hash"`
+	FileName     string    `gorm:"size:255" json:"file_name"`
+	FileSize     int64     `gorm:"not null;default:0" json:"file_size"`
+	ContentType  string    `gorm:"size:255" json:"content_type"`
+	StreamFormat string    `gorm:"size:20" json:"stream_format"`
+	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
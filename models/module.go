@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// Module adalah bagian kursus yang mengelompokkan materi, tugas, kuis dan diskusi secara berurutan
+type Module struct {
+	gorm.Model
+	Availability
+	ID                     uint         `gorm:"primaryKey" json:"id"`
+	CourseID               uint         `gorm:"not null;index" json:"course_id"`
+	Title                  string       `gorm:"size:255;not null" json:"title"`
+	Description            string       `gorm:"type:text" json:"description"`
+	Position               int          `gorm:"not null;default:0" json:"position"`
+	PrerequisiteModuleID   *uint        `json:"prerequisite_module_id"`
+	PrerequisiteMinPercent float64      `gorm:"not null;default:0" json:"prerequisite_min_percent"`
+	Items                  []ModuleItem `gorm:"foreignKey:ModuleID" json:"items"`
+	CreatedAt              time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt              time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// ModuleItem menempatkan satu aktivitas kursus di dalam modul. Setiap aktivitas hanya boleh berada di satu modul.
+type ModuleItem struct {
+	gorm.Model
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	ModuleID  uint         `gorm:"not null;index" json:"module_id"`
+	ItemType  ProgressType `gorm:"type:enum('material','assignment','quiz','discussion');not null;uniqueIndex:idx_module_item" json:"item_type"`
+	ItemID    uint         `gorm:"not null;uniqueIndex:idx_module_item" json:"item_id"`
+	Position  int          `gorm:"not null;default:0" json:"position"`
+	CreatedAt time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Judul, isi dan kunci aktivitas yang diisi saat kerangka kursus disusun
+	Title string            `gorm:"-" json:"title"`
+	Item  interface{}       `gorm:"-" json:"item,omitempty"`
+	Lock  *AvailabilityLock `gorm:"-" json:"lock,omitempty"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// NotificationType adalah jenis pemberitahuan untuk pengguna
+type NotificationType string
+
+const (
+	NotificationGrade      NotificationType = "grade"
+	NotificationReply      NotificationType = "reply"
+	NotificationComment    NotificationType = "comment"
+	NotificationMention    NotificationType = "mention"
+	NotificationAssignment NotificationType = "assignment"
+	NotificationMaterial   NotificationType = "material"
+	NotificationDeadline   NotificationType = "deadline"
+)
+
+// Notification adalah pemberitahuan dalam kotak masuk seorang pengguna.
+// ResourceType dan ResourceID menunjuk sumber daya yang dibicarakan, misalnya komentar atau penilaian.
+type Notification struct {
+	gorm.Model
+	ID           uint             `gorm:"primaryKey" json:"id"`
+	UserID       uint             `gorm:"not null;index:idx_notification_user_read" json:"user_id"`
+	Type         NotificationType `gorm:"type:enum('grade','reply','comment','mention','assignment','material','deadline');not null" json:"type"`
+	CourseID     uint             `gorm:"not null" json:"course_id"`
+	ActorID      *uint            `json:"actor_id"`
+	ResourceType string           `gorm:"size:50;not null" json:"resource_type"`
+	ResourceID   uint             `gorm:"not null" json:"resource_id"`
+	Title        string           `gorm:"size:255;not null" json:"title"`
+	Message      string           `gorm:"type:text" json:"message"`
+	ReadAt       *time.Time       `gorm:"index:idx_notification_user_read" json:"read_at"`
+	CreatedAt    time.Time        `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time        `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+type QuestionType string
+
+const (
+	QuestionTypeMultipleChoice QuestionType = "multiple_choice"
+	QuestionTypeTrueFalse      QuestionType = "true_false"
+	QuestionTypeNumeric        QuestionType = "numeric"
+)
+
+type AttemptStatus string
+
+const (
+	AttemptStatusInProgress AttemptStatus = "in_progress"
+	AttemptStatusSubmitted  AttemptStatus = "submitted"
+	AttemptStatusExpired    AttemptStatus = "expired"
+)
+
+type Quiz struct {
+	gorm.Model
+	Availability
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	CourseID         uint           `gorm:"not null" json:"course_id"`
+	Course           Course         `gorm:"foreignKey:CourseID" json:"course,omitempty"`
+	Title            string         `gorm:"size:255;not null" json:"title"`
+	Description      string         `gorm:"type:text" json:"description"`
+	TimeLimitMinutes *int           `json:"time_limit_minutes"`
+	MaxAttempts      *int           `json:"max_attempts"`
+	DueDate          *time.Time     `json:"due_date"`
+	CreatedAt        time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Questions        []QuizQuestion `gorm:"foreignKey:QuizID" json:"questions,omitempty"`
+}
+
+type QuizQuestion struct {
+	gorm.Model
+	ID            uint         `gorm:"primaryKey" json:"id"`
+	QuizID        uint         `gorm:"not null;index" json:"quiz_id"`
+	Type          QuestionType `gorm:"type:enum('multiple_choice','true_false','numeric');not null" json:"type"`
+	Prompt        string       `gorm:"type:text;not null" json:"prompt"`
+	Points        float64      `gorm:"not null;default:1" json:"points"`
+	Position      int          `gorm:"default:0" json:"position"`
+	BoolAnswer    *bool        `json:"bool_answer,omitempty"`
+	NumericAnswer *float64     `json:"numeric_answer,omitempty"`
+	Tolerance     float64      `gorm:"default:0" json:"tolerance,omitempty"`
+	CreatedAt     time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Choices       []QuizChoice `gorm:"foreignKey:QuestionID" json:"choices,omitempty"`
+}
+
+type QuizChoice struct {
+	gorm.Model
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	QuestionID uint      `gorm:"not null;index" json:"question_id"`
+	Text       string    `gorm:"type:text;not null" json:"text"`
+	IsCorrect  bool      `gorm:"default:false" json:"is_correct,omitempty"`
+	Position   int       `gorm:"default:0" json:"position"`
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+type QuizAttempt struct {
+	gorm.Model
+	ID            uint          `gorm:"primaryKey" json:"id"`
+	QuizID        uint          `gorm:"not null;index;uniqueIndex:idx_quiz_attempt" json:"quiz_id"`
+	Quiz          Quiz          `gorm:"foreignKey:QuizID" json:"quiz,omitempty"`
+	StudentID     uint          `gorm:"not null;index;uniqueIndex:idx_quiz_attempt" json:"student_id"`
+	Student       User          `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	AttemptNumber int           `gorm:"not null;uniqueIndex:idx_quiz_attempt" json:"attempt_number"`
+	Status        AttemptStatus `gorm:"type:enum('in_progress','submitted','expired');not null;default:'in_progress'" json:"status"`
+	StartedAt     time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"started_at"`
+	ExpiresAt     *time.Time    `json:"expires_at"`
+	SubmittedAt   *time.Time    `json:"submitted_at"`
+	Score         *float64      `json:"score"`
+	MaxScore      float64       `json:"max_score"`
+	CreatedAt     time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Answers       []QuizAnswer  `gorm:"foreignKey:AttemptID" json:"answers,omitempty"`
+}
+
+type QuizAnswer struct {
+	gorm.Model
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	AttemptID     uint      `gorm:"not null;index" json:"attempt_id"`
+	QuestionID    uint      `gorm:"not null" json:"question_id"`
+	ChoiceID      *uint     `json:"choice_id,omitempty"`
+	BoolAnswer    *bool     `json:"bool_answer,omitempty"`
+	NumericAnswer *float64  `json:"numeric_answer,omitempty"`
+	IsCorrect     bool      `gorm:"default:false" json:"is_correct"`
+	PointsAwarded float64   `gorm:"default:0" json:"points_awarded"`
+	CreatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// MaxScore menghitung total poin dari semua pertanyaan kuis
+func (q *Quiz) MaxScore() float64 {
+	total := 0.0
+	for _, question := range q.Questions {
+		total += question.Points
+	}
+	return total
+}
+
+// HideAnswers menghapus kunci jawaban agar kuis aman ditampilkan kepada siswa
+func (q *Quiz) HideAnswers() {
+	for i := range q.Questions {
+		q.Questions[i].BoolAnswer = nil
+		q.Questions[i].NumericAnswer = nil
+		q.Questions[i].Tolerance = 0
+		for j := range q.Questions[i].Choices {
+			q.Questions[i].Choices[j].IsCorrect = false
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+type RefreshToken struct {
+	gorm.Model
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	User         User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	TokenHash    string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	FamilyID     string     `gorm:"size:64;not null;index" json:"family_id"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *uint      `json:"replaced_by_id"`
+	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+type RevokedAccessToken struct {
+	gorm.Model
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	JTI       string    `gorm:"size:64;not null;uniqueIndex" json:"jti"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
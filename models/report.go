@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// ReportStatus adalah status laporan dalam antrean moderasi
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportResolved  ReportStatus = "resolved"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+// ContentReport adalah laporan pengguna atas diskusi atau komentar yang dianggap melanggar.
+// CommentID nil berarti yang dilaporkan adalah diskusinya sendiri.
+type ContentReport struct {
+	gorm.Model
+	ID           uint         `gorm:"primaryKey" json:"id"`
+	CourseID     uint         `gorm:"not null;index:idx_report_course_status" json:"course_id"`
+	DiscussionID uint         `gorm:"not null" json:"discussion_id"`
+	CommentID    *uint        `json:"comment_id"`
+	ReporterID   uint         `gorm:"not null" json:"reporter_id"`
+	Reporter     User         `gorm:"foreignKey:ReporterID" json:"reporter,omitempty"`
+	Reason       string       `gorm:"type:text;not null" json:"reason"`
+	Status       ReportStatus `gorm:"type:enum('open','resolved','dismissed');not null;default:'open';index:idx_report_course_status" json:"status"`
+	ResolvedByID *uint        `json:"resolved_by_id"`
+	ResolvedAt   *time.Time   `json:"resolved_at"`
+	Resolution   string       `gorm:"type:text" json:"resolution"`
+	CreatedAt    time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
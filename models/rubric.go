@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// Rubric adalah definisi rubrik penilaian yang dapat dipakai ulang oleh tugas dalam satu kursus
+type Rubric struct {
+	gorm.Model
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	CourseID    uint              `gorm:"not null;index" json:"course_id"`
+	Title       string            `gorm:"size:255;not null" json:"title"`
+	Description string            `gorm:"type:text" json:"description"`
+	CreatedByID uint              `gorm:"not null" json:"created_by_id"`
+	Criteria    []RubricCriterion `gorm:"foreignKey:RubricID" json:"criteria"`
+	CreatedAt   time.Time         `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time         `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Total poin maksimal dari semua kriteria
+	TotalPoints int `gorm:"-" json:"total_points"`
+}
+
+// RubricCriterion adalah satu kriteria rubrik beserta tingkat capaiannya
+type RubricCriterion struct {
+	gorm.Model
+	ID          uint          `gorm:"primaryKey" json:"id"`
+	RubricID    uint          `gorm:"not null;index" json:"rubric_id"`
+	Title       string        `gorm:"size:255;not null" json:"title"`
+	Description string        `gorm:"type:text" json:"description"`
+	Position    int           `gorm:"not null;default:0" json:"position"`
+	Levels      []RubricLevel `gorm:"foreignKey:CriterionID" json:"levels,omitempty"`
+	CreatedAt   time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName menetapkan nama tabel kriteria rubrik
+func (RubricCriterion) TableName() string {
+	return "rubric_criteria"
+}
+
+// RubricLevel adalah tingkat capaian sebuah kriteria beserta poinnya
+type RubricLevel struct {
+	gorm.Model
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CriterionID uint      `gorm:"not null;index" json:"criterion_id"`
+	Title       string    `gorm:"size:255;not null" json:"title"`
+	Description string    `gorm:"type:text" json:"description"`
+	Points      int       `gorm:"not null" json:"points"`
+	Position    int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// AssessmentCriterionScore adalah skor satu kriteria rubrik dalam sebuah penilaian
+type AssessmentCriterionScore struct {
+	gorm.Model
+	ID           uint            `gorm:"primaryKey" json:"id"`
+	AssessmentID uint            `gorm:"not null;index" json:"assessment_id"`
+	CriterionID  uint            `gorm:"not null" json:"criterion_id"`
+	Criterion    RubricCriterion `gorm:"foreignKey:CriterionID" json:"criterion,omitempty"`
+	LevelID      *uint           `json:"level_id"`
+	Level        *RubricLevel    `gorm:"foreignKey:LevelID" json:"level,omitempty"`
+	Points       int             `gorm:"not null" json:"points"`
+	MaxPoints    int             `gorm:"not null" json:"max_points"`
+	Comment      string          `gorm:"type:text" json:"comment"`
+	CreatedAt    time.Time       `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time       `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// MaxPoints mengembalikan poin tertinggi dari tingkat capaian kriteria
+func (c *RubricCriterion) MaxPoints() int {
+	maxPoints := 0
+	for _, level := range c.Levels {
+		if level.Points > maxPoints {
+			maxPoints = level.Points
+		}
+	}
+	return maxPoints
+}
+
+// CalculateTotalPoints menghitung total poin maksimal rubrik
+func (r *Rubric) CalculateTotalPoints() int {
+	total := 0
+	for i := range r.Criteria {
+		total += r.Criteria[i].MaxPoints()
+	}
+	r.TotalPoints = total
+	return total
+}
@@ -9,13 +9,18 @@ import (
 
 type Submission struct {
 	gorm.Model
-	ID           uint        `gorm:"primaryKey" json:"id"`
-	AssignmentID uint        `gorm:"not null" json:"assignment_id"`
-	Assignment   Assignment  `gorm:"foreignKey:AssignmentID" json:"assignment,omitempty"`
-	StudentID    uint        `gorm:"not null" json:"student_id"`
-	Student      User        `gorm:"foreignKey:StudentID" json:"student,omitempty"`
-	FilePath     string      `gorm:"size:255;not null" json:"file_path"`
-	SubmittedAt  time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"submitted_at"`
-	UpdatedAt    time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
-	Assessment   *Assessment `gorm:"foreignKey:SubmissionID" json:"assessment,omitempty"`
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	AssignmentID  uint        `gorm:"not null;uniqueIndex:idx_submission_attempt" json:"assignment_id"`
+	Assignment    Assignment  `gorm:"foreignKey:AssignmentID" json:"assignment,omitempty"`
+	StudentID     uint        `gorm:"not null;uniqueIndex:idx_submission_attempt" json:"student_id"`
+	Student       User        `gorm:"foreignKey:StudentID" json:"student,omitempty"`
+	AttemptNumber int         `gorm:"not null;default:1;uniqueIndex:idx_submission_attempt" json:"attempt_number"`
+	FilePath      string      `gorm:"size:255;not null" json:"file_path"`
+	FileHash      string      `gorm:"size:64;index" json:"file_hash"`
+	FileName      string      `gorm:"size:255" json:"file_name"`
+	SubmittedAt   time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"submitted_at"`
+	IsLate        bool        `gorm:"not null;default:false" json:"is_late"`
+	DaysLate      int         `gorm:"not null;default:0" json:"days_late"`
+	UpdatedAt     time.Time   `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+	Assessment    *Assessment `gorm:"foreignKey:SubmissionID" json:"assessment,omitempty"`
 }
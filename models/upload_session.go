@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// Status sesi unggahan bertahap
+const (
+	UploadStatusPending    = "pending"
+	UploadStatusAssembling = "assembling"
+	UploadStatusCompleted  = "completed"
+)
+
+// UploadSession adalah unggahan berkas besar yang dikirim per potongan dan dapat dilanjutkan
+type UploadSession struct {
+	gorm.Model
+	ID          uint          `gorm:"primaryKey" json:"-"`
+	Token       string        `gorm:"size:64;not null;uniqueIndex" json:"upload_id"`
+	UserID      uint          `gorm:"not null;index" json:"user_id"`
+	CourseID    uint          `gorm:"not null" json:"course_id"`
+	MaterialID  *uint         `json:"material_id"`
+	Title       string        `gorm:"size:255" json:"title"`
+	FileName    string        `gorm:"size:255;not null" json:"file_name"`
+	FileSize    int64         `gorm:"not null" json:"file_size"`
+	ChunkSize   int64         `gorm:"not null" json:"chunk_size"`
+	Checksum    string        `gorm:"size:64" json:"checksum"`
+	Status      string        `gorm:"size:20;not null;default:'pending'" json:"status"`
+	ExpiresAt   time.Time     `gorm:"not null;index" json:"expires_at"`
+	CompletedAt *time.Time    `json:"completed_at"`
+	CreatedAt   time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time     `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Chunks      []UploadChunk `gorm:"foreignKey:SessionID" json:"-"`
+
+	// Dihitung dari potongan yang sudah diterima
+	TotalChunks    int   `gorm:"-" json:"total_chunks"`
+	ReceivedChunks []int `gorm:"-" json:"received_chunks"`
+	ReceivedBytes  int64 `gorm:"-" json:"received_bytes"`
+}
+
+// UploadChunk adalah satu potongan yang sudah diterima dari sebuah sesi unggahan
+type UploadChunk struct {
+	gorm.Model
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	SessionID  uint      `gorm:"not null;uniqueIndex:idx_upload_chunk" json:"session_id"`
+	ChunkIndex int       `gorm:"not null;uniqueIndex:idx_upload_chunk" json:"chunk_index"`
+	Size       int64     `gorm:"not null" json:"size"`
+	Checksum   string    `gorm:"size:64;not null" json:"checksum"`
+	CreatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// ChunkCount menghitung jumlah potongan untuk ukuran berkas dan ukuran potongan sesi
+func (s *UploadSession) ChunkCount() int {
+	if s.ChunkSize <= 0 {
+		return 0
+	}
+	return int((s.FileSize + s.ChunkSize - 1) / s.ChunkSize)
+}
+
+// ChunkLength menghitung ukuran potongan ke-index; hanya potongan terakhir yang boleh lebih kecil
+func (s *UploadSession) ChunkLength(index int) int64 {
+	offset := int64(index) * s.ChunkSize
+	if remaining := s.FileSize - offset; remaining < s.ChunkSize {
+		return remaining
+	}
+	return s.ChunkSize
+}
@@ -18,13 +18,14 @@ const (
 
 type User struct {
 	gorm.Model
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Name      string    `gorm:"size:255;not null" json:"name"`
-	Email     string    `gorm:"size:255;not null;unique" json:"email"`
-	Password  string    `gorm:"size:255;not null" json:"-"`
-	Role      Role      `gorm:"type:enum('admin','mentor','student');not null" json:"role"`
-	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"size:255;not null" json:"name"`
+	Email        string    `gorm:"size:255;not null;unique" json:"email"`
+	Password     string    `gorm:"size:255;not null" json:"-"`
+	Role         Role      `gorm:"type:enum('admin','mentor','student');not null" json:"role"`
+	TokenVersion uint      `gorm:"not null;default:0" json:"-"`
+	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"	`
 }
 
 // BeforeSave melakuakan penyimpanan/ memasukan sandi pengguna sebelum menyimpan database
@@ -0,0 +1,147 @@
+package realtime
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+)
+
+// Jenis topik yang dapat diikuti klien
+const (
+	TopicCourse     = "course"
+	TopicDiscussion = "discussion"
+	TopicUser       = "user"
+)
+
+// ErrInvalidTopic dikembalikan ketika nama topik tidak berbentuk jenis:ID
+var ErrInvalidTopic = errors.New("invalid topic")
+
+// Message adalah satu pembaruan yang dikirim ke semua pelanggan sebuah topik
+type Message struct {
+	Topic string      `json:"topic"`
+	Type  string      `json:"type"`
+	Data  interface{} `json:"data"`
+}
+
+// Broker meneruskan pesan ke pelanggan topik. Hub di bawah bekerja di dalam satu proses;
+// implementasi lain dapat meneruskan pesan lewat broker pesan agar beberapa instans API berbagi pelanggan.
+type Broker interface {
+	// Publish mengirim pesan ke semua pelanggan topiknya tanpa menunggu pelanggan yang lambat
+	Publish(message Message)
+	// Subscribe membuat langganan untuk beberapa topik sekaligus
+	Subscribe(topics []string) *Subscription
+	// Unsubscribe mengakhiri langganan dan menutup salurannya
+	Unsubscribe(subscription *Subscription)
+}
+
+// Subscription adalah langganan satu klien. Saluran Messages ditutup ketika langganan berakhir,
+// termasuk saat klien terlalu lambat membaca sehingga perlu menyambung ulang.
+type Subscription struct {
+	Topics   []string
+	messages chan Message
+	closed   bool
+}
+
+// Messages mengembalikan saluran pesan langganan
+func (s *Subscription) Messages() <-chan Message {
+	return s.messages
+}
+
+// Hub adalah Broker di dalam proses
+type Hub struct {
+	mu         sync.RWMutex
+	topics     map[string]map[*Subscription]struct{}
+	bufferSize int
+}
+
+// NewHub membuat hub dengan bufferSize pesan tertunda per pelanggan
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = 64
+	}
+	return &Hub{
+		topics:     make(map[string]map[*Subscription]struct{}),
+		bufferSize: bufferSize,
+	}
+}
+
+// Publish mengirim pesan ke pelanggan topiknya; pelanggan yang penyangganya penuh diputus
+func (h *Hub) Publish(message Message) {
+	var slow []*Subscription
+
+	h.mu.RLock()
+	for subscription := range h.topics[message.Topic] {
+		select {
+		case subscription.messages <- message:
+		default:
+			slow = append(slow, subscription)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, subscription := range slow {
+		h.Unsubscribe(subscription)
+	}
+}
+
+// Subscribe membuat langganan untuk beberapa topik sekaligus
+func (h *Hub) Subscribe(topics []string) *Subscription {
+	subscription := &Subscription{
+		Topics:   topics,
+		messages: make(chan Message, h.bufferSize),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*Subscription]struct{})
+		}
+		h.topics[topic][subscription] = struct{}{}
+	}
+	return subscription
+}
+
+// Unsubscribe mengakhiri langganan; aman dipanggil lebih dari sekali
+func (h *Hub) Unsubscribe(subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if subscription.closed {
+		return
+	}
+	subscription.closed = true
+
+	for _, topic := range subscription.Topics {
+		delete(h.topics[topic], subscription)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+	close(subscription.messages)
+}
+
+// Topic membuat nama topik dari jenis dan ID, misalnya "discussion:12"
+func Topic(kind string, id uint) string {
+	return fmt.Sprintf("%s:%d", kind, id)
+}
+
+// ParseTopic memecah nama topik menjadi jenis dan ID
+func ParseTopic(topic string) (string, uint, error) {
+	kind, rawID, ok := strings.Cut(strings.TrimSpace(topic), ":")
+	if !ok {
+		return "", 0, ErrInvalidTopic
+	}
+	switch kind {
+	case TopicCourse, TopicDiscussion, TopicUser:
+	default:
+		return "", 0, ErrInvalidTopic
+	}
+	id, err := strconv.ParseUint(rawID, 10, 32)
+	if err != nil || id == 0 {
+		return "", 0, ErrInvalidTopic
+	}
+	return kind, uint(id), nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 )
 
@@ -21,7 +22,7 @@ func NewAssessmentRepository(db *gorm.DB) *AssessmentRepository {
 // FindByID menemukan penilaian berdasarkan ID
 func (r *AssessmentRepository) FindByID(id uint) (*models.Assessment, error) {
 	var assessment models.Assessment
-	result := r.DB.First(&assessment, id)
+	result := withCriterionScores(r.DB).First(&assessment, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, errors.New("assessment not found")
@@ -34,7 +35,7 @@ func (r *AssessmentRepository) FindByID(id uint) (*models.Assessment, error) {
 // FindBySubmission menemukan penilaian berdasarkan ID pengajuan
 func (r *AssessmentRepository) FindBySubmission(submissionID uint) (*models.Assessment, error) {
 	var assessment models.Assessment
-	result := r.DB.Where("submission_id = ?", submissionID).First(&assessment)
+	result := withCriterionScores(r.DB).Where("submission_id = ?", submissionID).First(&assessment)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, errors.New("assessment not found")
@@ -44,6 +45,46 @@ func (r *AssessmentRepository) FindBySubmission(submissionID uint) (*models.Asse
 	return &assessment, nil
 }
 
+// SaveWithCriterionScores menyimpan penilaian dan mengganti skor kriteria rubriknya dalam satu transaksi
+func (r *AssessmentRepository) SaveWithCriterionScores(assessment *models.Assessment, scores []models.AssessmentCriterionScore) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(assessment).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("assessment_id = ?", assessment.ID).Delete(&models.AssessmentCriterionScore{}).Error; err != nil {
+			return err
+		}
+
+		for i := range scores {
+			scores[i].ID = 0
+			scores[i].AssessmentID = assessment.ID
+		}
+		if len(scores) > 0 {
+			if err := tx.Omit(clause.Associations).Create(&scores).Error; err != nil {
+				return err
+			}
+		}
+
+		assessment.CriterionScores = scores
+		return nil
+	})
+}
+
+// withCriterionScores memuat skor kriteria rubrik beserta kriteria dan tingkat capaian yang dipilih
+func withCriterionScores(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("CriterionScores", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		Preload("CriterionScores.Criterion", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("CriterionScores.Level", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		})
+}
+
 // Buat membuat penilaian baru
 func (r *AssessmentRepository) Create(assessment *models.Assessment) error {
 	return r.DB.Create(assessment).Error
@@ -3,6 +3,7 @@ package repositories
 import (
 	"LMS/models"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -38,6 +39,13 @@ func (r *AssignmentRepository) FindByCourse(courseID uint) ([]models.Assignment,
 	return assignments, result.Error
 }
 
+// FindDueBetween menemukan penugasan dengan tenggat setelah from sampai dengan to
+func (r *AssignmentRepository) FindDueBetween(from, to time.Time) ([]models.Assignment, error) {
+	var assignments []models.Assignment
+	result := r.DB.Where("due_date IS NOT NULL AND due_date > ? AND due_date <= ?", from, to).Order("due_date ASC").Find(&assignments)
+	return assignments, result.Error
+}
+
 // Membuat membuat tugas baru
 func (r *AssignmentRepository) Create(assignment *models.Assignment) error {
 	return r.DB.Create(assignment).Error
@@ -48,7 +56,12 @@ func (r *AssignmentRepository) Update(assignment *models.Assignment) error {
 	return r.DB.Save(assignment).Error
 }
 
-// Delete menghapus penugasan
+// Delete menghapus penugasan beserta penempatannya di modul
 func (r *AssignmentRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.Assignment{}, id).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteModuleItems(tx, models.ProgressTypeAssignment, id); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Assignment{}, id).Error
+	})
 }
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+)
+
+// BlobRepository menangani operasi basis data untuk isi berkas bersama
+type BlobRepository struct {
+	DB *gorm.DB
+}
+
+// NewBlobRepository membuat repositori blob baru
+func NewBlobRepository(db *gorm.DB) *BlobRepository {
+	return &BlobRepository{DB: db}
+}
+
+// FileReference adalah baris materi atau kiriman yang menunjuk sebuah berkas
+type FileReference struct {
+	Table    string
+	ID       uint
+	FilePath string
+	FileHash string
+}
+
+// fileTables adalah tabel yang menyimpan referensi berkas
+var fileTables = []string{"materials", "material_files", "submissions"}
+
+// FindByHash menemukan blob berdasarkan hash
+func (r *BlobRepository) FindByHash(hash string) (*models.Blob, error) {
+	var blob models.Blob
+	result := r.DB.Where("hash = ?", hash).First(&blob)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("blob not found")
+		}
+		return nil, result.Error
+	}
+	return &blob, nil
+}
+
+// FindAll mendapatkan semua blob
+func (r *BlobRepository) FindAll() ([]models.Blob, error) {
+	var blobs []models.Blob
+	result := r.DB.Order("id").Find(&blobs)
+	return blobs, result.Error
+}
+
+// Acquire menambah jumlah referensi blob, membuat barisnya jika belum ada.
+// Isi blob harus sudah diunggah sebelum Acquire dipanggil; kunci baris hanya dipegang selama verify
+// memastikan objeknya masih ada, sehingga Release yang menghapus isi blob yang sama tidak dapat mendahului.
+// Jika verify gagal, tidak ada baris yang dibuat maupun diubah.
+func (r *BlobRepository) Acquire(blob *models.Blob, verify func(existing *models.Blob) error) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		// Baris baru dibuat dari salinan agar ID dari transaksi yang dibatalkan tidak terbawa ke percobaan berikutnya
+		candidate := models.Blob{Hash: blob.Hash, Size: blob.Size, ContentType: blob.ContentType}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&candidate).Error; err != nil {
+			return err
+		}
+
+		var locked models.Blob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("hash = ?", blob.Hash).First(&locked).Error; err != nil {
+			return err
+		}
+
+		if err := verify(&locked); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&locked).Update("ref_count", gorm.Expr("ref_count + 1")).Error; err != nil {
+			return err
+		}
+		locked.RefCount++
+		*blob = locked
+		return nil
+	})
+}
+
+// Discard menghapus isi blob yang sudah diunggah tetapi gagal direferensikan, kecuali blob itu
+// direferensikan unggahan lain. Unggahan lain yang sedang menulis isi yang sama akan mendapati
+// objeknya hilang saat Acquire lalu mengunggahnya ulang.
+func (r *BlobRepository) Discard(hash string, remove func() error) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var blob models.Blob
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("hash = ?", hash).First(&blob)
+		if result.Error != nil && !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return result.Error
+		}
+		if result.Error == nil && blob.RefCount > 0 {
+			return nil
+		}
+		return remove()
+	})
+}
+
+// Release mengurangi jumlah referensi blob; jika tidak ada lagi referensi, remove dipanggil
+// selagi baris terkunci lalu baris blob dihapus permanen
+func (r *BlobRepository) Release(hash string, remove func(blob *models.Blob) error) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var blob models.Blob
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("hash = ?", hash).First(&blob)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return result.Error
+		}
+
+		if blob.RefCount > 1 {
+			return tx.Model(&blob).Update("ref_count", gorm.Expr("ref_count - 1")).Error
+		}
+
+		if err := remove(&blob); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&blob).Error
+	})
+}
+
+// DeleteIfUnreferenced menghapus blob yang tidak direferensikan materi atau kiriman mana pun dan tidak berubah
+// sejak before; pemeriksaan dilakukan selagi baris terkunci agar tidak bertabrakan dengan unggahan baru
+func (r *BlobRepository) DeleteIfUnreferenced(hash string, before time.Time, remove func(blob *models.Blob) error) (bool, error) {
+	deleted := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var blob models.Blob
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("hash = ?", hash).First(&blob)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return result.Error
+		}
+		if !blob.UpdatedAt.Before(before) {
+			return nil
+		}
+
+		for _, table := range fileTables {
+			var count int64
+			if err := tx.Table(table).Where("file_hash = ? AND deleted_at IS NULL", hash).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return nil
+			}
+		}
+
+		if err := remove(&blob); err != nil {
+			return err
+		}
+		deleted = true
+		return tx.Unscoped().Delete(&blob).Error
+	})
+	return deleted, err
+}
+
+// SetRefCount mengatur ulang jumlah referensi blob
+func (r *BlobRepository) SetRefCount(id uint, count int) error {
+	return r.DB.Model(&models.Blob{}).Where("id = ?", id).Update("ref_count", count).Error
+}
+
+// CountReferences menghitung referensi setiap hash dari materi dan kiriman yang belum dihapus
+func (r *BlobRepository) CountReferences() (map[string]int, error) {
+	counts := map[string]int{}
+	for _, table := range fileTables {
+		var rows []struct {
+			FileHash string
+			Count    int
+		}
+		result := r.DB.Table(table).
+			Select("file_hash, COUNT(*) AS count").
+			Where("file_hash <> '' AND deleted_at IS NULL").
+			Group("file_hash").
+			Scan(&rows)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		for _, row := range rows {
+			counts[row.FileHash] += row.Count
+		}
+	}
+	return counts, nil
+}
+
+// FindLegacyFiles mendapatkan materi dan kiriman yang berkasnya belum disimpan sebagai blob
+func (r *BlobRepository) FindLegacyFiles() ([]FileReference, error) {
+	var references []FileReference
+	for _, table := range fileTables {
+		var rows []FileReference
+		result := r.DB.Table(table).
+			Select("id, file_path").
+			Where("(file_hash = '' OR file_hash IS NULL) AND file_path <> '' AND deleted_at IS NULL").
+			Scan(&rows)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		for _, row := range rows {
+			row.Table = table
+			references = append(references, row)
+		}
+	}
+	return references, nil
+}
+
+// UpdateFileReference mengarahkan materi atau kiriman ke berkas blob
+func (r *BlobRepository) UpdateFileReference(reference FileReference) error {
+	return r.DB.Table(reference.Table).
+		Where("id = ?", reference.ID).
+		Updates(map[string]interface{}{
+			"file_path": reference.FilePath,
+			"file_hash": reference.FileHash,
+		}).Error
+}
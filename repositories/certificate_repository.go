@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// CertificateRepository menangani operasi basis data untuk sertifikat dan templatnya
+type CertificateRepository struct {
+	DB *gorm.DB
+}
+
+// NewCertificateRepository membuat repositori sertifikat baru
+func NewCertificateRepository(db *gorm.DB) *CertificateRepository {
+	return &CertificateRepository{DB: db}
+}
+
+// FindByID menemukan sertifikat berdasarkan ID
+func (r *CertificateRepository) FindByID(id uint) (*models.Certificate, error) {
+	var certificate models.Certificate
+	result := r.DB.First(&certificate, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("certificate not found")
+		}
+		return nil, result.Error
+	}
+	return &certificate, nil
+}
+
+// FindByCode menemukan sertifikat berdasarkan kode verifikasinya
+func (r *CertificateRepository) FindByCode(code string) (*models.Certificate, error) {
+	var certificate models.Certificate
+	result := r.DB.Where("code = ?", code).First(&certificate)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("certificate not found")
+		}
+		return nil, result.Error
+	}
+	return &certificate, nil
+}
+
+// FindByUserAndCourse menemukan sertifikat seorang siswa untuk sebuah kursus
+func (r *CertificateRepository) FindByUserAndCourse(userID, courseID uint) (*models.Certificate, error) {
+	var certificate models.Certificate
+	result := r.DB.Where("user_id = ? AND course_id = ?", userID, courseID).First(&certificate)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("certificate not found")
+		}
+		return nil, result.Error
+	}
+	return &certificate, nil
+}
+
+// FindByUser menemukan semua sertifikat seorang siswa, yang terbaru lebih dulu
+func (r *CertificateRepository) FindByUser(userID uint) ([]models.Certificate, error) {
+	var certificates []models.Certificate
+	result := r.DB.Where("user_id = ?", userID).Order("issued_at DESC").Find(&certificates)
+	return certificates, result.Error
+}
+
+// FindByCourse menemukan semua sertifikat yang diterbitkan untuk sebuah kursus
+func (r *CertificateRepository) FindByCourse(courseID uint) ([]models.Certificate, error) {
+	var certificates []models.Certificate
+	result := r.DB.Where("course_id = ?", courseID).Order("issued_at DESC").Find(&certificates)
+	return certificates, result.Error
+}
+
+// Create membuat sertifikat baru
+func (r *CertificateRepository) Create(certificate *models.Certificate) error {
+	return r.DB.Create(certificate).Error
+}
+
+// Revoke mencabut sertifikat tanpa menghapus catatannya
+func (r *CertificateRepository) Revoke(id, revokedByID uint, reason string) error {
+	return r.DB.Model(&models.Certificate{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_by_id": revokedByID, "revocation_reason": reason}).Error
+}
+
+// FindTemplateByCourse menemukan templat sertifikat sebuah kursus
+func (r *CertificateRepository) FindTemplateByCourse(courseID uint) (*models.CertificateTemplate, error) {
+	var template models.CertificateTemplate
+	result := r.DB.Where("course_id = ?", courseID).First(&template)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("certificate template not found")
+		}
+		return nil, result.Error
+	}
+	return &template, nil
+}
+
+// SaveTemplate membuat atau mengganti templat sertifikat sebuah kursus
+func (r *CertificateRepository) SaveTemplate(template *models.CertificateTemplate) error {
+	var existing models.CertificateTemplate
+	err := r.DB.Where("course_id = ?", template.CourseID).First(&existing).Error
+	switch {
+	case err == nil:
+		template.ID = existing.ID
+		template.CreatedAt = existing.CreatedAt
+		return r.DB.Model(&models.CertificateTemplate{}).Where("id = ?", template.ID).Updates(map[string]interface{}{
+			"title": template.Title,
+			"body":  template.Body,
+		}).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return r.DB.Create(template).Error
+	default:
+		return err
+	}
+}
@@ -3,6 +3,8 @@ package repositories
 import (
 	"LMS/models"
 	"errors"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -38,6 +40,139 @@ func (r *CommentRepository) FindByDiscussion(discussionID uint) ([]models.Commen
 	return comments, result.Error
 }
 
+// FindTopLevelByDiscussion menemukan satu halaman komentar teratas sebuah diskusi beserta jumlah seluruhnya.
+// Urutan "newest" mendahulukan komentar terbaru, "top" mendahulukan komentar dengan upvote terbanyak.
+func (r *CommentRepository) FindTopLevelByDiscussion(discussionID uint, sort string, limit, offset int) ([]models.Comment, int64, error) {
+	query := r.DB.Model(&models.Comment{}).Where("discussion_id = ? AND parent_id IS NULL", discussionID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	switch sort {
+	case "newest":
+		query = query.Order("created_at DESC").Order("id DESC")
+	case "top":
+		query = query.Order(fmt.Sprintf("(SELECT COUNT(*) FROM comment_reactions WHERE comment_reactions.comment_id = comments.id AND comment_reactions.type = '%s') DESC", models.ReactionUpvote)).
+			Order("created_at ASC").Order("id ASC")
+	default:
+		query = query.Order("created_at ASC").Order("id ASC")
+	}
+
+	var comments []models.Comment
+	result := query.Preload("User").Limit(limit).Offset(offset).Find(&comments)
+	return comments, total, result.Error
+}
+
+// FindRepliesByDiscussion menemukan semua balasan dalam diskusi, yang terlama lebih dulu
+func (r *CommentRepository) FindRepliesByDiscussion(discussionID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	result := r.DB.Where("discussion_id = ? AND parent_id IS NOT NULL", discussionID).
+		Order("created_at ASC").Order("id ASC").
+		Preload("User").Find(&comments)
+	return comments, result.Error
+}
+
+// FindSubtreeIDs menemukan ID komentar beserta seluruh balasan di bawahnya
+func (r *CommentRepository) FindSubtreeIDs(id uint) ([]uint, error) {
+	ids := []uint{id}
+	parents := []uint{id}
+	for len(parents) > 0 {
+		var children []uint
+		if err := r.DB.Model(&models.Comment{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		parents = children
+	}
+	return ids, nil
+}
+
+// FindReactionCounts menghitung reaksi per jenis untuk setiap komentar
+func (r *CommentRepository) FindReactionCounts(commentIDs []uint) (map[uint]map[string]int, error) {
+	counts := make(map[uint]map[string]int)
+	if len(commentIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		CommentID uint
+		Type      string
+		Total     int
+	}
+	result := r.DB.Model(&models.CommentReaction{}).
+		Select("comment_id, type, COUNT(*) AS total").
+		Where("comment_id IN ?", commentIDs).
+		Group("comment_id, type").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, row := range rows {
+		if counts[row.CommentID] == nil {
+			counts[row.CommentID] = make(map[string]int)
+		}
+		counts[row.CommentID][row.Type] = row.Total
+	}
+	return counts, nil
+}
+
+// FindUserReactions menemukan reaksi yang diberikan seorang pengguna pada komentar-komentar
+func (r *CommentRepository) FindUserReactions(userID uint, commentIDs []uint) (map[uint][]models.ReactionType, error) {
+	reactions := make(map[uint][]models.ReactionType)
+	if len(commentIDs) == 0 {
+		return reactions, nil
+	}
+
+	var rows []models.CommentReaction
+	result := r.DB.Where("user_id = ? AND comment_id IN ?", userID, commentIDs).Order("id ASC").Find(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, row := range rows {
+		reactions[row.CommentID] = append(reactions[row.CommentID], row.Type)
+	}
+	return reactions, nil
+}
+
+// AddReaction menambahkan reaksi; reaksi yang sudah ada dibiarkan
+func (r *CommentRepository) AddReaction(reaction *models.CommentReaction) error {
+	return r.DB.Where(models.CommentReaction{
+		CommentID: reaction.CommentID,
+		UserID:    reaction.UserID,
+		Type:      reaction.Type,
+	}).FirstOrCreate(reaction).Error
+}
+
+// RemoveReaction menghapus reaksi seorang pengguna pada komentar
+func (r *CommentRepository) RemoveReaction(commentID, userID uint, reactionType models.ReactionType) error {
+	return r.DB.Unscoped().
+		Where("comment_id = ? AND user_id = ? AND type = ?", commentID, userID, reactionType).
+		Delete(&models.CommentReaction{}).Error
+}
+
+// Hide menyembunyikan komentar beserta alasan moderasinya.
+// Komentar yang menjadi jawaban diterima dilepas dari diskusinya.
+func (r *CommentRepository) Hide(id, hiddenByID uint, reason string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Discussion{}).Where("accepted_comment_id = ?", id).
+			Update("accepted_comment_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Comment{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"hidden_at": time.Now(), "hidden_by_id": hiddenByID, "hidden_reason": reason}).Error
+	})
+}
+
+// Unhide menampilkan kembali komentar yang disembunyikan
+func (r *CommentRepository) Unhide(id uint) error {
+	return r.DB.Model(&models.Comment{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"hidden_at": nil, "hidden_by_id": nil, "hidden_reason": ""}).Error
+}
+
 // Buat membuat komentar baru
 func (r *CommentRepository) Create(comment *models.Comment) error {
 	return r.DB.Create(comment).Error
@@ -48,7 +183,22 @@ func (r *CommentRepository) Update(comment *models.Comment) error {
 	return r.DB.Save(comment).Error
 }
 
-// Hapus menghapus komentar
+// Hapus menghapus komentar beserta seluruh balasan dan reaksinya.
+// Jawaban diterima yang ikut terhapus dilepas dari diskusinya.
 func (r *CommentRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.Comment{}, id).Error
+	ids, err := r.FindSubtreeIDs(id)
+	if err != nil {
+		return err
+	}
+
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("comment_id IN ?", ids).Delete(&models.CommentReaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Discussion{}).Where("accepted_comment_id IN ?", ids).
+			Update("accepted_comment_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Comment{}, ids).Error
+	})
 }
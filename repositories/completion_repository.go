@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+
+	"gorm.io/gorm"
+
+)
+
+// CompletionRepository menangani operasi basis data untuk aturan penyelesaian kursus
+type CompletionRepository struct {
+	DB *gorm.DB
+}
+
+// NewCompletionRepository membuat repositori aturan penyelesaian baru
+func NewCompletionRepository(db *gorm.DB) *CompletionRepository {
+	return &CompletionRepository{DB: db}
+}
+
+// FindRuleByCourse menemukan aturan penyelesaian sebuah kursus beserta aktivitas wajibnya
+func (r *CompletionRepository) FindRuleByCourse(courseID uint) (*models.CompletionRule, error) {
+	var rule models.CompletionRule
+	result := r.DB.Preload("Activities", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("course_id = ?", courseID).First(&rule)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("completion rule not found")
+		}
+		return nil, result.Error
+	}
+	return &rule, nil
+}
+
+// SaveRule membuat atau mengganti aturan penyelesaian kursus beserta seluruh aktivitas wajibnya
+func (r *CompletionRepository) SaveRule(rule *models.CompletionRule) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var existing models.CompletionRule
+		err := tx.Where("course_id = ?", rule.CourseID).First(&existing).Error
+		switch {
+		case err == nil:
+			rule.ID = existing.ID
+			rule.CreatedAt = existing.CreatedAt
+			if err := tx.Model(&models.CompletionRule{}).Where("id = ?", rule.ID).Updates(map[string]interface{}{
+				"min_percent_complete": rule.MinPercentComplete,
+				"min_final_percent":    rule.MinFinalPercent,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Where("rule_id = ?", rule.ID).Delete(&models.CompletionActivity{}).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Omit("Activities").Create(rule).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		for i := range rule.Activities {
+			rule.Activities[i].ID = 0
+			rule.Activities[i].RuleID = rule.ID
+		}
+		if len(rule.Activities) == 0 {
+			return nil
+		}
+		return tx.Create(&rule.Activities).Error
+	})
+}
+
+// FindAssessedAssignments menemukan tugas yang kirimannya sudah dinilai, dikelompokkan per siswa
+func (r *CompletionRepository) FindAssessedAssignments(courseID uint) (map[uint][]uint, error) {
+	var rows []struct {
+		StudentID    uint
+		AssignmentID uint
+	}
+	result := r.DB.Model(&models.Submission{}).
+		Select("DISTINCT submissions.student_id, submissions.assignment_id").
+		Joins("JOIN assignments ON assignments.id = submissions.assignment_id").
+		Joins("JOIN assessments ON assessments.submission_id = submissions.id").
+		Where("assignments.course_id = ? AND assignments.deleted_at IS NULL AND assessments.deleted_at IS NULL AND assessments.score IS NOT NULL", courseID).
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	assessed := make(map[uint][]uint)
+	for _, row := range rows {
+		assessed[row.StudentID] = append(assessed[row.StudentID], row.AssignmentID)
+	}
+	return assessed, nil
+}
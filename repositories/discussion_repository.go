@@ -3,6 +3,7 @@ package repositories
 import (
 	"LMS/models"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -31,10 +32,12 @@ func (r *DiscussionRepository) FindByID(id uint) (*models.Discussion, error) {
 	return &discussion, nil
 }
 
-// FindByCourse menemukan diskusi berdasarkan ID kursus
+// FindByCourse menemukan diskusi berdasarkan ID kursus, diskusi yang disematkan lebih dulu
 func (r *DiscussionRepository) FindByCourse(courseID uint) ([]models.Discussion, error) {
 	var discussions []models.Discussion
-	result := r.DB.Where("course_id = ?", courseID).Preload("User").Find(&discussions)
+	result := r.DB.Where("course_id = ?", courseID).
+		Order("pinned DESC").Order("pinned_at DESC").Order("id ASC").
+		Preload("User").Find(&discussions)
 	return discussions, result.Error
 }
 
@@ -55,7 +58,39 @@ func (r *DiscussionRepository) Update(discussion *models.Discussion) error {
 	return r.DB.Save(discussion).Error
 }
 
-// Hapus menghapus diskusi
+// SetAcceptedComment menandai komentar sebagai jawaban diskusi, atau melepasnya jika commentID nil
+func (r *DiscussionRepository) SetAcceptedComment(id uint, commentID *uint) error {
+	return r.DB.Model(&models.Discussion{}).Where("id = ?", id).Update("accepted_comment_id", commentID).Error
+}
+
+// SetPinned menyematkan atau melepas sematan diskusi
+func (r *DiscussionRepository) SetPinned(id uint, pinned bool) error {
+	var pinnedAt *time.Time
+	if pinned {
+		now := time.Now()
+		pinnedAt = &now
+	}
+	return r.DB.Model(&models.Discussion{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"pinned": pinned, "pinned_at": pinnedAt}).Error
+}
+
+// SetLocked mengunci atau membuka diskusi; lockedByID nil saat membuka
+func (r *DiscussionRepository) SetLocked(id uint, locked bool, lockedByID *uint) error {
+	var lockedAt *time.Time
+	if locked {
+		now := time.Now()
+		lockedAt = &now
+	}
+	return r.DB.Model(&models.Discussion{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"locked": locked, "locked_at": lockedAt, "locked_by_id": lockedByID}).Error
+}
+
+// Hapus menghapus diskusi beserta penempatannya di modul
 func (r *DiscussionRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.Discussion{}, id).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteModuleItems(tx, models.ProgressTypeDiscussion, id); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Discussion{}, id).Error
+	})
 }
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// EmailRepository menangani operasi basis data untuk preferensi dan antrean email
+type EmailRepository struct {
+	DB *gorm.DB
+}
+
+// NewEmailRepository membuat repositori email baru
+func NewEmailRepository(db *gorm.DB) *EmailRepository {
+	return &EmailRepository{DB: db}
+}
+
+// FindPreference menemukan preferensi email seorang pengguna
+func (r *EmailRepository) FindPreference(userID uint) (*models.EmailPreference, error) {
+	var preference models.EmailPreference
+	result := r.DB.Where("user_id = ?", userID).First(&preference)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("email preference not found")
+		}
+		return nil, result.Error
+	}
+	return &preference, nil
+}
+
+// FindPreferences menemukan preferensi email beberapa pengguna sekaligus
+func (r *EmailRepository) FindPreferences(userIDs []uint) ([]models.EmailPreference, error) {
+	var preferences []models.EmailPreference
+	if len(userIDs) == 0 {
+		return preferences, nil
+	}
+	result := r.DB.Where("user_id IN ?", userIDs).Find(&preferences)
+	return preferences, result.Error
+}
+
+// FindDigestsDue menemukan preferensi ringkasan harian yang belum dikirim sejak batas waktu
+func (r *EmailRepository) FindDigestsDue(cutoff time.Time) ([]models.EmailPreference, error) {
+	var preferences []models.EmailPreference
+	result := r.DB.Where("mode = ? AND (last_digest_at IS NULL OR last_digest_at < ?)", models.EmailModeDaily, cutoff).
+		Find(&preferences)
+	return preferences, result.Error
+}
+
+// SavePreference membuat atau mengganti preferensi email seorang pengguna
+func (r *EmailRepository) SavePreference(preference *models.EmailPreference) error {
+	var existing models.EmailPreference
+	err := r.DB.Where("user_id = ?", preference.UserID).First(&existing).Error
+	switch {
+	case err == nil:
+		preference.ID = existing.ID
+		preference.CreatedAt = existing.CreatedAt
+		preference.LastDigestAt = existing.LastDigestAt
+		return r.DB.Model(&models.EmailPreference{}).Where("id = ?", preference.ID).Update("mode", preference.Mode).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return r.DB.Create(preference).Error
+	default:
+		return err
+	}
+}
+
+// MarkDigestSent mencatat waktu ringkasan harian terakhir seorang pengguna
+func (r *EmailRepository) MarkDigestSent(preferenceID uint, sentAt time.Time) error {
+	return r.DB.Model(&models.EmailPreference{}).Where("id = ?", preferenceID).Update("last_digest_at", sentAt).Error
+}
+
+// CreateDeliveries memasukkan beberapa email ke antrean sekaligus
+func (r *EmailRepository) CreateDeliveries(deliveries []models.EmailDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.DB.Create(&deliveries).Error
+}
+
+// FindDueDeliveries menemukan email tertunda yang sudah waktunya dikirim, yang terlama lebih dulu
+func (r *EmailRepository) FindDueDeliveries(now time.Time, limit int) ([]models.EmailDelivery, error) {
+	var deliveries []models.EmailDelivery
+	result := r.DB.Where("status = ? AND next_attempt_at <= ?", models.EmailStatusPending, now).
+		Order("next_attempt_at ASC").Order("id ASC").Limit(limit).Find(&deliveries)
+	return deliveries, result.Error
+}
+
+// MarkSent menandai email sudah terkirim
+func (r *EmailRepository) MarkSent(id uint, attempts int, sentAt time.Time) error {
+	return r.DB.Model(&models.EmailDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     models.EmailStatusSent,
+		"attempts":   attempts,
+		"sent_at":    sentAt,
+		"last_error": "",
+	}).Error
+}
+
+// MarkRetry menjadwalkan ulang email yang gagal dikirim
+func (r *EmailRepository) MarkRetry(id uint, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return r.DB.Model(&models.EmailDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts":        attempts,
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      lastError,
+	}).Error
+}
+
+// MarkFailed menandai email gagal setelah semua percobaan habis
+func (r *EmailRepository) MarkFailed(id uint, attempts int, lastError string) error {
+	return r.DB.Model(&models.EmailDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     models.EmailStatusFailed,
+		"attempts":   attempts,
+		"last_error": lastError,
+	}).Error
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"LMS/models"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -58,6 +59,11 @@ func (r *EnrollmentRepository) FindByCourse(courseID uint) ([]models.Enrollment,
 	return enrollments, result.Error
 }
 
+// MarkCompleted mencatat waktu siswa menyelesaikan kursus
+func (r *EnrollmentRepository) MarkCompleted(id uint, completedAt time.Time) error {
+	return r.DB.Model(&models.Enrollment{}).Where("id = ?", id).Update("completed_at", completedAt).Error
+}
+
 // Buat membuat pendaftaran baru
 func (r *EnrollmentRepository) Create(enrollment *models.Enrollment) error {
 	return r.DB.Create(enrollment).Error
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// ExtensionRepository menangani operasi basis data untuk perpanjangan tenggat
+type ExtensionRepository struct {
+	DB *gorm.DB
+}
+
+// NewExtensionRepository membuat repositori perpanjangan baru
+func NewExtensionRepository(db *gorm.DB) *ExtensionRepository {
+	return &ExtensionRepository{DB: db}
+}
+
+// FindByID menemukan perpanjangan berdasarkan ID
+func (r *ExtensionRepository) FindByID(id uint) (*models.DueDateExtension, error) {
+	var extension models.DueDateExtension
+	result := r.DB.Preload("Student").Preload("GrantedBy").First(&extension, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("extension not found")
+		}
+		return nil, result.Error
+	}
+	return &extension, nil
+}
+
+// FindActive menemukan perpanjangan yang berlaku untuk siswa pada sebuah aktivitas
+func (r *ExtensionRepository) FindActive(activityType models.ExtensionActivityType, activityID, studentID uint) (*models.DueDateExtension, error) {
+	var extension models.DueDateExtension
+	result := r.DB.Where("activity_type = ? AND activity_id = ? AND student_id = ? AND revoked_at IS NULL", activityType, activityID, studentID).
+		Order("created_at DESC").First(&extension)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("extension not found")
+		}
+		return nil, result.Error
+	}
+	return &extension, nil
+}
+
+// FindByActivity menemukan seluruh riwayat perpanjangan sebuah aktivitas, termasuk yang dicabut
+func (r *ExtensionRepository) FindByActivity(activityType models.ExtensionActivityType, activityID uint) ([]models.DueDateExtension, error) {
+	var extensions []models.DueDateExtension
+	result := r.DB.Where("activity_type = ? AND activity_id = ?", activityType, activityID).
+		Order("created_at DESC").Preload("Student").Preload("GrantedBy").Find(&extensions)
+	return extensions, result.Error
+}
+
+// FindByStudentAndCourse menemukan seluruh riwayat perpanjangan siswa dalam sebuah kursus
+func (r *ExtensionRepository) FindByStudentAndCourse(studentID, courseID uint) ([]models.DueDateExtension, error) {
+	var extensions []models.DueDateExtension
+	result := r.DB.Where("student_id = ? AND course_id = ?", studentID, courseID).
+		Order("created_at DESC").Preload("GrantedBy").Find(&extensions)
+	return extensions, result.Error
+}
+
+// Grant menyimpan perpanjangan baru dan mencabut perpanjangan aktif sebelumnya dalam satu transaksi
+func (r *ExtensionRepository) Grant(extension *models.DueDateExtension) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.DueDateExtension{}).
+			Where("activity_type = ? AND activity_id = ? AND student_id = ? AND revoked_at IS NULL",
+				extension.ActivityType, extension.ActivityID, extension.StudentID).
+			Updates(map[string]interface{}{"revoked_at": now, "revoked_by_id": extension.GrantedByID}).Error; err != nil {
+			return err
+		}
+		return tx.Create(extension).Error
+	})
+}
+
+// Revoke mencabut perpanjangan tanpa menghapus catatannya
+func (r *ExtensionRepository) Revoke(id, revokedByID uint) error {
+	return r.DB.Model(&models.DueDateExtension{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_by_id": revokedByID}).Error
+}
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+
+	"gorm.io/gorm"
+
+)
+
+// GradebookRepository menangani operasi basis data untuk buku nilai
+type GradebookRepository struct {
+	DB *gorm.DB
+}
+
+// NewGradebookRepository membuat repositori buku nilai baru
+func NewGradebookRepository(db *gorm.DB) *GradebookRepository {
+	return &GradebookRepository{DB: db}
+}
+
+// FindCategoryByID menemukan kategori nilai berdasarkan ID
+func (r *GradebookRepository) FindCategoryByID(id uint) (*models.GradeCategory, error) {
+	var category models.GradeCategory
+	result := r.DB.First(&category, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("grade category not found")
+		}
+		return nil, result.Error
+	}
+	return &category, nil
+}
+
+// FindCategoriesByCourse menemukan kategori nilai sebuah kursus
+func (r *GradebookRepository) FindCategoriesByCourse(courseID uint) ([]models.GradeCategory, error) {
+	var categories []models.GradeCategory
+	result := r.DB.Where("course_id = ?", courseID).Order("id ASC").Find(&categories)
+	return categories, result.Error
+}
+
+// FindCategoryByType menemukan kategori nilai kursus untuk jenis aktivitas tertentu
+func (r *GradebookRepository) FindCategoryByType(courseID uint, activityType models.ProgressType) (*models.GradeCategory, error) {
+	var category models.GradeCategory
+	result := r.DB.Where("course_id = ? AND activity_type = ?", courseID, activityType).First(&category)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("grade category not found")
+		}
+		return nil, result.Error
+	}
+	return &category, nil
+}
+
+// CreateCategory membuat kategori nilai baru
+func (r *GradebookRepository) CreateCategory(category *models.GradeCategory) error {
+	return r.DB.Create(category).Error
+}
+
+// UpdateCategory memperbarui kategori nilai
+func (r *GradebookRepository) UpdateCategory(category *models.GradeCategory) error {
+	return r.DB.Save(category).Error
+}
+
+// DeleteCategory menghapus kategori nilai secara permanen agar jenis aktivitasnya dapat dipakai lagi
+func (r *GradebookRepository) DeleteCategory(id uint) error {
+	return r.DB.Unscoped().Delete(&models.GradeCategory{}, id).Error
+}
+
+// FindLetterGrades menemukan skala nilai huruf kursus, dari batas tertinggi
+func (r *GradebookRepository) FindLetterGrades(courseID uint) ([]models.LetterGrade, error) {
+	var grades []models.LetterGrade
+	result := r.DB.Where("course_id = ?", courseID).Order("min_percent DESC").Find(&grades)
+	return grades, result.Error
+}
+
+// ReplaceLetterGrades mengganti seluruh skala nilai huruf kursus dalam satu transaksi
+func (r *GradebookRepository) ReplaceLetterGrades(courseID uint, grades []models.LetterGrade) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("course_id = ?", courseID).Delete(&models.LetterGrade{}).Error; err != nil {
+			return err
+		}
+		if len(grades) == 0 {
+			return nil
+		}
+		return tx.Create(&grades).Error
+	})
+}
+
+// FindProgressByCourse menemukan semua baris kemajuan dalam sebuah kursus
+func (r *GradebookRepository) FindProgressByCourse(courseID uint) ([]models.LearningProgress, error) {
+	var progress []models.LearningProgress
+	result := r.DB.Where("course_id = ?", courseID).Find(&progress)
+	return progress, result.Error
+}
+
+// FindSubmissionsByCourse menemukan semua percobaan kiriman dalam sebuah kursus beserta penilaiannya
+func (r *GradebookRepository) FindSubmissionsByCourse(courseID uint) ([]models.Submission, error) {
+	var submissions []models.Submission
+	result := r.DB.Joins("JOIN assignments ON assignments.id = submissions.assignment_id").
+		Where("assignments.course_id = ? AND assignments.deleted_at IS NULL", courseID).
+		Order("submissions.attempt_number ASC").
+		Preload("Assessment").Find(&submissions)
+	return submissions, result.Error
+}
@@ -67,7 +67,12 @@ func (r *LearningProgressRepository) GetStudentProgress(studentID, courseID uint
 				progressItems[i].ActivityTitle = "Unknown Material"
 			}
 		case models.ProgressTypeQuiz:
-			progressItems[i].ActivityTitle = "Quiz " + string(progressItems[i].ActivityID)
+			var quiz models.Quiz
+			if err := r.DB.First(&quiz, progressItems[i].ActivityID).Error; err == nil {
+				progressItems[i].ActivityTitle = quiz.Title
+			} else {
+				progressItems[i].ActivityTitle = "Unknown Quiz"
+			}
 		case models.ProgressTypeDiscussion:
 			var discussion models.Discussion
 			if err := r.DB.First(&discussion, progressItems[i].ActivityID).Error; err == nil {
@@ -81,6 +86,13 @@ func (r *LearningProgressRepository) GetStudentProgress(studentID, courseID uint
 	return progressItems, nil
 }
 
+// FindCompleted menemukan aktivitas yang sudah diselesaikan siswa dalam sebuah kursus
+func (r *LearningProgressRepository) FindCompleted(studentID, courseID uint) ([]models.LearningProgress, error) {
+	var progressItems []models.LearningProgress
+	result := r.DB.Where("user_id = ? AND course_id = ? AND completed = ?", studentID, courseID, true).Find(&progressItems)
+	return progressItems, result.Error
+}
+
 // GetCourseStudentsProgress mendapatkan kemajuan untuk semua siswa dalam sebuah kursus
 func (r *LearningProgressRepository) GetCourseStudentsProgress(courseID uint) (map[uint][]models.LearningProgress, error) {
 	// Dapatkan semua siswa yang terdaftar dalam kursus
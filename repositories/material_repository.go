@@ -21,7 +21,7 @@ func NewMaterialRepository(db *gorm.DB) *MaterialRepository {
 // FindByID menemukan materi berdasarkan ID
 func (r *MaterialRepository) FindByID(id uint) (*models.Material, error) {
 	var material models.Material
-	result := r.DB.First(&material, id)
+	result := r.DB.Preload("Files", orderFilesByPosition).First(&material, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, errors.New("material not found")
@@ -34,7 +34,7 @@ func (r *MaterialRepository) FindByID(id uint) (*models.Material, error) {
 // FindByCourse menemukan materi berdasarkan ID kursus
 func (r *MaterialRepository) FindByCourse(courseID uint) ([]models.Material, error) {
 	var materials []models.Material
-	result := r.DB.Where("course_id = ?", courseID).Find(&materials)
+	result := r.DB.Preload("Files", orderFilesByPosition).Where("course_id = ?", courseID).Find(&materials)
 	return materials, result.Error
 }
 
@@ -43,12 +43,40 @@ func (r *MaterialRepository) Create(material *models.Material) error {
 	return r.DB.Create(material).Error
 }
 
-// Memperbarui materi yang sudah ada
+// Memperbarui materi yang sudah ada; berkas bundel dikelola terpisah
 func (r *MaterialRepository) Update(material *models.Material) error {
-	return r.DB.Save(material).Error
+	return r.DB.Omit("Files").Save(material).Error
 }
 
-// Hapus menghapus materi
+// UpdateWithFiles memperbarui materi dan menambahkan berkas bundel baru dalam satu transaksi
+func (r *MaterialRepository) UpdateWithFiles(material *models.Material, files []models.MaterialFile) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Files").Save(material).Error; err != nil {
+			return err
+		}
+		return tx.Create(&files).Error
+	})
+}
+
+// DeleteFile menghapus satu berkas bundel
+func (r *MaterialRepository) DeleteFile(id uint) error {
+	return r.DB.Delete(&models.MaterialFile{}, id).Error
+}
+
+// Hapus menghapus materi beserta berkas bundel dan penempatannya di modul
 func (r *MaterialRepository) Delete(id uint) error {
-	return r.DB.Delete(&models.Material{}, id).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("material_id = ?", id).Delete(&models.MaterialFile{}).Error; err != nil {
+			return err
+		}
+		if err := deleteModuleItems(tx, models.ProgressTypeMaterial, id); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Material{}, id).Error
+	})
+}
+
+// orderFilesByPosition mengurutkan berkas bundel yang dimuat
+func orderFilesByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
 }
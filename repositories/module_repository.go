@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+
+	"gorm.io/gorm"
+
+)
+
+// ModuleRepository menangani operasi basis data untuk modul kursus
+type ModuleRepository struct {
+	DB *gorm.DB
+}
+
+// NewModuleRepository membuat repositori modul baru
+func NewModuleRepository(db *gorm.DB) *ModuleRepository {
+	return &ModuleRepository{DB: db}
+}
+
+// withItems memuat butir modul sesuai urutannya
+func withItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC, id ASC")
+	})
+}
+
+// FindByID menemukan modul berdasarkan ID beserta butirnya
+func (r *ModuleRepository) FindByID(id uint) (*models.Module, error) {
+	var module models.Module
+	result := withItems(r.DB).First(&module, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("module not found")
+		}
+		return nil, result.Error
+	}
+	return &module, nil
+}
+
+// FindByCourse menemukan modul sebuah kursus sesuai urutannya
+func (r *ModuleRepository) FindByCourse(courseID uint) ([]models.Module, error) {
+	var modules []models.Module
+	result := withItems(r.DB).Where("course_id = ?", courseID).Order("position ASC, id ASC").Find(&modules)
+	return modules, result.Error
+}
+
+// NextPosition mengembalikan posisi setelah modul terakhir di kursus
+func (r *ModuleRepository) NextPosition(courseID uint) (int, error) {
+	var position int
+	err := r.DB.Model(&models.Module{}).Where("course_id = ?", courseID).
+		Select("COALESCE(MAX(position), -1) + 1").Scan(&position).Error
+	return position, err
+}
+
+// Create membuat modul baru
+func (r *ModuleRepository) Create(module *models.Module) error {
+	return r.DB.Omit("Items").Create(module).Error
+}
+
+// Update memperbarui judul, deskripsi dan aturan ketersediaan modul
+func (r *ModuleRepository) Update(module *models.Module) error {
+	return r.DB.Model(&models.Module{}).Where("id = ?", module.ID).Updates(map[string]interface{}{
+		"title":                    module.Title,
+		"description":              module.Description,
+		"publish_at":               module.PublishAt,
+		"unpublish_at":             module.UnpublishAt,
+		"release_after_days":       module.ReleaseAfterDays,
+		"prerequisite_module_id":   module.PrerequisiteModuleID,
+		"prerequisite_min_percent": module.PrerequisiteMinPercent,
+	}).Error
+}
+
+// Delete menghapus modul; aktivitas di dalamnya tidak dihapus dan kembali menjadi tanpa modul,
+// dan modul yang memakainya sebagai prasyarat tidak lagi memiliki prasyarat
+func (r *ModuleRepository) Delete(id uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("module_id = ?", id).Delete(&models.ModuleItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Module{}).Where("prerequisite_module_id = ?", id).Updates(map[string]interface{}{
+			"prerequisite_module_id":   nil,
+			"prerequisite_min_percent": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Module{}, id).Error
+	})
+}
+
+// Reorder menyimpan urutan modul kursus sesuai urutan ID yang diberikan
+func (r *ModuleRepository) Reorder(courseID uint, ids []uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for position, id := range ids {
+			if err := tx.Model(&models.Module{}).Where("id = ? AND course_id = ?", id, courseID).
+				Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindItemByID menemukan butir modul berdasarkan ID
+func (r *ModuleRepository) FindItemByID(id uint) (*models.ModuleItem, error) {
+	var item models.ModuleItem
+	result := r.DB.First(&item, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("module item not found")
+		}
+		return nil, result.Error
+	}
+	return &item, nil
+}
+
+// FindItem menemukan butir modul yang menempatkan sebuah aktivitas
+func (r *ModuleRepository) FindItem(itemType models.ProgressType, itemID uint) (*models.ModuleItem, error) {
+	var item models.ModuleItem
+	result := r.DB.Where("item_type = ? AND item_id = ?", itemType, itemID).First(&item)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("module item not found")
+		}
+		return nil, result.Error
+	}
+	return &item, nil
+}
+
+// NextItemPosition mengembalikan posisi setelah butir terakhir di modul
+func (r *ModuleRepository) NextItemPosition(moduleID uint) (int, error) {
+	var position int
+	err := r.DB.Model(&models.ModuleItem{}).Where("module_id = ?", moduleID).
+		Select("COALESCE(MAX(position), -1) + 1").Scan(&position).Error
+	return position, err
+}
+
+// SaveItem membuat atau memperbarui butir modul
+func (r *ModuleRepository) SaveItem(item *models.ModuleItem) error {
+	return r.DB.Save(item).Error
+}
+
+// DeleteItem menghapus butir modul secara permanen agar aktivitasnya dapat ditempatkan ulang
+func (r *ModuleRepository) DeleteItem(id uint) error {
+	return r.DB.Unscoped().Delete(&models.ModuleItem{}, id).Error
+}
+
+// ReorderItems menyimpan urutan butir modul sesuai urutan ID yang diberikan
+func (r *ModuleRepository) ReorderItems(moduleID uint, ids []uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for position, id := range ids {
+			if err := tx.Model(&models.ModuleItem{}).Where("id = ? AND module_id = ?", id, moduleID).
+				Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// deleteModuleItems menghapus penempatan aktivitas di modul saat aktivitasnya dihapus
+func deleteModuleItems(tx *gorm.DB, itemType models.ProgressType, itemID uint) error {
+	return tx.Unscoped().Where("item_type = ? AND item_id = ?", itemType, itemID).Delete(&models.ModuleItem{}).Error
+}
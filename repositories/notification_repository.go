@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// NotificationRepository menangani operasi basis data untuk pemberitahuan
+type NotificationRepository struct {
+	DB *gorm.DB
+}
+
+// NewNotificationRepository membuat repositori pemberitahuan baru
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{DB: db}
+}
+
+// FindByID menemukan pemberitahuan berdasarkan ID
+func (r *NotificationRepository) FindByID(id uint) (*models.Notification, error) {
+	var notification models.Notification
+	result := r.DB.First(&notification, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("notification not found")
+		}
+		return nil, result.Error
+	}
+	return &notification, nil
+}
+
+// FindByUser menemukan pemberitahuan seorang pengguna, yang terbaru lebih dulu
+func (r *NotificationRepository) FindByUser(userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	query := r.DB.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	result := query.Order("created_at DESC").Order("id DESC").Limit(limit).Offset(offset).Find(&notifications)
+	return notifications, result.Error
+}
+
+// CountUnread menghitung pemberitahuan yang belum dibaca seorang pengguna
+func (r *NotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	result := r.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count)
+	return count, result.Error
+}
+
+// CreateBatch membuat beberapa pemberitahuan sekaligus
+func (r *NotificationRepository) CreateBatch(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.DB.Create(&notifications).Error
+}
+
+// MarkRead menandai pemberitahuan milik pengguna sebagai sudah dibaca
+func (r *NotificationRepository) MarkRead(id, userID uint) error {
+	return r.DB.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NULL", id, userID).
+		Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead menandai semua pemberitahuan pengguna sebagai sudah dibaca dan mengembalikan jumlahnya
+func (r *NotificationRepository) MarkAllRead(userID uint) (int64, error) {
+	result := r.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// FindUnreadSince menemukan pemberitahuan pengguna yang belum dibaca dan dibuat setelah waktu tertentu, yang terlama lebih dulu
+func (r *NotificationRepository) FindUnreadSince(userID uint, since time.Time) ([]models.Notification, error) {
+	var notifications []models.Notification
+	result := r.DB.Where("user_id = ? AND read_at IS NULL AND created_at > ?", userID, since).
+		Order("created_at ASC").Order("id ASC").Find(&notifications)
+	return notifications, result.Error
+}
+
+// Exists memeriksa apakah pengguna sudah pernah menerima pemberitahuan jenis tertentu untuk sebuah sumber daya
+func (r *NotificationRepository) Exists(userID uint, notificationType models.NotificationType, resourceType string, resourceID uint) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND type = ? AND resource_type = ? AND resource_id = ?", userID, notificationType, resourceType, resourceID).
+		Count(&count)
+	return count > 0, result.Error
+}
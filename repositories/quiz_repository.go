@@ -0,0 +1,221 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+
+	"gorm.io/gorm"
+
+)
+
+// QuizRepository menangani operasi basis data untuk kuis
+type QuizRepository struct {
+	DB *gorm.DB
+}
+
+// NewQuizRepository membuat repositori kuis baru
+func NewQuizRepository(db *gorm.DB) *QuizRepository {
+	return &QuizRepository{DB: db}
+}
+
+// FindByID menemukan kuis berdasarkan ID
+func (r *QuizRepository) FindByID(id uint) (*models.Quiz, error) {
+	var quiz models.Quiz
+	result := r.DB.First(&quiz, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quiz not found")
+		}
+		return nil, result.Error
+	}
+	return &quiz, nil
+}
+
+// FindByIDWithQuestions menemukan kuis beserta pertanyaan dan pilihannya secara berurutan
+func (r *QuizRepository) FindByIDWithQuestions(id uint) (*models.Quiz, error) {
+	var quiz models.Quiz
+	result := r.DB.
+		Preload("Questions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		Preload("Questions.Choices", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		First(&quiz, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quiz not found")
+		}
+		return nil, result.Error
+	}
+	return &quiz, nil
+}
+
+// FindByCourse menemukan kuis berdasarkan ID kursus
+func (r *QuizRepository) FindByCourse(courseID uint) ([]models.Quiz, error) {
+	var quizzes []models.Quiz
+	result := r.DB.Where("course_id = ?", courseID).Find(&quizzes)
+	return quizzes, result.Error
+}
+
+// FindByCourseWithQuestions menemukan kuis kursus beserta pertanyaannya untuk menghitung skor maksimal
+func (r *QuizRepository) FindByCourseWithQuestions(courseID uint) ([]models.Quiz, error) {
+	var quizzes []models.Quiz
+	result := r.DB.Where("course_id = ?", courseID).Order("id ASC").Preload("Questions").Find(&quizzes)
+	return quizzes, result.Error
+}
+
+// Create membuat kuis baru beserta pertanyaan dan pilihannya
+func (r *QuizRepository) Create(quiz *models.Quiz) error {
+	return r.DB.Create(quiz).Error
+}
+
+// Update memperbarui data kuis tanpa menyentuh pertanyaannya
+func (r *QuizRepository) Update(quiz *models.Quiz) error {
+	return r.DB.Omit("Questions").Save(quiz).Error
+}
+
+// Delete menghapus kuis beserta penempatannya di modul
+func (r *QuizRepository) Delete(id uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteModuleItems(tx, models.ProgressTypeQuiz, id); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Quiz{}, id).Error
+	})
+}
+
+// FindQuestionByID menemukan pertanyaan kuis berdasarkan ID
+func (r *QuizRepository) FindQuestionByID(id uint) (*models.QuizQuestion, error) {
+	var question models.QuizQuestion
+	result := r.DB.Preload("Choices").First(&question, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("question not found")
+		}
+		return nil, result.Error
+	}
+	return &question, nil
+}
+
+// CreateQuestion membuat pertanyaan baru beserta pilihannya
+func (r *QuizRepository) CreateQuestion(question *models.QuizQuestion) error {
+	return r.DB.Create(question).Error
+}
+
+// ReplaceQuestion memperbarui pertanyaan dan mengganti seluruh pilihannya
+func (r *QuizRepository) ReplaceQuestion(question *models.QuizQuestion) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id = ?", question.ID).Delete(&models.QuizChoice{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Choices").Save(question).Error; err != nil {
+			return err
+		}
+		for i := range question.Choices {
+			question.Choices[i].ID = 0
+			question.Choices[i].QuestionID = question.ID
+		}
+		if len(question.Choices) == 0 {
+			return nil
+		}
+		return tx.Create(&question.Choices).Error
+	})
+}
+
+// DeleteQuestion menghapus pertanyaan beserta pilihannya
+func (r *QuizRepository) DeleteQuestion(id uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("question_id = ?", id).Delete(&models.QuizChoice{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.QuizQuestion{}, id).Error
+	})
+}
+
+// FindAttemptByID menemukan percobaan kuis berdasarkan ID
+func (r *QuizRepository) FindAttemptByID(id uint) (*models.QuizAttempt, error) {
+	var attempt models.QuizAttempt
+	result := r.DB.Preload("Answers").First(&attempt, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("quiz attempt not found")
+		}
+		return nil, result.Error
+	}
+	return &attempt, nil
+}
+
+// FindAttemptsByQuizAndStudent menemukan semua percobaan seorang siswa untuk sebuah kuis
+func (r *QuizRepository) FindAttemptsByQuizAndStudent(quizID, studentID uint) ([]models.QuizAttempt, error) {
+	var attempts []models.QuizAttempt
+	result := r.DB.Where("quiz_id = ? AND student_id = ?", quizID, studentID).
+		Order("attempt_number").
+		Find(&attempts)
+	return attempts, result.Error
+}
+
+// FindAttemptsByQuiz menemukan semua percobaan untuk sebuah kuis
+func (r *QuizRepository) FindAttemptsByQuiz(quizID uint) ([]models.QuizAttempt, error) {
+	var attempts []models.QuizAttempt
+	result := r.DB.Where("quiz_id = ?", quizID).
+		Preload("Student").
+		Order("student_id, attempt_number").
+		Find(&attempts)
+	return attempts, result.Error
+}
+
+// CreateAttempt membuat percobaan kuis baru. Hasilnya false jika nomor percobaan itu sudah dipakai
+// oleh permintaan lain yang berjalan bersamaan.
+func (r *QuizRepository) CreateAttempt(attempt *models.QuizAttempt) (bool, error) {
+	err := r.DB.Create(attempt).Error
+	if translator, ok := r.DB.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		err = translator.Translate(err)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// UpdateAttempt memperbarui percobaan kuis tanpa menyentuh jawabannya
+func (r *QuizRepository) UpdateAttempt(attempt *models.QuizAttempt) error {
+	return r.DB.Omit("Answers", "Quiz", "Student").Save(attempt).Error
+}
+
+// FinishAttempt menyimpan jawaban yang dinilai dan hasil percobaan dalam satu transaksi. Percobaan hanya
+// ditutup jika statusnya masih in_progress; hasilnya false jika permintaan lain sudah menutupnya.
+func (r *QuizRepository) FinishAttempt(attempt *models.QuizAttempt) (bool, error) {
+	finished := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.QuizAttempt{}).
+			Where("id = ? AND status = ?", attempt.ID, models.AttemptStatusInProgress).
+			Updates(map[string]interface{}{
+				"status":       attempt.Status,
+				"score":        attempt.Score,
+				"max_score":    attempt.MaxScore,
+				"submitted_at": attempt.SubmittedAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		finished = true
+
+		if err := tx.Where("attempt_id = ?", attempt.ID).Delete(&models.QuizAnswer{}).Error; err != nil {
+			return err
+		}
+		if len(attempt.Answers) > 0 {
+			for i := range attempt.Answers {
+				attempt.Answers[i].AttemptID = attempt.ID
+			}
+			if err := tx.Create(&attempt.Answers).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return finished, err
+}
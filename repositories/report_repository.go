@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// ReportRepository menangani operasi basis data untuk laporan moderasi
+type ReportRepository struct {
+	DB *gorm.DB
+}
+
+// NewReportRepository membuat repositori laporan baru
+func NewReportRepository(db *gorm.DB) *ReportRepository {
+	return &ReportRepository{DB: db}
+}
+
+// FindByID menemukan laporan berdasarkan ID
+func (r *ReportRepository) FindByID(id uint) (*models.ContentReport, error) {
+	var report models.ContentReport
+	result := r.DB.First(&report, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("report not found")
+		}
+		return nil, result.Error
+	}
+	return &report, nil
+}
+
+// FindOpenByReporter menemukan laporan terbuka seorang pengguna atas diskusi atau komentar yang sama
+func (r *ReportRepository) FindOpenByReporter(reporterID, discussionID uint, commentID *uint) (*models.ContentReport, error) {
+	query := r.DB.Where("reporter_id = ? AND discussion_id = ? AND status = ?", reporterID, discussionID, models.ReportOpen)
+	if commentID != nil {
+		query = query.Where("comment_id = ?", *commentID)
+	} else {
+		query = query.Where("comment_id IS NULL")
+	}
+
+	var report models.ContentReport
+	result := query.First(&report)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("report not found")
+		}
+		return nil, result.Error
+	}
+	return &report, nil
+}
+
+// FindByCourse menemukan laporan sebuah kursus, yang terlama lebih dulu. Status kosong berarti semua status.
+func (r *ReportRepository) FindByCourse(courseID uint, status models.ReportStatus) ([]models.ContentReport, error) {
+	query := r.DB.Where("course_id = ?", courseID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var reports []models.ContentReport
+	result := query.Order("created_at ASC").Order("id ASC").Preload("Reporter").Find(&reports)
+	return reports, result.Error
+}
+
+// Create membuat laporan baru
+func (r *ReportRepository) Create(report *models.ContentReport) error {
+	return r.DB.Create(report).Error
+}
+
+// Resolve menutup laporan terbuka dengan status dan catatan penyelesaiannya
+func (r *ReportRepository) Resolve(id uint, status models.ReportStatus, resolvedByID uint, resolution string) error {
+	result := r.DB.Model(&models.ContentReport{}).
+		Where("id = ? AND status = ?", id, models.ReportOpen).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"resolved_by_id": resolvedByID,
+			"resolved_at":    time.Now(),
+			"resolution":     resolution,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("report has already been resolved")
+	}
+	return nil
+}
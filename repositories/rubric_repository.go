@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+
+	"gorm.io/gorm"
+
+)
+
+// RubricRepository menangani operasi basis data untuk rubrik
+type RubricRepository struct {
+	DB *gorm.DB
+}
+
+// NewRubricRepository membuat repositori rubrik baru
+func NewRubricRepository(db *gorm.DB) *RubricRepository {
+	return &RubricRepository{DB: db}
+}
+
+// withCriteria memuat kriteria dan tingkat capaian sesuai urutannya
+func withCriteria(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Criteria", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, id ASC")
+		}).
+		Preload("Criteria.Levels", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, id ASC")
+		})
+}
+
+// FindByID menemukan rubrik berdasarkan ID beserta kriteria dan tingkat capaiannya
+func (r *RubricRepository) FindByID(id uint) (*models.Rubric, error) {
+	var rubric models.Rubric
+	result := withCriteria(r.DB).First(&rubric, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("rubric not found")
+		}
+		return nil, result.Error
+	}
+	rubric.CalculateTotalPoints()
+	return &rubric, nil
+}
+
+// FindByCourse menemukan rubrik sebuah kursus
+func (r *RubricRepository) FindByCourse(courseID uint) ([]models.Rubric, error) {
+	var rubrics []models.Rubric
+	result := withCriteria(r.DB).Where("course_id = ?", courseID).Order("id ASC").Find(&rubrics)
+	for i := range rubrics {
+		rubrics[i].CalculateTotalPoints()
+	}
+	return rubrics, result.Error
+}
+
+// Create membuat rubrik baru beserta kriteria dan tingkat capaiannya
+func (r *RubricRepository) Create(rubric *models.Rubric) error {
+	return r.DB.Create(rubric).Error
+}
+
+// Update memperbarui rubrik dan mengganti seluruh kriteria serta tingkat capaiannya dalam satu transaksi
+func (r *RubricRepository) Update(rubric *models.Rubric) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteCriteria(tx, rubric.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Rubric{}).Where("id = ?", rubric.ID).Updates(map[string]interface{}{
+			"title":       rubric.Title,
+			"description": rubric.Description,
+		}).Error; err != nil {
+			return err
+		}
+
+		for i := range rubric.Criteria {
+			rubric.Criteria[i].RubricID = rubric.ID
+		}
+		if len(rubric.Criteria) == 0 {
+			return nil
+		}
+		return tx.Create(&rubric.Criteria).Error
+	})
+}
+
+// Delete menghapus rubrik beserta kriteria dan tingkat capaiannya
+func (r *RubricRepository) Delete(id uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteCriteria(tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Rubric{}, id).Error
+	})
+}
+
+// CountAssignments menghitung tugas yang memakai rubrik
+func (r *RubricRepository) CountAssignments(id uint) (int64, error) {
+	var count int64
+	result := r.DB.Model(&models.Assignment{}).Where("rubric_id = ?", id).Count(&count)
+	return count, result.Error
+}
+
+// CountCriterionScores menghitung skor kriteria penilaian yang merujuk rubrik
+func (r *RubricRepository) CountCriterionScores(id uint) (int64, error) {
+	var count int64
+	result := r.DB.Model(&models.AssessmentCriterionScore{}).
+		Joins("JOIN rubric_criteria ON rubric_criteria.id = assessment_criterion_scores.criterion_id").
+		Where("rubric_criteria.rubric_id = ?", id).
+		Count(&count)
+	return count, result.Error
+}
+
+// deleteCriteria menghapus kriteria dan tingkat capaian sebuah rubrik secara permanen
+func deleteCriteria(tx *gorm.DB, rubricID uint) error {
+	criteriaIDs := tx.Model(&models.RubricCriterion{}).Select("id").Where("rubric_id = ?", rubricID)
+	if err := tx.Unscoped().Where("criterion_id IN (?)", criteriaIDs).Delete(&models.RubricLevel{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("rubric_id = ?", rubricID).Delete(&models.RubricCriterion{}).Error
+}
@@ -34,21 +34,26 @@ func (r *SubmissionRepository) FindByID(id uint) (*models.Submission, error) {
 // FindByAssignment menemukan kiriman berdasarkan ID penugasan
 func (r *SubmissionRepository) FindByAssignment(assignmentID uint) ([]models.Submission, error) {
 	var submissions []models.Submission
-	result := r.DB.Where("assignment_id = ?", assignmentID).Preload("Student").Find(&submissions)
+	result := r.DB.Where("assignment_id = ?", assignmentID).
+		Order("student_id ASC").Order("attempt_number ASC").
+		Preload("Student").Find(&submissions)
 	return submissions, result.Error
 }
 
 // FindByStudent menemukan kiriman berdasarkan ID siswa
 func (r *SubmissionRepository) FindByStudent(studentID uint) ([]models.Submission, error) {
 	var submissions []models.Submission
-	result := r.DB.Where("student_id = ?", studentID).Preload("Assignment").Find(&submissions)
+	result := r.DB.Where("student_id = ?", studentID).
+		Order("assignment_id ASC").Order("attempt_number ASC").
+		Preload("Assignment").Find(&submissions)
 	return submissions, result.Error
 }
 
-// FindByAssignmentAndStudent menemukan kiriman berdasarkan ID tugas dan ID siswa
+// FindByAssignmentAndStudent menemukan percobaan kiriman terbaru berdasarkan ID tugas dan ID siswa
 func (r *SubmissionRepository) FindByAssignmentAndStudent(assignmentID, studentID uint) (*models.Submission, error) {
 	var submission models.Submission
-	result := r.DB.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).First(&submission)
+	result := r.DB.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+		Order("attempt_number DESC").First(&submission)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, errors.New("submission not found")
@@ -58,6 +63,37 @@ func (r *SubmissionRepository) FindByAssignmentAndStudent(assignmentID, studentI
 	return &submission, nil
 }
 
+// FindAttempt menemukan percobaan kiriman tertentu milik siswa
+func (r *SubmissionRepository) FindAttempt(assignmentID, studentID uint, attemptNumber int) (*models.Submission, error) {
+	var submission models.Submission
+	result := r.DB.Where("assignment_id = ? AND student_id = ? AND attempt_number = ?", assignmentID, studentID, attemptNumber).
+		First(&submission)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("submission attempt not found")
+		}
+		return nil, result.Error
+	}
+	return &submission, nil
+}
+
+// FindAttempts menemukan semua percobaan kiriman siswa untuk sebuah tugas beserta penilaiannya
+func (r *SubmissionRepository) FindAttempts(assignmentID, studentID uint) ([]models.Submission, error) {
+	var submissions []models.Submission
+	result := r.DB.Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+		Order("attempt_number ASC").Preload("Assessment").Find(&submissions)
+	return submissions, result.Error
+}
+
+// LastAttemptNumber mengembalikan nomor percobaan tertinggi yang pernah dipakai, termasuk yang sudah dihapus
+func (r *SubmissionRepository) LastAttemptNumber(assignmentID, studentID uint) (int, error) {
+	var last int
+	result := r.DB.Unscoped().Model(&models.Submission{}).
+		Where("assignment_id = ? AND student_id = ?", assignmentID, studentID).
+		Select("COALESCE(MAX(attempt_number), 0)").Scan(&last)
+	return last, result.Error
+}
+
 // Buat membuat pengajuan baru
 func (r *SubmissionRepository) Create(submission *models.Submission) error {
 	return r.DB.Create(submission).Error
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// TokenRepository menangani operasi basis data untuk refresh token dan pencabutan token akses
+type TokenRepository struct {
+	DB *gorm.DB
+}
+
+// NewTokenRepository membuat repositori token baru
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{DB: db}
+}
+
+// CreateRefreshToken menyimpan refresh token baru
+func (r *TokenRepository) CreateRefreshToken(token *models.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+// FindRefreshTokenByHash menemukan refresh token berdasarkan hash-nya
+func (r *TokenRepository) FindRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	result := r.DB.Where("token_hash = ?", hash).First(&token)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("refresh token not found")
+		}
+		return nil, result.Error
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken mencabut refresh token yang masih aktif dan mengembalikan apakah token berhasil dicabut
+func (r *TokenRepository) RevokeRefreshToken(id uint) (bool, error) {
+	result := r.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected == 1, result.Error
+}
+
+// SetReplacedBy mencatat token pengganti hasil rotasi
+func (r *TokenRepository) SetReplacedBy(id uint, replacedByID uint) error {
+	return r.DB.Model(&models.RefreshToken{}).Where("id = ?", id).Update("replaced_by_id", replacedByID).Error
+}
+
+// RevokeFamily mencabut semua refresh token dalam satu keluarga rotasi
+func (r *TokenRepository) RevokeFamily(familyID string) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser mencabut semua refresh token milik pengguna
+func (r *TokenRepository) RevokeAllForUser(userID uint) error {
+	return r.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DenyAccessToken menambahkan jti token akses ke daftar penolakan
+func (r *TokenRepository) DenyAccessToken(jti string, userID uint, expiresAt time.Time) error {
+	return r.DB.Create(&models.RevokedAccessToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+// IsAccessTokenDenied memeriksa apakah jti token akses ada dalam daftar penolakan
+func (r *TokenRepository) IsAccessTokenDenied(jti string) (bool, error) {
+	var count int64
+	result := r.DB.Model(&models.RevokedAccessToken{}).Where("jti = ?", jti).Count(&count)
+	return count > 0, result.Error
+}
+
+// PurgeExpired menghapus token yang sudah kedaluwarsa dari basis data
+func (r *TokenRepository) PurgeExpired() error {
+	now := time.Now()
+	if err := r.DB.Unscoped().Where("expires_at < ?", now).Delete(&models.RevokedAccessToken{}).Error; err != nil {
+		return err
+	}
+	return r.DB.Unscoped().Where("expires_at < ?", now).Delete(&models.RefreshToken{}).Error
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"LMS/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+)
+
+// UploadRepository menangani operasi basis data untuk sesi unggahan bertahap
+type UploadRepository struct {
+	DB *gorm.DB
+}
+
+// NewUploadRepository membuat repositori unggahan baru
+func NewUploadRepository(db *gorm.DB) *UploadRepository {
+	return &UploadRepository{DB: db}
+}
+
+// Create membuat sesi unggahan baru
+func (r *UploadRepository) Create(session *models.UploadSession) error {
+	return r.DB.Create(session).Error
+}
+
+// FindByToken menemukan sesi unggahan beserta potongannya berdasarkan token
+func (r *UploadRepository) FindByToken(token string) (*models.UploadSession, error) {
+	var session models.UploadSession
+	result := r.DB.Preload("Chunks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("chunk_index")
+	}).Where("token = ?", token).First(&session)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, errors.New("upload session not found")
+		}
+		return nil, result.Error
+	}
+	return &session, nil
+}
+
+// SaveChunk mencatat potongan yang diterima dan memperpanjang masa berlaku sesi sampai expiresAt.
+// Potongan yang dikirim ulang menimpa catatan sebelumnya. Potongan hanya dicatat selama sesi masih pending;
+// jika sesi sudah mulai disusun, tidak ada yang diubah dan hasilnya false.
+func (r *UploadRepository) SaveChunk(chunk *models.UploadChunk, expiresAt time.Time) (bool, error) {
+	saved := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// Baris sesi dikunci agar StartAssembling menunggu sampai potongan ini tercatat
+		var session models.UploadSession
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", chunk.SessionID, models.UploadStatusPending).
+			First(&session)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return result.Error
+		}
+
+		if err := tx.Model(&session).Update("expires_at", expiresAt).Error; err != nil {
+			return err
+		}
+
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "session_id"}, {Name: "chunk_index"}},
+			DoUpdates: clause.AssignmentColumns([]string{"size", "checksum", "updated_at"}),
+		}).Create(chunk).Error
+		saved = err == nil
+		return err
+	})
+	return saved, err
+}
+
+// UpdateStatus mengubah status sesi hanya jika statusnya masih from dan mengembalikan apakah perubahan terjadi
+func (r *UploadRepository) UpdateStatus(id uint, from, to string) (bool, error) {
+	result := r.DB.Model(&models.UploadSession{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected == 1, result.Error
+}
+
+// StartAssembling memindahkan sesi dari pending ke assembling sekaligus memperpanjang masa berlakunya
+// agar pembersihan sesi kedaluwarsa tidak menghapus berkas yang sedang disusun.
+// Mengembalikan false jika sesi sudah tidak pending.
+func (r *UploadRepository) StartAssembling(id uint, expiresAt time.Time) (bool, error) {
+	result := r.DB.Model(&models.UploadSession{}).
+		Where("id = ? AND status = ?", id, models.UploadStatusPending).
+		Updates(map[string]interface{}{
+			"status":     models.UploadStatusAssembling,
+			"expires_at": expiresAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// MarkCompleted menandai sesi selesai dengan materi yang menerima berkasnya dan menghapus catatan potongannya
+func (r *UploadRepository) MarkCompleted(id uint, materialID uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&models.UploadSession{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":       models.UploadStatusCompleted,
+			"material_id":  materialID,
+			"completed_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("session_id = ?", id).Delete(&models.UploadChunk{}).Error
+	})
+}
+
+// FindExpired mendapatkan sesi yang masa berlakunya sudah lewat
+func (r *UploadRepository) FindExpired(now time.Time) ([]models.UploadSession, error) {
+	var sessions []models.UploadSession
+	result := r.DB.Where("expires_at < ?", now).Find(&sessions)
+	return sessions, result.Error
+}
+
+// Delete menghapus sesi beserta potongannya secara permanen
+func (r *UploadRepository) Delete(id uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("session_id = ?", id).Delete(&models.UploadChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.UploadSession{}, id).Error
+	})
+}
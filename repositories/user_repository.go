@@ -44,6 +44,16 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByIDs menemukan beberapa pengguna sekaligus berdasarkan ID
+func (r *UserRepository) FindByIDs(ids []uint) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	result := r.DB.Where("id IN ?", ids).Find(&users)
+	return users, result.Error
+}
+
 // Buat membuat pengguna baru
 func (r *UserRepository) Create(user *models.User) error {
 	return r.DB.Create(user).Error
@@ -65,3 +75,10 @@ func (r *UserRepository) ListAll(limit, offset int) ([]models.User, error) {
 	result := r.DB.Limit(limit).Offset(offset).Find(&users)
 	return users, result.Error
 }
+
+// IncrementTokenVersion menaikkan versi token pengguna sehingga semua token akses lama ditolak
+func (r *UserRepository) IncrementTokenVersion(id uint) error {
+	// UpdateColumn melewati hook BeforeSave agar sandi tidak di-hash ulang
+	return r.DB.Model(&models.User{}).Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + ?", 1)).Error
+}
@@ -1,11 +1,17 @@
 package routes
 
 import (
+	"LMS/authz"
 	"LMS/controllers"
+	"LMS/events"
+	"LMS/mailer"
 	"LMS/middleware"
 	"LMS/models"
+	"LMS/realtime"
 	"LMS/repositories"
 	"LMS/services"
+	"LMS/storage"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -13,7 +19,7 @@ import (
 )
 
 // SetupRoutes mengkonfigurasi rute API
-func SetupRoutes(router *gin.Engine, db *gorm.DB) {
+func SetupRoutes(router *gin.Engine, db *gorm.DB, store storage.Backend, uploadLimits services.UploadLimits, materialCompletion services.MaterialCompletion, mail mailer.Mailer, emailSettings services.EmailSettings) {
 	// Buat repositori
 	userRepo := repositories.NewUserRepository(db)
 	courseRepo := repositories.NewCourseRepository(db)
@@ -25,33 +31,89 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 	discussionRepo := repositories.NewDiscussionRepository(db)
 	commentRepo := repositories.NewCommentRepository(db)
 	progressRepo := repositories.NewLearningProgressRepository(db)
+	quizRepo := repositories.NewQuizRepository(db)
+	tokenRepo := repositories.NewTokenRepository(db)
+	extensionRepo := repositories.NewExtensionRepository(db)
+	gradebookRepo := repositories.NewGradebookRepository(db)
+	rubricRepo := repositories.NewRubricRepository(db)
+	blobRepo := repositories.NewBlobRepository(db)
+	uploadRepo := repositories.NewUploadRepository(db)
+	moduleRepo := repositories.NewModuleRepository(db)
+	completionRepo := repositories.NewCompletionRepository(db)
+	reportRepo := repositories.NewReportRepository(db)
+	certificateRepo := repositories.NewCertificateRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	emailRepo := repositories.NewEmailRepository(db)
+
+	// Bus kejadian untuk layanan yang bereaksi terhadap perubahan, misalnya pemberitahuan
+	bus := events.NewBus()
+	// Hub realtime di dalam proses; dapat diganti dengan Broker lain yang memakai broker pesan
+	hub := realtime.NewHub(64)
 
 	// buat service
-	authService := services.NewAuthService(userRepo)
-	courseService := services.NewCourseService(courseRepo, userRepo)
-	materialService := services.NewMaterialService(materialRepo, courseRepo)
-	assignmentService := services.NewAssignmentService(assignmentRepo, courseRepo)
+	authService := services.NewAuthService(userRepo, tokenRepo)
+	availabilityService := services.NewAvailabilityService(moduleRepo, enrollmentRepo, progressRepo)
+	moduleService := services.NewModuleService(moduleRepo, courseRepo, materialRepo, assignmentRepo, quizRepo, discussionRepo, availabilityService)
+	courseService := services.NewCourseService(courseRepo, userRepo, moduleService)
+	blobService := services.NewBlobService(blobRepo, store)
+	extensionService := services.NewExtensionService(extensionRepo, assignmentRepo, quizRepo, enrollmentRepo, userRepo)
+	progressService := services.NewLearningProgressService(progressRepo, userRepo, courseRepo, enrollmentRepo, assignmentRepo, materialCompletion)
+	materialService := services.NewMaterialService(materialRepo, courseRepo, blobService, availabilityService, progressService, bus, uploadLimits)
+	uploadService := services.NewResumableUploadService(uploadRepo, courseRepo, materialRepo, materialService, uploadLimits)
+	assignmentService := services.NewAssignmentService(assignmentRepo, courseRepo, rubricRepo, extensionService, availabilityService, bus)
 	enrollmentService := services.NewEnrollmentService(enrollmentRepo, userRepo, courseRepo)
-	submissionService := services.NewSubmissionService(submissionRepo, assignmentRepo, enrollmentRepo, userRepo)
-	assessmentService := services.NewAssessmentService(assessmentRepo, submissionRepo, assignmentRepo, userRepo)
-	discussionService := services.NewDiscussionService(discussionRepo, courseRepo, userRepo, enrollmentRepo)
-	commentService := services.NewCommentService(commentRepo, discussionRepo, userRepo, courseRepo, enrollmentRepo)
-	progressService := services.NewLearningProgressService(progressRepo, userRepo, courseRepo, enrollmentRepo, assignmentRepo)
+	submissionService := services.NewSubmissionService(submissionRepo, assignmentRepo, enrollmentRepo, userRepo, courseRepo, extensionService, availabilityService, blobService, uploadLimits)
+	assessmentService := services.NewAssessmentService(assessmentRepo, submissionRepo, assignmentRepo, userRepo, rubricRepo, bus)
+	discussionService := services.NewDiscussionService(discussionRepo, courseRepo, userRepo, enrollmentRepo, bus)
+	commentService := services.NewCommentService(commentRepo, discussionRepo, userRepo, courseRepo, enrollmentRepo, bus)
+	reportService := services.NewReportService(reportRepo, discussionRepo, commentRepo, commentService)
+	quizService := services.NewQuizService(quizRepo, courseRepo, enrollmentRepo, userRepo, progressService, extensionService, availabilityService)
+	rubricService := services.NewRubricService(rubricRepo, courseRepo)
+	gradebookService := services.NewGradebookService(gradebookRepo, courseRepo, enrollmentRepo, assignmentRepo, quizRepo, materialRepo, discussionRepo, progressService)
+	completionService := services.NewCompletionService(completionRepo, courseRepo, enrollmentRepo, progressRepo, moduleService, gradebookService)
+	progressService.CompletionService = completionService
+	certificateService := services.NewCertificateService(certificateRepo, enrollmentRepo, courseRepo, userRepo, gradebookService, completionService)
+	completionService.CertificateService = certificateService
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, courseRepo, enrollmentRepo, assessmentRepo, submissionRepo, assignmentRepo, materialRepo, discussionRepo, commentRepo, availabilityService, extensionService)
+	emailService := services.NewEmailService(emailRepo, notificationRepo, userRepo, courseRepo, mail, emailSettings)
+	notificationService.EmailService = emailService
+	bus.Subscribe(notificationService.HandleEvent)
+	realtimeService := services.NewRealtimeService(hub, userRepo, discussionRepo, commentRepo, assessmentRepo, submissionRepo)
+	bus.Subscribe(realtimeService.HandleEvent)
+
+	// Buat kebijakan otorisasi per kursus
+	policy := authz.NewPolicy(courseRepo, enrollmentRepo, materialRepo, assignmentRepo, quizRepo, submissionRepo, assessmentRepo, discussionRepo, commentRepo, progressRepo, extensionRepo, rubricRepo, moduleRepo, certificateRepo)
 
 	// buat controllers
 	authController := controllers.NewAuthController(authService)
-	courseController := controllers.NewCourseController(courseService)
-	materialController := controllers.NewMaterialController(materialService)
-	assignmentController := controllers.NewAssignmentController(assignmentService)
-	enrollmentController := controllers.NewEnrollmentController(enrollmentService)
-	submissionController := controllers.NewSubmissionController(submissionService)
-	assessmentController := controllers.NewAssessmentController(assessmentService)
-	discussionController := controllers.NewDiscussionController(discussionService)
-	commentController := controllers.NewCommentController(commentService)
-	progressController := controllers.NewProgressController(progressService)
-
-	// Buat direktori unggahan
-	createUploadDirectories()
+	courseController := controllers.NewCourseController(courseService, policy)
+	materialController := controllers.NewMaterialController(materialService, policy)
+	assignmentController := controllers.NewAssignmentController(assignmentService, policy)
+	enrollmentController := controllers.NewEnrollmentController(enrollmentService, policy)
+	submissionController := controllers.NewSubmissionController(submissionService, policy)
+	assessmentController := controllers.NewAssessmentController(assessmentService, policy)
+	discussionController := controllers.NewDiscussionController(discussionService, policy)
+	commentController := controllers.NewCommentController(commentService, policy)
+	reportController := controllers.NewReportController(reportService, policy)
+	progressController := controllers.NewProgressController(progressService, policy)
+	quizController := controllers.NewQuizController(quizService, policy)
+	extensionController := controllers.NewExtensionController(extensionService, policy)
+	gradebookController := controllers.NewGradebookController(gradebookService, policy)
+	rubricController := controllers.NewRubricController(rubricService, policy)
+	fileController := controllers.NewFileController(store, blobService, materialService)
+	uploadController := controllers.NewUploadController(uploadService, policy)
+	moduleController := controllers.NewModuleController(moduleService, policy)
+	completionController := controllers.NewCompletionController(completionService, policy)
+	certificateController := controllers.NewCertificateController(certificateService, policy)
+	notificationController := controllers.NewNotificationController(notificationService, emailService)
+	realtimeController := controllers.NewRealtimeController(hub, policy, authService)
+
+	// Bersihkan sesi unggahan bertahap yang ditinggalkan secara berkala
+	go uploadService.RunCleanup(time.Hour)
+
+	// Kirim antrean email dan pengingat tenggat di latar belakang
+	go emailService.RunWorker(30 * time.Second)
+	go notificationService.RunDueReminders(15 * time.Minute)
 
 	// router
 	api := router.Group("/api")
@@ -61,21 +123,39 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 		{
 			auth.POST("/register", authController.Register)
 			auth.POST("/login", authController.Login)
+			auth.POST("/refresh", authController.Refresh)
+			auth.POST("/logout", middleware.AuthMiddleware(authService), authController.Logout)
 		}
 
+		// Unduhan berkas lokal lewat URL sementara; akses dijamin oleh tanda tangan URL
+		api.GET("/files/*key", fileController.DownloadSignedFile)
+
+		// Verifikasi sertifikat oleh pihak luar tanpa login
+		api.GET("/certificates/verify/:code", certificateController.VerifyCertificate)
+
 		// Rute yang dilindungi
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(authService))
 		{
 			// Profil pengguna
 			protected.GET("/profile", authController.GetProfile)
 
+			// Rute hanya untuk admin
+			adminUsers := protected.Group("/users")
+			adminUsers.Use(func(c *gin.Context) {
+				middleware.RoleMiddleware(models.RoleAdmin)(c)
+			})
+			{
+				adminUsers.POST("/:id/revoke-tokens", authController.RevokeUserTokens)
+			}
+
 			// Courses
 			courses := protected.Group("/courses")
 			{
 				//Rute untuk para admin dan mentor
 				courses.GET("", courseController.GetAllCourses)
 				courses.GET("/:id", courseController.GetCourseByID)
+				courses.GET("/:id/outline", moduleController.GetCourseOutline)
 
 				//Rute untuk para admin dan mentor
 				adminMentorCourses := courses.Group("/")
@@ -97,18 +177,68 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 				materials.GET("/:id", materialController.GetMaterialByID)
 				materials.GET("/course/:course_id", materialController.GetMaterialsByCourse)
 				materials.GET("/download/:id", materialController.DownloadMaterial)
+				materials.GET("/download/:id/url", materialController.GetMaterialDownloadURL)
+				materials.GET("/stream/:id", materialController.StreamMaterial)
+				materials.GET("/stream/:id/hls/*path", materialController.StreamMaterialHLS)
+				materials.GET("/download/:id/files/:file_id", materialController.DownloadMaterialFile)
+
+				// Rute untuk siswa
+				studentMaterials := materials.Group("/")
+				studentMaterials.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleStudent)(c)
+				})
+				{
+					studentMaterials.POST("/:id/view", materialController.MarkMaterialViewed)
+				}
+
 				// Rute untuk admin dan mentor
 				adminMentorMaterials := materials.Group("/")
 				adminMentorMaterials.Use(func(c *gin.Context) {
 					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
 				})
 				{
-					adminMentorMaterials.POST("", materialController.CreateMaterial)
-					adminMentorMaterials.PUT("/:id", materialController.UpdateMaterial)
+					adminMentorMaterials.POST("", middleware.BodySizeLimit(uploadLimits.RequestSize(uploadLimits.MaxMaterialSize)), materialController.CreateMaterial)
+					adminMentorMaterials.PUT("/:id", middleware.BodySizeLimit(uploadLimits.RequestSize(uploadLimits.MaxMaterialSize)), materialController.UpdateMaterial)
 					adminMentorMaterials.DELETE("/:id", materialController.DeleteMaterial)
+					adminMentorMaterials.DELETE("/:id/files/:file_id", materialController.DeleteMaterialFile)
+				}
+			}
+
+			// Modules
+			modules := protected.Group("/modules")
+			{
+				// Rute untuk semua pengguna yang diautentikasi
+				modules.GET("/:id", moduleController.GetModuleByID)
+
+				// Rute untuk admin dan mentor
+				adminMentorModules := modules.Group("/")
+				adminMentorModules.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorModules.POST("", moduleController.CreateModule)
+					adminMentorModules.PUT("/:id", moduleController.UpdateModule)
+					adminMentorModules.DELETE("/:id", moduleController.DeleteModule)
+					adminMentorModules.PUT("/course/:course_id/order", moduleController.ReorderModules)
+					adminMentorModules.POST("/:id/items", moduleController.AddModuleItem)
+					adminMentorModules.DELETE("/:id/items/:item_id", moduleController.RemoveModuleItem)
+					adminMentorModules.PUT("/:id/items/order", moduleController.ReorderModuleItems)
 				}
 			}
 
+			// Unggahan bertahap untuk materi berukuran besar
+			uploads := protected.Group("/uploads")
+			uploads.Use(func(c *gin.Context) {
+				middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+			})
+			{
+				uploads.POST("", uploadController.InitUpload)
+				uploads.GET("/:upload_id", uploadController.GetUpload)
+				uploads.PUT("/:upload_id/chunks/:index", middleware.BodySizeLimit(services.MaxResumableChunkSize), uploadController.UploadChunk)
+				uploads.POST("/:upload_id/complete", uploadController.CompleteUpload)
+				uploads.DELETE("/:upload_id", uploadController.AbortUpload)
+			}
+
 			// Assignments
 			assignments := protected.Group("/assignments")
 			{
@@ -162,6 +292,8 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 				// Rute untuk semua pengguna yang diautentikasi
 				submissions.GET("/:id", submissionController.GetSubmissionByID)
 				submissions.GET("/download/:id", submissionController.DownloadSubmission)
+				submissions.GET("/download/:id/url", submissionController.GetSubmissionDownloadURL)
+				submissions.GET("/assignment/:assignment_id/student/:student_id/attempts", submissionController.GetSubmissionAttempts)
 
 				// Rute untuk siswa
 				studentSubmissions := submissions.Group("/")
@@ -169,7 +301,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 					middleware.RoleMiddleware(models.RoleStudent)(c)
 				})
 				{
-					studentSubmissions.POST("", submissionController.CreateSubmission)
+					studentSubmissions.POST("", middleware.BodySizeLimit(uploadLimits.RequestSize(uploadLimits.MaxSubmissionSize)), submissionController.CreateSubmission)
 					studentSubmissions.DELETE("/:id", submissionController.DeleteSubmission)
 				}
 
@@ -212,6 +344,18 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 				discussions.POST("", discussionController.CreateDiscussion)
 				discussions.PUT("/:id", discussionController.UpdateDiscussion)
 				discussions.DELETE("/:id", discussionController.DeleteDiscussion)
+
+				// Rute moderasi untuk admin dan mentor
+				adminMentorDiscussions := discussions.Group("/")
+				adminMentorDiscussions.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorDiscussions.POST("/:id/pin", discussionController.PinDiscussion)
+					adminMentorDiscussions.DELETE("/:id/pin", discussionController.UnpinDiscussion)
+					adminMentorDiscussions.POST("/:id/lock", discussionController.LockDiscussion)
+					adminMentorDiscussions.DELETE("/:id/lock", discussionController.UnlockDiscussion)
+				}
 			}
 
 			// Comments
@@ -220,9 +364,42 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 				// Rute untuk semua pengguna yang diautentikasi
 				comments.GET("/:id", commentController.GetCommentByID)
 				comments.GET("/discussion/:discussion_id", commentController.GetCommentsByDiscussion)
+				comments.GET("/discussion/:discussion_id/tree", commentController.GetCommentTree)
 				comments.POST("", commentController.CreateComment)
 				comments.PUT("/:id", commentController.UpdateComment)
 				comments.DELETE("/:id", commentController.DeleteComment)
+				comments.POST("/:id/reactions", commentController.AddReaction)
+				comments.DELETE("/:id/reactions/:type", commentController.RemoveReaction)
+				comments.POST("/:id/accept", commentController.AcceptAnswer)
+				comments.DELETE("/:id/accept", commentController.UnacceptAnswer)
+
+				// Rute moderasi untuk admin dan mentor
+				adminMentorComments := comments.Group("/")
+				adminMentorComments.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorComments.POST("/:id/hide", commentController.HideComment)
+					adminMentorComments.DELETE("/:id/hide", commentController.UnhideComment)
+				}
+			}
+
+			// Laporan diskusi dan komentar
+			reports := protected.Group("/reports")
+			{
+				// Rute untuk semua pengguna yang diautentikasi
+				reports.POST("", reportController.CreateReport)
+
+				// Rute untuk admin dan mentor
+				adminMentorReports := reports.Group("/")
+				adminMentorReports.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorReports.GET("/course/:course_id", reportController.GetReportsByCourse)
+					adminMentorReports.GET("/:id", reportController.GetReportByID)
+					adminMentorReports.POST("/:id/resolve", reportController.ResolveReport)
+				}
 			}
 			// Learning Progress
 			progress := protected.Group("/progress")
@@ -251,11 +428,182 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB) {
 				}
 			}
 
-		}
-	}
-}
+			// Quizzes
+			quizzes := protected.Group("/quizzes")
+			{
+				// Rute untuk semua pengguna yang diautentikasi
+				quizzes.GET("/:id", quizController.GetQuizByID)
+				quizzes.GET("/course/:course_id", quizController.GetQuizzesByCourse)
+				quizzes.GET("/:id/attempts", quizController.GetQuizAttempts)
+				quizzes.GET("/attempts/:attempt_id", quizController.GetAttemptByID)
 
-// Fungsi pembantu untuk membuat direktori unggahan
-func createUploadDirectories() {
+				// Rute untuk siswa
+				studentQuizzes := quizzes.Group("/")
+				studentQuizzes.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleStudent)(c)
+				})
+				{
+					studentQuizzes.POST("/:id/attempts", quizController.StartAttempt)
+					studentQuizzes.POST("/attempts/:attempt_id/submit", quizController.SubmitAttempt)
+				}
 
+				// Rute untuk admin dan mentor
+				adminMentorQuizzes := quizzes.Group("/")
+				adminMentorQuizzes.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorQuizzes.POST("", quizController.CreateQuiz)
+					adminMentorQuizzes.PUT("/:id", quizController.UpdateQuiz)
+					adminMentorQuizzes.DELETE("/:id", quizController.DeleteQuiz)
+					adminMentorQuizzes.POST("/:id/questions", quizController.AddQuestion)
+					adminMentorQuizzes.PUT("/questions/:question_id", quizController.UpdateQuestion)
+					adminMentorQuizzes.DELETE("/questions/:question_id", quizController.DeleteQuestion)
+				}
+			}
+
+			// Extensions
+			extensions := protected.Group("/extensions")
+			{
+				// Rute untuk semua pengguna yang diautentikasi
+				extensions.GET("/:id", extensionController.GetExtensionByID)
+				extensions.GET("/student/:student_id/course/:course_id", extensionController.GetStudentExtensions)
+
+				// Rute untuk admin dan mentor
+				adminMentorExtensions := extensions.Group("/")
+				adminMentorExtensions.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorExtensions.POST("", extensionController.GrantExtension)
+					adminMentorExtensions.GET("/activity/:activity_type/:activity_id", extensionController.GetExtensionsByActivity)
+					adminMentorExtensions.POST("/:id/revoke", extensionController.RevokeExtension)
+				}
+			}
+
+			// Rubrics
+			rubrics := protected.Group("/rubrics")
+			{
+				// Rute untuk semua pengguna yang diautentikasi
+				rubrics.GET("/:id", rubricController.GetRubricByID)
+				rubrics.GET("/course/:course_id", rubricController.GetRubricsByCourse)
+
+				// Rute untuk admin dan mentor
+				adminMentorRubrics := rubrics.Group("/")
+				adminMentorRubrics.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorRubrics.POST("", rubricController.CreateRubric)
+					adminMentorRubrics.PUT("/:id", rubricController.UpdateRubric)
+					adminMentorRubrics.DELETE("/:id", rubricController.DeleteRubric)
+					adminMentorRubrics.POST("/:id/duplicate", rubricController.DuplicateRubric)
+				}
+			}
+
+			// Gradebook
+			gradebook := protected.Group("/gradebook")
+			{
+				// Rute untuk semua pengguna yang diautentikasi
+				gradebook.GET("/course/:course_id/categories", gradebookController.GetCategories)
+				gradebook.GET("/course/:course_id/scale", gradebookController.GetLetterGrades)
+				gradebook.GET("/course/:course_id/student/:student_id", gradebookController.GetStudentGrade)
+
+				// Rute untuk admin dan mentor
+				adminMentorGradebook := gradebook.Group("/")
+				adminMentorGradebook.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorGradebook.GET("/course/:course_id", gradebookController.GetCourseGradebook)
+					adminMentorGradebook.GET("/course/:course_id/export", gradebookController.ExportGradebook)
+					adminMentorGradebook.POST("/course/:course_id/import", gradebookController.ImportGradebook)
+					adminMentorGradebook.POST("/course/:course_id/categories", gradebookController.CreateCategory)
+					adminMentorGradebook.PUT("/categories/:id", gradebookController.UpdateCategory)
+					adminMentorGradebook.DELETE("/categories/:id", gradebookController.DeleteCategory)
+					adminMentorGradebook.PUT("/course/:course_id/scale", gradebookController.SetLetterGrades)
+				}
+			}
+
+			// Course completion
+			completion := protected.Group("/completion")
+			{
+				// Rute untuk semua pengguna yang diautentikasi
+				completion.GET("/course/:course_id/rule", completionController.GetCompletionRule)
+				completion.GET("/course/:course_id/student/:student_id", completionController.GetStudentCompletion)
+
+				// Rute untuk admin dan mentor
+				adminMentorCompletion := completion.Group("/")
+				adminMentorCompletion.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorCompletion.PUT("/course/:course_id/rule", completionController.SetCompletionRule)
+					adminMentorCompletion.GET("/course/:course_id/students", completionController.GetCourseCompletions)
+				}
+			}
+
+			// Student dashboard
+			dashboard := protected.Group("/dashboard")
+			dashboard.Use(func(c *gin.Context) {
+				middleware.RoleMiddleware(models.RoleStudent)(c)
+			})
+			{
+				dashboard.GET("", completionController.GetDashboard)
+			}
+
+			// Certificates
+			certificates := protected.Group("/certificates")
+			{
+				// Rute untuk semua pengguna yang diautentikasi
+				certificates.GET("", certificateController.GetMyCertificates)
+				certificates.GET("/:id", certificateController.GetCertificateByID)
+				certificates.GET("/:id/pdf", certificateController.DownloadCertificate)
+
+				// Rute untuk siswa
+				studentCertificates := certificates.Group("/")
+				studentCertificates.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleStudent)(c)
+				})
+				{
+					studentCertificates.POST("/course/:course_id", certificateController.ClaimCertificate)
+				}
+
+				// Rute untuk admin dan mentor
+				adminMentorCertificates := certificates.Group("/")
+				adminMentorCertificates.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin, models.RoleMentor)(c)
+				})
+				{
+					adminMentorCertificates.GET("/course/:course_id", certificateController.GetCertificatesByCourse)
+					adminMentorCertificates.GET("/course/:course_id/template", certificateController.GetCertificateTemplate)
+					adminMentorCertificates.PUT("/course/:course_id/template", certificateController.SetCertificateTemplate)
+				}
+
+				// Rute hanya untuk admin
+				adminCertificates := certificates.Group("/")
+				adminCertificates.Use(func(c *gin.Context) {
+					middleware.RoleMiddleware(models.RoleAdmin)(c)
+				})
+				{
+					adminCertificates.POST("/:id/revoke", certificateController.RevokeCertificate)
+				}
+			}
+
+			// Notifications; setiap pengguna hanya melihat kotak masuknya sendiri
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", notificationController.GetNotifications)
+				notifications.GET("/unread-count", notificationController.GetUnreadCount)
+				notifications.GET("/preferences", notificationController.GetEmailPreference)
+				notifications.PUT("/preferences", notificationController.SetEmailPreference)
+				notifications.POST("/read-all", notificationController.MarkAllRead)
+				notifications.POST("/:id/read", notificationController.MarkRead)
+			}
+
+			// Pembaruan realtime lewat Server-Sent Events
+			protected.GET("/realtime/stream", realtimeController.Stream)
+
+		}
+	}
 }
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"LMS/events"
 	"LMS/models"
 	"LMS/repositories"
 	"errors"
+	"fmt"
+	"math"
 	"time"
 
 )
@@ -14,6 +17,8 @@ type AssessmentService struct {
 	SubmissionRepo *repositories.SubmissionRepository
 	AssignmentRepo *repositories.AssignmentRepository
 	UserRepo       *repositories.UserRepository
+	RubricRepo     *repositories.RubricRepository
+	Events         *events.Bus
 }
 
 // NewAssessmentService membuat layanan penilaian baru
@@ -22,17 +27,30 @@ func NewAssessmentService(
 	submissionRepo *repositories.SubmissionRepository,
 	assignmentRepo *repositories.AssignmentRepository,
 	userRepo *repositories.UserRepository,
+	rubricRepo *repositories.RubricRepository,
+	bus *events.Bus,
 ) *AssessmentService {
 	return &AssessmentService{
 		AssessmentRepo: assessmentRepo,
 		SubmissionRepo: submissionRepo,
 		AssignmentRepo: assignmentRepo,
 		UserRepo:       userRepo,
+		RubricRepo:     rubricRepo,
+		Events:         bus,
 	}
 }
 
+// CriterionScoreInput adalah skor satu kriteria rubrik yang dikirim mentor.
+// Isi LevelID untuk memakai poin tingkat capaian, atau Points untuk poin di antara tingkat.
+type CriterionScoreInput struct {
+	CriterionID uint
+	LevelID     *uint
+	Points      *int
+	Comment     string
+}
+
 // CreateAssessment membuat penilaian baru
-func (s *AssessmentService) CreateAssessment(assessment *models.Assessment) error {
+func (s *AssessmentService) CreateAssessment(assessment *models.Assessment, criteria []CriterionScoreInput) error {
 	// Verifikasi adanya kiriman
 	submission, err := s.SubmissionRepo.FindByID(assessment.SubmissionID)
 	if err != nil {
@@ -45,6 +63,16 @@ func (s *AssessmentService) CreateAssessment(assessment *models.Assessment) erro
 		return errors.New("assignment not found")
 	}
 
+	// Skor dihitung dari rubrik jika tugas memakai rubrik
+	scores, err := s.scoreRubric(assignment, assessment.Score, criteria)
+	if err != nil {
+		return err
+	}
+	if scores != nil {
+		total := totalCriterionPoints(scores)
+		assessment.Score = &total
+	}
+
 	// Validasi skor jika skor maksimal ditetapkan
 	if assignment.MaxScore != nil && assessment.Score != nil {
 		if *assessment.Score < 0 || *assessment.Score > *assignment.MaxScore {
@@ -56,17 +84,37 @@ func (s *AssessmentService) CreateAssessment(assessment *models.Assessment) erro
 	existingAssessment, err := s.AssessmentRepo.FindBySubmission(assessment.SubmissionID)
 	if err == nil && existingAssessment != nil {
 		// Jika penilaian ada, perbarui
-		existingAssessment.Score = assessment.Score
+		applyLatePenalty(existingAssessment, assessment.Score, submission, assignment)
 		existingAssessment.Feedback = assessment.Feedback
 		existingAssessment.AssessedAt = time.Now()
-		return s.AssessmentRepo.Update(existingAssessment)
+		if err := s.AssessmentRepo.SaveWithCriterionScores(existingAssessment, scores); err != nil {
+			return err
+		}
+		*assessment = *existingAssessment
+		s.publish(events.AssessmentUpdated, assessment, assignment)
+		return nil
 	}
 
+	// Skor yang dikirim mentor adalah skor mentah; potongan keterlambatan diterapkan otomatis
+	applyLatePenalty(assessment, assessment.Score, submission, assignment)
+
 	// Tetapkan tanggal penilaian
 	assessment.AssessedAt = time.Now()
 
 	// Buat penilaian
-	return s.AssessmentRepo.Create(assessment)
+	if err := s.AssessmentRepo.SaveWithCriterionScores(assessment, scores); err != nil {
+		return err
+	}
+	s.publish(events.AssessmentCreated, assessment, assignment)
+	return nil
+}
+
+// ResolveSubmission menemukan percobaan kiriman tertentu, atau percobaan terbaru jika nomor tidak diberikan
+func (s *AssessmentService) ResolveSubmission(assignmentID, studentID uint, attemptNumber *int) (*models.Submission, error) {
+	if attemptNumber != nil {
+		return s.SubmissionRepo.FindAttempt(assignmentID, studentID, *attemptNumber)
+	}
+	return s.SubmissionRepo.FindByAssignmentAndStudent(assignmentID, studentID)
 }
 
 // GetAssessmentByID mendapatkan penilaian berdasarkan ID
@@ -80,7 +128,7 @@ func (s *AssessmentService) GetAssessmentBySubmission(submissionID uint) (*model
 }
 
 // UpdateAssessment memperbarui penilaian
-func (s *AssessmentService) UpdateAssessment(assessment *models.Assessment) error {
+func (s *AssessmentService) UpdateAssessment(assessment *models.Assessment, criteria []CriterionScoreInput) error {
 	// Verifikasi penilaian yang ada
 	existingAssessment, err := s.AssessmentRepo.FindByID(assessment.ID)
 	if err != nil {
@@ -99,6 +147,15 @@ func (s *AssessmentService) UpdateAssessment(assessment *models.Assessment) erro
 		return errors.New("assignment not found")
 	}
 
+	scores, err := s.scoreRubric(assignment, assessment.Score, criteria)
+	if err != nil {
+		return err
+	}
+	if scores != nil {
+		total := totalCriterionPoints(scores)
+		assessment.Score = &total
+	}
+
 	// Validasi skor jika skor maksimal ditetapkan
 	if assignment.MaxScore != nil && assessment.Score != nil {
 		if *assessment.Score < 0 || *assessment.Score > *assignment.MaxScore {
@@ -107,14 +164,139 @@ func (s *AssessmentService) UpdateAssessment(assessment *models.Assessment) erro
 	}
 
 	// Perbarui hanya bidang yang diizinkan
-	existingAssessment.Score = assessment.Score
+	applyLatePenalty(existingAssessment, assessment.Score, submission, assignment)
 	existingAssessment.Feedback = assessment.Feedback
 	existingAssessment.AssessedAt = time.Now()
 
-	return s.AssessmentRepo.Update(existingAssessment)
+	if err := s.AssessmentRepo.SaveWithCriterionScores(existingAssessment, scores); err != nil {
+		return err
+	}
+	*assessment = *existingAssessment
+	s.publish(events.AssessmentUpdated, assessment, assignment)
+	return nil
+}
+
+// publish mengumumkan penilaian yang baru disimpan kepada pelanggan bus kejadian
+func (s *AssessmentService) publish(eventType events.Type, assessment *models.Assessment, assignment *models.Assignment) {
+	s.Events.Publish(events.Event{
+		Type:       eventType,
+		CourseID:   assignment.CourseID,
+		ResourceID: assessment.ID,
+	})
 }
 
 // HapusPenilaian menghapus penilaian
 func (s *AssessmentService) DeleteAssessment(id uint) error {
 	return s.AssessmentRepo.Delete(id)
 }
+
+// applyLatePenalty menyimpan skor mentah dan menghitung skor akhir setelah potongan keterlambatan
+func applyLatePenalty(assessment *models.Assessment, rawScore *int, submission *models.Submission, assignment *models.Assignment) {
+	assessment.RawScore = rawScore
+	assessment.Score = rawScore
+	assessment.LatePenaltyPercent = 0
+
+	if rawScore == nil || !submission.IsLate {
+		return
+	}
+
+	percent := assignment.LatePenaltyPercent(submission.DaysLate)
+	penalized := int(math.Round(float64(*rawScore) * (100 - percent) / 100))
+	assessment.LatePenaltyPercent = percent
+	assessment.Score = &penalized
+}
+
+// scoreRubric memvalidasi skor per kriteria terhadap rubrik tugas.
+// Mengembalikan nil jika tugas tidak memakai rubrik.
+func (s *AssessmentService) scoreRubric(assignment *models.Assignment, score *int, criteria []CriterionScoreInput) ([]models.AssessmentCriterionScore, error) {
+	if assignment.RubricID == nil {
+		if len(criteria) > 0 {
+			return nil, errors.New("assignment does not use a rubric")
+		}
+		return nil, nil
+	}
+
+	rubric, err := s.RubricRepo.FindByID(*assignment.RubricID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(criteria) == 0 {
+		return nil, errors.New("assignment uses a rubric; score every criterion")
+	}
+
+	inputs := make(map[uint]CriterionScoreInput, len(criteria))
+	for _, input := range criteria {
+		if _, ok := inputs[input.CriterionID]; ok {
+			return nil, fmt.Errorf("criterion %d is scored more than once", input.CriterionID)
+		}
+		inputs[input.CriterionID] = input
+	}
+
+	scores := make([]models.AssessmentCriterionScore, 0, len(rubric.Criteria))
+	for _, criterion := range rubric.Criteria {
+		input, ok := inputs[criterion.ID]
+		if !ok {
+			return nil, fmt.Errorf("criterion %q is not scored", criterion.Title)
+		}
+		delete(inputs, criterion.ID)
+
+		maxPoints := criterion.MaxPoints()
+		criterionScore := models.AssessmentCriterionScore{
+			CriterionID: criterion.ID,
+			MaxPoints:   maxPoints,
+			Comment:     input.Comment,
+		}
+
+		if input.LevelID != nil {
+			level := findRubricLevel(criterion, *input.LevelID)
+			if level == nil {
+				return nil, fmt.Errorf("level %d does not belong to criterion %q", *input.LevelID, criterion.Title)
+			}
+			criterionScore.LevelID = &level.ID
+			criterionScore.Points = level.Points
+		}
+
+		if input.Points != nil {
+			criterionScore.Points = *input.Points
+		} else if input.LevelID == nil {
+			return nil, fmt.Errorf("criterion %q requires a level or points", criterion.Title)
+		}
+
+		if criterionScore.Points < 0 || criterionScore.Points > maxPoints {
+			return nil, fmt.Errorf("points for criterion %q must be between 0 and %d", criterion.Title, maxPoints)
+		}
+
+		scores = append(scores, criterionScore)
+	}
+
+	if len(inputs) > 0 {
+		return nil, errors.New("criteria do not belong to the assignment rubric")
+	}
+
+	// Skor yang dikirim bersama rubrik harus sama dengan total kriteria
+	if score != nil && *score != totalCriterionPoints(scores) {
+		return nil, errors.New("score must equal the rubric total")
+	}
+
+	return scores, nil
+}
+
+// findRubricLevel mencari tingkat capaian pada kriteria
+func findRubricLevel(criterion models.RubricCriterion, levelID uint) *models.RubricLevel {
+	for i := range criterion.Levels {
+		if criterion.Levels[i].ID == levelID {
+			return &criterion.Levels[i]
+		}
+	}
+	return nil
+}
+
+// totalCriterionPoints menjumlahkan poin semua kriteria
+func totalCriterionPoints(scores []models.AssessmentCriterionScore) int {
+	total := 0
+	for _, score := range scores {
+		total += score.Points
+	}
+	return total
+}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"LMS/events"
 	"LMS/models"
 	"LMS/repositories"
+	"LMS/utils"
 	"errors"
 	"time"
 
@@ -10,15 +12,30 @@ import (
 
 // AssignmentService menangani logika bisnis penugasan
 type AssignmentService struct {
-	AssignmentRepo *repositories.AssignmentRepository
-	CourseRepo     *repositories.CourseRepository
+	AssignmentRepo      *repositories.AssignmentRepository
+	CourseRepo          *repositories.CourseRepository
+	RubricRepo          *repositories.RubricRepository
+	ExtensionService    *ExtensionService
+	AvailabilityService *AvailabilityService
+	Events              *events.Bus
 }
 
 // NewAssignmentService membuat layanan penugasan baru
-func NewAssignmentService(assignmentRepo *repositories.AssignmentRepository, courseRepo *repositories.CourseRepository) *AssignmentService {
+func NewAssignmentService(
+	assignmentRepo *repositories.AssignmentRepository,
+	courseRepo *repositories.CourseRepository,
+	rubricRepo *repositories.RubricRepository,
+	extensionService *ExtensionService,
+	availabilityService *AvailabilityService,
+	bus *events.Bus,
+) *AssignmentService {
 	return &AssignmentService{
-		AssignmentRepo: assignmentRepo,
-		CourseRepo:     courseRepo,
+		AssignmentRepo:      assignmentRepo,
+		CourseRepo:          courseRepo,
+		RubricRepo:          rubricRepo,
+		ExtensionService:    extensionService,
+		AvailabilityService: availabilityService,
+		Events:              bus,
 	}
 }
 
@@ -30,16 +47,38 @@ func (s *AssignmentService) CreateAssignment(assignment *models.Assignment) erro
 		return errors.New("course not found")
 	}
 
+	if err := validateAssignmentSettings(assignment); err != nil {
+		return err
+	}
+
+	if err := s.validateRubric(assignment, assignment.CourseID); err != nil {
+		return err
+	}
+
 	// Tetapkan tanggal pembuatan
 	assignment.CreatedAt = time.Now()
 
 	// Buat tugas
-	return s.AssignmentRepo.Create(assignment)
+	if err := s.AssignmentRepo.Create(assignment); err != nil {
+		return err
+	}
+
+	s.Events.Publish(events.Event{
+		Type:       events.AssignmentCreated,
+		CourseID:   assignment.CourseID,
+		ResourceID: assignment.ID,
+	})
+	return nil
 }
 
-// GetAssignmentByID mendapatkan penugasan dengan ID
+// GetAssignmentByID mendapatkan penugasan dengan ID beserta rubriknya
 func (s *AssignmentService) GetAssignmentByID(id uint) (*models.Assignment, error) {
-	return s.AssignmentRepo.FindByID(id)
+	assignment, err := s.AssignmentRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.loadRubric(assignment)
+	return assignment, nil
 }
 
 // GetAssignmentsByCourse mendapatkan tugas berdasarkan ID kursus
@@ -47,6 +86,38 @@ func (s *AssignmentService) GetAssignmentsByCourse(courseID uint) ([]models.Assi
 	return s.AssignmentRepo.FindByCourse(courseID)
 }
 
+// GetAssignmentForStudent mendapatkan penugasan beserta tenggat efektif siswa.
+// Penugasan yang masih terkunci menghasilkan ContentLockedError.
+func (s *AssignmentService) GetAssignmentForStudent(id, studentID uint) (*models.Assignment, error) {
+	assignment, err := s.AssignmentRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.AvailabilityService.CheckAccess(assignment.CourseID, studentID, models.ProgressTypeAssignment, assignment.ID, assignment.Availability); err != nil {
+		return nil, err
+	}
+	s.ExtensionService.ApplyToAssignment(assignment, studentID)
+	s.loadRubric(assignment)
+	return assignment, nil
+}
+
+// GetAssignmentsByCourseForStudent mendapatkan tugas kursus beserta tenggat efektif dan kunci masing-masing
+func (s *AssignmentService) GetAssignmentsByCourseForStudent(courseID, studentID uint) ([]models.Assignment, error) {
+	assignments, err := s.AssignmentRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := s.AvailabilityService.ForStudent(courseID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range assignments {
+		s.ExtensionService.ApplyToAssignment(&assignments[i], studentID)
+		availability.LockAssignment(&assignments[i])
+	}
+	return assignments, nil
+}
+
 // UpdateAssignment memperbarui penugasan
 func (s *AssignmentService) UpdateAssignment(assignment *models.Assignment) error {
 	// Verifikasi bahwa penugasan itu ada
@@ -55,11 +126,28 @@ func (s *AssignmentService) UpdateAssignment(assignment *models.Assignment) erro
 		return err
 	}
 
+	if err := validateAssignmentSettings(assignment); err != nil {
+		return err
+	}
+
+	if err := s.validateRubric(assignment, existingAssignment.CourseID); err != nil {
+		return err
+	}
+
 	// Perbarui hanya bidang yang diizinkan
 	existingAssignment.Title = assignment.Title
 	existingAssignment.Description = assignment.Description
 	existingAssignment.DueDate = assignment.DueDate
 	existingAssignment.MaxScore = assignment.MaxScore
+	existingAssignment.MaxAttempts = assignment.MaxAttempts
+	existingAssignment.AllowLateSubmissions = assignment.AllowLateSubmissions
+	existingAssignment.LateGracePeriodMinutes = assignment.LateGracePeriodMinutes
+	existingAssignment.LateCutoffDate = assignment.LateCutoffDate
+	existingAssignment.LatePenaltyPerDay = assignment.LatePenaltyPerDay
+	existingAssignment.RubricID = assignment.RubricID
+	existingAssignment.AllowedFileTypes = assignment.AllowedFileTypes
+	existingAssignment.MaxFileSizeMB = assignment.MaxFileSizeMB
+	existingAssignment.Availability = assignment.Availability
 
 	return s.AssignmentRepo.Update(existingAssignment)
 }
@@ -68,3 +156,73 @@ func (s *AssignmentService) UpdateAssignment(assignment *models.Assignment) erro
 func (s *AssignmentService) DeleteAssignment(id uint) error {
 	return s.AssignmentRepo.Delete(id)
 }
+
+// validateRubric memastikan rubrik tugas berasal dari kursus yang sama dan totalnya sesuai skor maksimal.
+// Jika skor maksimal belum ditetapkan, total poin rubrik dipakai sebagai skor maksimal.
+func (s *AssignmentService) validateRubric(assignment *models.Assignment, courseID uint) error {
+	if assignment.RubricID == nil {
+		return nil
+	}
+
+	rubric, err := s.RubricRepo.FindByID(*assignment.RubricID)
+	if err != nil {
+		return err
+	}
+	if rubric.CourseID != courseID {
+		return errors.New("rubric belongs to another course")
+	}
+
+	if assignment.MaxScore == nil {
+		total := rubric.TotalPoints
+		assignment.MaxScore = &total
+		return nil
+	}
+	if *assignment.MaxScore != rubric.TotalPoints {
+		return errors.New("rubric total points must equal the assignment max score")
+	}
+	return nil
+}
+
+// loadRubric memuat rubrik tugas untuk ditampilkan
+func (s *AssignmentService) loadRubric(assignment *models.Assignment) {
+	if assignment.RubricID == nil {
+		return
+	}
+	if rubric, err := s.RubricRepo.FindByID(*assignment.RubricID); err == nil {
+		assignment.Rubric = rubric
+	}
+}
+
+// validateAssignmentSettings memvalidasi pengaturan tugas
+func validateAssignmentSettings(assignment *models.Assignment) error {
+	if assignment.MaxAttempts != nil && *assignment.MaxAttempts < 1 {
+		return errors.New("max attempts must be at least 1")
+	}
+	if assignment.LateGracePeriodMinutes < 0 {
+		return errors.New("late grace period cannot be negative")
+	}
+	if assignment.LatePenaltyPerDay < 0 || assignment.LatePenaltyPerDay > 100 {
+		return errors.New("late penalty per day must be between 0 and 100")
+	}
+	if assignment.LateCutoffDate != nil {
+		if assignment.DueDate == nil {
+			return errors.New("late cutoff date requires a due date")
+		}
+		if !assignment.LateCutoffDate.After(*assignment.DueDate) {
+			return errors.New("late cutoff date must be after the due date")
+		}
+	}
+	if assignment.MaxFileSizeMB != nil && *assignment.MaxFileSizeMB < 1 {
+		return errors.New("max file size must be at least 1 MB")
+	}
+	if err := validateAvailability(assignment.Availability); err != nil {
+		return err
+	}
+
+	allowedTypes, err := utils.ParseFileTypes(assignment.AllowedFileTypes)
+	if err != nil {
+		return err
+	}
+	assignment.AllowedFileTypes = allowedTypes
+	return nil
+}
@@ -5,18 +5,28 @@ import (
 	"LMS/repositories"
 	"LMS/utils"
 	"errors"
+	"time"
 
 )
 
+// TokenPair berisi token akses dan refresh token yang diterbitkan bersama
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
 // AuthService menangani logika bisnis otentikasi
 type AuthService struct {
-	UserRepo *repositories.UserRepository
+	UserRepo  *repositories.UserRepository
+	TokenRepo *repositories.TokenRepository
 }
 
 // NewAuthService membuat layanan otentikasi baru
-func NewAuthService(userRepo *repositories.UserRepository) *AuthService {
+func NewAuthService(userRepo *repositories.UserRepository, tokenRepo *repositories.TokenRepository) *AuthService {
 	return &AuthService{
-		UserRepo: userRepo,
+		UserRepo:  userRepo,
+		TokenRepo: tokenRepo,
 	}
 }
 
@@ -31,28 +41,196 @@ func (s *AuthService) Register(user *models.User) error {
 	return s.UserRepo.Create(user)
 }
 
-// Login memasukkan pengguna dan mengembalikan token JWT
-func (s *AuthService) Login(email, password string) (string, error) {
+// Login memasukkan pengguna dan mengembalikan token akses beserta refresh token
+func (s *AuthService) Login(email, password string) (*TokenPair, error) {
 	// Temukan pengguna melalui email
 	user, err := s.UserRepo.FindByEmail(email)
 	if err != nil {
-		return "", errors.New("invalid email or password")
+		return nil, errors.New("invalid email or password")
 	}
 
 	// Periksa kata sandi
 	if err := user.CheckPassword(password); err != nil {
-		return "", errors.New("invalid email or password")
+		return nil, errors.New("invalid email or password")
+	}
+
+	// Setiap login memulai keluarga refresh token baru
+	familyID, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	pair, _, err := s.issueTokens(user, familyID)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return pair, nil
+}
+
+// Refresh merotasi refresh token dan menerbitkan token akses baru
+func (s *AuthService) Refresh(refreshToken string) (*TokenPair, error) {
+	stored, err := s.TokenRepo.FindRefreshTokenByHash(utils.HashToken(refreshToken))
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
 	}
-	// Menghasilkan token JWT
-	token, err := utils.GenerateToken(user)
+
+	// Token yang sudah dirotasi dipakai lagi: anggap dicuri dan matikan seluruh keluarganya
+	if stored.RevokedAt != nil {
+		if err := s.TokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token reuse detected")
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, errors.New("refresh token expired")
+	}
+
+	user, err := s.UserRepo.FindByID(stored.UserID)
 	if err != nil {
-		return "", errors.New("failed to generate token")
+		return nil, errors.New("invalid refresh token")
+	}
+
+	// Pencabutan bersyarat mencegah dua permintaan paralel merotasi token yang sama
+	revoked, err := s.TokenRepo.RevokeRefreshToken(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		if err := s.TokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token reuse detected")
+	}
+
+	pair, replacement, err := s.issueTokens(user, stored.FamilyID)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	if err := s.TokenRepo.SetReplacedBy(stored.ID, replacement.ID); err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// Logout mencabut token akses saat ini dan refresh token yang diberikan, atau semua sesi pengguna
+func (s *AuthService) Logout(claims *utils.JWTClaims, refreshToken string, allDevices bool) error {
+	if allDevices {
+		return s.RevokeAllUserTokens(claims.UserID)
+	}
+
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.TokenRepo.DenyAccessToken(claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken != "" {
+		stored, err := s.TokenRepo.FindRefreshTokenByHash(utils.HashToken(refreshToken))
+		if err != nil {
+			return errors.New("invalid refresh token")
+		}
+		if stored.UserID != claims.UserID {
+			return errors.New("refresh token does not belong to this user")
+		}
+		if err := s.TokenRepo.RevokeFamily(stored.FamilyID); err != nil {
+			return err
+		}
 	}
 
-	return token, nil
+	// Bersihkan entri kedaluwarsa agar daftar penolakan tetap kecil
+	return s.TokenRepo.PurgeExpired()
+}
+
+// RevokeAllUserTokens membatalkan semua token akses dan refresh token milik pengguna
+func (s *AuthService) RevokeAllUserTokens(userID uint) error {
+	if _, err := s.UserRepo.FindByID(userID); err != nil {
+		return err
+	}
+
+	if err := s.UserRepo.IncrementTokenVersion(userID); err != nil {
+		return err
+	}
+
+	return s.TokenRepo.RevokeAllForUser(userID)
+}
+
+// ValidateAccessToken memvalidasi token akses dan memastikan token belum dicabut
+func (s *AuthService) ValidateAccessToken(tokenString string) (*utils.JWTClaims, error) {
+	claims, err := utils.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.CheckAccessClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// CheckAccessClaims memeriksa ulang klaim token akses yang sudah diverifikasi, untuk koneksi yang
+// berumur panjang: token belum kedaluwarsa, belum masuk daftar tolak, dan versi tokennya masih berlaku
+func (s *AuthService) CheckAccessClaims(claims *utils.JWTClaims) error {
+	if claims.ExpiresAt != nil && !time.Now().Before(claims.ExpiresAt.Time) {
+		return errors.New("token has expired")
+	}
+
+	if claims.ID != "" {
+		denied, err := s.TokenRepo.IsAccessTokenDenied(claims.ID)
+		if err != nil {
+			return err
+		}
+		if denied {
+			return errors.New("token has been revoked")
+		}
+	}
+
+	// Pengguna yang dihapus atau versi tokennya dinaikkan tidak lagi diterima
+	user, err := s.UserRepo.FindByID(claims.UserID)
+	if err != nil {
+		return errors.New("token has been revoked")
+	}
+	if user.TokenVersion != claims.TokenVersion {
+		return errors.New("token has been revoked")
+	}
+
+	return nil
 }
 
 // GetUserByID mendapatkan pengguna dengan ID
 func (s *AuthService) GetUserByID(id uint) (*models.User, error) {
 	return s.UserRepo.FindByID(id)
 }
+
+// issueTokens menerbitkan token akses dan refresh token baru dalam keluarga rotasi tertentu
+func (s *AuthService) issueTokens(user *models.User, familyID string) (*TokenPair, *models.RefreshToken, error) {
+	accessToken, err := utils.GenerateToken(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stored := &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL()),
+	}
+	if err := s.TokenRepo.CreateRefreshToken(stored); err != nil {
+		return nil, nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL().Seconds()),
+	}, stored, nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"fmt"
+	"time"
+
+)
+
+// ContentLockedError dikembalikan ketika siswa mengakses konten yang belum atau tidak lagi terbuka
+type ContentLockedError struct {
+	Lock *models.AvailabilityLock
+}
+
+// Error mengembalikan alasan konten terkunci
+func (e *ContentLockedError) Error() string {
+	return "content is locked: " + e.Lock.Message
+}
+
+// AvailabilityService menerapkan aturan ketersediaan konten kursus bagi siswa
+type AvailabilityService struct {
+	ModuleRepo     *repositories.ModuleRepository
+	EnrollmentRepo *repositories.EnrollmentRepository
+	ProgressRepo   *repositories.LearningProgressRepository
+}
+
+// NewAvailabilityService membuat layanan ketersediaan konten baru
+func NewAvailabilityService(
+	moduleRepo *repositories.ModuleRepository,
+	enrollmentRepo *repositories.EnrollmentRepository,
+	progressRepo *repositories.LearningProgressRepository,
+) *AvailabilityService {
+	return &AvailabilityService{
+		ModuleRepo:     moduleRepo,
+		EnrollmentRepo: enrollmentRepo,
+		ProgressRepo:   progressRepo,
+	}
+}
+
+// StudentAvailability adalah keadaan seorang siswa di satu kursus yang dipakai untuk menghitung kunci konten
+type StudentAvailability struct {
+	now         time.Time
+	enrolledAt  *time.Time
+	modules     map[uint]*models.Module
+	itemModules map[outlineKey]*models.Module
+	completed   map[outlineKey]bool
+}
+
+// ForStudent memuat pendaftaran, modul dan aktivitas yang sudah diselesaikan siswa di sebuah kursus
+func (s *AvailabilityService) ForStudent(courseID, studentID uint) (*StudentAvailability, error) {
+	availability := &StudentAvailability{
+		now:         time.Now(),
+		modules:     make(map[uint]*models.Module),
+		itemModules: make(map[outlineKey]*models.Module),
+		completed:   make(map[outlineKey]bool),
+	}
+
+	if enrollment, err := s.EnrollmentRepo.FindByUserAndCourse(studentID, courseID); err == nil {
+		availability.enrolledAt = &enrollment.EnrollmentDate
+	}
+
+	modules, err := s.ModuleRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range modules {
+		module := &modules[i]
+		availability.modules[module.ID] = module
+		for _, item := range module.Items {
+			availability.itemModules[outlineKey{item.ItemType, item.ItemID}] = module
+		}
+	}
+
+	completed, err := s.ProgressRepo.FindCompleted(studentID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, progress := range completed {
+		availability.completed[outlineKey{progress.ActivityType, progress.ActivityID}] = true
+	}
+
+	return availability, nil
+}
+
+// CheckAccess mengembalikan ContentLockedError jika aktivitas masih terkunci bagi siswa
+func (s *AvailabilityService) CheckAccess(courseID, studentID uint, itemType models.ProgressType, itemID uint, rules models.Availability) error {
+	availability, err := s.ForStudent(courseID, studentID)
+	if err != nil {
+		return err
+	}
+	if lock := availability.ItemLock(itemType, itemID, rules); lock != nil {
+		return &ContentLockedError{Lock: lock}
+	}
+	return nil
+}
+
+// ItemLock menghitung kunci aktivitas dari aturannya sendiri lalu dari modul yang memuatnya
+func (a *StudentAvailability) ItemLock(itemType models.ProgressType, itemID uint, rules models.Availability) *models.AvailabilityLock {
+	if lock := rules.LockAt(a.now, a.enrolledAt); lock != nil {
+		return lock
+	}
+	if module, ok := a.itemModules[outlineKey{itemType, itemID}]; ok {
+		return a.ModuleLock(module)
+	}
+	return nil
+}
+
+// ModuleLock menghitung kunci modul dari aturan waktunya dan syarat modul prasyaratnya
+func (a *StudentAvailability) ModuleLock(module *models.Module) *models.AvailabilityLock {
+	if lock := module.Availability.LockAt(a.now, a.enrolledAt); lock != nil {
+		lock.ModuleID = &module.ID
+		return lock
+	}
+
+	if module.PrerequisiteModuleID == nil {
+		return nil
+	}
+	prerequisite, ok := a.modules[*module.PrerequisiteModuleID]
+	if !ok {
+		return nil
+	}
+	percent := a.modulePercent(prerequisite)
+	if percent >= module.PrerequisiteMinPercent {
+		return nil
+	}
+	return &models.AvailabilityLock{
+		Reason:               models.LockReasonPrerequisite,
+		Message:              fmt.Sprintf("complete module %q with at least %.0f%%", prerequisite.Title, module.PrerequisiteMinPercent),
+		ModuleID:             &module.ID,
+		PrerequisiteModuleID: &prerequisite.ID,
+		RequiredPercent:      module.PrerequisiteMinPercent,
+		CurrentPercent:       percent,
+	}
+}
+
+// LockMaterial mengisi kunci materi dan menyembunyikan isinya jika terkunci
+func (a *StudentAvailability) LockMaterial(material *models.Material) {
+	material.Lock = a.ItemLock(models.ProgressTypeMaterial, material.ID, material.Availability)
+	if material.Lock != nil {
+		material.HideContent()
+	}
+}
+
+// LockAssignment mengisi kunci tugas dan menyembunyikan isinya jika terkunci
+func (a *StudentAvailability) LockAssignment(assignment *models.Assignment) {
+	assignment.Lock = a.ItemLock(models.ProgressTypeAssignment, assignment.ID, assignment.Availability)
+	if assignment.Lock != nil {
+		assignment.HideContent()
+	}
+}
+
+// LockQuiz mengisi kunci kuis dan menyembunyikan isinya jika terkunci
+func (a *StudentAvailability) LockQuiz(quiz *models.Quiz) {
+	quiz.Lock = a.ItemLock(models.ProgressTypeQuiz, quiz.ID, quiz.Availability)
+	if quiz.Lock != nil {
+		quiz.HideContent()
+	}
+}
+
+// modulePercent menghitung persentase aktivitas modul yang sudah diselesaikan siswa
+func (a *StudentAvailability) modulePercent(module *models.Module) float64 {
+	if len(module.Items) == 0 {
+		return 100
+	}
+	completed := 0
+	for _, item := range module.Items {
+		if a.completed[outlineKey{item.ItemType, item.ItemID}] {
+			completed++
+		}
+	}
+	return float64(completed) * 100 / float64(len(module.Items))
+}
+
+// validateAvailability memeriksa aturan ketersediaan yang dikirim mentor
+func validateAvailability(rules models.Availability) error {
+	if rules.PublishAt != nil && rules.UnpublishAt != nil && !rules.UnpublishAt.After(*rules.PublishAt) {
+		return errors.New("unpublish_at must be after publish_at")
+	}
+	if rules.ReleaseAfterDays != nil && *rules.ReleaseAfterDays < 0 {
+		return errors.New("release_after_days cannot be negative")
+	}
+	return nil
+}
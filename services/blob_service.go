@@ -0,0 +1,489 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"LMS/storage"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+)
+
+// ErrChecksumMismatch dikembalikan saat isi berkas yang dibaca tidak cocok dengan hash-nya
+var ErrChecksumMismatch = errors.New("file checksum mismatch")
+
+// blobGracePeriod melindungi unggahan yang sedang berjalan dari pembersihan: objek dan blob
+// yang lebih baru dari ini belum dianggap yatim
+const blobGracePeriod = time.Hour
+
+// BlobService menyimpan berkas berdasarkan hash SHA-256 isinya sehingga berkas identik hanya disimpan sekali
+type BlobService struct {
+	BlobRepo *repositories.BlobRepository
+	Storage  storage.Backend
+}
+
+// NewBlobService membuat layanan blob baru
+func NewBlobService(blobRepo *repositories.BlobRepository, store storage.Backend) *BlobService {
+	return &BlobService{
+		BlobRepo: blobRepo,
+		Storage:  store,
+	}
+}
+
+// Store menyimpan isi reader sebagai blob dan menambah jumlah referensinya
+func (s *BlobService) Store(reader io.ReadSeeker, contentType string) (*models.Blob, error) {
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	blob := &models.Blob{
+		Hash:        hex.EncodeToString(hasher.Sum(nil)),
+		Size:        size,
+		ContentType: contentType,
+	}
+
+	err = s.acquire(blob, func() error {
+		if _, err := reader.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return s.Storage.Put(models.BlobKey(blob.Hash), reader, size, contentType)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// maxAcquireAttempts membatasi unggahan ulang ketika isi blob terus dihapus oleh Release yang berjalan bersamaan
+const maxAcquireAttempts = 3
+
+// acquire mengunggah isi blob lewat put bila belum tersimpan, lalu menambah jumlah referensinya.
+// Pengunggahan dilakukan di luar kunci baris blob; di dalam kunci hanya diperiksa bahwa objeknya masih ada.
+// Jika objek terhapus di antara keduanya oleh Release referensi terakhir, isi diunggah ulang.
+func (s *BlobService) acquire(blob *models.Blob, put func() error) error {
+	key := models.BlobKey(blob.Hash)
+
+	// Blob yang sudah direferensikan dan objeknya ada tidak perlu diunggah ulang
+	uploaded := false
+	if !s.blobStored(blob.Hash) {
+		if err := put(); err != nil {
+			return err
+		}
+		uploaded = true
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := s.BlobRepo.Acquire(blob, func(existing *models.Blob) error {
+			_, err := s.Storage.Stat(key)
+			return err
+		})
+		if err == nil {
+			return nil
+		}
+
+		if !errors.Is(err, storage.ErrNotFound) || attempt == maxAcquireAttempts {
+			// Objek yang baru diunggah tidak dibiarkan yatim jika tidak ada yang mereferensikannya
+			if uploaded {
+				if discardErr := s.BlobRepo.Discard(blob.Hash, func() error {
+					return s.Storage.Delete(key)
+				}); discardErr != nil {
+					log.Printf("failed to discard blob %s: %v", blob.Hash, discardErr)
+				}
+			}
+			return err
+		}
+
+		if err := put(); err != nil {
+			return err
+		}
+		uploaded = true
+	}
+}
+
+// blobStored memeriksa tanpa mengunci apakah blob sudah direferensikan dan objeknya ada
+func (s *BlobService) blobStored(hash string) bool {
+	existing, err := s.BlobRepo.FindByHash(hash)
+	if err != nil || existing.RefCount == 0 {
+		return false
+	}
+	_, err = s.Storage.Stat(models.BlobKey(hash))
+	return err == nil
+}
+
+// Release melepas satu referensi berkas; berkas lama tanpa hash langsung dihapus
+func (s *BlobService) Release(fileHash, filePath string) {
+	var err error
+	if fileHash == "" {
+		err = s.Storage.Delete(filePath)
+	} else {
+		err = s.BlobRepo.Release(fileHash, func(blob *models.Blob) error {
+			return s.Storage.Delete(models.BlobKey(blob.Hash))
+		})
+	}
+
+	// Kegagalan hanya dicatat karena data sudah konsisten; sisa berkas ditemukan oleh pemeriksaan blob
+	if err != nil {
+		log.Printf("failed to release file %s: %v", filePath, err)
+	}
+}
+
+// Open membuka berkas untuk dibaca; isi blob diverifikasi terhadap hash-nya saat dibaca
+func (s *BlobService) Open(fileHash, filePath string) (io.ReadCloser, *storage.Object, error) {
+	if fileHash == "" {
+		return s.Storage.Get(filePath)
+	}
+
+	blob, err := s.BlobRepo.FindByHash(fileHash)
+	if err != nil {
+		return nil, nil, storage.ErrNotFound
+	}
+
+	reader, object, err := s.Storage.Get(models.BlobKey(blob.Hash))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object.Size = blob.Size
+	object.ContentType = blob.ContentType
+	object.ETag = blob.Hash
+	return &verifyingReader{reader: reader, hasher: sha256.New(), expected: blob.Hash}, object, nil
+}
+
+// OpenSeekable membuka berkas untuk dibaca dari posisi mana pun, misalnya untuk permintaan Range.
+// Hanya pembacaan seluruh isi yang diverifikasi terhadap hash karena potongan tidak dapat diperiksa sendiri.
+func (s *BlobService) OpenSeekable(fileHash, filePath string) (*storage.ReadSeeker, *storage.Object, error) {
+	key := filePath
+	var blob *models.Blob
+	if fileHash != "" {
+		found, err := s.BlobRepo.FindByHash(fileHash)
+		if err != nil {
+			return nil, nil, storage.ErrNotFound
+		}
+		blob = found
+		key = models.BlobKey(blob.Hash)
+	}
+
+	object, err := s.Storage.Stat(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blob != nil {
+		object.Size = blob.Size
+		object.ContentType = blob.ContentType
+		object.ETag = blob.Hash
+	}
+
+	open := func(offset, length int64) (io.ReadCloser, error) {
+		if blob != nil && offset == 0 && length == blob.Size {
+			reader, _, err := s.Storage.Get(key)
+			if err != nil {
+				return nil, err
+			}
+			return &verifyingReader{reader: reader, hasher: sha256.New(), expected: blob.Hash}, nil
+		}
+		return s.Storage.GetRange(key, offset, length)
+	}
+	return storage.NewReadSeeker(object.Size, open), object, nil
+}
+
+// PresignedURL membuat URL unduhan sementara untuk berkas
+func (s *BlobService) PresignedURL(fileHash, filePath string) (string, error) {
+	return s.PresignedURLWithParams(fileHash, filePath, nil)
+}
+
+// PresignedURLWithParams membuat URL unduhan sementara yang membawa parameter bertanda tangan.
+// Parameter hanya dipakai penyimpanan lokal, yang URL-nya dilayani aplikasi sendiri; penyimpanan lain mengabaikannya.
+func (s *BlobService) PresignedURLWithParams(fileHash, filePath string, params url.Values) (string, error) {
+	key := filePath
+	if fileHash != "" {
+		key = models.BlobKey(fileHash)
+	}
+	if local, ok := s.Storage.(*storage.LocalBackend); ok && len(params) > 0 {
+		return local.PresignedURLWithParams(key, DownloadURLExpiry, params)
+	}
+	return s.Storage.PresignedURL(key, DownloadURLExpiry)
+}
+
+// ServesSignedURLs memeriksa apakah URL sementara dilayani aplikasi sendiri (penyimpanan lokal);
+// URL S3 diunduh langsung dari bucket tanpa melewati aplikasi
+func (s *BlobService) ServesSignedURLs() bool {
+	_, ok := s.Storage.(*storage.LocalBackend)
+	return ok
+}
+
+// verifyingReader menghitung hash saat isi dibaca dan mengembalikan ErrChecksumMismatch di akhir jika tidak cocok.
+// Byte terakhir yang dibaca selalu ditahan sampai hash terverifikasi, sehingga isi yang rusak
+// sampai ke penerima dalam keadaan terpotong dan tidak tampak lengkap.
+type verifyingReader struct {
+	reader   io.ReadCloser
+	hasher   hash.Hash
+	expected string
+	held     []byte
+	err      error
+}
+
+// Read membaca isi dan memverifikasi hash setelah EOF
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	offset := copy(p, r.held)
+	r.held = r.held[:0]
+	n, err := r.reader.Read(p[offset:])
+	r.hasher.Write(p[offset : offset+n])
+	total := offset + n
+
+	if err == io.EOF {
+		if hex.EncodeToString(r.hasher.Sum(nil)) != r.expected {
+			r.err = ErrChecksumMismatch
+			if total > 0 {
+				total--
+			}
+			return total, nil
+		}
+		r.err = io.EOF
+		return total, io.EOF
+	}
+	if err != nil {
+		r.err = err
+		return total, err
+	}
+
+	if total > 0 {
+		r.held = append(r.held, p[total-1])
+		total--
+	}
+	return total, nil
+}
+
+// Close menutup reader asli
+func (r *verifyingReader) Close() error {
+	return r.reader.Close()
+}
+
+// BlobReport adalah hasil pemeriksaan konsistensi penyimpanan blob
+type BlobReport struct {
+	Checked            int                `json:"checked"`
+	OrphanedObjects    []string           `json:"orphaned_objects"`
+	UnreferencedBlobs  []string           `json:"unreferenced_blobs"`
+	MissingBlobs       []string           `json:"missing_blobs"`
+	CorruptBlobs       []string           `json:"corrupt_blobs"`
+	DanglingReferences []string           `json:"dangling_references"`
+	RefCountMismatches []RefCountMismatch `json:"ref_count_mismatches"`
+}
+
+// RefCountMismatch adalah blob yang jumlah referensinya tidak sesuai dengan data
+type RefCountMismatch struct {
+	BlobID   uint   `json:"blob_id"`
+	Hash     string `json:"hash"`
+	Recorded int    `json:"recorded"`
+	Actual   int    `json:"actual"`
+}
+
+// Clean mengembalikan apakah pemeriksaan tidak menemukan masalah
+func (r *BlobReport) Clean() bool {
+	return len(r.OrphanedObjects) == 0 && len(r.UnreferencedBlobs) == 0 && len(r.MissingBlobs) == 0 &&
+		len(r.CorruptBlobs) == 0 && len(r.DanglingReferences) == 0 && len(r.RefCountMismatches) == 0
+}
+
+// Check membandingkan isi penyimpanan, tabel blob dan referensi materi serta kiriman.
+// Dengan verify, isi setiap blob dibaca ulang dan hash-nya dihitung.
+func (s *BlobService) Check(verify bool) (*BlobReport, error) {
+	report := &BlobReport{}
+	cutoff := time.Now().Add(-blobGracePeriod)
+
+	blobs, err := s.BlobRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	references, err := s.BlobRepo.CountReferences()
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s.Storage.List("blobs/")
+	if err != nil {
+		return nil, err
+	}
+
+	stored := map[string]storage.Object{}
+	for _, object := range objects {
+		stored[object.Key] = object
+	}
+
+	known := map[string]bool{}
+	for _, blob := range blobs {
+		report.Checked++
+		known[blob.Hash] = true
+		key := models.BlobKey(blob.Hash)
+
+		object, exists := stored[key]
+		switch {
+		case !exists:
+			report.MissingBlobs = append(report.MissingBlobs, blob.Hash)
+		case object.Size != blob.Size:
+			report.CorruptBlobs = append(report.CorruptBlobs, blob.Hash)
+		case verify:
+			if err := s.verifyBlob(blob.Hash); errors.Is(err, ErrChecksumMismatch) {
+				report.CorruptBlobs = append(report.CorruptBlobs, blob.Hash)
+			} else if err != nil {
+				return nil, err
+			}
+		}
+
+		actual := references[blob.Hash]
+		if actual == 0 && blob.UpdatedAt.Before(cutoff) {
+			report.UnreferencedBlobs = append(report.UnreferencedBlobs, blob.Hash)
+		} else if actual > 0 && actual != blob.RefCount {
+			report.RefCountMismatches = append(report.RefCountMismatches, RefCountMismatch{
+				BlobID:   blob.ID,
+				Hash:     blob.Hash,
+				Recorded: blob.RefCount,
+				Actual:   actual,
+			})
+		}
+	}
+
+	for hash := range references {
+		if !known[hash] {
+			report.DanglingReferences = append(report.DanglingReferences, hash)
+		}
+	}
+
+	for _, object := range objects {
+		hash := object.Key[strings.LastIndex(object.Key, "/")+1:]
+		if object.Key != models.BlobKey(hash) || !known[hash] {
+			if object.LastModified.Before(cutoff) {
+				report.OrphanedObjects = append(report.OrphanedObjects, object.Key)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// Repair menghapus objek yatim dan blob tanpa referensi serta memperbaiki jumlah referensi dari hasil Check.
+// Blob yang hilang atau rusak hanya dilaporkan karena isinya harus dipulihkan dari cadangan.
+func (s *BlobService) Repair(report *BlobReport) error {
+	for _, key := range report.OrphanedObjects {
+		if err := s.Storage.Delete(key); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+
+	cutoff := time.Now().Add(-blobGracePeriod)
+	for _, hash := range report.UnreferencedBlobs {
+		_, err := s.BlobRepo.DeleteIfUnreferenced(hash, cutoff, func(blob *models.Blob) error {
+			return s.Storage.Delete(models.BlobKey(blob.Hash))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete blob %s: %w", hash, err)
+		}
+	}
+
+	for _, mismatch := range report.RefCountMismatches {
+		if err := s.BlobRepo.SetRefCount(mismatch.BlobID, mismatch.Actual); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateLegacy memindahkan berkas lama yang disimpan per unggahan ke penyimpanan blob dan mengembalikan jumlah berkas yang dipindahkan
+func (s *BlobService) MigrateLegacy() (int, error) {
+	references, err := s.BlobRepo.FindLegacyFiles()
+	if err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	for _, reference := range references {
+		blob, err := s.storeObject(reference.FilePath)
+		if err != nil {
+			log.Printf("skipping %s %d: %v", reference.Table, reference.ID, err)
+			continue
+		}
+
+		oldPath := reference.FilePath
+		reference.FilePath = models.BlobKey(blob.Hash)
+		reference.FileHash = blob.Hash
+		if err := s.BlobRepo.UpdateFileReference(reference); err != nil {
+			s.Release(blob.Hash, reference.FilePath)
+			return migrated, err
+		}
+
+		if err := s.Storage.Delete(oldPath); err != nil {
+			log.Printf("failed to delete migrated file %s: %v", oldPath, err)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// storeObject menyalin objek penyimpanan yang ada menjadi blob; isinya dibaca dua kali agar tidak perlu ditampung di memori
+func (s *BlobService) storeObject(key string) (*models.Blob, error) {
+	reader, object, err := s.Storage.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, reader)
+	reader.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	blob := &models.Blob{
+		Hash:        hex.EncodeToString(hasher.Sum(nil)),
+		Size:        size,
+		ContentType: object.ContentType,
+	}
+
+	err = s.acquire(blob, func() error {
+		reader, _, err := s.Storage.Get(key)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		return s.Storage.Put(models.BlobKey(blob.Hash), reader, size, blob.ContentType)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// verifyBlob membaca ulang isi blob dan memeriksa hash-nya
+func (s *BlobService) verifyBlob(hash string) error {
+	reader, _, err := s.Storage.Get(models.BlobKey(hash))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return err
+	}
+	if hex.EncodeToString(hasher.Sum(nil)) != hash {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"LMS/utils"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+)
+
+// Ukuran halaman sertifikat: A4 mendatar dalam poin
+const (
+	certificatePageWidth  = 842
+	certificatePageHeight = 595
+)
+
+// Placeholder yang dapat dipakai pada templat sertifikat
+var certificatePlaceholders = map[string]bool{
+	"student_name":      true,
+	"course_title":      true,
+	"mentor_name":       true,
+	"completion_date":   true,
+	"final_grade":       true,
+	"verification_code": true,
+}
+
+var certificatePlaceholderPattern = regexp.MustCompile(`\{\{\s*([a-zA-Z_]+)\s*\}\}`)
+
+// CertificateVerification adalah hasil pemeriksaan publik sebuah kode sertifikat
+type CertificateVerification struct {
+	Code             string     `json:"code"`
+	Valid            bool       `json:"valid"`
+	StudentName      string     `json:"student_name"`
+	CourseTitle      string     `json:"course_title"`
+	MentorName       string     `json:"mentor_name"`
+	CompletedAt      time.Time  `json:"completed_at"`
+	FinalGrade       string     `json:"final_grade"`
+	IssuedAt         time.Time  `json:"issued_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevocationReason string     `json:"revocation_reason,omitempty"`
+}
+
+// CertificateService menangani penerbitan, verifikasi dan pencabutan sertifikat penyelesaian kursus
+type CertificateService struct {
+	CertificateRepo   *repositories.CertificateRepository
+	EnrollmentRepo    *repositories.EnrollmentRepository
+	CourseRepo        *repositories.CourseRepository
+	UserRepo          *repositories.UserRepository
+	GradebookService  *GradebookService
+	CompletionService *CompletionService
+}
+
+// NewCertificateService membuat layanan sertifikat baru
+func NewCertificateService(
+	certificateRepo *repositories.CertificateRepository,
+	enrollmentRepo *repositories.EnrollmentRepository,
+	courseRepo *repositories.CourseRepository,
+	userRepo *repositories.UserRepository,
+	gradebookService *GradebookService,
+	completionService *CompletionService,
+) *CertificateService {
+	return &CertificateService{
+		CertificateRepo:   certificateRepo,
+		EnrollmentRepo:    enrollmentRepo,
+		CourseRepo:        courseRepo,
+		UserRepo:          userRepo,
+		GradebookService:  gradebookService,
+		CompletionService: completionService,
+	}
+}
+
+// DefaultCertificateTemplate adalah templat untuk kursus yang belum menentukan templatnya sendiri
+func DefaultCertificateTemplate(courseID uint) *models.CertificateTemplate {
+	return &models.CertificateTemplate{
+		CourseID: courseID,
+		Title:    "Certificate of Completion",
+		Body: "This certifies that\n{{student_name}}\nhas successfully completed the course\n{{course_title}}\n" +
+			"on {{completion_date}} with a final grade of {{final_grade}}.\nMentor: {{mentor_name}}",
+	}
+}
+
+// GetTemplate mendapatkan templat sertifikat kursus, atau templat bawaan jika belum ditentukan
+func (s *CertificateService) GetTemplate(courseID uint) (*models.CertificateTemplate, error) {
+	if _, err := s.CourseRepo.FindByID(courseID); err != nil {
+		return nil, errors.New("course not found")
+	}
+	return s.template(courseID)
+}
+
+// SetTemplate menyimpan templat sertifikat kursus. Sertifikat yang sudah terbit tidak berubah.
+func (s *CertificateService) SetTemplate(template *models.CertificateTemplate) error {
+	if _, err := s.CourseRepo.FindByID(template.CourseID); err != nil {
+		return errors.New("course not found")
+	}
+
+	template.Title = strings.TrimSpace(template.Title)
+	template.Body = strings.TrimSpace(template.Body)
+	if template.Title == "" || template.Body == "" {
+		return errors.New("certificate template needs a title and a body")
+	}
+	for _, text := range []string{template.Title, template.Body} {
+		for _, match := range certificatePlaceholderPattern.FindAllStringSubmatch(text, -1) {
+			if !certificatePlaceholders[strings.ToLower(match[1])] {
+				return fmt.Errorf("unknown placeholder %s", match[0])
+			}
+		}
+	}
+
+	return s.CertificateRepo.SaveTemplate(template)
+}
+
+// Issue menerbitkan sertifikat untuk siswa yang sudah menyelesaikan kursus.
+// Jika sertifikat sudah ada, sertifikat yang sama dikembalikan.
+func (s *CertificateService) Issue(studentID, courseID uint) (*models.Certificate, error) {
+	enrollment, err := s.EnrollmentRepo.FindByUserAndCourse(studentID, courseID)
+	if err != nil {
+		return nil, errors.New("student is not enrolled in this course")
+	}
+
+	if enrollment.CompletedAt == nil {
+		completion, err := s.CompletionService.GetStudentCompletion(courseID, studentID)
+		if err != nil {
+			return nil, err
+		}
+		if !completion.Completed {
+			return nil, errors.New("course has not been completed yet")
+		}
+		enrollment.CompletedAt = completion.CompletedAt
+	}
+
+	return s.issue(enrollment)
+}
+
+// issue membuat sertifikat untuk pendaftaran yang sudah selesai, atau mengembalikan sertifikat yang sudah ada
+func (s *CertificateService) issue(enrollment *models.Enrollment) (*models.Certificate, error) {
+	if existing, err := s.CertificateRepo.FindByUserAndCourse(enrollment.UserID, enrollment.CourseID); err == nil {
+		if existing.RevokedAt != nil {
+			return nil, errors.New("certificate has been revoked")
+		}
+		return existing, nil
+	}
+
+	course, err := s.CourseRepo.FindByID(enrollment.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	student, err := s.UserRepo.FindByID(enrollment.UserID)
+	if err != nil {
+		return nil, err
+	}
+	mentorName := ""
+	if mentor, err := s.UserRepo.FindByID(course.MentorID); err == nil {
+		mentorName = mentor.Name
+	}
+	grade, err := s.GradebookService.GetStudentGrade(course.ID, student.ID)
+	if err != nil {
+		return nil, err
+	}
+	template, err := s.template(course.ID)
+	if err != nil {
+		return nil, err
+	}
+	code, err := generateCertificateCode()
+	if err != nil {
+		return nil, err
+	}
+
+	certificate := &models.Certificate{
+		Code:         code,
+		UserID:       student.ID,
+		CourseID:     course.ID,
+		EnrollmentID: enrollment.ID,
+		StudentName:  student.Name,
+		CourseTitle:  course.Title,
+		MentorName:   mentorName,
+		CompletedAt:  *enrollment.CompletedAt,
+		FinalPercent: grade.FinalPercent,
+		LetterGrade:  grade.LetterGrade,
+		IssuedAt:     time.Now(),
+	}
+	certificate.Title = renderCertificateText(template.Title, certificate)
+	certificate.Body = renderCertificateText(template.Body, certificate)
+
+	if err := s.CertificateRepo.Create(certificate); err != nil {
+		return nil, err
+	}
+	return certificate, nil
+}
+
+// GetCertificateByID mendapatkan sertifikat berdasarkan ID
+func (s *CertificateService) GetCertificateByID(id uint) (*models.Certificate, error) {
+	return s.CertificateRepo.FindByID(id)
+}
+
+// GetCertificatesByUser mendapatkan semua sertifikat seorang siswa
+func (s *CertificateService) GetCertificatesByUser(userID uint) ([]models.Certificate, error) {
+	return s.CertificateRepo.FindByUser(userID)
+}
+
+// GetCertificatesByCourse mendapatkan semua sertifikat yang diterbitkan untuk sebuah kursus
+func (s *CertificateService) GetCertificatesByCourse(courseID uint) ([]models.Certificate, error) {
+	if _, err := s.CourseRepo.FindByID(courseID); err != nil {
+		return nil, errors.New("course not found")
+	}
+	return s.CertificateRepo.FindByCourse(courseID)
+}
+
+// Verify memeriksa kode sertifikat dan mengembalikan data yang boleh dilihat publik
+func (s *CertificateService) Verify(code string) (*CertificateVerification, error) {
+	certificate, err := s.CertificateRepo.FindByCode(normalizeCertificateCode(code))
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &CertificateVerification{
+		Code:        certificate.Code,
+		Valid:       certificate.RevokedAt == nil,
+		StudentName: certificate.StudentName,
+		CourseTitle: certificate.CourseTitle,
+		MentorName:  certificate.MentorName,
+		CompletedAt: certificate.CompletedAt,
+		FinalGrade:  formatFinalGrade(certificate),
+		IssuedAt:    certificate.IssuedAt,
+		RevokedAt:   certificate.RevokedAt,
+	}
+	if certificate.RevokedAt != nil {
+		verification.RevocationReason = certificate.RevocationReason
+	}
+	return verification, nil
+}
+
+// Revoke mencabut sertifikat. Kode verifikasinya tetap dapat diperiksa dan dilaporkan tidak berlaku.
+func (s *CertificateService) Revoke(id, revokedByID uint, reason string) error {
+	certificate, err := s.CertificateRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if certificate.RevokedAt != nil {
+		return errors.New("certificate has already been revoked")
+	}
+
+	return s.CertificateRepo.Revoke(id, revokedByID, strings.TrimSpace(reason))
+}
+
+// WritePDF menulis sertifikat sebagai dokumen PDF
+func (s *CertificateService) WritePDF(w io.Writer, certificate *models.Certificate) error {
+	page := utils.PDFPage{
+		Width:  certificatePageWidth,
+		Height: certificatePageHeight,
+		Border: true,
+	}
+
+	y := float64(certificatePageHeight) - 130
+	for _, line := range utils.WrapPDFText(certificate.Title, 30, true, certificatePageWidth-160) {
+		page.Lines = append(page.Lines, utils.PDFText{Text: line, Size: 30, Bold: true, Y: y})
+		y -= 38
+	}
+
+	y -= 22
+	for _, line := range utils.WrapPDFText(certificate.Body, 16, false, certificatePageWidth-160) {
+		page.Lines = append(page.Lines, utils.PDFText{Text: line, Size: 16, Y: y})
+		y -= 26
+	}
+
+	page.Lines = append(page.Lines,
+		utils.PDFText{Text: "Issued " + certificate.IssuedAt.Format("2 January 2006"), Size: 10, Y: 72},
+		utils.PDFText{Text: "Verification code: " + certificate.Code, Size: 10, Bold: true, Y: 56},
+	)
+
+	return utils.WritePDF(w, page)
+}
+
+// template mendapatkan templat tersimpan sebuah kursus, atau templat bawaan
+func (s *CertificateService) template(courseID uint) (*models.CertificateTemplate, error) {
+	template, err := s.CertificateRepo.FindTemplateByCourse(courseID)
+	if err != nil {
+		if err.Error() == "certificate template not found" {
+			return DefaultCertificateTemplate(courseID), nil
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// renderCertificateText mengganti placeholder templat dengan data sertifikat
+func renderCertificateText(text string, certificate *models.Certificate) string {
+	return certificatePlaceholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := strings.ToLower(certificatePlaceholderPattern.FindStringSubmatch(placeholder)[1])
+		switch name {
+		case "student_name":
+			return certificate.StudentName
+		case "course_title":
+			return certificate.CourseTitle
+		case "mentor_name":
+			return certificate.MentorName
+		case "completion_date":
+			return certificate.CompletedAt.Format("2 January 2006")
+		case "final_grade":
+			return formatFinalGrade(certificate)
+		case "verification_code":
+			return certificate.Code
+		}
+		return placeholder
+	})
+}
+
+// formatFinalGrade menampilkan nilai akhir sertifikat, misalnya "87.5% (B)"
+func formatFinalGrade(certificate *models.Certificate) string {
+	if certificate.FinalPercent == nil {
+		return "-"
+	}
+	grade := fmt.Sprintf("%.1f%%", *certificate.FinalPercent)
+	if certificate.LetterGrade != "" {
+		grade += " (" + certificate.LetterGrade + ")"
+	}
+	return grade
+}
+
+// generateCertificateCode membuat kode verifikasi acak, misalnya "K7QF-2MXA-9PZC-4TRB"
+func generateCertificateCode() (string, error) {
+	raw := make([]byte, 10)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	encoded := base32.StdEncoding.EncodeToString(raw)
+
+	groups := make([]string, 0, len(encoded)/4)
+	for i := 0; i < len(encoded); i += 4 {
+		groups = append(groups, encoded[i:i+4])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+// normalizeCertificateCode menyeragamkan kode yang diketik pengguna sebelum dicari
+func normalizeCertificateCode(code string) string {
+	code = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+	compact := strings.ReplaceAll(code, "-", "")
+	if len(compact) != 16 {
+		return code
+	}
+	return compact[0:4] + "-" + compact[4:8] + "-" + compact[8:12] + "-" + compact[12:16]
+}
@@ -1,13 +1,35 @@
 package services
 
 import (
+	"LMS/events"
 	"LMS/models"
 	"LMS/repositories"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 )
 
+// MaxCommentDepth adalah kedalaman balasan terdalam di bawah komentar teratas
+const MaxCommentDepth = 4
+
+// Batas jumlah komentar teratas per halaman pohon komentar
+const (
+	DefaultCommentPageSize = 20
+	MaxCommentPageSize     = 100
+)
+
+// CommentTree adalah satu halaman komentar teratas sebuah diskusi beserta seluruh balasannya
+type CommentTree struct {
+	DiscussionID      uint             `json:"discussion_id"`
+	AcceptedCommentID *uint            `json:"accepted_comment_id"`
+	Total             int64            `json:"total"`
+	Limit             int              `json:"limit"`
+	Offset            int              `json:"offset"`
+	Comments          []models.Comment `json:"comments"`
+}
+
 // CommentService menangani logika bisnis komentar
 type CommentService struct {
 	CommentRepo    *repositories.CommentRepository
@@ -15,6 +37,7 @@ type CommentService struct {
 	UserRepo       *repositories.UserRepository
 	CourseRepo     *repositories.CourseRepository
 	EnrollmentRepo *repositories.EnrollmentRepository
+	Events         *events.Bus
 }
 
 // NewCommentService membuat layanan komentar baru
@@ -24,6 +47,7 @@ func NewCommentService(
 	userRepo *repositories.UserRepository,
 	courseRepo *repositories.CourseRepository,
 	enrollmentRepo *repositories.EnrollmentRepository,
+	bus *events.Bus,
 ) *CommentService {
 	return &CommentService{
 		CommentRepo:    commentRepo,
@@ -31,6 +55,7 @@ func NewCommentService(
 		UserRepo:       userRepo,
 		CourseRepo:     courseRepo,
 		EnrollmentRepo: enrollmentRepo,
+		Events:         bus,
 	}
 }
 
@@ -56,10 +81,50 @@ func (s *CommentService) CreateComment(comment *models.Comment) error {
 		}
 	}
 
+	// Diskusi yang dikunci hanya menerima komentar dari mentor kursus dan admin
+	if discussion.Locked {
+		course, err := s.CourseRepo.FindByID(discussion.CourseID)
+		if err != nil {
+			return errors.New("course not found")
+		}
+		if user.Role != models.RoleAdmin && !(user.Role == models.RoleMentor && course.MentorID == user.ID) {
+			return errors.New("discussion is locked")
+		}
+	}
+
+	// Balasan harus berada di diskusi yang sama dan tidak melebihi kedalaman maksimum
+	comment.Depth = 0
+	if comment.ParentID != nil {
+		parent, err := s.CommentRepo.FindByID(*comment.ParentID)
+		if err != nil {
+			return errors.New("parent comment not found")
+		}
+		if parent.DiscussionID != comment.DiscussionID {
+			return errors.New("parent comment does not belong to this discussion")
+		}
+		if parent.HiddenAt != nil {
+			return errors.New("cannot reply to a hidden comment")
+		}
+		if parent.Depth+1 > MaxCommentDepth {
+			return fmt.Errorf("replies cannot be nested more than %d levels deep", MaxCommentDepth)
+		}
+		comment.Depth = parent.Depth + 1
+	}
+
 	// Tetapkan tanggal pembuatan
 	comment.CreatedAt = time.Now()
 	// Buat komentar
-	return s.CommentRepo.Create(comment)
+	if err := s.CommentRepo.Create(comment); err != nil {
+		return err
+	}
+
+	s.Events.Publish(events.Event{
+		Type:       events.CommentCreated,
+		CourseID:   discussion.CourseID,
+		ResourceID: comment.ID,
+		ActorID:    comment.UserID,
+	})
+	return nil
 }
 
 // GetCommentByID mendapatkan komentar berdasarkan ID
@@ -67,9 +132,236 @@ func (s *CommentService) GetCommentByID(id uint) (*models.Comment, error) {
 	return s.CommentRepo.FindByID(id)
 }
 
-// GetCommentsByDiscussion mendapatkan komentar berdasarkan ID diskusi
-func (s *CommentService) GetCommentsByDiscussion(discussionID uint) ([]models.Comment, error) {
-	return s.CommentRepo.FindByDiscussion(discussionID)
+// GetVisibleComment mendapatkan komentar berdasarkan ID dengan isi tersembunyi disamarkan untuk viewerID
+func (s *CommentService) GetVisibleComment(id, viewerID uint, moderator bool) (*models.Comment, error) {
+	comment, err := s.CommentRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	maskHidden(comment, viewerID, moderator)
+	return comment, nil
+}
+
+// GetCommentsByDiscussion mendapatkan komentar berdasarkan ID diskusi dengan isi tersembunyi disamarkan untuk viewerID
+func (s *CommentService) GetCommentsByDiscussion(discussionID, viewerID uint, moderator bool) ([]models.Comment, error) {
+	comments, err := s.CommentRepo.FindByDiscussion(discussionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range comments {
+		maskHidden(&comments[i], viewerID, moderator)
+	}
+	return comments, nil
+}
+
+// GetCommentTree mendapatkan satu halaman komentar teratas diskusi sebagai pohon balasan,
+// lengkap dengan jumlah reaksi, reaksi milik viewerID dan tanda jawaban diterima.
+// Isi komentar tersembunyi hanya terlihat oleh moderator dan penulisnya.
+func (s *CommentService) GetCommentTree(discussionID, viewerID uint, moderator bool, sort string, limit, offset int) (*CommentTree, error) {
+	discussion, err := s.DiscussionRepo.FindByID(discussionID)
+	if err != nil {
+		return nil, errors.New("discussion not found")
+	}
+
+	if sort == "" {
+		sort = "oldest"
+	}
+	if sort != "oldest" && sort != "newest" && sort != "top" {
+		return nil, errors.New("sort must be oldest, newest or top")
+	}
+	if limit <= 0 {
+		limit = DefaultCommentPageSize
+	}
+	if limit > MaxCommentPageSize {
+		limit = MaxCommentPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	topLevel, total, err := s.CommentRepo.FindTopLevelByDiscussion(discussionID, sort, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := s.CommentRepo.FindRepliesByDiscussion(discussionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(topLevel)+len(replies))
+	children := make(map[uint][]models.Comment)
+	for _, comment := range topLevel {
+		ids = append(ids, comment.ID)
+	}
+	for _, reply := range replies {
+		ids = append(ids, reply.ID)
+		children[*reply.ParentID] = append(children[*reply.ParentID], reply)
+	}
+
+	counts, err := s.CommentRepo.FindReactionCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+	mine, err := s.CommentRepo.FindUserReactions(viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// build menempelkan balasan dan reaksi secara rekursif
+	var build func(comment models.Comment) models.Comment
+	build = func(comment models.Comment) models.Comment {
+		comment.ReactionCounts = counts[comment.ID]
+		comment.MyReactions = mine[comment.ID]
+		comment.IsAccepted = discussion.AcceptedCommentID != nil && *discussion.AcceptedCommentID == comment.ID
+		maskHidden(&comment, viewerID, moderator)
+		for _, reply := range children[comment.ID] {
+			comment.Replies = append(comment.Replies, build(reply))
+		}
+		return comment
+	}
+
+	tree := &CommentTree{
+		DiscussionID:      discussionID,
+		AcceptedCommentID: discussion.AcceptedCommentID,
+		Total:             total,
+		Limit:             limit,
+		Offset:            offset,
+		Comments:          make([]models.Comment, 0, len(topLevel)),
+	}
+	for _, comment := range topLevel {
+		tree.Comments = append(tree.Comments, build(comment))
+	}
+
+	return tree, nil
+}
+
+// AddReaction menambahkan reaksi pengguna pada komentar dan mengembalikan jumlah reaksi terbaru
+func (s *CommentService) AddReaction(commentID, userID uint, reactionType models.ReactionType) (map[string]int, error) {
+	if !validReaction(reactionType) {
+		return nil, errors.New("invalid reaction type")
+	}
+	if _, err := s.CommentRepo.FindByID(commentID); err != nil {
+		return nil, err
+	}
+
+	reaction := &models.CommentReaction{
+		CommentID: commentID,
+		UserID:    userID,
+		Type:      reactionType,
+	}
+	if err := s.CommentRepo.AddReaction(reaction); err != nil {
+		return nil, err
+	}
+
+	return s.reactionCounts(commentID)
+}
+
+// RemoveReaction menghapus reaksi pengguna pada komentar dan mengembalikan jumlah reaksi terbaru
+func (s *CommentService) RemoveReaction(commentID, userID uint, reactionType models.ReactionType) (map[string]int, error) {
+	if !validReaction(reactionType) {
+		return nil, errors.New("invalid reaction type")
+	}
+	if _, err := s.CommentRepo.FindByID(commentID); err != nil {
+		return nil, err
+	}
+
+	if err := s.CommentRepo.RemoveReaction(commentID, userID, reactionType); err != nil {
+		return nil, err
+	}
+
+	return s.reactionCounts(commentID)
+}
+
+// AcceptAnswer menandai komentar sebagai jawaban diskusinya, menggantikan jawaban sebelumnya.
+// Komentar yang disembunyikan moderator tidak dapat diterima sebagai jawaban.
+func (s *CommentService) AcceptAnswer(commentID uint) error {
+	comment, err := s.CommentRepo.FindByID(commentID)
+	if err != nil {
+		return err
+	}
+	if comment.HiddenAt != nil {
+		return errors.New("hidden comment cannot be accepted as the answer")
+	}
+	return s.DiscussionRepo.SetAcceptedComment(comment.DiscussionID, &comment.ID)
+}
+
+// UnacceptAnswer melepas tanda jawaban dari komentar
+func (s *CommentService) UnacceptAnswer(commentID uint) error {
+	comment, err := s.CommentRepo.FindByID(commentID)
+	if err != nil {
+		return err
+	}
+
+	discussion, err := s.DiscussionRepo.FindByID(comment.DiscussionID)
+	if err != nil {
+		return err
+	}
+	if discussion.AcceptedCommentID == nil || *discussion.AcceptedCommentID != comment.ID {
+		return errors.New("comment is not the accepted answer")
+	}
+
+	return s.DiscussionRepo.SetAcceptedComment(discussion.ID, nil)
+}
+
+// HideComment menyembunyikan komentar dengan alasan moderasi. Balasannya tetap tampil,
+// tetapi jika komentar itu jawaban diterima, tandanya dilepas.
+func (s *CommentService) HideComment(id, moderatorID uint, reason string) error {
+	comment, err := s.CommentRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return errors.New("a reason is required to hide a comment")
+	}
+	if comment.HiddenAt != nil {
+		return errors.New("comment is already hidden")
+	}
+
+	return s.CommentRepo.Hide(id, moderatorID, reason)
+}
+
+// UnhideComment menampilkan kembali komentar yang disembunyikan
+func (s *CommentService) UnhideComment(id uint) error {
+	comment, err := s.CommentRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if comment.HiddenAt == nil {
+		return errors.New("comment is not hidden")
+	}
+
+	return s.CommentRepo.Unhide(id)
+}
+
+// maskHidden mengosongkan isi komentar tersembunyi bagi pengguna selain moderator dan penulisnya
+func maskHidden(comment *models.Comment, viewerID uint, moderator bool) {
+	if comment.HiddenAt != nil && !moderator && comment.UserID != viewerID {
+		comment.Content = ""
+	}
+}
+
+// reactionCounts menghitung reaksi per jenis pada satu komentar
+func (s *CommentService) reactionCounts(commentID uint) (map[string]int, error) {
+	counts, err := s.CommentRepo.FindReactionCounts([]uint{commentID})
+	if err != nil {
+		return nil, err
+	}
+	if counts[commentID] == nil {
+		return map[string]int{}, nil
+	}
+	return counts[commentID], nil
+}
+
+// validReaction memeriksa apakah jenis reaksi dikenal
+func validReaction(reactionType models.ReactionType) bool {
+	switch reactionType {
+	case models.ReactionUpvote, models.ReactionLike, models.ReactionThanks, models.ReactionInsightful, models.ReactionConfused:
+		return true
+	}
+	return false
 }
 
 // UpdateComment memperbarui komentar
@@ -90,15 +382,15 @@ func (s *CommentService) UpdateComment(comment *models.Comment) error {
 	return s.CommentRepo.Update(existingComment)
 }
 
-// DeleteComment menghapus komentar
-func (s *CommentService) DeleteComment(id uint, userID uint, isAdmin bool) error {
+// DeleteComment menghapus komentar beserta seluruh balasannya
+func (s *CommentService) DeleteComment(id uint, userID uint, isModerator bool) error {
 	// Verifikasi bahwa komentar tersebut ada
 	comment, err := s.CommentRepo.FindByID(id)
 	if err != nil {
 		return err
 	}
-	// Verifikasi pengguna yang memiliki komentar atau admin
-	if !isAdmin && comment.UserID != userID {
+	// Verifikasi pengguna yang memiliki komentar atau moderator
+	if !isModerator && comment.UserID != userID {
 		return errors.New("user does not own this comment")
 	}
 
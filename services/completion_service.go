@@ -0,0 +1,464 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+)
+
+// Jenis syarat aturan penyelesaian kursus
+const (
+	CompletionRequirementPercentComplete = "percent_complete"
+	CompletionRequirementFinalGrade      = "final_grade"
+)
+
+// ActivityCompletion adalah status penyelesaian satu aktivitas wajib
+type ActivityCompletion struct {
+	ActivityType models.ProgressType `json:"activity_type"`
+	ActivityID   uint                `json:"activity_id"`
+	Title        string              `json:"title"`
+	Completed    bool                `json:"completed"`
+}
+
+// CompletionRequirement adalah satu syarat aturan penyelesaian beserta capaian siswa
+type CompletionRequirement struct {
+	Type     string   `json:"type"`
+	Required float64  `json:"required"`
+	Current  *float64 `json:"current"`
+	Met      bool     `json:"met"`
+}
+
+// CourseCompletion adalah kemajuan seorang siswa menuju penyelesaian sebuah kursus
+type CourseCompletion struct {
+	CourseID        uint                    `json:"course_id"`
+	CourseTitle     string                  `json:"course_title"`
+	StudentID       uint                    `json:"student_id"`
+	StudentName     string                  `json:"student_name,omitempty"`
+	EnrolledAt      time.Time               `json:"enrolled_at"`
+	PercentComplete float64                 `json:"percent_complete"`
+	CompletedCount  int                     `json:"completed_count"`
+	RequiredCount   int                     `json:"required_count"`
+	FinalPercent    *float64                `json:"final_percent"`
+	LetterGrade     string                  `json:"letter_grade"`
+	Requirements    []CompletionRequirement `json:"requirements"`
+	Completed       bool                    `json:"completed"`
+	CompletedAt     *time.Time              `json:"completed_at"`
+	NextActivity    *ActivityCompletion     `json:"next_activity,omitempty"`
+	Activities      []ActivityCompletion    `json:"activities,omitempty"`
+}
+
+// completionData adalah aturan dan aktivitas wajib sebuah kursus yang dipakai untuk menghitung penyelesaian
+type completionData struct {
+	course   *models.Course
+	rule     *models.CompletionRule
+	required []ActivityCompletion
+	assessed map[uint][]uint
+}
+
+// CompletionService menangani aturan penyelesaian kursus dan menghitung kemajuan siswa terhadapnya
+type CompletionService struct {
+	CompletionRepo   *repositories.CompletionRepository
+	CourseRepo       *repositories.CourseRepository
+	EnrollmentRepo   *repositories.EnrollmentRepository
+	ProgressRepo     *repositories.LearningProgressRepository
+	ModuleService    *ModuleService
+	GradebookService *GradebookService
+
+	// Menerbitkan sertifikat saat kursus pertama kali selesai; diisi setelah layanan sertifikat dibuat
+	CertificateService *CertificateService
+}
+
+// NewCompletionService membuat layanan penyelesaian kursus baru
+func NewCompletionService(
+	completionRepo *repositories.CompletionRepository,
+	courseRepo *repositories.CourseRepository,
+	enrollmentRepo *repositories.EnrollmentRepository,
+	progressRepo *repositories.LearningProgressRepository,
+	moduleService *ModuleService,
+	gradebookService *GradebookService,
+) *CompletionService {
+	return &CompletionService{
+		CompletionRepo:   completionRepo,
+		CourseRepo:       courseRepo,
+		EnrollmentRepo:   enrollmentRepo,
+		ProgressRepo:     progressRepo,
+		ModuleService:    moduleService,
+		GradebookService: gradebookService,
+	}
+}
+
+// DefaultCompletionRule adalah aturan kursus yang belum menentukan aturannya sendiri:
+// semua materi, tugas dan kuis harus diselesaikan
+func DefaultCompletionRule(courseID uint) *models.CompletionRule {
+	return &models.CompletionRule{
+		CourseID:           courseID,
+		MinPercentComplete: 100,
+		Activities:         []models.CompletionActivity{},
+	}
+}
+
+// GetRule mendapatkan aturan penyelesaian kursus, atau aturan bawaan jika belum ditentukan
+func (s *CompletionService) GetRule(courseID uint) (*models.CompletionRule, error) {
+	if _, err := s.CourseRepo.FindByID(courseID); err != nil {
+		return nil, errors.New("course not found")
+	}
+
+	rule, err := s.rule(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Aktivitas yang sudah dihapus tidak lagi ditampilkan
+	activities, err := s.ModuleService.courseActivities(courseID)
+	if err != nil {
+		return nil, err
+	}
+	listed := make([]models.CompletionActivity, 0, len(rule.Activities))
+	for _, activity := range rule.Activities {
+		if found, ok := activities[outlineKey{activity.ActivityType, activity.ActivityID}]; ok {
+			activity.Title = found.title
+			listed = append(listed, activity)
+		}
+	}
+	rule.Activities = listed
+
+	return rule, nil
+}
+
+// SetRule menyimpan aturan penyelesaian kursus, menggantikan aturan sebelumnya
+func (s *CompletionService) SetRule(rule *models.CompletionRule) error {
+	if _, err := s.CourseRepo.FindByID(rule.CourseID); err != nil {
+		return errors.New("course not found")
+	}
+
+	if rule.MinPercentComplete < 0 || rule.MinPercentComplete > 100 {
+		return errors.New("min_percent_complete must be between 0 and 100")
+	}
+	if rule.MinFinalPercent != nil && (*rule.MinFinalPercent < 0 || *rule.MinFinalPercent > 100) {
+		return errors.New("min_final_percent must be between 0 and 100")
+	}
+	if rule.MinPercentComplete == 0 && rule.MinFinalPercent == nil {
+		return errors.New("completion rule needs a min_percent_complete or a min_final_percent")
+	}
+
+	activities, err := s.ModuleService.courseActivities(rule.CourseID)
+	if err != nil {
+		return err
+	}
+	seen := make(map[outlineKey]bool, len(rule.Activities))
+	for i, activity := range rule.Activities {
+		key := outlineKey{activity.ActivityType, activity.ActivityID}
+		found, ok := activities[key]
+		if !ok {
+			return fmt.Errorf("%s %d does not belong to this course", activity.ActivityType, activity.ActivityID)
+		}
+		if seen[key] {
+			return fmt.Errorf("%s %d is listed more than once", activity.ActivityType, activity.ActivityID)
+		}
+		seen[key] = true
+		rule.Activities[i].Title = found.title
+	}
+
+	return s.CompletionRepo.SaveRule(rule)
+}
+
+// GetStudentCompletion menghitung kemajuan seorang siswa menuju penyelesaian kursus beserta rincian aktivitasnya
+func (s *CompletionService) GetStudentCompletion(courseID, studentID uint) (*CourseCompletion, error) {
+	enrollment, err := s.EnrollmentRepo.FindByUserAndCourse(studentID, courseID)
+	if err != nil {
+		return nil, errors.New("student is not enrolled in this course")
+	}
+
+	data, err := s.load(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	grade, err := s.GradebookService.GetStudentGrade(courseID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.evaluate(data, enrollment, grade)
+}
+
+// GetCourseCompletions menghitung kemajuan penyelesaian semua siswa dalam sebuah kursus
+func (s *CompletionService) GetCourseCompletions(courseID uint) ([]CourseCompletion, error) {
+	data, err := s.load(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	gradebook, err := s.GradebookService.GetCourseGradebook(courseID)
+	if err != nil {
+		return nil, err
+	}
+	grades := make(map[uint]*StudentGrade, len(gradebook.Students))
+	for i := range gradebook.Students {
+		grades[gradebook.Students[i].StudentID] = &gradebook.Students[i]
+	}
+
+	enrollments, err := s.EnrollmentRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	completions := []CourseCompletion{}
+	for i := range enrollments {
+		if enrollments[i].User.Role != models.RoleStudent {
+			continue
+		}
+		completion, err := s.evaluate(data, &enrollments[i], grades[enrollments[i].UserID])
+		if err != nil {
+			return nil, err
+		}
+		completion.StudentName = enrollments[i].User.Name
+		completion.Activities = nil
+		completions = append(completions, *completion)
+	}
+
+	sort.Slice(completions, func(i, j int) bool {
+		return strings.ToLower(completions[i].StudentName) < strings.ToLower(completions[j].StudentName)
+	})
+
+	return completions, nil
+}
+
+// GetDashboard menghitung ringkasan kemajuan seorang siswa di semua kursus yang diikutinya
+func (s *CompletionService) GetDashboard(studentID uint) ([]CourseCompletion, error) {
+	enrollments, err := s.EnrollmentRepo.FindByUser(studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := []CourseCompletion{}
+	for i := range enrollments {
+		data, err := s.load(enrollments[i].CourseID)
+		if err != nil {
+			return nil, err
+		}
+		grade, err := s.GradebookService.GetStudentGrade(enrollments[i].CourseID, studentID)
+		if err != nil {
+			return nil, err
+		}
+
+		completion, err := s.evaluate(data, &enrollments[i], grade)
+		if err != nil {
+			return nil, err
+		}
+		completion.Activities = nil
+		dashboard = append(dashboard, *completion)
+	}
+
+	return dashboard, nil
+}
+
+// Refresh memeriksa ulang penyelesaian kursus seorang siswa setelah kemajuannya berubah
+// dan mencatat waktu penyelesaian begitu aturan terpenuhi
+func (s *CompletionService) Refresh(studentID, courseID uint) error {
+	enrollment, err := s.EnrollmentRepo.FindByUserAndCourse(studentID, courseID)
+	if err != nil || enrollment.CompletedAt != nil {
+		return nil
+	}
+
+	data, err := s.load(courseID)
+	if err != nil {
+		return err
+	}
+
+	// Nilai akhir hanya dihitung jika aturan membutuhkannya dan syarat aktivitas sudah terpenuhi
+	var grade *StudentGrade
+	if data.rule.MinFinalPercent != nil {
+		done, err := s.completedActivities(data, studentID)
+		if err != nil {
+			return err
+		}
+		if completion := data.evaluate(enrollment, done, nil); !data.activitiesMet(completion) {
+			return nil
+		}
+		if grade, err = s.GradebookService.GetStudentGrade(courseID, studentID); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.evaluate(data, enrollment, grade)
+	return err
+}
+
+// rule mendapatkan aturan tersimpan sebuah kursus, atau aturan bawaan
+func (s *CompletionService) rule(courseID uint) (*models.CompletionRule, error) {
+	rule, err := s.CompletionRepo.FindRuleByCourse(courseID)
+	if err != nil {
+		if err.Error() == "completion rule not found" {
+			return DefaultCompletionRule(courseID), nil
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// load mengumpulkan aturan kursus dan aktivitas wajibnya sesuai urutan kerangka kursus
+func (s *CompletionService) load(courseID uint) (*completionData, error) {
+	course, err := s.CourseRepo.FindByID(courseID)
+	if err != nil {
+		return nil, errors.New("course not found")
+	}
+
+	rule, err := s.rule(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	modules, ungrouped, err := s.ModuleService.buildOutline(courseID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make(map[outlineKey]bool, len(rule.Activities))
+	for _, activity := range rule.Activities {
+		listed[outlineKey{activity.ActivityType, activity.ActivityID}] = true
+	}
+
+	data := &completionData{course: course, rule: rule}
+	addRequired := func(items []models.ModuleItem) {
+		for _, item := range items {
+			// Tanpa daftar aktivitas, diskusi tidak wajib karena tidak memiliki penyelesaian otomatis
+			if len(listed) > 0 && !listed[outlineKey{item.ItemType, item.ItemID}] {
+				continue
+			}
+			if len(listed) == 0 && item.ItemType == models.ProgressTypeDiscussion {
+				continue
+			}
+			data.required = append(data.required, ActivityCompletion{
+				ActivityType: item.ItemType,
+				ActivityID:   item.ItemID,
+				Title:        item.Title,
+			})
+		}
+	}
+	for _, module := range modules {
+		addRequired(module.Items)
+	}
+	addRequired(ungrouped)
+
+	if data.assessed, err = s.CompletionRepo.FindAssessedAssignments(courseID); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// completedActivities mengumpulkan aktivitas yang sudah diselesaikan siswa: baris kemajuan yang selesai
+// dan tugas yang kirimannya sudah dinilai
+func (s *CompletionService) completedActivities(data *completionData, studentID uint) (map[outlineKey]bool, error) {
+	progress, err := s.ProgressRepo.FindCompleted(studentID, data.course.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(map[outlineKey]bool, len(progress))
+	for _, row := range progress {
+		done[outlineKey{row.ActivityType, row.ActivityID}] = true
+	}
+	for _, assignmentID := range data.assessed[studentID] {
+		done[outlineKey{models.ProgressTypeAssignment, assignmentID}] = true
+	}
+	return done, nil
+}
+
+// evaluate menghitung penyelesaian kursus seorang siswa dan mencatat waktu penyelesaiannya yang pertama
+func (s *CompletionService) evaluate(data *completionData, enrollment *models.Enrollment, grade *StudentGrade) (*CourseCompletion, error) {
+	done, err := s.completedActivities(data, enrollment.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	completion := data.evaluate(enrollment, done, grade)
+	if completion.Completed && enrollment.CompletedAt == nil {
+		now := time.Now()
+		if err := s.EnrollmentRepo.MarkCompleted(enrollment.ID, now); err != nil {
+			return nil, err
+		}
+		enrollment.CompletedAt = &now
+		completion.CompletedAt = &now
+
+		if s.CertificateService != nil {
+			if _, err := s.CertificateService.issue(enrollment); err != nil {
+				log.Printf("failed to issue certificate for enrollment %d: %v", enrollment.ID, err)
+			}
+		}
+	}
+	return &completion, nil
+}
+
+// evaluate menghitung persentase aktivitas wajib yang selesai dan memeriksa setiap syarat aturan.
+// Kursus yang pernah selesai tetap selesai meskipun aturannya berubah kemudian.
+func (d *completionData) evaluate(enrollment *models.Enrollment, done map[outlineKey]bool, grade *StudentGrade) CourseCompletion {
+	completion := CourseCompletion{
+		CourseID:      d.course.ID,
+		CourseTitle:   d.course.Title,
+		StudentID:     enrollment.UserID,
+		EnrolledAt:    enrollment.EnrollmentDate,
+		RequiredCount: len(d.required),
+		Requirements:  []CompletionRequirement{},
+		CompletedAt:   enrollment.CompletedAt,
+		Activities:    make([]ActivityCompletion, 0, len(d.required)),
+	}
+
+	for _, activity := range d.required {
+		activity.Completed = done[outlineKey{activity.ActivityType, activity.ActivityID}]
+		if activity.Completed {
+			completion.CompletedCount++
+		} else if completion.NextActivity == nil {
+			next := activity
+			completion.NextActivity = &next
+		}
+		completion.Activities = append(completion.Activities, activity)
+	}
+
+	completion.PercentComplete = 100
+	if completion.RequiredCount > 0 {
+		completion.PercentComplete = float64(completion.CompletedCount) * 100 / float64(completion.RequiredCount)
+	}
+	if grade != nil {
+		completion.FinalPercent = grade.FinalPercent
+		completion.LetterGrade = grade.LetterGrade
+	}
+
+	met := true
+	if d.rule.MinPercentComplete > 0 {
+		percent := completion.PercentComplete
+		requirement := CompletionRequirement{
+			Type:     CompletionRequirementPercentComplete,
+			Required: d.rule.MinPercentComplete,
+			Current:  &percent,
+			Met:      percent >= d.rule.MinPercentComplete,
+		}
+		met = met && requirement.Met
+		completion.Requirements = append(completion.Requirements, requirement)
+	}
+	if d.rule.MinFinalPercent != nil {
+		requirement := CompletionRequirement{
+			Type:     CompletionRequirementFinalGrade,
+			Required: *d.rule.MinFinalPercent,
+			Current:  completion.FinalPercent,
+			Met:      completion.FinalPercent != nil && *completion.FinalPercent >= *d.rule.MinFinalPercent,
+		}
+		met = met && requirement.Met
+		completion.Requirements = append(completion.Requirements, requirement)
+	}
+
+	completion.Completed = met || enrollment.CompletedAt != nil
+	return completion
+}
+
+// activitiesMet memeriksa apakah syarat persentase aktivitas sudah terpenuhi
+func (d *completionData) activitiesMet(completion CourseCompletion) bool {
+	return completion.PercentComplete >= d.rule.MinPercentComplete
+}
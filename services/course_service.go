@@ -3,21 +3,24 @@ package services
 import (
 	"LMS/models"
 	"LMS/repositories"
+	"LMS/utils"
 	"errors"
 
 )
 
 // CourseService menangani logika bisnis kursus
 type CourseService struct {
-	CourseRepo *repositories.CourseRepository
-	UserRepo   *repositories.UserRepository
+	CourseRepo    *repositories.CourseRepository
+	UserRepo      *repositories.UserRepository
+	ModuleService *ModuleService
 }
 
 // NewCourseService membuat layanan kursus baru
-func NewCourseService(courseRepo *repositories.CourseRepository, userRepo *repositories.UserRepository) *CourseService {
+func NewCourseService(courseRepo *repositories.CourseRepository, userRepo *repositories.UserRepository, moduleService *ModuleService) *CourseService {
 	return &CourseService{
-		CourseRepo: courseRepo,
-		UserRepo:   userRepo,
+		CourseRepo:    courseRepo,
+		UserRepo:      userRepo,
+		ModuleService: moduleService,
 	}
 }
 
@@ -34,13 +37,60 @@ func (s *CourseService) CreateCourse(course *models.Course) error {
 		return errors.New("user is not a mentor")
 	}
 
+	allowedTypes, err := utils.ParseFileTypes(course.AllowedFileTypes)
+	if err != nil {
+		return err
+	}
+	course.AllowedFileTypes = allowedTypes
+
 	// Buat kursus
 	return s.CourseRepo.Create(course)
 }
 
-// GetCourseByID mendapatkan kursus dengan ID
+// GetCourseByID mendapatkan kursus dengan ID beserta kerangka modulnya
 func (s *CourseService) GetCourseByID(id uint) (*models.Course, error) {
-	return s.CourseRepo.FindByIDWithDetails(id)
+	course, err := s.CourseRepo.FindByIDWithDetails(id)
+	if err != nil {
+		return nil, err
+	}
+
+	modules, ungrouped, err := s.ModuleService.buildOutline(course.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+	course.Modules = modules
+	course.UngroupedItems = ungrouped
+
+	return course, nil
+}
+
+// GetCourseForStudent mendapatkan kursus beserta kerangka modulnya dengan kunci yang berlaku bagi siswa
+func (s *CourseService) GetCourseForStudent(id, studentID uint) (*models.Course, error) {
+	course, err := s.CourseRepo.FindByIDWithDetails(id)
+	if err != nil {
+		return nil, err
+	}
+
+	availability, err := s.ModuleService.AvailabilityService.ForStudent(course.ID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	modules, ungrouped, err := s.ModuleService.buildOutline(course.ID, availability)
+	if err != nil {
+		return nil, err
+	}
+	course.Modules = modules
+	course.UngroupedItems = ungrouped
+
+	for i := range course.Materials {
+		availability.LockMaterial(&course.Materials[i])
+	}
+	for i := range course.Assignments {
+		availability.LockAssignment(&course.Assignments[i])
+	}
+
+	return course, nil
 }
 
 // UpdateCourse memperbarui sebuah kursus
@@ -51,9 +101,15 @@ func (s *CourseService) UpdateCourse(course *models.Course) error {
 		return err
 	}
 
+	allowedTypes, err := utils.ParseFileTypes(course.AllowedFileTypes)
+	if err != nil {
+		return err
+	}
+
 	// Perbarui hanya bidang yang diizinkan
 	existingCourse.Title = course.Title
 	existingCourse.Description = course.Description
+	existingCourse.AllowedFileTypes = allowedTypes
 
 	return s.CourseRepo.Update(existingCourse)
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"LMS/events"
 	"LMS/models"
 	"LMS/repositories"
 	"errors"
@@ -14,6 +15,7 @@ type DiscussionService struct {
 	CourseRepo     *repositories.CourseRepository
 	UserRepo       *repositories.UserRepository
 	EnrollmentRepo *repositories.EnrollmentRepository
+	Events         *events.Bus
 }
 
 // NewDiscussionService membuat layanan diskusi baru
@@ -22,12 +24,14 @@ func NewDiscussionService(
 	courseRepo *repositories.CourseRepository,
 	userRepo *repositories.UserRepository,
 	enrollmentRepo *repositories.EnrollmentRepository,
+	bus *events.Bus,
 ) *DiscussionService {
 	return &DiscussionService{
 		DiscussionRepo: discussionRepo,
 		CourseRepo:     courseRepo,
 		UserRepo:       userRepo,
 		EnrollmentRepo: enrollmentRepo,
+		Events:         bus,
 	}
 }
 
@@ -57,7 +61,17 @@ func (s *DiscussionService) CreateDiscussion(discussion *models.Discussion) erro
 	discussion.CreatedAt = time.Now()
 
 	// Buat diskusi
-	return s.DiscussionRepo.Create(discussion)
+	if err := s.DiscussionRepo.Create(discussion); err != nil {
+		return err
+	}
+
+	s.Events.Publish(events.Event{
+		Type:       events.DiscussionCreated,
+		CourseID:   discussion.CourseID,
+		ResourceID: discussion.ID,
+		ActorID:    discussion.UserID,
+	})
+	return nil
 }
 
 // GetDiscussionByID mendapatkan diskusi berdasarkan ID
@@ -87,19 +101,64 @@ func (s *DiscussionService) UpdateDiscussion(discussion *models.Discussion) erro
 	existingDiscussion.Title = discussion.Title
 	existingDiscussion.Content = discussion.Content
 
-	return s.DiscussionRepo.Update(existingDiscussion)
+	if err := s.DiscussionRepo.Update(existingDiscussion); err != nil {
+		return err
+	}
+	s.publishUpdate(existingDiscussion, discussion.UserID)
+	return nil
+}
+
+// PinDiscussion menyematkan atau melepas sematan diskusi agar tampil paling atas di forum kursus
+func (s *DiscussionService) PinDiscussion(id uint, pinned bool) error {
+	discussion, err := s.DiscussionRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.DiscussionRepo.SetPinned(id, pinned); err != nil {
+		return err
+	}
+	s.publishUpdate(discussion, 0)
+	return nil
+}
+
+// LockDiscussion mengunci diskusi dari komentar baru atau membukanya kembali
+func (s *DiscussionService) LockDiscussion(id uint, locked bool, moderatorID uint) error {
+	discussion, err := s.DiscussionRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	var lockedByID *uint
+	if locked {
+		lockedByID = &moderatorID
+	}
+	if err := s.DiscussionRepo.SetLocked(id, locked, lockedByID); err != nil {
+		return err
+	}
+	s.publishUpdate(discussion, moderatorID)
+	return nil
+}
+
+// publishUpdate mengumumkan diskusi yang diubah, disematkan atau dikunci kepada pelanggan bus kejadian
+func (s *DiscussionService) publishUpdate(discussion *models.Discussion, actorID uint) {
+	s.Events.Publish(events.Event{
+		Type:       events.DiscussionUpdated,
+		CourseID:   discussion.CourseID,
+		ResourceID: discussion.ID,
+		ActorID:    actorID,
+	})
 }
 
 // DeleteDiscussion menghapus diskusi
-func (s *DiscussionService) DeleteDiscussion(id uint, userID uint, isAdmin bool) error {
+func (s *DiscussionService) DeleteDiscussion(id uint, userID uint, isModerator bool) error {
 	// Verifikasi adanya diskusi
 	discussion, err := s.DiscussionRepo.FindByID(id)
 	if err != nil {
 		return err
 	}
 
-	// Verifikasi pengguna yang memiliki diskusi atau admin
-	if !isAdmin && discussion.UserID != userID {
+	// Verifikasi pengguna yang memiliki diskusi atau moderator
+	if !isModerator && discussion.UserID != userID {
 		return errors.New("user does not own this discussion")
 	}
 
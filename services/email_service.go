@@ -0,0 +1,333 @@
+package services
+
+import (
+	"LMS/mailer"
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+)
+
+// EmailSettings adalah pengaturan pengiriman email pemberitahuan
+type EmailSettings struct {
+	// AppURL ditautkan di setiap email; kosong berarti tanpa tautan
+	AppURL string
+	// MaxAttempts adalah jumlah percobaan kirim sebelum email dianggap gagal
+	MaxAttempts int
+	// RetryDelay adalah jeda sebelum percobaan kedua; jeda berikutnya berlipat dua
+	RetryDelay time.Duration
+	// DigestHour adalah jam (waktu server) pengiriman ringkasan harian
+	DigestHour int
+	// BatchSize adalah jumlah email yang diambil dari antrean setiap putaran
+	BatchSize int
+}
+
+// DefaultEmailSettings mengembalikan pengaturan email bawaan
+func DefaultEmailSettings() EmailSettings {
+	return EmailSettings{
+		MaxAttempts: 5,
+		RetryDelay:  time.Minute,
+		DigestHour:  7,
+		BatchSize:   50,
+	}
+}
+
+// EmailService mengubah pemberitahuan menjadi email dan mengirimnya lewat antrean di latar belakang.
+// Permintaan API hanya memasukkan email ke antrean sehingga server email yang lambat tidak menahannya.
+type EmailService struct {
+	EmailRepo        *repositories.EmailRepository
+	NotificationRepo *repositories.NotificationRepository
+	UserRepo         *repositories.UserRepository
+	CourseRepo       *repositories.CourseRepository
+	Mailer           mailer.Mailer
+	Settings         EmailSettings
+}
+
+// NewEmailService membuat layanan email baru
+func NewEmailService(
+	emailRepo *repositories.EmailRepository,
+	notificationRepo *repositories.NotificationRepository,
+	userRepo *repositories.UserRepository,
+	courseRepo *repositories.CourseRepository,
+	mail mailer.Mailer,
+	settings EmailSettings,
+) *EmailService {
+	return &EmailService{
+		EmailRepo:        emailRepo,
+		NotificationRepo: notificationRepo,
+		UserRepo:         userRepo,
+		CourseRepo:       courseRepo,
+		Mailer:           mail,
+		Settings:         settings,
+	}
+}
+
+// notificationEmail adalah data templat email satu pemberitahuan
+type notificationEmail struct {
+	Name        string
+	Title       string
+	Message     string
+	CourseTitle string
+	AppURL      string
+}
+
+// digestEmail adalah data templat ringkasan harian
+type digestEmail struct {
+	Name   string
+	Since  string
+	Items  []notificationEmail
+	AppURL string
+}
+
+// GetPreference mendapatkan preferensi email pengguna; pengguna tanpa preferensi menerima email seketika
+func (s *EmailService) GetPreference(userID uint) (*models.EmailPreference, error) {
+	preference, err := s.EmailRepo.FindPreference(userID)
+	if err != nil {
+		return &models.EmailPreference{UserID: userID, Mode: models.EmailModeInstant}, nil
+	}
+	return preference, nil
+}
+
+// SetPreference menetapkan cara pengguna menerima email pemberitahuan
+func (s *EmailService) SetPreference(userID uint, mode models.EmailMode) (*models.EmailPreference, error) {
+	switch mode {
+	case models.EmailModeInstant, models.EmailModeDaily, models.EmailModeOff:
+	default:
+		return nil, errors.New("mode must be instant, daily or off")
+	}
+
+	preference := &models.EmailPreference{UserID: userID, Mode: mode}
+	if err := s.EmailRepo.SavePreference(preference); err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// Enqueue memasukkan email untuk pemberitahuan yang baru dibuat ke antrean.
+// Hanya penerima dengan mode seketika yang dikirimi email; mode harian dikumpulkan oleh QueueDigests.
+func (s *EmailService) Enqueue(notifications []models.Notification) error {
+	if s == nil || len(notifications) == 0 {
+		return nil
+	}
+
+	userIDs := make([]uint, 0, len(notifications))
+	for _, notification := range notifications {
+		userIDs = append(userIDs, notification.UserID)
+	}
+
+	preferences, err := s.EmailRepo.FindPreferences(userIDs)
+	if err != nil {
+		return err
+	}
+	modes := make(map[uint]models.EmailMode, len(preferences))
+	for _, preference := range preferences {
+		modes[preference.UserID] = preference.Mode
+	}
+
+	users, err := s.UserRepo.FindByIDs(userIDs)
+	if err != nil {
+		return err
+	}
+	recipients := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		recipients[user.ID] = user
+	}
+
+	courseTitles := make(map[uint]string)
+	now := time.Now()
+	var deliveries []models.EmailDelivery
+	for _, notification := range notifications {
+		if mode, ok := modes[notification.UserID]; ok && mode != models.EmailModeInstant {
+			continue
+		}
+		user, ok := recipients[notification.UserID]
+		if !ok || user.Email == "" {
+			continue
+		}
+
+		data := s.notificationEmail(user, notification, courseTitles)
+		text, html, err := mailer.Render(mailer.TemplateNotification, data)
+		if err != nil {
+			return err
+		}
+
+		notificationID := notification.ID
+		deliveries = append(deliveries, models.EmailDelivery{
+			UserID:         user.ID,
+			NotificationID: &notificationID,
+			Recipient:      user.Email,
+			Subject:        notification.Title,
+			TextBody:       text,
+			HTMLBody:       html,
+			Status:         models.EmailStatusPending,
+			NextAttemptAt:  now,
+		})
+	}
+	return s.EmailRepo.CreateDeliveries(deliveries)
+}
+
+// QueueDigests memasukkan ringkasan harian ke antrean untuk pengguna yang memilihnya.
+// Ringkasan dibuat sekali sehari setelah DigestHour dan hanya jika ada pemberitahuan yang belum dibaca.
+func (s *EmailService) QueueDigests(now time.Time) (int, error) {
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), s.Settings.DigestHour, 0, 0, 0, now.Location())
+	if now.Before(cutoff) {
+		return 0, nil
+	}
+
+	preferences, err := s.EmailRepo.FindDigestsDue(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	courseTitles := make(map[uint]string)
+	for _, preference := range preferences {
+		since := now.Add(-24 * time.Hour)
+		if preference.LastDigestAt != nil {
+			since = *preference.LastDigestAt
+		}
+
+		delivery, err := s.digestDelivery(preference.UserID, since, now, courseTitles)
+		if err != nil {
+			log.Printf("failed to build email digest for user %d: %v", preference.UserID, err)
+			continue
+		}
+		if delivery != nil {
+			if err := s.EmailRepo.CreateDeliveries([]models.EmailDelivery{*delivery}); err != nil {
+				return queued, err
+			}
+			queued++
+		}
+		if err := s.EmailRepo.MarkDigestSent(preference.ID, now); err != nil {
+			return queued, err
+		}
+	}
+	return queued, nil
+}
+
+// digestDelivery menyusun ringkasan pemberitahuan yang belum dibaca sejak waktu tertentu; nil jika tidak ada
+func (s *EmailService) digestDelivery(userID uint, since, now time.Time, courseTitles map[uint]string) (*models.EmailDelivery, error) {
+	notifications, err := s.NotificationRepo.FindUnreadSince(userID, since)
+	if err != nil {
+		return nil, err
+	}
+	if len(notifications) == 0 {
+		return nil, nil
+	}
+
+	user, err := s.UserRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == "" {
+		return nil, nil
+	}
+
+	data := digestEmail{
+		Name:   user.Name,
+		Since:  since.Format("2 Jan 2006 15:04"),
+		AppURL: s.Settings.AppURL,
+	}
+	for _, notification := range notifications {
+		data.Items = append(data.Items, s.notificationEmail(*user, notification, courseTitles))
+	}
+
+	text, html, err := mailer.Render(mailer.TemplateDigest, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EmailDelivery{
+		UserID:        user.ID,
+		Recipient:     user.Email,
+		Subject:       fmt.Sprintf("Your daily digest: %d unread notifications", len(notifications)),
+		TextBody:      text,
+		HTMLBody:      html,
+		Status:        models.EmailStatusPending,
+		NextAttemptAt: now,
+	}, nil
+}
+
+// notificationEmail menyiapkan data templat untuk satu pemberitahuan
+func (s *EmailService) notificationEmail(user models.User, notification models.Notification, courseTitles map[uint]string) notificationEmail {
+	title, ok := courseTitles[notification.CourseID]
+	if !ok {
+		if course, err := s.CourseRepo.FindByID(notification.CourseID); err == nil {
+			title = course.Title
+		}
+		courseTitles[notification.CourseID] = title
+	}
+
+	return notificationEmail{
+		Name:        user.Name,
+		Title:       notification.Title,
+		Message:     notification.Message,
+		CourseTitle: title,
+		AppURL:      s.Settings.AppURL,
+	}
+}
+
+// ProcessQueue mengirim email tertunda yang sudah waktunya dan menjadwalkan ulang yang gagal
+func (s *EmailService) ProcessQueue(now time.Time) (int, int, error) {
+	deliveries, err := s.EmailRepo.FindDueDeliveries(now, s.Settings.BatchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sent, failed := 0, 0
+	for _, delivery := range deliveries {
+		attempts := delivery.Attempts + 1
+		err := s.Mailer.Send(mailer.Message{
+			To:      delivery.Recipient,
+			Subject: delivery.Subject,
+			Text:    delivery.TextBody,
+			HTML:    delivery.HTMLBody,
+		})
+		if err == nil {
+			if err := s.EmailRepo.MarkSent(delivery.ID, attempts, time.Now()); err != nil {
+				return sent, failed, err
+			}
+			sent++
+			continue
+		}
+
+		if attempts >= s.Settings.MaxAttempts {
+			log.Printf("giving up on email %d to %s after %d attempts: %v", delivery.ID, delivery.Recipient, attempts, err)
+			if err := s.EmailRepo.MarkFailed(delivery.ID, attempts, err.Error()); err != nil {
+				return sent, failed, err
+			}
+			failed++
+			continue
+		}
+
+		// Jeda berlipat dua setiap kali gagal: 1x, 2x, 4x, ... RetryDelay
+		delay := s.Settings.RetryDelay << (attempts - 1)
+		if err := s.EmailRepo.MarkRetry(delivery.ID, attempts, time.Now().Add(delay), err.Error()); err != nil {
+			return sent, failed, err
+		}
+	}
+	return sent, failed, nil
+}
+
+// RunWorker membuat ringkasan harian dan mengirim antrean email secara berkala; dipanggil sebagai goroutine
+func (s *EmailService) RunWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := s.QueueDigests(time.Now()); err != nil {
+			log.Printf("failed to queue email digests: %v", err)
+		}
+
+		sent, failed, err := s.ProcessQueue(time.Now())
+		if err != nil {
+			log.Printf("failed to process email queue: %v", err)
+			continue
+		}
+		if failed > 0 {
+			log.Printf("sent %d emails, %d failed permanently", sent, failed)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"LMS/mailer"
+	"LMS/models"
+	"LMS/repositories"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+)
+
+// mailerFunc mengubah fungsi menjadi mailer.Mailer
+type mailerFunc func(message mailer.Message) error
+
+func (f mailerFunc) Send(message mailer.Message) error { return f(message) }
+
+func TestProcessQueueRetryAndBackoff(t *testing.T) {
+	settings := DefaultEmailSettings()
+	settings.MaxAttempts = 4
+	settings.RetryDelay = time.Minute
+	unavailable := errors.New("451 mailbox temporarily unavailable")
+
+	tests := []struct {
+		name          string
+		attempts      int
+		sendErr       error
+		wantStatus    models.EmailStatus
+		wantAttempts  int
+		wantDelay     time.Duration
+		wantSent      int
+		wantFailed    int
+		wantLastError string
+	}{
+		{"first failure waits one delay", 0, unavailable, "", 1, time.Minute, 0, 0, unavailable.Error()},
+		{"second failure doubles the delay", 1, unavailable, "", 2, 2 * time.Minute, 0, 0, unavailable.Error()},
+		{"third failure doubles again", 2, unavailable, "", 3, 4 * time.Minute, 0, 0, unavailable.Error()},
+		{"last attempt fails permanently", 3, unavailable, models.EmailStatusFailed, 4, 0, 0, 1, unavailable.Error()},
+		{"retry succeeds", 2, nil, models.EmailStatusSent, 3, 0, 1, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &recordingDB{tables: map[string][]map[string]driver.Value{
+				"email_deliveries": {{
+					"id":              int64(9),
+					"user_id":         int64(7),
+					"recipient":       "student@example.com",
+					"subject":         "New grade",
+					"text_body":       "Your assignment was graded.",
+					"html_body":       "<p>Your assignment was graded.</p>",
+					"status":          string(models.EmailStatusPending),
+					"attempts":        int64(tt.attempts),
+					"next_attempt_at": time.Now().Add(-time.Minute),
+				}},
+			}}
+			gormDB := newRecordingGorm(t, db)
+
+			var delivered []mailer.Message
+			service := NewEmailService(
+				repositories.NewEmailRepository(gormDB),
+				repositories.NewNotificationRepository(gormDB),
+				repositories.NewUserRepository(gormDB),
+				repositories.NewCourseRepository(gormDB),
+				mailerFunc(func(message mailer.Message) error {
+					delivered = append(delivered, message)
+					return tt.sendErr
+				}),
+				settings,
+			)
+
+			before := time.Now()
+			sent, failed, err := service.ProcessQueue(time.Now())
+			after := time.Now()
+			if err != nil {
+				t.Fatalf("ProcessQueue: %v", err)
+			}
+			if sent != tt.wantSent || failed != tt.wantFailed {
+				t.Errorf("sent, failed = %d, %d, want %d, %d", sent, failed, tt.wantSent, tt.wantFailed)
+			}
+			if len(delivered) != 1 || delivered[0].To != "student@example.com" || delivered[0].HTML != "<p>Your assignment was graded.</p>" {
+				t.Fatalf("delivered = %+v", delivered)
+			}
+
+			if len(db.updates) != 1 || db.updates[0].table != "email_deliveries" {
+				t.Fatalf("updates = %+v, want one email_deliveries update", db.updates)
+			}
+			values := db.updates[0].values
+			if values["attempts"] != int64(tt.wantAttempts) {
+				t.Errorf("attempts = %v, want %d", values["attempts"], tt.wantAttempts)
+			}
+			if values["last_error"] != tt.wantLastError {
+				t.Errorf("last_error = %v, want %q", values["last_error"], tt.wantLastError)
+			}
+
+			status, hasStatus := values["status"]
+			switch {
+			case tt.wantStatus == "" && hasStatus:
+				t.Errorf("status = %v, want the delivery to stay pending", status)
+			case tt.wantStatus != "" && status != string(tt.wantStatus):
+				t.Errorf("status = %v, want %s", status, tt.wantStatus)
+			}
+
+			next, hasNext := values["next_attempt_at"].(time.Time)
+			if tt.wantDelay == 0 {
+				if hasNext {
+					t.Errorf("next_attempt_at = %v, want it unchanged", next)
+				}
+				return
+			}
+			if !hasNext || next.Before(before.Add(tt.wantDelay)) || next.After(after.Add(tt.wantDelay)) {
+				t.Errorf("next_attempt_at = %v, want %v after processing", values["next_attempt_at"], tt.wantDelay)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+)
+
+// ExtensionService menangani logika bisnis perpanjangan tenggat dan akomodasi siswa
+type ExtensionService struct {
+	ExtensionRepo  *repositories.ExtensionRepository
+	AssignmentRepo *repositories.AssignmentRepository
+	QuizRepo       *repositories.QuizRepository
+	EnrollmentRepo *repositories.EnrollmentRepository
+	UserRepo       *repositories.UserRepository
+}
+
+// NewExtensionService membuat layanan perpanjangan baru
+func NewExtensionService(
+	extensionRepo *repositories.ExtensionRepository,
+	assignmentRepo *repositories.AssignmentRepository,
+	quizRepo *repositories.QuizRepository,
+	enrollmentRepo *repositories.EnrollmentRepository,
+	userRepo *repositories.UserRepository,
+) *ExtensionService {
+	return &ExtensionService{
+		ExtensionRepo:  extensionRepo,
+		AssignmentRepo: assignmentRepo,
+		QuizRepo:       quizRepo,
+		EnrollmentRepo: enrollmentRepo,
+		UserRepo:       userRepo,
+	}
+}
+
+// GrantExtensions memberikan perpanjangan yang sama kepada satu atau beberapa siswa.
+// Perpanjangan aktif sebelumnya untuk aktivitas yang sama dicabut dan tetap tercatat.
+func (s *ExtensionService) GrantExtensions(template *models.DueDateExtension, studentIDs []uint) ([]models.DueDateExtension, error) {
+	courseID, dueDate, err := s.activityCourseAndDueDate(template.ActivityType, template.ActivityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateExtension(template, dueDate); err != nil {
+		return nil, err
+	}
+
+	if len(studentIDs) == 0 {
+		return nil, errors.New("at least one student is required")
+	}
+
+	// Validasi semua siswa terlebih dahulu agar pemberian massal tidak berhenti di tengah
+	seen := make(map[uint]bool, len(studentIDs))
+	for _, studentID := range studentIDs {
+		if seen[studentID] {
+			return nil, errors.New("duplicate student in extension request")
+		}
+		seen[studentID] = true
+
+		student, err := s.UserRepo.FindByID(studentID)
+		if err != nil {
+			return nil, errors.New("student not found")
+		}
+		if student.Role != models.RoleStudent {
+			return nil, errors.New("extensions can only be granted to students")
+		}
+		if _, err := s.EnrollmentRepo.FindByUserAndCourse(studentID, courseID); err != nil {
+			return nil, errors.New("student is not enrolled in this course")
+		}
+	}
+
+	// Semua perpanjangan diberikan dalam satu transaksi: jika satu gagal, tidak ada yang tersimpan
+	var extensions []models.DueDateExtension
+	err = s.ExtensionRepo.DB.Transaction(func(tx *gorm.DB) error {
+		extensionRepo := repositories.NewExtensionRepository(tx)
+		extensions = make([]models.DueDateExtension, 0, len(studentIDs))
+		for _, studentID := range studentIDs {
+			extension := models.DueDateExtension{
+				ActivityType:          template.ActivityType,
+				ActivityID:            template.ActivityID,
+				CourseID:              courseID,
+				StudentID:             studentID,
+				NewDueDate:            template.NewDueDate,
+				ExtraMinutes:          template.ExtraMinutes,
+				ExtraTimeLimitMinutes: template.ExtraTimeLimitMinutes,
+				Reason:                template.Reason,
+				GrantedByID:           template.GrantedByID,
+			}
+			if err := extensionRepo.Grant(&extension); err != nil {
+				return err
+			}
+			extensions = append(extensions, extension)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return extensions, nil
+}
+
+// RevokeExtension mencabut perpanjangan dan mencatat siapa yang mencabutnya
+func (s *ExtensionService) RevokeExtension(id, revokedByID uint) error {
+	extension, err := s.ExtensionRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if extension.RevokedAt != nil {
+		return errors.New("extension has already been revoked")
+	}
+
+	return s.ExtensionRepo.Revoke(id, revokedByID)
+}
+
+// GetExtensionByID mendapatkan perpanjangan berdasarkan ID
+func (s *ExtensionService) GetExtensionByID(id uint) (*models.DueDateExtension, error) {
+	return s.ExtensionRepo.FindByID(id)
+}
+
+// GetExtensionsByActivity mendapatkan riwayat perpanjangan sebuah aktivitas
+func (s *ExtensionService) GetExtensionsByActivity(activityType models.ExtensionActivityType, activityID uint) ([]models.DueDateExtension, error) {
+	if _, _, err := s.activityCourseAndDueDate(activityType, activityID); err != nil {
+		return nil, err
+	}
+	return s.ExtensionRepo.FindByActivity(activityType, activityID)
+}
+
+// GetStudentExtensions mendapatkan riwayat perpanjangan siswa dalam sebuah kursus
+func (s *ExtensionService) GetStudentExtensions(studentID, courseID uint) ([]models.DueDateExtension, error) {
+	return s.ExtensionRepo.FindByStudentAndCourse(studentID, courseID)
+}
+
+// ActiveExtension mendapatkan perpanjangan yang berlaku, atau nil jika siswa tidak memilikinya
+func (s *ExtensionService) ActiveExtension(activityType models.ExtensionActivityType, activityID, studentID uint) *models.DueDateExtension {
+	extension, err := s.ExtensionRepo.FindActive(activityType, activityID, studentID)
+	if err != nil {
+		return nil
+	}
+	return extension
+}
+
+// ApplyToAssignment mengisi tenggat efektif tugas untuk siswa berdasarkan perpanjangan aktifnya
+func (s *ExtensionService) ApplyToAssignment(assignment *models.Assignment, studentID uint) {
+	assignment.EffectiveDueDate = assignment.DueDate
+	assignment.Extension = nil
+
+	extension := s.ActiveExtension(models.ExtensionActivityAssignment, assignment.ID, studentID)
+	if extension == nil {
+		return
+	}
+
+	assignment.Extension = extension
+	assignment.EffectiveDueDate = extension.ApplyToDueDate(assignment.DueDate)
+}
+
+// activityCourseAndDueDate mendapatkan kursus dan tanggal jatuh tempo dari aktivitas yang diberi perpanjangan
+func (s *ExtensionService) activityCourseAndDueDate(activityType models.ExtensionActivityType, activityID uint) (uint, *time.Time, error) {
+	switch activityType {
+	case models.ExtensionActivityAssignment:
+		assignment, err := s.AssignmentRepo.FindByID(activityID)
+		if err != nil {
+			return 0, nil, errors.New("assignment not found")
+		}
+		return assignment.CourseID, assignment.DueDate, nil
+
+	case models.ExtensionActivityQuiz:
+		quiz, err := s.QuizRepo.FindByID(activityID)
+		if err != nil {
+			return 0, nil, errors.New("quiz not found")
+		}
+		return quiz.CourseID, quiz.DueDate, nil
+	}
+
+	return 0, nil, errors.New("invalid activity type")
+}
+
+// validateExtension memvalidasi isi perpanjangan terhadap tanggal jatuh tempo aktivitas, jika ada
+func validateExtension(extension *models.DueDateExtension, dueDate *time.Time) error {
+	if extension.ExtraMinutes < 0 || extension.ExtraTimeLimitMinutes < 0 {
+		return errors.New("extra minutes cannot be negative")
+	}
+	if extension.NewDueDate != nil && extension.ExtraMinutes > 0 {
+		return errors.New("use either a new due date or extra minutes, not both")
+	}
+	if extension.ExtraTimeLimitMinutes > 0 && extension.ActivityType != models.ExtensionActivityQuiz {
+		return errors.New("extra time limit only applies to quizzes")
+	}
+	if extension.NewDueDate == nil && extension.ExtraMinutes == 0 && extension.ExtraTimeLimitMinutes == 0 {
+		return errors.New("extension must set a new due date, extra minutes or extra time limit")
+	}
+	// Perpanjangan tidak boleh memajukan tenggat siswa
+	if extension.NewDueDate != nil && dueDate != nil && extension.NewDueDate.Before(*dueDate) {
+		return errors.New("new due date cannot be earlier than the current due date")
+	}
+	return nil
+}
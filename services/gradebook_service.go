@@ -0,0 +1,921 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+)
+
+// GradebookItem adalah satu kolom buku nilai: aktivitas yang dapat dinilai
+type GradebookItem struct {
+	ActivityType models.ProgressType `json:"activity_type"`
+	ActivityID   uint                `json:"activity_id"`
+	Title        string              `json:"title"`
+	MaxScore     *float64            `json:"max_score"`
+}
+
+// GradebookEntry adalah nilai seorang siswa untuk satu aktivitas
+type GradebookEntry struct {
+	ActivityType models.ProgressType `json:"activity_type"`
+	ActivityID   uint                `json:"activity_id"`
+	Score        *float64            `json:"score"`
+	MaxScore     *float64            `json:"max_score"`
+	Percent      *float64            `json:"percent"`
+	Feedback     string              `json:"feedback,omitempty"`
+	Source       string              `json:"source,omitempty"`
+	Dropped      bool                `json:"dropped"`
+}
+
+// CategoryGrade adalah nilai seorang siswa dalam satu kategori berbobot
+type CategoryGrade struct {
+	CategoryID   uint                `json:"category_id"`
+	Name         string              `json:"name"`
+	ActivityType models.ProgressType `json:"activity_type"`
+	Weight       float64             `json:"weight"`
+	Percent      *float64            `json:"percent"`
+	GradedCount  int                 `json:"graded_count"`
+	DroppedCount int                 `json:"dropped_count"`
+}
+
+// StudentGrade adalah nilai akhir kursus seorang siswa beserta rinciannya
+type StudentGrade struct {
+	StudentID    uint             `json:"student_id"`
+	StudentName  string           `json:"student_name"`
+	StudentEmail string           `json:"student_email"`
+	Entries      []GradebookEntry `json:"entries"`
+	Categories   []CategoryGrade  `json:"categories"`
+	FinalPercent *float64         `json:"final_percent"`
+	LetterGrade  string           `json:"letter_grade"`
+}
+
+// CourseGradebook adalah matriks nilai seluruh siswa dalam sebuah kursus
+type CourseGradebook struct {
+	CourseID   uint                   `json:"course_id"`
+	Items      []GradebookItem        `json:"items"`
+	Categories []models.GradeCategory `json:"categories"`
+	Scale      []models.LetterGrade   `json:"scale"`
+	Students   []StudentGrade         `json:"students"`
+}
+
+// gradebookKey mengidentifikasi nilai satu siswa untuk satu aktivitas
+type gradebookKey struct {
+	activityType models.ProgressType
+	activityID   uint
+	studentID    uint
+}
+
+// gradebookData adalah data kursus yang dibutuhkan untuk menghitung nilai
+type gradebookData struct {
+	items      []GradebookItem
+	categories []models.GradeCategory
+	scale      []models.LetterGrade
+	entries    map[gradebookKey]GradebookEntry
+}
+
+// GradebookService menangani logika bisnis buku nilai berbobot
+type GradebookService struct {
+	GradebookRepo   *repositories.GradebookRepository
+	CourseRepo      *repositories.CourseRepository
+	EnrollmentRepo  *repositories.EnrollmentRepository
+	AssignmentRepo  *repositories.AssignmentRepository
+	QuizRepo        *repositories.QuizRepository
+	MaterialRepo    *repositories.MaterialRepository
+	DiscussionRepo  *repositories.DiscussionRepository
+	ProgressService *LearningProgressService
+}
+
+// NewGradebookService membuat layanan buku nilai baru
+func NewGradebookService(
+	gradebookRepo *repositories.GradebookRepository,
+	courseRepo *repositories.CourseRepository,
+	enrollmentRepo *repositories.EnrollmentRepository,
+	assignmentRepo *repositories.AssignmentRepository,
+	quizRepo *repositories.QuizRepository,
+	materialRepo *repositories.MaterialRepository,
+	discussionRepo *repositories.DiscussionRepository,
+	progressService *LearningProgressService,
+) *GradebookService {
+	return &GradebookService{
+		GradebookRepo:   gradebookRepo,
+		CourseRepo:      courseRepo,
+		EnrollmentRepo:  enrollmentRepo,
+		AssignmentRepo:  assignmentRepo,
+		QuizRepo:        quizRepo,
+		MaterialRepo:    materialRepo,
+		DiscussionRepo:  discussionRepo,
+		ProgressService: progressService,
+	}
+}
+
+// GetCategories mendapatkan kategori nilai kursus
+func (s *GradebookService) GetCategories(courseID uint) ([]models.GradeCategory, error) {
+	if _, err := s.CourseRepo.FindByID(courseID); err != nil {
+		return nil, errors.New("course not found")
+	}
+	return s.GradebookRepo.FindCategoriesByCourse(courseID)
+}
+
+// GetCategoryByID mendapatkan kategori nilai berdasarkan ID
+func (s *GradebookService) GetCategoryByID(id uint) (*models.GradeCategory, error) {
+	return s.GradebookRepo.FindCategoryByID(id)
+}
+
+// CreateCategory membuat kategori nilai baru untuk kursus
+func (s *GradebookService) CreateCategory(category *models.GradeCategory) error {
+	if _, err := s.CourseRepo.FindByID(category.CourseID); err != nil {
+		return errors.New("course not found")
+	}
+
+	if err := validateGradeCategory(category); err != nil {
+		return err
+	}
+
+	// Setiap jenis aktivitas hanya boleh berada dalam satu kategori
+	if _, err := s.GradebookRepo.FindCategoryByType(category.CourseID, category.ActivityType); err == nil {
+		return errors.New("a category for this activity type already exists")
+	}
+
+	return s.GradebookRepo.CreateCategory(category)
+}
+
+// UpdateCategory memperbarui kategori nilai
+func (s *GradebookService) UpdateCategory(category *models.GradeCategory) error {
+	existingCategory, err := s.GradebookRepo.FindCategoryByID(category.ID)
+	if err != nil {
+		return err
+	}
+
+	category.CourseID = existingCategory.CourseID
+	if err := validateGradeCategory(category); err != nil {
+		return err
+	}
+
+	if category.ActivityType != existingCategory.ActivityType {
+		if _, err := s.GradebookRepo.FindCategoryByType(category.CourseID, category.ActivityType); err == nil {
+			return errors.New("a category for this activity type already exists")
+		}
+	}
+
+	// Perbarui hanya bidang yang diizinkan
+	existingCategory.Name = category.Name
+	existingCategory.ActivityType = category.ActivityType
+	existingCategory.Weight = category.Weight
+	existingCategory.DropLowest = category.DropLowest
+
+	return s.GradebookRepo.UpdateCategory(existingCategory)
+}
+
+// DeleteCategory menghapus kategori nilai
+func (s *GradebookService) DeleteCategory(id uint) error {
+	return s.GradebookRepo.DeleteCategory(id)
+}
+
+// GetLetterGrades mendapatkan skala nilai huruf kursus, atau skala bawaan jika belum ditentukan
+func (s *GradebookService) GetLetterGrades(courseID uint) ([]models.LetterGrade, bool, error) {
+	if _, err := s.CourseRepo.FindByID(courseID); err != nil {
+		return nil, false, errors.New("course not found")
+	}
+
+	grades, err := s.GradebookRepo.FindLetterGrades(courseID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(grades) == 0 {
+		return models.DefaultLetterGrades(), true, nil
+	}
+	return grades, false, nil
+}
+
+// SetLetterGrades mengganti skala nilai huruf kursus; skala kosong mengembalikan skala bawaan
+func (s *GradebookService) SetLetterGrades(courseID uint, grades []models.LetterGrade) error {
+	if _, err := s.CourseRepo.FindByID(courseID); err != nil {
+		return errors.New("course not found")
+	}
+
+	if len(grades) > 0 {
+		if err := validateLetterGrades(grades); err != nil {
+			return err
+		}
+	}
+
+	for i := range grades {
+		grades[i].CourseID = courseID
+	}
+
+	return s.GradebookRepo.ReplaceLetterGrades(courseID, grades)
+}
+
+// GetCourseGradebook menghitung matriks nilai berbobot untuk semua siswa dalam kursus
+func (s *GradebookService) GetCourseGradebook(courseID uint) (*CourseGradebook, error) {
+	data, err := s.load(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.EnrollmentRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	gradebook := &CourseGradebook{
+		CourseID:   courseID,
+		Items:      data.items,
+		Categories: data.categories,
+		Scale:      data.scale,
+		Students:   []StudentGrade{},
+	}
+
+	for _, enrollment := range enrollments {
+		if enrollment.User.Role != models.RoleStudent {
+			continue
+		}
+		gradebook.Students = append(gradebook.Students, data.studentGrade(enrollment.User))
+	}
+
+	sort.Slice(gradebook.Students, func(i, j int) bool {
+		return strings.ToLower(gradebook.Students[i].StudentName) < strings.ToLower(gradebook.Students[j].StudentName)
+	})
+
+	return gradebook, nil
+}
+
+// GetStudentGrade menghitung nilai akhir berbobot seorang siswa dalam kursus
+func (s *GradebookService) GetStudentGrade(courseID, studentID uint) (*StudentGrade, error) {
+	data, err := s.load(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.EnrollmentRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, enrollment := range enrollments {
+		if enrollment.UserID == studentID && enrollment.User.Role == models.RoleStudent {
+			grade := data.studentGrade(enrollment.User)
+			return &grade, nil
+		}
+	}
+
+	return nil, errors.New("student is not enrolled in this course")
+}
+
+// load mengumpulkan aktivitas, kategori, skala, dan nilai mentah sebuah kursus.
+// Baris LearningProgress diutamakan; penilaian tugas dipakai jika belum ada baris kemajuan.
+func (s *GradebookService) load(courseID uint) (*gradebookData, error) {
+	if _, err := s.CourseRepo.FindByID(courseID); err != nil {
+		return nil, errors.New("course not found")
+	}
+
+	data := &gradebookData{entries: make(map[gradebookKey]GradebookEntry)}
+
+	var err error
+	if data.categories, err = s.GradebookRepo.FindCategoriesByCourse(courseID); err != nil {
+		return nil, err
+	}
+	if data.scale, _, err = s.GetLetterGrades(courseID); err != nil {
+		return nil, err
+	}
+
+	assignments, err := s.AssignmentRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	assignmentMax := make(map[uint]*float64, len(assignments))
+	for _, assignment := range assignments {
+		var maxScore *float64
+		if assignment.MaxScore != nil {
+			value := float64(*assignment.MaxScore)
+			maxScore = &value
+		}
+		assignmentMax[assignment.ID] = maxScore
+		data.items = append(data.items, GradebookItem{
+			ActivityType: models.ProgressTypeAssignment,
+			ActivityID:   assignment.ID,
+			Title:        assignment.Title,
+			MaxScore:     maxScore,
+		})
+	}
+
+	quizzes, err := s.QuizRepo.FindByCourseWithQuestions(courseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, quiz := range quizzes {
+		maxScore := quiz.MaxScore()
+		data.items = append(data.items, GradebookItem{
+			ActivityType: models.ProgressTypeQuiz,
+			ActivityID:   quiz.ID,
+			Title:        quiz.Title,
+			MaxScore:     &maxScore,
+		})
+	}
+
+	// Nilai dari penilaian tugas: percobaan terakhir yang sudah dinilai
+	submissions, err := s.GradebookRepo.FindSubmissionsByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, submission := range submissions {
+		maxScore := assignmentMax[submission.AssignmentID]
+		if submission.Assessment == nil || submission.Assessment.Score == nil || maxScore == nil || *maxScore <= 0 {
+			continue
+		}
+		score := float64(*submission.Assessment.Score)
+		data.entries[gradebookKey{models.ProgressTypeAssignment, submission.AssignmentID, submission.StudentID}] =
+			newGradebookEntry(models.ProgressTypeAssignment, submission.AssignmentID, score, *maxScore, submission.Assessment.Feedback, "assessment")
+	}
+
+	// Nilai dari kemajuan pembelajaran menimpa penilaian karena merupakan nilai resmi mentor
+	progress, err := s.GradebookRepo.FindProgressByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	extraItems := make(map[gradebookKey]bool)
+	for _, row := range progress {
+		if row.Score == nil || row.MaxScore == nil || *row.MaxScore <= 0 {
+			continue
+		}
+		data.entries[gradebookKey{row.ActivityType, row.ActivityID, row.UserID}] =
+			newGradebookEntry(row.ActivityType, row.ActivityID, *row.Score, *row.MaxScore, row.Feedback, "progress")
+
+		if row.ActivityType == models.ProgressTypeMaterial || row.ActivityType == models.ProgressTypeDiscussion {
+			extraItems[gradebookKey{activityType: row.ActivityType, activityID: row.ActivityID}] = true
+		}
+	}
+
+	// Materi dan diskusi hanya menjadi kolom jika pernah dinilai
+	if len(extraItems) > 0 {
+		if err := s.appendExtraItems(courseID, data, extraItems); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// appendExtraItems menambahkan kolom materi dan diskusi yang memiliki nilai
+func (s *GradebookService) appendExtraItems(courseID uint, data *gradebookData, extraItems map[gradebookKey]bool) error {
+	materials, err := s.MaterialRepo.FindByCourse(courseID)
+	if err != nil {
+		return err
+	}
+	for _, material := range materials {
+		if extraItems[gradebookKey{activityType: models.ProgressTypeMaterial, activityID: material.ID}] {
+			data.items = append(data.items, GradebookItem{
+				ActivityType: models.ProgressTypeMaterial,
+				ActivityID:   material.ID,
+				Title:        material.Title,
+			})
+		}
+	}
+
+	discussions, err := s.DiscussionRepo.FindByCourse(courseID)
+	if err != nil {
+		return err
+	}
+	for _, discussion := range discussions {
+		if extraItems[gradebookKey{activityType: models.ProgressTypeDiscussion, activityID: discussion.ID}] {
+			data.items = append(data.items, GradebookItem{
+				ActivityType: models.ProgressTypeDiscussion,
+				ActivityID:   discussion.ID,
+				Title:        discussion.Title,
+			})
+		}
+	}
+
+	return nil
+}
+
+// studentGrade menghitung nilai kategori, nilai akhir, dan nilai huruf seorang siswa
+func (d *gradebookData) studentGrade(student models.User) StudentGrade {
+	studentID := student.ID
+	grade := StudentGrade{
+		StudentID:    student.ID,
+		StudentName:  student.Name,
+		StudentEmail: student.Email,
+		Entries:      make([]GradebookEntry, 0, len(d.items)),
+		Categories:   make([]CategoryGrade, 0, len(d.categories)),
+	}
+
+	for _, item := range d.items {
+		entry, ok := d.entries[gradebookKey{item.ActivityType, item.ActivityID, studentID}]
+		if !ok {
+			entry = GradebookEntry{ActivityType: item.ActivityType, ActivityID: item.ActivityID}
+		}
+		grade.Entries = append(grade.Entries, entry)
+	}
+
+	// Tanpa kategori, nilai akhir adalah total poin dari semua aktivitas yang sudah dinilai
+	if len(d.categories) == 0 {
+		var earned, possible float64
+		for _, entry := range grade.Entries {
+			if entry.Score != nil {
+				earned += *entry.Score
+				possible += *entry.MaxScore
+			}
+		}
+		if possible > 0 {
+			final := earned / possible * 100
+			grade.FinalPercent = &final
+		}
+		grade.LetterGrade = letterFor(d.scale, grade.FinalPercent)
+		return grade
+	}
+
+	var weighted, totalWeight float64
+	for _, category := range d.categories {
+		categoryGrade := CategoryGrade{
+			CategoryID:   category.ID,
+			Name:         category.Name,
+			ActivityType: category.ActivityType,
+			Weight:       category.Weight,
+		}
+
+		// Kumpulkan nilai kategori yang sudah dinilai, dari persentase terendah
+		var graded []int
+		for i, entry := range grade.Entries {
+			if entry.ActivityType == category.ActivityType && entry.Percent != nil {
+				graded = append(graded, i)
+			}
+		}
+		sort.SliceStable(graded, func(a, b int) bool {
+			return *grade.Entries[graded[a]].Percent < *grade.Entries[graded[b]].Percent
+		})
+
+		// Selalu sisakan setidaknya satu nilai
+		drop := category.DropLowest
+		if drop > len(graded)-1 {
+			drop = len(graded) - 1
+		}
+		if drop < 0 {
+			drop = 0
+		}
+
+		var earned, possible float64
+		for rank, index := range graded {
+			if rank < drop {
+				grade.Entries[index].Dropped = true
+				continue
+			}
+			earned += *grade.Entries[index].Score
+			possible += *grade.Entries[index].MaxScore
+		}
+
+		categoryGrade.GradedCount = len(graded)
+		categoryGrade.DroppedCount = drop
+		if possible > 0 {
+			percent := earned / possible * 100
+			categoryGrade.Percent = &percent
+			weighted += percent * category.Weight
+			totalWeight += category.Weight
+		}
+
+		grade.Categories = append(grade.Categories, categoryGrade)
+	}
+
+	// Bobot dinormalisasi terhadap kategori yang sudah memiliki nilai
+	if totalWeight > 0 {
+		final := weighted / totalWeight
+		grade.FinalPercent = &final
+	}
+	grade.LetterGrade = letterFor(d.scale, grade.FinalPercent)
+
+	return grade
+}
+
+// newGradebookEntry membuat entri nilai beserta persentasenya
+func newGradebookEntry(activityType models.ProgressType, activityID uint, score, maxScore float64, feedback, source string) GradebookEntry {
+	percent := score / maxScore * 100
+	return GradebookEntry{
+		ActivityType: activityType,
+		ActivityID:   activityID,
+		Score:        &score,
+		MaxScore:     &maxScore,
+		Percent:      &percent,
+		Feedback:     feedback,
+		Source:       source,
+	}
+}
+
+// letterFor mencari nilai huruf untuk persentase pada skala yang terurut dari batas tertinggi
+func letterFor(scale []models.LetterGrade, percent *float64) string {
+	if percent == nil {
+		return ""
+	}
+	for _, grade := range scale {
+		if *percent >= grade.MinPercent {
+			return grade.Letter
+		}
+	}
+	return ""
+}
+
+// validateGradeCategory memvalidasi kategori nilai
+func validateGradeCategory(category *models.GradeCategory) error {
+	switch category.ActivityType {
+	case models.ProgressTypeAssignment, models.ProgressTypeQuiz, models.ProgressTypeDiscussion, models.ProgressTypeMaterial:
+	default:
+		return errors.New("invalid activity type")
+	}
+	if strings.TrimSpace(category.Name) == "" {
+		return errors.New("category name is required")
+	}
+	if category.Weight <= 0 || category.Weight > 100 {
+		return errors.New("weight must be greater than 0 and at most 100")
+	}
+	if category.DropLowest < 0 {
+		return errors.New("drop lowest cannot be negative")
+	}
+	return nil
+}
+
+// validateLetterGrades memvalidasi dan mengurutkan skala nilai huruf dari batas tertinggi
+func validateLetterGrades(grades []models.LetterGrade) error {
+	letters := make(map[string]bool, len(grades))
+	minimums := make(map[float64]bool, len(grades))
+	hasZero := false
+
+	for _, grade := range grades {
+		letter := strings.TrimSpace(grade.Letter)
+		if letter == "" {
+			return errors.New("letter is required")
+		}
+		if letters[letter] {
+			return errors.New("duplicate letter in grade scale")
+		}
+		letters[letter] = true
+
+		if grade.MinPercent < 0 || grade.MinPercent > 100 {
+			return errors.New("min percent must be between 0 and 100")
+		}
+		if minimums[grade.MinPercent] {
+			return errors.New("duplicate min percent in grade scale")
+		}
+		minimums[grade.MinPercent] = true
+
+		if grade.MinPercent == 0 {
+			hasZero = true
+		}
+	}
+
+	if !hasZero {
+		return errors.New("grade scale must include a letter with min percent 0")
+	}
+
+	sort.Slice(grades, func(i, j int) bool {
+		return grades[i].MinPercent > grades[j].MinPercent
+	})
+
+	return nil
+}
+
+// GradebookImportError adalah satu kesalahan validasi pada baris impor buku nilai
+type GradebookImportError struct {
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// GradebookImportRow adalah hasil impor satu baris buku nilai
+type GradebookImportRow struct {
+	Row       int                    `json:"row"`
+	StudentID uint                   `json:"student_id,omitempty"`
+	Grades    int                    `json:"grades"`
+	Applied   bool                   `json:"applied"`
+	Errors    []GradebookImportError `json:"errors,omitempty"`
+}
+
+// GradebookImportResult adalah ringkasan impor buku nilai
+type GradebookImportResult struct {
+	DryRun        bool                 `json:"dry_run"`
+	TotalRows     int                  `json:"total_rows"`
+	ValidRows     int                  `json:"valid_rows"`
+	InvalidRows   int                  `json:"invalid_rows"`
+	GradesApplied int                  `json:"grades_applied"`
+	Rows          []GradebookImportRow `json:"rows"`
+}
+
+// gradebookImportGrade adalah satu nilai yang siap diterapkan dari baris impor
+type gradebookImportGrade struct {
+	item     GradebookItem
+	score    float64
+	feedback string
+}
+
+// gradebookColumn adalah kolom aktivitas pada berkas buku nilai
+type gradebookColumn struct {
+	item       GradebookItem
+	header     string
+	isFeedback bool
+}
+
+const gradebookStudentIDHeader = "Student ID"
+
+// gradebookColumnPattern mengenali kolom aktivitas, misalnya "Essay [assignment:12]" atau "Essay [assignment:12] Feedback"
+var gradebookColumnPattern = regexp.MustCompile(`(?i)\[(assignment|quiz):([0-9]+)\](\s+feedback)?\s*$`)
+
+// ExportGradebook menyusun buku nilai kursus sebagai baris spreadsheet:
+// satu baris per siswa, satu kolom nilai dan satu kolom umpan balik per tugas dan kuis
+func (s *GradebookService) ExportGradebook(courseID uint) ([][]string, error) {
+	gradebook, err := s.GetCourseGradebook(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []GradebookItem
+	header := []string{gradebookStudentIDHeader, "Student Name", "Student Email"}
+	for _, item := range gradebook.Items {
+		if item.ActivityType != models.ProgressTypeAssignment && item.ActivityType != models.ProgressTypeQuiz {
+			continue
+		}
+		items = append(items, item)
+		label := fmt.Sprintf("%s [%s:%d]", item.Title, item.ActivityType, item.ActivityID)
+		header = append(header, label, label+" Feedback")
+	}
+	header = append(header, "Final Percent", "Letter Grade")
+
+	rows := [][]string{header}
+	for _, student := range gradebook.Students {
+		entries := make(map[gradebookKey]GradebookEntry, len(student.Entries))
+		for _, entry := range student.Entries {
+			entries[gradebookKey{activityType: entry.ActivityType, activityID: entry.ActivityID}] = entry
+		}
+
+		row := []string{strconv.FormatUint(uint64(student.StudentID), 10), student.StudentName, student.StudentEmail}
+		for _, item := range items {
+			entry := entries[gradebookKey{activityType: item.ActivityType, activityID: item.ActivityID}]
+			score := ""
+			if entry.Score != nil {
+				score = strconv.FormatFloat(*entry.Score, 'f', -1, 64)
+			}
+			row = append(row, score, entry.Feedback)
+		}
+
+		finalPercent := ""
+		if student.FinalPercent != nil {
+			finalPercent = strconv.FormatFloat(*student.FinalPercent, 'f', 2, 64)
+		}
+		row = append(row, finalPercent, student.LetterGrade)
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ImportGradebook menerapkan nilai dan umpan balik dari baris spreadsheet melalui CreateOrUpdateGrade.
+// Baris yang memiliki kesalahan dilewati seluruhnya; dengan dryRun tidak ada nilai yang disimpan.
+func (s *GradebookService) ImportGradebook(courseID, graderID uint, rows [][]string, dryRun bool) (*GradebookImportResult, error) {
+	if len(rows) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	data, err := s.load(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, studentColumn, err := parseGradebookHeader(rows[0], data.items)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := s.EnrollmentRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	students := make(map[uint]bool, len(enrollments))
+	for _, enrollment := range enrollments {
+		if enrollment.User.Role == models.RoleStudent {
+			students[enrollment.UserID] = true
+		}
+	}
+
+	result := &GradebookImportResult{DryRun: dryRun, Rows: []GradebookImportRow{}}
+	seen := make(map[uint]int)
+
+	for index, record := range rows[1:] {
+		// Nomor baris mengikuti berkas, dengan header sebagai baris 1
+		rowNumber := index + 2
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row := GradebookImportRow{Row: rowNumber}
+		grades := s.validateImportRow(record, studentColumn, columns, students, seen, &row)
+
+		result.TotalRows++
+		if len(row.Errors) > 0 {
+			result.InvalidRows++
+			result.Rows = append(result.Rows, row)
+			continue
+		}
+		result.ValidRows++
+
+		if !dryRun {
+			for _, grade := range grades {
+				err := s.ProgressService.CreateOrUpdateGrade(
+					graderID,
+					row.StudentID,
+					courseID,
+					grade.item.ActivityType,
+					grade.item.ActivityID,
+					grade.score,
+					*grade.item.MaxScore,
+					grade.feedback,
+					true,
+				)
+				if err != nil {
+					row.Errors = append(row.Errors, GradebookImportError{
+						Column:  fmt.Sprintf("%s:%d", grade.item.ActivityType, grade.item.ActivityID),
+						Message: err.Error(),
+					})
+					continue
+				}
+				result.GradesApplied++
+			}
+			row.Applied = len(row.Errors) == 0
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+// validateImportRow memvalidasi satu baris impor dan mengembalikan nilai yang akan diterapkan
+func (s *GradebookService) validateImportRow(
+	record []string,
+	studentColumn int,
+	columns map[int]gradebookColumn,
+	students map[uint]bool,
+	seen map[uint]int,
+	row *GradebookImportRow,
+) []gradebookImportGrade {
+	studentID, err := strconv.ParseUint(strings.TrimSpace(recordCell(record, studentColumn)), 10, 32)
+	if err != nil {
+		row.Errors = append(row.Errors, GradebookImportError{Column: gradebookStudentIDHeader, Message: "invalid student ID"})
+		return nil
+	}
+	row.StudentID = uint(studentID)
+
+	if !students[row.StudentID] {
+		row.Errors = append(row.Errors, GradebookImportError{Column: gradebookStudentIDHeader, Message: "student is not enrolled in this course"})
+	}
+	if previousRow, ok := seen[row.StudentID]; ok {
+		row.Errors = append(row.Errors, GradebookImportError{
+			Column:  gradebookStudentIDHeader,
+			Message: fmt.Sprintf("student already appears in row %d", previousRow),
+		})
+	} else {
+		seen[row.StudentID] = row.Row
+	}
+
+	// Kumpulkan nilai dan umpan balik per aktivitas
+	scores := make(map[gradebookKey]*gradebookImportGrade)
+	var order []gradebookKey
+	indexes := make([]int, 0, len(columns))
+	for index := range columns {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		column := columns[index]
+		value := strings.TrimSpace(recordCell(record, index))
+		if value == "" {
+			continue
+		}
+
+		key := gradebookKey{activityType: column.item.ActivityType, activityID: column.item.ActivityID}
+		grade, ok := scores[key]
+		if !ok {
+			grade = &gradebookImportGrade{item: column.item, score: -1}
+			scores[key] = grade
+			order = append(order, key)
+		}
+
+		if column.isFeedback {
+			grade.feedback = value
+			continue
+		}
+
+		score, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(score) || math.IsInf(score, 0) {
+			row.Errors = append(row.Errors, GradebookImportError{Column: column.header, Message: "score must be a number"})
+			continue
+		}
+		if column.item.MaxScore == nil || *column.item.MaxScore <= 0 {
+			row.Errors = append(row.Errors, GradebookImportError{Column: column.header, Message: "activity has no max score"})
+			continue
+		}
+		if score < 0 || score > *column.item.MaxScore {
+			row.Errors = append(row.Errors, GradebookImportError{
+				Column:  column.header,
+				Message: fmt.Sprintf("score must be between 0 and %s", strconv.FormatFloat(*column.item.MaxScore, 'f', -1, 64)),
+			})
+			continue
+		}
+		grade.score = score
+	}
+
+	grades := make([]gradebookImportGrade, 0, len(order))
+	for _, key := range order {
+		grade := scores[key]
+		if grade.score < 0 {
+			// Umpan balik tanpa nilai, atau nilai yang tidak valid dan sudah dilaporkan
+			if grade.feedback != "" && !hasColumnError(row.Errors, grade.item) {
+				row.Errors = append(row.Errors, GradebookImportError{
+					Column:  fmt.Sprintf("%s [%s:%d] Feedback", grade.item.Title, grade.item.ActivityType, grade.item.ActivityID),
+					Message: "feedback requires a score",
+				})
+			}
+			continue
+		}
+		grades = append(grades, *grade)
+	}
+	row.Grades = len(grades)
+
+	return grades
+}
+
+// parseGradebookHeader memetakan kolom berkas ke aktivitas kursus
+func parseGradebookHeader(header []string, items []GradebookItem) (map[int]gradebookColumn, int, error) {
+	itemsByKey := make(map[gradebookKey]GradebookItem, len(items))
+	for _, item := range items {
+		itemsByKey[gradebookKey{activityType: item.ActivityType, activityID: item.ActivityID}] = item
+	}
+
+	studentColumn := -1
+	columns := make(map[int]gradebookColumn)
+	for index, name := range header {
+		name = strings.TrimSpace(name)
+		if strings.EqualFold(name, gradebookStudentIDHeader) {
+			studentColumn = index
+			continue
+		}
+
+		match := gradebookColumnPattern.FindStringSubmatch(name)
+		if match == nil {
+			// Kolom lain seperti nama, email, dan nilai akhir hanya informatif
+			continue
+		}
+
+		activityID, err := strconv.ParseUint(match[2], 10, 32)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid activity column %q", name)
+		}
+		item, ok := itemsByKey[gradebookKey{activityType: models.ProgressType(strings.ToLower(match[1])), activityID: uint(activityID)}]
+		if !ok {
+			return nil, 0, fmt.Errorf("column %q does not match an activity in this course", name)
+		}
+
+		columns[index] = gradebookColumn{item: item, header: name, isFeedback: match[3] != ""}
+	}
+
+	if studentColumn < 0 {
+		return nil, 0, errors.New("missing Student ID column")
+	}
+	if len(columns) == 0 {
+		return nil, 0, errors.New("no assignment or quiz columns found")
+	}
+
+	return columns, studentColumn, nil
+}
+
+// hasColumnError memeriksa apakah aktivitas sudah memiliki kesalahan pada baris
+func hasColumnError(rowErrors []GradebookImportError, item GradebookItem) bool {
+	marker := fmt.Sprintf("[%s:%d]", item.ActivityType, item.ActivityID)
+	for _, rowError := range rowErrors {
+		if strings.Contains(strings.ToLower(rowError.Column), marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordCell mengembalikan isi sel atau string kosong jika baris lebih pendek
+func recordCell(record []string, index int) string {
+	if index < len(record) {
+		return record[index]
+	}
+	return ""
+}
+
+// isBlankRecord memeriksa apakah seluruh sel baris kosong
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
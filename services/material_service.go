@@ -1,42 +1,122 @@
 package services
 
 import (
+	"LMS/events"
 	"LMS/models"
 	"LMS/repositories"
+	"LMS/storage"
+	"LMS/utils"
 	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/url"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 )
 
+// Batas isi materi
+const (
+	maxBundleFiles       = 50
+	maxPageContentLength = 1 << 20
+)
+
+// ErrMaterialHasNoFile dikembalikan saat berkas diminta dari materi yang tidak berupa berkas
+var ErrMaterialHasNoFile = errors.New("material has no file")
+
 // MaterialService menangani logika bisnis material
 type MaterialService struct {
-	MaterialRepo *repositories.MaterialRepository
-	CourseRepo   *repositories.CourseRepository
+	MaterialRepo        *repositories.MaterialRepository
+	CourseRepo          *repositories.CourseRepository
+	BlobService         *BlobService
+	AvailabilityService *AvailabilityService
+	ProgressService     *LearningProgressService
+	Events              *events.Bus
+	Limits              UploadLimits
 }
 
 // NewMaterialService membuat layanan material baru
-func NewMaterialService(materialRepo *repositories.MaterialRepository, courseRepo *repositories.CourseRepository) *MaterialService {
+func NewMaterialService(
+	materialRepo *repositories.MaterialRepository,
+	courseRepo *repositories.CourseRepository,
+	blobService *BlobService,
+	availabilityService *AvailabilityService,
+	progressService *LearningProgressService,
+	bus *events.Bus,
+	limits UploadLimits,
+) *MaterialService {
 	return &MaterialService{
-		MaterialRepo: materialRepo,
-		CourseRepo:   courseRepo,
+		MaterialRepo:        materialRepo,
+		CourseRepo:          courseRepo,
+		BlobService:         blobService,
+		AvailabilityService: availabilityService,
+		ProgressService:     progressService,
+		Events:              bus,
+		Limits:              limits,
+	}
+}
+
+// CreateMaterial membuat materi baru sesuai jenisnya; headers berisi berkas untuk materi berkas dan bundel
+func (s *MaterialService) CreateMaterial(material *models.Material, headers []*multipart.FileHeader) error {
+	files, err := openUploads(headers)
+	if err != nil {
+		return err
 	}
+	defer closeUploads(files)
+
+	return s.createMaterial(material, files, s.Limits.MaxMaterialSize)
 }
 
-// CreateMaterial membuat materi baru
-func (s *MaterialService) CreateMaterial(material *models.Material, filePath string) error {
+// createMaterial memvalidasi isi dan berkas yang sudah dibuka lalu membuat materinya
+func (s *MaterialService) createMaterial(material *models.Material, files []*uploadedFile, maxSize int64) error {
+	if material.Type == "" {
+		material.Type = models.MaterialTypeFile
+	}
+
 	// Verifikasi keberadaan kursus
-	_, err := s.CourseRepo.FindByID(material.CourseID)
+	course, err := s.CourseRepo.FindByID(material.CourseID)
 	if err != nil {
 		return errors.New("course not found")
 	}
 
-	// Mengatur jalur file dan tanggal pengunggahan
-	material.FilePath = filePath
+	if err := checkFileCount(material.Type, len(files), 0, true); err != nil {
+		return err
+	}
+	if err := validateAvailability(material.Availability); err != nil {
+		return err
+	}
+	if err := prepareContent(material); err != nil {
+		return err
+	}
+
+	// Simpan berkas ke penyimpanan; isi yang sama dipakai bersama
+	stored, err := s.storeFiles(course, files, maxSize, 0)
+	if err != nil {
+		return err
+	}
+	switch material.Type {
+	case models.MaterialTypeFile:
+		applyFile(material, stored[0])
+	case models.MaterialTypeBundle:
+		material.Files = stored
+	}
 	material.UploadedAt = time.Now()
 
-	// Buat materi
-	return s.MaterialRepo.Create(material)
+	// Buat materi; lepaskan berkas jika gagal agar tidak tertinggal
+	if err := s.MaterialRepo.Create(material); err != nil {
+		s.releaseFiles(stored)
+		return err
+	}
+
+	s.Events.Publish(events.Event{
+		Type:       events.MaterialCreated,
+		CourseID:   material.CourseID,
+		ResourceID: material.ID,
+	})
+	return nil
 }
 
 // GetMaterialByID mendapatkan material dengan ID
@@ -49,28 +129,425 @@ func (s *MaterialService) GetMaterialsByCourse(courseID uint) ([]models.Material
 	return s.MaterialRepo.FindByCourse(courseID)
 }
 
-// UpdateMaterial memperbarui materi
-func (s *MaterialService) UpdateMaterial(material *models.Material, filePath string) error {
+// GetMaterialForStudent mendapatkan materi untuk siswa; isi materi yang terkunci disembunyikan
+func (s *MaterialService) GetMaterialForStudent(id, studentID uint) (*models.Material, error) {
+	material, err := s.MaterialRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := s.AvailabilityService.ForStudent(material.CourseID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	availability.LockMaterial(material)
+	return material, nil
+}
+
+// GetMaterialsByCourseForStudent mendapatkan materi kursus untuk siswa beserta kunci masing-masing
+func (s *MaterialService) GetMaterialsByCourseForStudent(courseID, studentID uint) ([]models.Material, error) {
+	materials, err := s.MaterialRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := s.AvailabilityService.ForStudent(courseID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range materials {
+		availability.LockMaterial(&materials[i])
+	}
+	return materials, nil
+}
+
+// CheckAccess mengembalikan ContentLockedError jika materi masih terkunci bagi siswa
+func (s *MaterialService) CheckAccess(material *models.Material, studentID uint) error {
+	return s.AvailabilityService.CheckAccess(material.CourseID, studentID, models.ProgressTypeMaterial, material.ID, material.Availability)
+}
+
+// RecordView mencatat tayangan materi yang dilaporkan siswa, termasuk lama membuka dan persentase tontonan
+func (s *MaterialService) RecordView(material *models.Material, studentID uint, secondsSpent int, watchPercent *float64) (*models.LearningProgress, error) {
+	if err := s.CheckAccess(material, studentID); err != nil {
+		return nil, err
+	}
+	return s.ProgressService.RecordMaterialAccess(studentID, material, MaterialAccess{
+		SecondsSpent: secondsSpent,
+		WatchPercent: watchPercent,
+	})
+}
+
+// RecordDownload mencatat unduhan materi oleh siswa. Kegagalan pencatatan tidak menggagalkan unduhan.
+func (s *MaterialService) RecordDownload(material *models.Material, studentID uint) {
+	if _, err := s.ProgressService.RecordMaterialAccess(studentID, material, MaterialAccess{Download: true}); err != nil {
+		log.Printf("failed to record download of material %d by user %d: %v", material.ID, studentID, err)
+	}
+}
+
+// UpdateMaterial memperbarui materi. Berkas baru mengganti berkas materi berkas atau ditambahkan
+// ke materi bundel; isi halaman dan URL hanya diganti jika diisi.
+func (s *MaterialService) UpdateMaterial(material *models.Material, headers []*multipart.FileHeader) error {
+	files, err := openUploads(headers)
+	if err != nil {
+		return err
+	}
+	defer closeUploads(files)
+
+	return s.updateMaterial(material, files, s.Limits.MaxMaterialSize)
+}
+
+// updateMaterial memperbarui materi dengan berkas yang sudah dibuka
+func (s *MaterialService) updateMaterial(material *models.Material, files []*uploadedFile, maxSize int64) error {
 	// Verifikasi materi yang ada
 	existingMaterial, err := s.MaterialRepo.FindByID(material.ID)
 	if err != nil {
 		return err
 	}
+	if material.Type != "" && material.Type != existingMaterial.Type {
+		return errors.New("material type cannot be changed")
+	}
+	if err := checkFileCount(existingMaterial.Type, len(files), len(existingMaterial.Files), false); err != nil {
+		return err
+	}
+	if err := validateAvailability(material.Availability); err != nil {
+		return err
+	}
 
 	// Perbarui hanya bidang yang diizinkan
 	existingMaterial.Title = material.Title
+	existingMaterial.Availability = material.Availability
+	if material.Content != "" {
+		existingMaterial.Content = material.Content
+	}
+	if material.ContentFormat != "" {
+		existingMaterial.ContentFormat = material.ContentFormat
+	}
+	if material.URL != "" {
+		existingMaterial.URL = material.URL
+	}
+	if err := prepareContent(existingMaterial); err != nil {
+		return err
+	}
 
-	// Jika file baru disediakan, perbarui jalur file
-	if filePath != "" {
-		existingMaterial.FilePath = filePath
+	// Jika berkas baru disediakan, simpan lalu ganti atau tambahkan
+	var stored []models.MaterialFile
+	oldHash, oldPath := existingMaterial.FileHash, existingMaterial.FilePath
+	if len(files) > 0 {
+		course, err := s.CourseRepo.FindByID(existingMaterial.CourseID)
+		if err != nil {
+			return errors.New("course not found")
+		}
+
+		stored, err = s.storeFiles(course, files, maxSize, nextFilePosition(existingMaterial.Files))
+		if err != nil {
+			return err
+		}
+		if existingMaterial.Type == models.MaterialTypeFile {
+			applyFile(existingMaterial, stored[0])
+		}
 	}
 
-	return s.MaterialRepo.Update(existingMaterial)
+	if existingMaterial.Type == models.MaterialTypeBundle && len(stored) > 0 {
+		for i := range stored {
+			stored[i].MaterialID = existingMaterial.ID
+		}
+		err = s.MaterialRepo.UpdateWithFiles(existingMaterial, stored)
+		existingMaterial.Files = append(existingMaterial.Files, stored...)
+	} else {
+		err = s.MaterialRepo.Update(existingMaterial)
+	}
+	if err != nil {
+		s.releaseFiles(stored)
+		return err
+	}
+
+	if existingMaterial.Type == models.MaterialTypeFile && len(stored) > 0 {
+		s.BlobService.Release(oldHash, oldPath)
+	}
+	*material = *existingMaterial
+	return nil
 }
 
-// DeleteMaterial menghapus material
+// DeleteMaterial menghapus material beserta berkasnya
 func (s *MaterialService) DeleteMaterial(id uint) error {
-	return s.MaterialRepo.Delete(id)
+	material, err := s.MaterialRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.MaterialRepo.Delete(id); err != nil {
+		return err
+	}
+
+	if material.FilePath != "" {
+		s.BlobService.Release(material.FileHash, material.FilePath)
+	}
+	s.releaseFiles(material.Files)
+	return nil
+}
+
+// DeleteMaterialFile menghapus satu berkas dari materi bundel
+func (s *MaterialService) DeleteMaterialFile(materialID, fileID uint) error {
+	material, err := s.MaterialRepo.FindByID(materialID)
+	if err != nil {
+		return err
+	}
+
+	file, err := findMaterialFile(material, fileID)
+	if err != nil {
+		return err
+	}
+	if len(material.Files) == 1 {
+		return errors.New("a bundle must keep at least one file")
+	}
+
+	if err := s.MaterialRepo.DeleteFile(file.ID); err != nil {
+		return err
+	}
+	s.BlobService.Release(file.FileHash, file.FilePath)
+	return nil
+}
+
+// OpenMaterialStream membuka berkas materi untuk diputar dengan permintaan Range
+func (s *MaterialService) OpenMaterialStream(material *models.Material) (*storage.ReadSeeker, *storage.Object, error) {
+	if material.Type != models.MaterialTypeFile || material.FilePath == "" {
+		return nil, nil, ErrMaterialHasNoFile
+	}
+	return s.BlobService.OpenSeekable(material.FileHash, material.FilePath)
+}
+
+// OpenMaterialFileStream membuka satu berkas materi bundel
+func (s *MaterialService) OpenMaterialFileStream(material *models.Material, fileID uint) (*models.MaterialFile, *storage.ReadSeeker, *storage.Object, error) {
+	file, err := findMaterialFile(material, fileID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	content, object, err := s.BlobService.OpenSeekable(file.FileHash, file.FilePath)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return file, content, object, nil
+}
+
+// Parameter URL unduhan sementara yang menandai unduhan materi oleh siswa
+const (
+	downloadMaterialParam = "material"
+	downloadStudentParam  = "student"
+)
+
+// GetMaterialDownloadURL membuat URL unduhan sementara untuk berkas materi. Untuk siswa (studentID bukan 0)
+// URL penyimpanan lokal membawa materi dan siswa sehingga unduhan dicatat saat URL dibuka. URL S3 diunduh
+// langsung dari bucket, sehingga unduhannya dicatat saat URL diterbitkan.
+func (s *MaterialService) GetMaterialDownloadURL(material *models.Material, studentID uint) (string, error) {
+	if material.Type != models.MaterialTypeFile || material.FilePath == "" {
+		return "", ErrMaterialHasNoFile
+	}
+
+	var params url.Values
+	if studentID != 0 {
+		params = url.Values{}
+		params.Set(downloadMaterialParam, strconv.FormatUint(uint64(material.ID), 10))
+		params.Set(downloadStudentParam, strconv.FormatUint(uint64(studentID), 10))
+	}
+
+	downloadURL, err := s.BlobService.PresignedURLWithParams(material.FileHash, material.FilePath, params)
+	if err != nil {
+		return "", err
+	}
+	if studentID != 0 && !s.BlobService.ServesSignedURLs() {
+		s.RecordDownload(material, studentID)
+	}
+	return downloadURL, nil
+}
+
+// RecordSignedDownload mencatat unduhan materi dari URL sementara yang tanda tangannya sudah diperiksa;
+// URL tanpa materi dan siswa, misalnya milik mentor atau kiriman, diabaikan
+func (s *MaterialService) RecordSignedDownload(params url.Values) {
+	materialID, err := strconv.ParseUint(params.Get(downloadMaterialParam), 10, 32)
+	if err != nil {
+		return
+	}
+	studentID, err := strconv.ParseUint(params.Get(downloadStudentParam), 10, 32)
+	if err != nil {
+		return
+	}
+
+	material, err := s.MaterialRepo.FindByID(uint(materialID))
+	if err != nil {
+		log.Printf("failed to record download of material %d by user %d: %v", materialID, studentID, err)
+		return
+	}
+	s.RecordDownload(material, uint(studentID))
+}
+
+// validateFile memeriksa berkas materi terhadap batas aplikasi dan jenis berkas yang diizinkan kursus
+func (s *MaterialService) validateFile(course *models.Course, file *uploadedFile, maxSize int64) (*utils.FileType, error) {
+	return validateUpload(file, uploadRules{
+		MaxSize:      maxSize,
+		AllowedTypes: course.AllowedFileTypes,
+	}, s.Limits)
+}
+
+// storeFiles memvalidasi semua berkas lebih dulu lalu menyimpannya; jika satu gagal disimpan,
+// berkas yang sudah tersimpan dilepaskan kembali
+func (s *MaterialService) storeFiles(course *models.Course, files []*uploadedFile, maxSize int64, position int) ([]models.MaterialFile, error) {
+	fileTypes := make([]*utils.FileType, len(files))
+	for i, file := range files {
+		fileType, err := s.validateFile(course, file, maxSize)
+		if err != nil {
+			// Tandai berkas yang bermasalah jika ada beberapa berkas
+			var validationErr *UploadValidationError
+			if len(files) > 1 && errors.As(err, &validationErr) {
+				for j := range validationErr.Errors {
+					validationErr.Errors[j].Field = fmt.Sprintf("files[%d]", i)
+				}
+			}
+			return nil, err
+		}
+		fileTypes[i] = fileType
+	}
+
+	stored := make([]models.MaterialFile, 0, len(files))
+	for i, file := range files {
+		blob, err := s.BlobService.Store(file.Reader, fileTypes[i].MIME)
+		if err != nil {
+			s.releaseFiles(stored)
+			return nil, errors.New("failed to save file")
+		}
+		stored = append(stored, models.MaterialFile{
+			Position:     position + i,
+			FilePath:     models.BlobKey(blob.Hash),
+			FileHash:     blob.Hash,
+			FileName:     cleanFileName(file.Name),
+			FileSize:     file.Size,
+			ContentType:  fileTypes[i].MIME,
+			StreamFormat: streamFormat(fileTypes[i], file),
+		})
+	}
+	return stored, nil
+}
+
+// releaseFiles melepaskan berkas-berkas yang tersimpan
+func (s *MaterialService) releaseFiles(files []models.MaterialFile) {
+	for _, file := range files {
+		s.BlobService.Release(file.FileHash, file.FilePath)
+	}
+}
+
+// applyFile menjadikan berkas tersimpan sebagai berkas utama materi
+func applyFile(material *models.Material, file models.MaterialFile) {
+	material.FilePath = file.FilePath
+	material.FileHash = file.FileHash
+	material.FileName = file.FileName
+	material.StreamFormat = file.StreamFormat
+}
+
+// checkFileCount memeriksa jumlah berkas yang diunggah untuk jenis materi tertentu
+func checkFileCount(materialType string, count, existing int, creating bool) error {
+	switch materialType {
+	case models.MaterialTypeFile:
+		if creating && count == 0 {
+			return errors.New("file is required")
+		}
+		if count > 1 {
+			return errors.New("a file material accepts exactly one file")
+		}
+	case models.MaterialTypeBundle:
+		if creating && count == 0 {
+			return errors.New("a bundle requires at least one file")
+		}
+		if existing+count > maxBundleFiles {
+			return fmt.Errorf("a bundle can contain at most %d files", maxBundleFiles)
+		}
+	default:
+		if count > 0 {
+			return errors.New("files can only be uploaded to file and bundle materials")
+		}
+	}
+	return nil
+}
+
+// prepareContent memeriksa isi khusus setiap jenis materi, merender halaman menjadi HTML yang aman
+// dan mengosongkan bidang milik jenis lain
+func prepareContent(material *models.Material) error {
+	switch material.Type {
+	case models.MaterialTypeFile, models.MaterialTypeBundle:
+		material.ContentFormat, material.Content, material.ContentHTML = "", "", ""
+		material.URL, material.EmbedURL = "", ""
+
+	case models.MaterialTypePage:
+		if material.ContentFormat == "" {
+			material.ContentFormat = models.ContentFormatMarkdown
+		}
+		if strings.TrimSpace(material.Content) == "" {
+			return errors.New("content is required for page materials")
+		}
+		if len(material.Content) > maxPageContentLength {
+			return fmt.Errorf("content can be at most %s", formatBytes(maxPageContentLength))
+		}
+		switch material.ContentFormat {
+		case models.ContentFormatMarkdown:
+			material.ContentHTML = utils.SanitizeHTML(utils.RenderMarkdown(material.Content))
+		case models.ContentFormatHTML:
+			material.ContentHTML = utils.SanitizeHTML(material.Content)
+		default:
+			return errors.New("content format must be markdown or html")
+		}
+		material.URL, material.EmbedURL = "", ""
+
+	case models.MaterialTypeLink:
+		material.URL = strings.TrimSpace(material.URL)
+		if !utils.SafeURL(material.URL, false) {
+			return errors.New("url must be an absolute http or https URL")
+		}
+		material.ContentFormat, material.Content, material.ContentHTML = "", "", ""
+		material.EmbedURL = ""
+
+	case models.MaterialTypeVideo:
+		material.URL = strings.TrimSpace(material.URL)
+		embedURL, err := utils.VideoEmbedURL(material.URL)
+		if err != nil {
+			return err
+		}
+		material.EmbedURL = embedURL
+		material.ContentFormat, material.Content, material.ContentHTML = "", "", ""
+
+	default:
+		return errors.New("material type must be one of file, page, link, video or bundle")
+	}
+	return nil
+}
+
+// findMaterialFile mencari berkas bundel di dalam materi
+func findMaterialFile(material *models.Material, fileID uint) (*models.MaterialFile, error) {
+	for i := range material.Files {
+		if material.Files[i].ID == fileID {
+			return &material.Files[i], nil
+		}
+	}
+	return nil, errors.New("material file not found")
+}
+
+// nextFilePosition mengembalikan urutan untuk berkas bundel berikutnya
+func nextFilePosition(files []models.MaterialFile) int {
+	position := 0
+	for _, file := range files {
+		if file.Position >= position {
+			position = file.Position + 1
+		}
+	}
+	return position
+}
+
+// streamFormat menentukan cara berkas materi diputar di peramban: video dan audio diputar langsung,
+// sedangkan ZIP berisi playlist HLS diputar per segmen
+func streamFormat(fileType *utils.FileType, file *uploadedFile) string {
+	switch {
+	case fileType.Name == "zip" && utils.IsHLSPackage(file.Reader, file.Size):
+		return models.StreamFormatHLS
+	case strings.HasPrefix(fileType.MIME, "video/"), strings.HasPrefix(fileType.MIME, "audio/"):
+		return models.StreamFormatProgressive
+	}
+	return ""
 }
 
 // GetFileExtension mendapatkan ekstensi file dari jalur file
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"LMS/storage"
+	"database/sql/driver"
+	"net/url"
+	"strings"
+	"testing"
+
+)
+
+// newDownloadTestService membuat layanan materi di atas basis data palsu dengan siswa 7 terdaftar di kursus 3
+func newDownloadTestService(t *testing.T, store storage.Backend) (*MaterialService, *recordingDB) {
+	db := &recordingDB{tables: map[string][]map[string]driver.Value{
+		"enrollments": {{"id": int64(1), "user_id": int64(7), "course_id": int64(3)}},
+	}}
+	gormDB := newRecordingGorm(t, db)
+
+	progressService := NewLearningProgressService(
+		repositories.NewLearningProgressRepository(gormDB),
+		repositories.NewUserRepository(gormDB),
+		repositories.NewCourseRepository(gormDB),
+		repositories.NewEnrollmentRepository(gormDB),
+		repositories.NewAssignmentRepository(gormDB),
+		DefaultMaterialCompletion(),
+	)
+	service := NewMaterialService(
+		repositories.NewMaterialRepository(gormDB),
+		repositories.NewCourseRepository(gormDB),
+		NewBlobService(repositories.NewBlobRepository(gormDB), store),
+		nil,
+		progressService,
+		nil,
+		DefaultUploadLimits(),
+	)
+	return service, db
+}
+
+// downloadTestMaterial adalah materi berkas milik kursus 3 yang disimpan sebagai blob
+func downloadTestMaterial() *models.Material {
+	hash := strings.Repeat("ab", 32)
+	material := &models.Material{
+		CourseID: 3,
+		Type:     models.MaterialTypeFile,
+		FilePath: models.BlobKey(hash),
+		FileHash: hash,
+	}
+	material.ID = 5
+	return material
+}
+
+func TestGetMaterialDownloadURLRecordsS3Download(t *testing.T) {
+	store, err := storage.NewS3Backend(storage.S3Config{
+		Endpoint:     "http://127.0.0.1:9000",
+		Bucket:       "lms",
+		AccessKey:    "access",
+		SecretKey:    "secret",
+		UsePathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Backend: %v", err)
+	}
+	service, db := newDownloadTestService(t, store)
+
+	link, err := service.GetMaterialDownloadURL(downloadTestMaterial(), 7)
+	if err != nil {
+		t.Fatalf("GetMaterialDownloadURL: %v", err)
+	}
+	if !strings.Contains(link, "X-Amz-Signature=") {
+		t.Errorf("expected a presigned S3 URL, got %s", link)
+	}
+
+	// S3 melayani unduhan sendiri sehingga unduhan dicatat saat URL diterbitkan
+	if len(db.inserts) != 1 || db.inserts[0].table != "learning_progresses" {
+		t.Fatalf("inserts = %+v, want one learning_progresses row", db.inserts)
+	}
+	values := db.inserts[0].values
+	if values["downloads"] != int64(1) || values["completed"] != true || values["user_id"] != int64(7) {
+		t.Errorf("progress = downloads %v, completed %v, user %v", values["downloads"], values["completed"], values["user_id"])
+	}
+}
+
+func TestGetMaterialDownloadURLDefersLocalDownload(t *testing.T) {
+	store, err := storage.NewLocalBackend(t.TempDir(), "http://localhost:8080", []byte("secret"))
+	if err != nil {
+		t.Fatalf("NewLocalBackend: %v", err)
+	}
+	material := downloadTestMaterial()
+	if err := store.Put(material.FilePath, strings.NewReader("pdf"), 3, "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	service, db := newDownloadTestService(t, store)
+
+	link, err := service.GetMaterialDownloadURL(material, 7)
+	if err != nil {
+		t.Fatalf("GetMaterialDownloadURL: %v", err)
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("parse %s: %v", link, err)
+	}
+	if parsed.Query().Get("material") != "5" || parsed.Query().Get("student") != "7" {
+		t.Errorf("local URL must carry the material and student, got %s", link)
+	}
+
+	// Unduhan lokal dicatat oleh FileController saat URL dibuka
+	if len(db.inserts) != 0 {
+		t.Errorf("inserts = %+v, want none before the URL is opened", db.inserts)
+	}
+}
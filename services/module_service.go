@@ -0,0 +1,512 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"sort"
+	"strings"
+
+)
+
+// CourseOutline adalah kerangka kursus: modul berurutan beserta aktivitasnya,
+// ditambah aktivitas yang belum ditempatkan di modul mana pun
+type CourseOutline struct {
+	CourseID       uint                `json:"course_id"`
+	Title          string              `json:"title"`
+	Modules        []models.Module     `json:"modules"`
+	UngroupedItems []models.ModuleItem `json:"ungrouped_items"`
+}
+
+// outlineActivity adalah aktivitas kursus yang dapat ditempatkan di modul
+type outlineActivity struct {
+	title string
+	item  interface{}
+}
+
+// outlineKey mengidentifikasi satu aktivitas kursus
+type outlineKey struct {
+	itemType models.ProgressType
+	itemID   uint
+}
+
+// ModuleService menangani logika bisnis modul kursus
+type ModuleService struct {
+	ModuleRepo          *repositories.ModuleRepository
+	CourseRepo          *repositories.CourseRepository
+	MaterialRepo        *repositories.MaterialRepository
+	AssignmentRepo      *repositories.AssignmentRepository
+	QuizRepo            *repositories.QuizRepository
+	DiscussionRepo      *repositories.DiscussionRepository
+	AvailabilityService *AvailabilityService
+}
+
+// NewModuleService membuat layanan modul baru
+func NewModuleService(
+	moduleRepo *repositories.ModuleRepository,
+	courseRepo *repositories.CourseRepository,
+	materialRepo *repositories.MaterialRepository,
+	assignmentRepo *repositories.AssignmentRepository,
+	quizRepo *repositories.QuizRepository,
+	discussionRepo *repositories.DiscussionRepository,
+	availabilityService *AvailabilityService,
+) *ModuleService {
+	return &ModuleService{
+		ModuleRepo:          moduleRepo,
+		CourseRepo:          courseRepo,
+		MaterialRepo:        materialRepo,
+		AssignmentRepo:      assignmentRepo,
+		QuizRepo:            quizRepo,
+		DiscussionRepo:      discussionRepo,
+		AvailabilityService: availabilityService,
+	}
+}
+
+// CreateModule membuat modul baru di akhir urutan modul kursus
+func (s *ModuleService) CreateModule(module *models.Module) error {
+	if _, err := s.CourseRepo.FindByID(module.CourseID); err != nil {
+		return errors.New("course not found")
+	}
+
+	module.Title = strings.TrimSpace(module.Title)
+	if module.Title == "" {
+		return errors.New("module title is required")
+	}
+	if err := s.validateRules(module, module.CourseID); err != nil {
+		return err
+	}
+
+	position, err := s.ModuleRepo.NextPosition(module.CourseID)
+	if err != nil {
+		return err
+	}
+	module.Position = position
+	module.Items = []models.ModuleItem{}
+
+	return s.ModuleRepo.Create(module)
+}
+
+// GetModuleByID mendapatkan modul beserta aktivitasnya
+func (s *ModuleService) GetModuleByID(id uint) (*models.Module, error) {
+	module, err := s.ModuleRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := s.courseActivities(module.CourseID)
+	if err != nil {
+		return nil, err
+	}
+	module.Items = resolveItems(module.Items, activities)
+	return module, nil
+}
+
+// GetModuleForStudent mendapatkan modul beserta aktivitasnya dengan kunci yang berlaku bagi siswa
+func (s *ModuleService) GetModuleForStudent(id, studentID uint) (*models.Module, error) {
+	module, err := s.GetModuleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	availability, err := s.AvailabilityService.ForStudent(module.CourseID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	module.Lock = availability.ModuleLock(module)
+	lockItems(module.Items, availability)
+	return module, nil
+}
+
+// UpdateModule memperbarui judul dan deskripsi modul
+func (s *ModuleService) UpdateModule(module *models.Module) error {
+	existingModule, err := s.ModuleRepo.FindByID(module.ID)
+	if err != nil {
+		return err
+	}
+
+	module.Title = strings.TrimSpace(module.Title)
+	if module.Title == "" {
+		return errors.New("module title is required")
+	}
+	if err := s.validateRules(module, existingModule.CourseID); err != nil {
+		return err
+	}
+
+	if err := s.ModuleRepo.Update(module); err != nil {
+		return err
+	}
+
+	module.CourseID = existingModule.CourseID
+	module.Position = existingModule.Position
+	module.Items = existingModule.Items
+	return nil
+}
+
+// DeleteModule menghapus modul tanpa menghapus aktivitas di dalamnya
+func (s *ModuleService) DeleteModule(id uint) error {
+	if _, err := s.ModuleRepo.FindByID(id); err != nil {
+		return err
+	}
+	return s.ModuleRepo.Delete(id)
+}
+
+// AddItem menempatkan aktivitas kursus di akhir modul.
+// Aktivitas yang sudah berada di modul lain dipindahkan ke modul ini.
+func (s *ModuleService) AddItem(moduleID uint, itemType models.ProgressType, itemID uint) (*models.ModuleItem, error) {
+	module, err := s.ModuleRepo.FindByID(moduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	courseID, err := s.activityCourseID(itemType, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if courseID != module.CourseID {
+		return nil, errors.New("item does not belong to the module's course")
+	}
+
+	item, err := s.ModuleRepo.FindItem(itemType, itemID)
+	if err != nil {
+		if err.Error() != "module item not found" {
+			return nil, err
+		}
+		item = &models.ModuleItem{ItemType: itemType, ItemID: itemID}
+	} else if item.ModuleID == moduleID {
+		return nil, errors.New("item is already in this module")
+	}
+
+	position, err := s.ModuleRepo.NextItemPosition(moduleID)
+	if err != nil {
+		return nil, err
+	}
+	item.ModuleID = moduleID
+	item.Position = position
+
+	if err := s.ModuleRepo.SaveItem(item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// RemoveItem mengeluarkan aktivitas dari modulnya
+func (s *ModuleService) RemoveItem(moduleID, itemID uint) error {
+	item, err := s.ModuleRepo.FindItemByID(itemID)
+	if err != nil {
+		return err
+	}
+	if item.ModuleID != moduleID {
+		return errors.New("module item not found")
+	}
+	return s.ModuleRepo.DeleteItem(itemID)
+}
+
+// ReorderModules mengatur ulang urutan modul kursus; daftar ID harus memuat semua modul kursus tepat satu kali
+func (s *ModuleService) ReorderModules(courseID uint, moduleIDs []uint) (*CourseOutline, error) {
+	modules, err := s.ModuleRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	existingIDs := make([]uint, len(modules))
+	for i, module := range modules {
+		existingIDs[i] = module.ID
+	}
+	if !samePermutation(existingIDs, moduleIDs) {
+		return nil, errors.New("module_ids must list every module of the course exactly once")
+	}
+
+	if err := s.ModuleRepo.Reorder(courseID, moduleIDs); err != nil {
+		return nil, err
+	}
+	return s.GetCourseOutline(courseID)
+}
+
+// ReorderItems mengatur ulang urutan aktivitas di modul; daftar ID harus memuat semua butir modul tepat satu kali
+func (s *ModuleService) ReorderItems(moduleID uint, itemIDs []uint) (*models.Module, error) {
+	module, err := s.ModuleRepo.FindByID(moduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	existingIDs := make([]uint, len(module.Items))
+	for i, item := range module.Items {
+		existingIDs[i] = item.ID
+	}
+	if !samePermutation(existingIDs, itemIDs) {
+		return nil, errors.New("item_ids must list every item of the module exactly once")
+	}
+
+	if err := s.ModuleRepo.ReorderItems(moduleID, itemIDs); err != nil {
+		return nil, err
+	}
+	return s.GetModuleByID(moduleID)
+}
+
+// GetCourseOutline mendapatkan kerangka kursus lengkap sesuai urutan modul dan aktivitasnya
+func (s *ModuleService) GetCourseOutline(courseID uint) (*CourseOutline, error) {
+	return s.courseOutline(courseID, nil)
+}
+
+// GetCourseOutlineForStudent mendapatkan kerangka kursus dengan kunci modul dan aktivitas yang berlaku bagi siswa
+func (s *ModuleService) GetCourseOutlineForStudent(courseID, studentID uint) (*CourseOutline, error) {
+	availability, err := s.AvailabilityService.ForStudent(courseID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.courseOutline(courseID, availability)
+}
+
+// courseOutline menyusun kerangka kursus; availability bernilai nil untuk mentor dan admin
+func (s *ModuleService) courseOutline(courseID uint, availability *StudentAvailability) (*CourseOutline, error) {
+	course, err := s.CourseRepo.FindByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	modules, ungrouped, err := s.buildOutline(courseID, availability)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CourseOutline{
+		CourseID:       course.ID,
+		Title:          course.Title,
+		Modules:        modules,
+		UngroupedItems: ungrouped,
+	}, nil
+}
+
+// buildOutline menyusun modul berurutan beserta aktivitasnya dan aktivitas yang belum ditempatkan.
+// Jika availability diisi, kunci modul dan aktivitas bagi siswa ikut dihitung.
+func (s *ModuleService) buildOutline(courseID uint, availability *StudentAvailability) ([]models.Module, []models.ModuleItem, error) {
+	modules, err := s.ModuleRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	activities, err := s.courseActivities(courseID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	placed := make(map[outlineKey]bool)
+	for i := range modules {
+		modules[i].Items = resolveItems(modules[i].Items, activities)
+		for _, item := range modules[i].Items {
+			placed[outlineKey{item.ItemType, item.ItemID}] = true
+		}
+	}
+
+	// Aktivitas tanpa modul ditampilkan setelah modul, dikelompokkan per jenis sesuai urutan pembuatannya
+	ungrouped := []models.ModuleItem{}
+	for _, key := range activityOrder(activities) {
+		if placed[key] {
+			continue
+		}
+		activity := activities[key]
+		ungrouped = append(ungrouped, models.ModuleItem{
+			ItemType: key.itemType,
+			ItemID:   key.itemID,
+			Position: len(ungrouped),
+			Title:    activity.title,
+			Item:     activity.item,
+		})
+	}
+
+	if availability != nil {
+		for i := range modules {
+			modules[i].Lock = availability.ModuleLock(&modules[i])
+			lockItems(modules[i].Items, availability)
+		}
+		lockItems(ungrouped, availability)
+	}
+
+	return modules, ungrouped, nil
+}
+
+// validateRules memeriksa aturan ketersediaan modul dan modul prasyaratnya.
+// Prasyarat harus berasal dari kursus yang sama dan tidak boleh membentuk lingkaran.
+func (s *ModuleService) validateRules(module *models.Module, courseID uint) error {
+	if err := validateAvailability(module.Availability); err != nil {
+		return err
+	}
+
+	if module.PrerequisiteModuleID == nil {
+		module.PrerequisiteMinPercent = 0
+		return nil
+	}
+	if module.PrerequisiteMinPercent == 0 {
+		module.PrerequisiteMinPercent = 100
+	}
+	if module.PrerequisiteMinPercent < 0 || module.PrerequisiteMinPercent > 100 {
+		return errors.New("prerequisite_min_percent must be between 0 and 100")
+	}
+
+	// Telusuri rantai prasyarat untuk memastikan modul ini tidak menjadi prasyarat dirinya sendiri
+	visited := make(map[uint]bool)
+	for id := module.PrerequisiteModuleID; id != nil; {
+		if module.ID != 0 && *id == module.ID {
+			return errors.New("module prerequisites cannot form a cycle")
+		}
+		if visited[*id] {
+			break
+		}
+		visited[*id] = true
+
+		prerequisite, err := s.ModuleRepo.FindByID(*id)
+		if err != nil {
+			return errors.New("prerequisite module not found")
+		}
+		if prerequisite.CourseID != courseID {
+			return errors.New("prerequisite module must belong to the same course")
+		}
+		id = prerequisite.PrerequisiteModuleID
+	}
+	return nil
+}
+
+// courseActivities memuat semua materi, tugas, kuis dan diskusi sebuah kursus
+func (s *ModuleService) courseActivities(courseID uint) (map[outlineKey]outlineActivity, error) {
+	activities := make(map[outlineKey]outlineActivity)
+
+	materials, err := s.MaterialRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range materials {
+		activities[outlineKey{models.ProgressTypeMaterial, materials[i].ID}] = outlineActivity{materials[i].Title, &materials[i]}
+	}
+
+	assignments, err := s.AssignmentRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range assignments {
+		activities[outlineKey{models.ProgressTypeAssignment, assignments[i].ID}] = outlineActivity{assignments[i].Title, &assignments[i]}
+	}
+
+	quizzes, err := s.QuizRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range quizzes {
+		activities[outlineKey{models.ProgressTypeQuiz, quizzes[i].ID}] = outlineActivity{quizzes[i].Title, &quizzes[i]}
+	}
+
+	discussions, err := s.DiscussionRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range discussions {
+		activities[outlineKey{models.ProgressTypeDiscussion, discussions[i].ID}] = outlineActivity{discussions[i].Title, &discussions[i]}
+	}
+
+	return activities, nil
+}
+
+// activityCourseID mengembalikan kursus pemilik sebuah aktivitas
+func (s *ModuleService) activityCourseID(itemType models.ProgressType, itemID uint) (uint, error) {
+	switch itemType {
+	case models.ProgressTypeMaterial:
+		material, err := s.MaterialRepo.FindByID(itemID)
+		if err != nil {
+			return 0, err
+		}
+		return material.CourseID, nil
+	case models.ProgressTypeAssignment:
+		assignment, err := s.AssignmentRepo.FindByID(itemID)
+		if err != nil {
+			return 0, err
+		}
+		return assignment.CourseID, nil
+	case models.ProgressTypeQuiz:
+		quiz, err := s.QuizRepo.FindByID(itemID)
+		if err != nil {
+			return 0, err
+		}
+		return quiz.CourseID, nil
+	case models.ProgressTypeDiscussion:
+		discussion, err := s.DiscussionRepo.FindByID(itemID)
+		if err != nil {
+			return 0, err
+		}
+		return discussion.CourseID, nil
+	}
+	return 0, errors.New("invalid item type")
+}
+
+// resolveItems mengisi judul dan isi setiap butir modul; butir yang aktivitasnya sudah tidak ada dilewati
+func resolveItems(items []models.ModuleItem, activities map[outlineKey]outlineActivity) []models.ModuleItem {
+	resolved := make([]models.ModuleItem, 0, len(items))
+	for _, item := range items {
+		activity, ok := activities[outlineKey{item.ItemType, item.ItemID}]
+		if !ok {
+			continue
+		}
+		item.Title = activity.title
+		item.Item = activity.item
+		resolved = append(resolved, item)
+	}
+	return resolved
+}
+
+// lockItems mengisi kunci setiap butir bagi siswa dan menyembunyikan isi aktivitas yang terkunci
+func lockItems(items []models.ModuleItem, availability *StudentAvailability) {
+	for i := range items {
+		switch activity := items[i].Item.(type) {
+		case *models.Material:
+			availability.LockMaterial(activity)
+			items[i].Lock = activity.Lock
+		case *models.Assignment:
+			availability.LockAssignment(activity)
+			items[i].Lock = activity.Lock
+		case *models.Quiz:
+			availability.LockQuiz(activity)
+			items[i].Lock = activity.Lock
+		default:
+			// Diskusi tidak memiliki aturan sendiri dan hanya mengikuti modulnya
+			items[i].Lock = availability.ItemLock(items[i].ItemType, items[i].ItemID, models.Availability{})
+		}
+	}
+}
+
+// activityOrder mengurutkan aktivitas per jenis lalu per ID
+func activityOrder(activities map[outlineKey]outlineActivity) []outlineKey {
+	typeOrder := map[models.ProgressType]int{
+		models.ProgressTypeMaterial:   0,
+		models.ProgressTypeAssignment: 1,
+		models.ProgressTypeQuiz:       2,
+		models.ProgressTypeDiscussion: 3,
+	}
+
+	keys := make([]outlineKey, 0, len(activities))
+	for key := range activities {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].itemType != keys[j].itemType {
+			return typeOrder[keys[i].itemType] < typeOrder[keys[j].itemType]
+		}
+		return keys[i].itemID < keys[j].itemID
+	})
+	return keys
+}
+
+// samePermutation memeriksa apakah daftar ID yang diminta memuat semua ID yang ada tepat satu kali
+func samePermutation(existing, requested []uint) bool {
+	if len(existing) != len(requested) {
+		return false
+	}
+	remaining := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		remaining[id] = true
+	}
+	for _, id := range requested {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+	return true
+}
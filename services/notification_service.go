@@ -0,0 +1,499 @@
+package services
+
+import (
+	"LMS/events"
+	"LMS/models"
+	"LMS/repositories"
+	"LMS/utils"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+)
+
+// Ukuran halaman kotak masuk pemberitahuan
+const (
+	DefaultNotificationPageSize = 20
+	MaxNotificationPageSize     = 100
+)
+
+// Pengingat tenggat dikirim sekali untuk penugasan yang jatuh tempo dalam DueReminderWindow.
+// Penugasan dengan tenggat asli sampai dueReminderLookback yang lalu tetap diperiksa
+// agar siswa dengan perpanjangan tenggat juga diingatkan.
+const (
+	DueReminderWindow   = 24 * time.Hour
+	dueReminderLookback = 14 * 24 * time.Hour
+)
+
+// NotificationService menangani kotak masuk pemberitahuan dan mengubah kejadian menjadi pemberitahuan
+type NotificationService struct {
+	NotificationRepo    *repositories.NotificationRepository
+	UserRepo            *repositories.UserRepository
+	CourseRepo          *repositories.CourseRepository
+	EnrollmentRepo      *repositories.EnrollmentRepository
+	AssessmentRepo      *repositories.AssessmentRepository
+	SubmissionRepo      *repositories.SubmissionRepository
+	AssignmentRepo      *repositories.AssignmentRepository
+	MaterialRepo        *repositories.MaterialRepository
+	DiscussionRepo      *repositories.DiscussionRepository
+	CommentRepo         *repositories.CommentRepository
+	AvailabilityService *AvailabilityService
+	ExtensionService    *ExtensionService
+	EmailService        *EmailService
+}
+
+// NewNotificationService membuat layanan pemberitahuan baru
+func NewNotificationService(
+	notificationRepo *repositories.NotificationRepository,
+	userRepo *repositories.UserRepository,
+	courseRepo *repositories.CourseRepository,
+	enrollmentRepo *repositories.EnrollmentRepository,
+	assessmentRepo *repositories.AssessmentRepository,
+	submissionRepo *repositories.SubmissionRepository,
+	assignmentRepo *repositories.AssignmentRepository,
+	materialRepo *repositories.MaterialRepository,
+	discussionRepo *repositories.DiscussionRepository,
+	commentRepo *repositories.CommentRepository,
+	availabilityService *AvailabilityService,
+	extensionService *ExtensionService,
+) *NotificationService {
+	return &NotificationService{
+		NotificationRepo:    notificationRepo,
+		UserRepo:            userRepo,
+		CourseRepo:          courseRepo,
+		EnrollmentRepo:      enrollmentRepo,
+		AssessmentRepo:      assessmentRepo,
+		SubmissionRepo:      submissionRepo,
+		AssignmentRepo:      assignmentRepo,
+		MaterialRepo:        materialRepo,
+		DiscussionRepo:      discussionRepo,
+		CommentRepo:         commentRepo,
+		AvailabilityService: availabilityService,
+		ExtensionService:    extensionService,
+	}
+}
+
+// GetNotifications mendapatkan pemberitahuan pengguna beserta jumlah yang belum dibaca
+func (s *NotificationService) GetNotifications(userID uint, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	if limit <= 0 {
+		limit = DefaultNotificationPageSize
+	}
+	if limit > MaxNotificationPageSize {
+		limit = MaxNotificationPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	notifications, err := s.NotificationRepo.FindByUser(userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	unread, err := s.NotificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, unread, nil
+}
+
+// CountUnread menghitung pemberitahuan pengguna yang belum dibaca
+func (s *NotificationService) CountUnread(userID uint) (int64, error) {
+	return s.NotificationRepo.CountUnread(userID)
+}
+
+// MarkRead menandai satu pemberitahuan milik pengguna sebagai sudah dibaca.
+// Pemberitahuan milik pengguna lain diperlakukan seolah tidak ada.
+func (s *NotificationService) MarkRead(id, userID uint) (*models.Notification, error) {
+	notification, err := s.NotificationRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if notification.UserID != userID {
+		return nil, errors.New("notification not found")
+	}
+
+	if notification.ReadAt == nil {
+		if err := s.NotificationRepo.MarkRead(id, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.NotificationRepo.FindByID(id)
+}
+
+// MarkAllRead menandai semua pemberitahuan pengguna sebagai sudah dibaca
+func (s *NotificationService) MarkAllRead(userID uint) (int64, error) {
+	return s.NotificationRepo.MarkAllRead(userID)
+}
+
+// HandleEvent mengubah kejadian dari bus menjadi pemberitahuan bagi penerima yang relevan
+func (s *NotificationService) HandleEvent(event events.Event) error {
+	var (
+		notifications []models.Notification
+		err           error
+	)
+
+	switch event.Type {
+	case events.AssessmentCreated, events.AssessmentUpdated:
+		notifications, err = s.assessmentNotifications(event)
+	case events.CommentCreated:
+		notifications, err = s.commentNotifications(event)
+	case events.DiscussionCreated:
+		notifications, err = s.discussionNotifications(event)
+	case events.AssignmentCreated:
+		notifications, err = s.assignmentNotifications(event)
+	case events.MaterialCreated:
+		notifications, err = s.materialNotifications(event)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range notifications {
+		notifications[i].CourseID = event.CourseID
+		if event.ActorID != 0 {
+			actorID := event.ActorID
+			notifications[i].ActorID = &actorID
+		}
+	}
+	return s.deliver(notifications)
+}
+
+// deliver menyimpan pemberitahuan lalu memasukkan emailnya ke antrean.
+// Kegagalan antrean email hanya dicatat karena pemberitahuan di kotak masuk sudah tersimpan.
+func (s *NotificationService) deliver(notifications []models.Notification) error {
+	if err := s.NotificationRepo.CreateBatch(notifications); err != nil {
+		return err
+	}
+	if err := s.EmailService.Enqueue(notifications); err != nil {
+		log.Printf("failed to queue notification emails: %v", err)
+	}
+	return nil
+}
+
+// SendDueReminders mengingatkan siswa yang belum mengumpulkan penugasan yang tenggat efektifnya sudah dekat.
+// Setiap siswa hanya diingatkan sekali per penugasan.
+func (s *NotificationService) SendDueReminders(now time.Time) (int, error) {
+	assignments, err := s.AssignmentRepo.FindDueBetween(now.Add(-dueReminderLookback), now.Add(DueReminderWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	var notifications []models.Notification
+	for i := range assignments {
+		assignment := &assignments[i]
+		students, err := s.studentsWithAccess(assignment.CourseID, models.ProgressTypeAssignment, assignment.ID, assignment.Availability)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, studentID := range students {
+			s.ExtensionService.ApplyToAssignment(assignment, studentID)
+			dueDate := assignment.EffectiveDueDate
+			if dueDate == nil || !dueDate.After(now) || dueDate.After(now.Add(DueReminderWindow)) {
+				continue
+			}
+			if _, err := s.SubmissionRepo.FindByAssignmentAndStudent(assignment.ID, studentID); err == nil {
+				continue
+			}
+			reminded, err := s.NotificationRepo.Exists(studentID, models.NotificationDeadline, "assignment", assignment.ID)
+			if err != nil {
+				return 0, err
+			}
+			if reminded {
+				continue
+			}
+
+			notifications = append(notifications, models.Notification{
+				UserID:       studentID,
+				Type:         models.NotificationDeadline,
+				CourseID:     assignment.CourseID,
+				ResourceType: "assignment",
+				ResourceID:   assignment.ID,
+				Title:        fmt.Sprintf("%q is due soon", assignment.Title),
+				Message:      fmt.Sprintf("Due %s", dueDate.Format("2 Jan 2006 15:04")),
+			})
+		}
+	}
+
+	if err := s.deliver(notifications); err != nil {
+		return 0, err
+	}
+	return len(notifications), nil
+}
+
+// RunDueReminders menjalankan SendDueReminders secara berkala; dipanggil sebagai goroutine
+func (s *NotificationService) RunDueReminders(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := s.SendDueReminders(time.Now()); err != nil {
+			log.Printf("failed to send due date reminders: %v", err)
+		}
+	}
+}
+
+// assessmentNotifications memberi tahu siswa bahwa kirimannya sudah dinilai
+func (s *NotificationService) assessmentNotifications(event events.Event) ([]models.Notification, error) {
+	assessment, err := s.AssessmentRepo.FindByID(event.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	submission, err := s.SubmissionRepo.FindByID(assessment.SubmissionID)
+	if err != nil {
+		return nil, err
+	}
+	assignment, err := s.AssignmentRepo.FindByID(submission.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	title := fmt.Sprintf("Your submission for %q has been graded", assignment.Title)
+	if event.Type == events.AssessmentUpdated {
+		title = fmt.Sprintf("Your grade for %q has been updated", assignment.Title)
+	}
+
+	message := ""
+	if assessment.Score != nil {
+		message = fmt.Sprintf("Score: %d", *assessment.Score)
+		if assignment.MaxScore != nil {
+			message = fmt.Sprintf("Score: %d/%d", *assessment.Score, *assignment.MaxScore)
+		}
+	}
+
+	return []models.Notification{{
+		UserID:       submission.StudentID,
+		Type:         models.NotificationGrade,
+		ResourceType: "assessment",
+		ResourceID:   assessment.ID,
+		Title:        title,
+		Message:      message,
+	}}, nil
+}
+
+// commentNotifications memberi tahu penulis komentar yang dibalas, anggota yang disebut dan penulis diskusi.
+// Setiap penerima hanya mendapat satu pemberitahuan dengan urutan prioritas balasan, sebutan, lalu komentar.
+func (s *NotificationService) commentNotifications(event events.Event) ([]models.Notification, error) {
+	comment, err := s.CommentRepo.FindByID(event.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	discussion, err := s.DiscussionRepo.FindByID(comment.DiscussionID)
+	if err != nil {
+		return nil, err
+	}
+	actorName := s.userName(comment.UserID)
+
+	recipients := make(map[uint]bool)
+	var notifications []models.Notification
+	add := func(userID uint, notificationType models.NotificationType, title string) {
+		if userID == comment.UserID || recipients[userID] {
+			return
+		}
+		recipients[userID] = true
+		notifications = append(notifications, models.Notification{
+			UserID:       userID,
+			Type:         notificationType,
+			ResourceType: "comment",
+			ResourceID:   comment.ID,
+			Title:        title,
+			Message:      excerpt(comment.Content),
+		})
+	}
+
+	if comment.ParentID != nil {
+		if parent, err := s.CommentRepo.FindByID(*comment.ParentID); err == nil {
+			add(parent.UserID, models.NotificationReply, fmt.Sprintf("%s replied to your comment in %q", actorName, discussion.Title))
+		}
+	}
+
+	mentioned, err := s.mentionedMembers(discussion.CourseID, comment.Content)
+	if err != nil {
+		return nil, err
+	}
+	for _, userID := range mentioned {
+		add(userID, models.NotificationMention, fmt.Sprintf("%s mentioned you in %q", actorName, discussion.Title))
+	}
+
+	add(discussion.UserID, models.NotificationComment, fmt.Sprintf("%s commented on your discussion %q", actorName, discussion.Title))
+	return notifications, nil
+}
+
+// discussionNotifications memberi tahu anggota kursus yang disebut di diskusi baru
+func (s *NotificationService) discussionNotifications(event events.Event) ([]models.Notification, error) {
+	discussion, err := s.DiscussionRepo.FindByID(event.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentioned, err := s.mentionedMembers(discussion.CourseID, discussion.Title+"\n"+discussion.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	actorName := s.userName(discussion.UserID)
+	var notifications []models.Notification
+	for _, userID := range mentioned {
+		if userID == discussion.UserID {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID:       userID,
+			Type:         models.NotificationMention,
+			ResourceType: "discussion",
+			ResourceID:   discussion.ID,
+			Title:        fmt.Sprintf("%s mentioned you in %q", actorName, discussion.Title),
+			Message:      excerpt(discussion.Content),
+		})
+	}
+	return notifications, nil
+}
+
+// assignmentNotifications memberi tahu siswa terdaftar tentang tugas baru yang sudah dapat diakses
+func (s *NotificationService) assignmentNotifications(event events.Event) ([]models.Notification, error) {
+	assignment, err := s.AssignmentRepo.FindByID(event.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	course, err := s.CourseRepo.FindByID(assignment.CourseID)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := s.studentsWithAccess(course.ID, models.ProgressTypeAssignment, assignment.ID, assignment.Availability)
+	if err != nil {
+		return nil, err
+	}
+
+	message := ""
+	if assignment.DueDate != nil {
+		message = fmt.Sprintf("Due %s", assignment.DueDate.Format("2 Jan 2006 15:04"))
+	}
+
+	notifications := make([]models.Notification, 0, len(students))
+	for _, userID := range students {
+		notifications = append(notifications, models.Notification{
+			UserID:       userID,
+			Type:         models.NotificationAssignment,
+			ResourceType: "assignment",
+			ResourceID:   assignment.ID,
+			Title:        fmt.Sprintf("New assignment in %s: %s", course.Title, assignment.Title),
+			Message:      message,
+		})
+	}
+	return notifications, nil
+}
+
+// materialNotifications memberi tahu siswa terdaftar tentang materi baru yang sudah dapat diakses
+func (s *NotificationService) materialNotifications(event events.Event) ([]models.Notification, error) {
+	material, err := s.MaterialRepo.FindByID(event.ResourceID)
+	if err != nil {
+		return nil, err
+	}
+	course, err := s.CourseRepo.FindByID(material.CourseID)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := s.studentsWithAccess(course.ID, models.ProgressTypeMaterial, material.ID, material.Availability)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := make([]models.Notification, 0, len(students))
+	for _, userID := range students {
+		notifications = append(notifications, models.Notification{
+			UserID:       userID,
+			Type:         models.NotificationMaterial,
+			ResourceType: "material",
+			ResourceID:   material.ID,
+			Title:        fmt.Sprintf("New material in %s: %s", course.Title, material.Title),
+		})
+	}
+	return notifications, nil
+}
+
+// studentsWithAccess mengembalikan siswa terdaftar yang sudah dapat membuka sebuah item.
+// Siswa yang itemnya masih terkunci tidak diberi tahu agar tidak menerima tautan yang belum bisa dibuka.
+func (s *NotificationService) studentsWithAccess(courseID uint, itemType models.ProgressType, itemID uint, rules models.Availability) ([]uint, error) {
+	enrollments, err := s.EnrollmentRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var students []uint
+	for _, enrollment := range enrollments {
+		if s.AvailabilityService.CheckAccess(courseID, enrollment.UserID, itemType, itemID, rules) != nil {
+			continue
+		}
+		students = append(students, enrollment.UserID)
+	}
+	return students, nil
+}
+
+// mentionedMembers mencocokkan sebutan @nama di dalam teks dengan anggota kursus, yaitu siswa terdaftar dan mentornya
+func (s *NotificationService) mentionedMembers(courseID uint, text string) ([]uint, error) {
+	handles := utils.ParseMentions(text)
+	if len(handles) == 0 {
+		return nil, nil
+	}
+
+	course, err := s.CourseRepo.FindByID(courseID)
+	if err != nil {
+		return nil, err
+	}
+	enrollments, err := s.EnrollmentRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]models.User, 0, len(enrollments)+1)
+	if mentor, err := s.UserRepo.FindByID(course.MentorID); err == nil {
+		members = append(members, *mentor)
+	}
+	for _, enrollment := range enrollments {
+		members = append(members, enrollment.User)
+	}
+
+	wanted := make(map[string]bool, len(handles))
+	for _, handle := range handles {
+		wanted[handle] = true
+	}
+
+	seen := make(map[uint]bool)
+	var userIDs []uint
+	for _, member := range members {
+		if seen[member.ID] {
+			continue
+		}
+		for _, handle := range utils.MentionHandles(member.Name, member.Email) {
+			if wanted[handle] {
+				seen[member.ID] = true
+				userIDs = append(userIDs, member.ID)
+				break
+			}
+		}
+	}
+	return userIDs, nil
+}
+
+// userName mengembalikan nama pengguna untuk judul pemberitahuan
+func (s *NotificationService) userName(userID uint) string {
+	user, err := s.UserRepo.FindByID(userID)
+	if err != nil {
+		return "Someone"
+	}
+	return user.Name
+}
+
+// excerpt memotong teks panjang untuk isi pemberitahuan
+func excerpt(text string) string {
+	const maxLength = 200
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+	return string(runes[:maxLength]) + "…"
+}
@@ -4,6 +4,8 @@ import (
 	"LMS/models"
 	"LMS/repositories"
 	"errors"
+	"fmt"
+	"log"
 	"time"
 
 )
@@ -15,6 +17,10 @@ type LearningProgressService struct {
 	CourseRepo     *repositories.CourseRepository
 	EnrollmentRepo *repositories.EnrollmentRepository
 	AssignmentRepo *repositories.AssignmentRepository
+	Completion     MaterialCompletion
+
+	// CompletionService diisi setelah dibuat karena bergantung pada buku nilai yang memakai layanan ini
+	CompletionService *CompletionService
 }
 
 // NewLearningProgressService membuat layanan kemajuan pembelajaran baru
@@ -24,6 +30,7 @@ func NewLearningProgressService(
 	courseRepo *repositories.CourseRepository,
 	enrollmentRepo *repositories.EnrollmentRepository,
 	assignmentRepo *repositories.AssignmentRepository,
+	completion MaterialCompletion,
 ) *LearningProgressService {
 	return &LearningProgressService{
 		ProgressRepo:   progressRepo,
@@ -31,6 +38,114 @@ func NewLearningProgressService(
 		CourseRepo:     courseRepo,
 		EnrollmentRepo: enrollmentRepo,
 		AssignmentRepo: assignmentRepo,
+		Completion:     completion,
+	}
+}
+
+// MaxReportedSeconds adalah waktu terlama yang dapat dilaporkan dalam satu laporan tayangan materi
+const MaxReportedSeconds = 24 * 60 * 60
+
+// MaterialCompletion adalah ambang untuk menandai materi selesai tanpa campur tangan mentor
+type MaterialCompletion struct {
+	// MinSeconds adalah total waktu membuka materi non-video yang dibutuhkan; 0 berarti cukup sekali dilihat
+	MinSeconds int
+	// MinWatchPercent adalah persentase video atau audio yang harus ditonton
+	MinWatchPercent float64
+}
+
+// DefaultMaterialCompletion mengembalikan ambang penyelesaian materi bawaan
+func DefaultMaterialCompletion() MaterialCompletion {
+	return MaterialCompletion{
+		MinSeconds:      0,
+		MinWatchPercent: 90,
+	}
+}
+
+// Reached memeriksa apakah catatan akses siswa sudah memenuhi ambang penyelesaian materi.
+// Video dan audio dinilai dari persentase tontonan; materi lain selesai saat diunduh atau
+// setelah dibuka cukup lama.
+func (c MaterialCompletion) Reached(material *models.Material, progress *models.LearningProgress) bool {
+	if material.Type == models.MaterialTypeVideo || material.StreamFormat != "" {
+		return progress.WatchPercent >= c.MinWatchPercent
+	}
+	if progress.Downloads > 0 {
+		return true
+	}
+	return progress.Views > 0 && progress.TimeSpentSeconds >= c.MinSeconds
+}
+
+// MaterialAccess adalah satu akses siswa ke materi: unduhan, atau tayangan yang dilaporkan klien
+type MaterialAccess struct {
+	Download     bool
+	SecondsSpent int
+	WatchPercent *float64
+}
+
+// RecordMaterialAccess mencatat akses siswa ke materi dan menandainya selesai begitu ambang terpenuhi
+func (s *LearningProgressService) RecordMaterialAccess(studentID uint, material *models.Material, access MaterialAccess) (*models.LearningProgress, error) {
+	if access.SecondsSpent < 0 || access.SecondsSpent > MaxReportedSeconds {
+		return nil, fmt.Errorf("seconds_spent must be between 0 and %d", MaxReportedSeconds)
+	}
+	if access.WatchPercent != nil && (*access.WatchPercent < 0 || *access.WatchPercent > 100) {
+		return nil, errors.New("watch_percent must be between 0 and 100")
+	}
+
+	// Hanya siswa yang terdaftar yang dicatat kemajuannya
+	if _, err := s.EnrollmentRepo.FindByUserAndCourse(studentID, material.CourseID); err != nil {
+		return nil, errors.New("student is not enrolled in this course")
+	}
+
+	now := time.Now()
+	progress, err := s.ProgressRepo.FindByActivityAndStudent(models.ProgressTypeMaterial, material.ID, studentID, material.CourseID)
+	if err != nil {
+		progress = &models.LearningProgress{
+			UserID:       studentID,
+			CourseID:     material.CourseID,
+			ActivityType: models.ProgressTypeMaterial,
+			ActivityID:   material.ID,
+			CreatedAt:    now,
+		}
+	}
+
+	if access.Download {
+		progress.Downloads++
+	} else {
+		progress.Views++
+	}
+	progress.TimeSpentSeconds += access.SecondsSpent
+	if access.WatchPercent != nil && *access.WatchPercent > progress.WatchPercent {
+		progress.WatchPercent = *access.WatchPercent
+	}
+	progress.LastAccessedAt = &now
+	progress.UpdatedAt = now
+
+	justCompleted := !progress.Completed && s.Completion.Reached(material, progress)
+	if justCompleted {
+		progress.Completed = true
+		progress.CompletedAt = &now
+	}
+
+	if progress.ID == 0 {
+		err = s.ProgressRepo.Create(progress)
+	} else {
+		err = s.ProgressRepo.Update(progress)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if justCompleted {
+		s.refreshCompletion(studentID, material.CourseID)
+	}
+	return progress, nil
+}
+
+// refreshCompletion memeriksa ulang penyelesaian kursus siswa; kegagalannya tidak menggagalkan pencatatan kemajuan
+func (s *LearningProgressService) refreshCompletion(studentID, courseID uint) {
+	if s.CompletionService == nil {
+		return
+	}
+	if err := s.CompletionService.Refresh(studentID, courseID); err != nil {
+		log.Printf("failed to refresh course %d completion for user %d: %v", courseID, studentID, err)
 	}
 }
 
@@ -107,7 +222,7 @@ func (s *LearningProgressService) CreateOrUpdateGrade(
 		existingProgress.Score = &scoreValue
 		existingProgress.MaxScore = &maxScoreValue
 		existingProgress.Feedback = feedback
-		existingProgress.GradedBy = graderId
+		existingProgress.GradedBy = &graderId
 		existingProgress.UpdatedAt = now
 
 		if completed && !existingProgress.Completed {
@@ -115,7 +230,11 @@ func (s *LearningProgressService) CreateOrUpdateGrade(
 			existingProgress.CompletedAt = &now
 		}
 
-		return s.ProgressRepo.Update(existingProgress)
+		if err := s.ProgressRepo.Update(existingProgress); err != nil {
+			return err
+		}
+		s.refreshCompletion(studentID, courseID)
+		return nil
 	}
 	// Buat kemajuan baru
 	progress := &models.LearningProgress{
@@ -126,7 +245,7 @@ func (s *LearningProgressService) CreateOrUpdateGrade(
 		Score:        &scoreValue,
 		MaxScore:     &maxScoreValue,
 		Feedback:     feedback,
-		GradedBy:     graderId,
+		GradedBy:     &graderId,
 		Completed:    completed,
 		CreatedAt:    now,
 		UpdatedAt:    now,
@@ -136,7 +255,11 @@ func (s *LearningProgressService) CreateOrUpdateGrade(
 		progress.CompletedAt = &now
 	}
 
-	return s.ProgressRepo.Create(progress)
+	if err := s.ProgressRepo.Create(progress); err != nil {
+		return err
+	}
+	s.refreshCompletion(studentID, courseID)
+	return nil
 }
 
 // GetProgressByID mendapatkan kemajuan pembelajaran berdasarkan ID
@@ -240,7 +363,7 @@ func (s *LearningProgressService) UpdateGradeByMentor(
 	progress.Score = &scoreValue
 	progress.MaxScore = &maxScoreValue
 	progress.Feedback = feedback
-	progress.GradedBy = mentorID
+	progress.GradedBy = &mentorID
 	progress.UpdatedAt = now
 
 	if completed && !progress.Completed {
@@ -248,5 +371,9 @@ func (s *LearningProgressService) UpdateGradeByMentor(
 		progress.CompletedAt = &now
 	}
 
-	return s.ProgressRepo.Update(progress)
+	if err := s.ProgressRepo.Update(progress); err != nil {
+		return err
+	}
+	s.refreshCompletion(progress.UserID, progress.CourseID)
+	return nil
 }
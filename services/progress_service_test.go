@@ -0,0 +1,201 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+)
+
+// recordingDB adalah basis data palsu yang menjawab kueri dari tabel tetap dan mencatat setiap INSERT dan UPDATE
+type recordingDB struct {
+	mu      sync.Mutex
+	tables  map[string][]map[string]driver.Value
+	inserts []recordedWrite
+	updates []recordedWrite
+}
+
+// recordedWrite adalah satu INSERT atau UPDATE beserta nilai per kolomnya
+type recordedWrite struct {
+	table  string
+	values map[string]driver.Value
+}
+
+func (d *recordingDB) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{db: d}, nil
+}
+func (d *recordingDB) Driver() driver.Driver { return nil }
+
+type recordingConn struct {
+	db *recordingDB
+}
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+// QueryContext mengembalikan baris pertama tabel yang disebut kueri, atau hasil kosong
+func (c *recordingConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	for table, rows := range c.db.tables {
+		if strings.Contains(query, "FROM `"+table+"`") && len(rows) > 0 {
+			return newRecordingRows(rows[0]), nil
+		}
+	}
+	return &recordingRows{}, nil
+}
+
+// ExecContext mencatat nilai kolom setiap INSERT dan UPDATE
+func (c *recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.HasPrefix(query, "UPDATE `") {
+		c.recordUpdate(query, args)
+		return driver.RowsAffected(1), nil
+	}
+	if !strings.HasPrefix(query, "INSERT INTO `") {
+		return driver.RowsAffected(1), nil
+	}
+
+	table := query[len("INSERT INTO `"):]
+	table = table[:strings.Index(table, "`")]
+	columnList := query[strings.Index(query, "(")+1 : strings.Index(query, ")")]
+
+	values := make(map[string]driver.Value)
+	for i, column := range strings.Split(columnList, ",") {
+		values[strings.Trim(column, "` ")] = args[i].Value
+	}
+
+	c.db.mu.Lock()
+	c.db.inserts = append(c.db.inserts, recordedWrite{table: table, values: values})
+	id := int64(len(c.db.inserts))
+	c.db.mu.Unlock()
+	return recordingResult(id), nil
+}
+
+// recordUpdate mencatat nilai kolom SET sebuah UPDATE; nilai kondisi WHERE tidak dicatat
+func (c *recordingConn) recordUpdate(query string, args []driver.NamedValue) {
+	table := query[len("UPDATE `"):]
+	table = table[:strings.Index(table, "`")]
+	assignments := query[strings.Index(query, " SET ")+len(" SET "):]
+	if end := strings.Index(assignments, " WHERE "); end >= 0 {
+		assignments = assignments[:end]
+	}
+
+	values := make(map[string]driver.Value)
+	for i, assignment := range strings.Split(assignments, ",") {
+		column := assignment[:strings.Index(assignment, "=")]
+		values[strings.Trim(column, "` ")] = args[i].Value
+	}
+
+	c.db.mu.Lock()
+	c.db.updates = append(c.db.updates, recordedWrite{table: table, values: values})
+	c.db.mu.Unlock()
+}
+
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+type recordingResult int64
+
+func (r recordingResult) LastInsertId() (int64, error) { return int64(r), nil }
+func (r recordingResult) RowsAffected() (int64, error) { return 1, nil }
+
+type recordingRows struct {
+	columns []string
+	row     []driver.Value
+	done    bool
+}
+
+func newRecordingRows(row map[string]driver.Value) *recordingRows {
+	rows := &recordingRows{}
+	for column, value := range row {
+		rows.columns = append(rows.columns, column)
+		rows.row = append(rows.row, value)
+	}
+	return rows
+}
+
+func (r *recordingRows) Columns() []string { return r.columns }
+func (r *recordingRows) Close() error      { return nil }
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if r.done || r.row == nil {
+		return io.EOF
+	}
+	copy(dest, r.row)
+	r.done = true
+	return nil
+}
+
+// newRecordingGorm membuka gorm dengan dialek MySQL di atas basis data palsu
+func newRecordingGorm(t *testing.T, db *recordingDB) *gorm.DB {
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sql.OpenDB(db),
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open gorm: %v", err)
+	}
+	return gormDB
+}
+
+func TestRecordMaterialAccessLeavesGraderEmpty(t *testing.T) {
+	db := &recordingDB{tables: map[string][]map[string]driver.Value{
+		"enrollments": {{"id": int64(1), "user_id": int64(7), "course_id": int64(3)}},
+	}}
+	gormDB := newRecordingGorm(t, db)
+
+	service := NewLearningProgressService(
+		repositories.NewLearningProgressRepository(gormDB),
+		repositories.NewUserRepository(gormDB),
+		repositories.NewCourseRepository(gormDB),
+		repositories.NewEnrollmentRepository(gormDB),
+		repositories.NewAssignmentRepository(gormDB),
+		DefaultMaterialCompletion(),
+	)
+
+	material := &models.Material{CourseID: 3, Type: models.MaterialTypeFile}
+	material.ID = 5
+
+	progress, err := service.RecordMaterialAccess(7, material, MaterialAccess{SecondsSpent: 30})
+	if err != nil {
+		t.Fatalf("RecordMaterialAccess: %v", err)
+	}
+	if progress.GradedBy != nil {
+		t.Errorf("GradedBy = %d, want nil for a student's own view", *progress.GradedBy)
+	}
+	if progress.Views != 1 || progress.TimeSpentSeconds != 30 || !progress.Completed {
+		t.Errorf("progress = views %d, seconds %d, completed %v", progress.Views, progress.TimeSpentSeconds, progress.Completed)
+	}
+
+	if len(db.inserts) != 1 || db.inserts[0].table != "learning_progresses" {
+		t.Fatalf("inserts = %+v, want one learning_progresses row", db.inserts)
+	}
+	values := db.inserts[0].values
+	gradedBy, ok := values["graded_by"]
+	if !ok {
+		t.Fatalf("graded_by column was not written: %v", values)
+	}
+	if gradedBy != nil {
+		t.Errorf("graded_by = %v, want NULL so the users foreign key is not violated", gradedBy)
+	}
+	if values["user_id"] != int64(7) || values["activity_id"] != int64(5) {
+		t.Errorf("user_id = %v, activity_id = %v", values["user_id"], values["activity_id"])
+	}
+}
@@ -0,0 +1,547 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+)
+
+// quizSubmissionGrace memberi toleransi keterlambatan jaringan saat mengirim jawaban kuis
+const quizSubmissionGrace = 30 * time.Second
+
+// maxAttemptStartTries adalah jumlah percobaan membuat percobaan kuis ketika nomornya bentrok dengan permintaan lain
+const maxAttemptStartTries = 3
+
+// QuizService menangani logika bisnis kuis
+type QuizService struct {
+	QuizRepo            *repositories.QuizRepository
+	CourseRepo          *repositories.CourseRepository
+	EnrollmentRepo      *repositories.EnrollmentRepository
+	UserRepo            *repositories.UserRepository
+	ProgressService     *LearningProgressService
+	ExtensionService    *ExtensionService
+	AvailabilityService *AvailabilityService
+}
+
+// NewQuizService membuat layanan kuis baru
+func NewQuizService(
+	quizRepo *repositories.QuizRepository,
+	courseRepo *repositories.CourseRepository,
+	enrollmentRepo *repositories.EnrollmentRepository,
+	userRepo *repositories.UserRepository,
+	progressService *LearningProgressService,
+	extensionService *ExtensionService,
+	availabilityService *AvailabilityService,
+) *QuizService {
+	return &QuizService{
+		QuizRepo:            quizRepo,
+		CourseRepo:          courseRepo,
+		EnrollmentRepo:      enrollmentRepo,
+		UserRepo:            userRepo,
+		ProgressService:     progressService,
+		ExtensionService:    extensionService,
+		AvailabilityService: availabilityService,
+	}
+}
+
+// CreateQuiz membuat kuis baru beserta pertanyaannya
+func (s *QuizService) CreateQuiz(quiz *models.Quiz) error {
+	// Verifikasi keberadaan kursus
+	_, err := s.CourseRepo.FindByID(quiz.CourseID)
+	if err != nil {
+		return errors.New("course not found")
+	}
+
+	if err := validateQuizSettings(quiz); err != nil {
+		return err
+	}
+
+	// Validasi setiap pertanyaan
+	for i := range quiz.Questions {
+		if err := validateQuestion(&quiz.Questions[i]); err != nil {
+			return fmt.Errorf("question %d: %w", i+1, err)
+		}
+		if quiz.Questions[i].Position == 0 {
+			quiz.Questions[i].Position = i + 1
+		}
+	}
+
+	// Buat kuis
+	return s.QuizRepo.Create(quiz)
+}
+
+// GetQuizByID mendapatkan kuis beserta pertanyaannya
+func (s *QuizService) GetQuizByID(id uint) (*models.Quiz, error) {
+	return s.QuizRepo.FindByIDWithQuestions(id)
+}
+
+// GetQuizzesByCourse mendapatkan kuis berdasarkan ID kursus
+func (s *QuizService) GetQuizzesByCourse(courseID uint) ([]models.Quiz, error) {
+	return s.QuizRepo.FindByCourse(courseID)
+}
+
+// GetQuizForStudent mendapatkan kuis untuk siswa tanpa kunci jawaban.
+// Kuis yang masih terkunci menghasilkan ContentLockedError.
+func (s *QuizService) GetQuizForStudent(id, studentID uint) (*models.Quiz, error) {
+	quiz, err := s.QuizRepo.FindByIDWithQuestions(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.AvailabilityService.CheckAccess(quiz.CourseID, studentID, models.ProgressTypeQuiz, quiz.ID, quiz.Availability); err != nil {
+		return nil, err
+	}
+	quiz.HideAnswers()
+	return quiz, nil
+}
+
+// GetQuizzesByCourseForStudent mendapatkan kuis kursus untuk siswa beserta kunci masing-masing
+func (s *QuizService) GetQuizzesByCourseForStudent(courseID, studentID uint) ([]models.Quiz, error) {
+	quizzes, err := s.QuizRepo.FindByCourse(courseID)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := s.AvailabilityService.ForStudent(courseID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range quizzes {
+		availability.LockQuiz(&quizzes[i])
+	}
+	return quizzes, nil
+}
+
+// UpdateQuiz memperbarui pengaturan kuis
+func (s *QuizService) UpdateQuiz(quiz *models.Quiz) error {
+	// Verifikasi keberadaan kuis
+	existingQuiz, err := s.QuizRepo.FindByID(quiz.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateQuizSettings(quiz); err != nil {
+		return err
+	}
+
+	// Perbarui hanya bidang yang diizinkan
+	existingQuiz.Title = quiz.Title
+	existingQuiz.Description = quiz.Description
+	existingQuiz.TimeLimitMinutes = quiz.TimeLimitMinutes
+	existingQuiz.MaxAttempts = quiz.MaxAttempts
+	existingQuiz.DueDate = quiz.DueDate
+	existingQuiz.Availability = quiz.Availability
+
+	return s.QuizRepo.Update(existingQuiz)
+}
+
+// DeleteQuiz menghapus kuis
+func (s *QuizService) DeleteQuiz(id uint) error {
+	return s.QuizRepo.Delete(id)
+}
+
+// AddQuestion menambahkan pertanyaan ke kuis
+func (s *QuizService) AddQuestion(question *models.QuizQuestion) error {
+	// Verifikasi keberadaan kuis
+	quiz, err := s.QuizRepo.FindByIDWithQuestions(question.QuizID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateQuestion(question); err != nil {
+		return err
+	}
+
+	if question.Position == 0 {
+		question.Position = len(quiz.Questions) + 1
+	}
+
+	return s.QuizRepo.CreateQuestion(question)
+}
+
+// UpdateQuestion memperbarui pertanyaan beserta pilihannya
+func (s *QuizService) UpdateQuestion(question *models.QuizQuestion) error {
+	// Verifikasi keberadaan pertanyaan
+	existingQuestion, err := s.QuizRepo.FindQuestionByID(question.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateQuestion(question); err != nil {
+		return err
+	}
+
+	// Perbarui hanya bidang yang diizinkan
+	existingQuestion.Type = question.Type
+	existingQuestion.Prompt = question.Prompt
+	existingQuestion.Points = question.Points
+	existingQuestion.Position = question.Position
+	existingQuestion.BoolAnswer = question.BoolAnswer
+	existingQuestion.NumericAnswer = question.NumericAnswer
+	existingQuestion.Tolerance = question.Tolerance
+	existingQuestion.Choices = question.Choices
+
+	return s.QuizRepo.ReplaceQuestion(existingQuestion)
+}
+
+// GetQuestionByID mendapatkan pertanyaan kuis berdasarkan ID
+func (s *QuizService) GetQuestionByID(id uint) (*models.QuizQuestion, error) {
+	return s.QuizRepo.FindQuestionByID(id)
+}
+
+// DeleteQuestion menghapus pertanyaan dari kuis
+func (s *QuizService) DeleteQuestion(id uint) error {
+	return s.QuizRepo.DeleteQuestion(id)
+}
+
+// StartAttempt memulai percobaan kuis baru untuk siswa
+func (s *QuizService) StartAttempt(quizID, studentID uint) (*models.QuizAttempt, error) {
+	// Verifikasi keberadaan kuis
+	quiz, err := s.QuizRepo.FindByIDWithQuestions(quizID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(quiz.Questions) == 0 {
+		return nil, errors.New("quiz has no questions")
+	}
+
+	// Verifikasi bahwa pengguna adalah siswa yang terdaftar
+	student, err := s.UserRepo.FindByID(studentID)
+	if err != nil {
+		return nil, errors.New("student not found")
+	}
+
+	if student.Role != models.RoleStudent {
+		return nil, errors.New("only students can attempt quizzes")
+	}
+
+	_, err = s.EnrollmentRepo.FindByUserAndCourse(studentID, quiz.CourseID)
+	if err != nil {
+		return nil, errors.New("student is not enrolled in this course")
+	}
+
+	if err := s.AvailabilityService.CheckAccess(quiz.CourseID, studentID, models.ProgressTypeQuiz, quiz.ID, quiz.Availability); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	// Perpanjangan siswa dapat menggeser tanggal jatuh tempo dan menambah batas waktu
+	dueDate := quiz.DueDate
+	extraTimeLimit := 0
+	if extension := s.ExtensionService.ActiveExtension(models.ExtensionActivityQuiz, quiz.ID, studentID); extension != nil {
+		dueDate = extension.ApplyToDueDate(quiz.DueDate)
+		extraTimeLimit = extension.ExtraTimeLimitMinutes
+	}
+
+	// Periksa tanggal jatuh tempo jika ditetapkan
+	if dueDate != nil && now.After(*dueDate) {
+		return nil, errors.New("quiz due date has passed")
+	}
+
+	// Nomor percobaan unik per siswa; jika permintaan lain membuat percobaan lebih dulu,
+	// baca ulang agar percobaan itu yang dilanjutkan
+	for try := 0; try < maxAttemptStartTries; try++ {
+		attempts, err := s.QuizRepo.FindAttemptsByQuizAndStudent(quizID, studentID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Lanjutkan percobaan yang masih berjalan, atau tutup jika waktunya habis
+		for i := range attempts {
+			if attempts[i].Status != models.AttemptStatusInProgress {
+				continue
+			}
+			if attempts[i].ExpiresAt == nil || now.Before(attempts[i].ExpiresAt.Add(quizSubmissionGrace)) {
+				return &attempts[i], nil
+			}
+			if err := s.expireAttempt(quiz, &attempts[i]); err != nil {
+				return nil, err
+			}
+		}
+
+		// Periksa batas jumlah percobaan
+		if quiz.MaxAttempts != nil && len(attempts) >= *quiz.MaxAttempts {
+			return nil, errors.New("maximum number of attempts reached")
+		}
+
+		attempt := &models.QuizAttempt{
+			QuizID:        quizID,
+			StudentID:     studentID,
+			AttemptNumber: len(attempts) + 1,
+			Status:        models.AttemptStatusInProgress,
+			StartedAt:     now,
+			MaxScore:      quiz.MaxScore(),
+		}
+
+		if quiz.TimeLimitMinutes != nil {
+			expiresAt := now.Add(time.Duration(*quiz.TimeLimitMinutes+extraTimeLimit) * time.Minute)
+			attempt.ExpiresAt = &expiresAt
+		}
+
+		created, err := s.QuizRepo.CreateAttempt(attempt)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return attempt, nil
+		}
+	}
+
+	return nil, errors.New("quiz attempt is already being started, please try again")
+}
+
+// SubmitAttempt menilai jawaban siswa secara otomatis dan mencatat kemajuannya
+func (s *QuizService) SubmitAttempt(attemptID, studentID uint, answers []models.QuizAnswer) (*models.QuizAttempt, error) {
+	// Verifikasi keberadaan percobaan
+	attempt, err := s.QuizRepo.FindAttemptByID(attemptID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attempt.StudentID != studentID {
+		return nil, errors.New("user does not own this quiz attempt")
+	}
+
+	if attempt.Status != models.AttemptStatusInProgress {
+		return nil, errors.New("quiz attempt has already been submitted")
+	}
+
+	quiz, err := s.QuizRepo.FindByIDWithQuestions(attempt.QuizID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tolak jawaban jika batas waktu sudah terlewati
+	now := time.Now()
+	if attempt.ExpiresAt != nil && now.After(attempt.ExpiresAt.Add(quizSubmissionGrace)) {
+		if err := s.expireAttempt(quiz, attempt); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("quiz attempt time limit has expired")
+	}
+
+	graded, err := gradeAnswers(quiz, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	score := 0.0
+	for _, answer := range graded {
+		score += answer.PointsAwarded
+	}
+
+	attempt.Answers = graded
+	attempt.Score = &score
+	attempt.MaxScore = quiz.MaxScore()
+	attempt.Status = models.AttemptStatusSubmitted
+	attempt.SubmittedAt = &now
+
+	// Hanya satu pengiriman yang dapat menutup percobaan; pengiriman bersamaan lainnya ditolak
+	finished, err := s.QuizRepo.FinishAttempt(attempt)
+	if err != nil {
+		return nil, err
+	}
+	if !finished {
+		return nil, errors.New("quiz attempt has already been submitted")
+	}
+
+	if err := s.recordProgress(quiz, attempt.StudentID); err != nil {
+		return attempt, err
+	}
+
+	return attempt, nil
+}
+
+// GetAttemptByID mendapatkan percobaan kuis berdasarkan ID
+func (s *QuizService) GetAttemptByID(id uint) (*models.QuizAttempt, error) {
+	return s.QuizRepo.FindAttemptByID(id)
+}
+
+// GetStudentAttempts mendapatkan semua percobaan seorang siswa untuk sebuah kuis
+func (s *QuizService) GetStudentAttempts(quizID, studentID uint) ([]models.QuizAttempt, error) {
+	return s.QuizRepo.FindAttemptsByQuizAndStudent(quizID, studentID)
+}
+
+// GetQuizAttempts mendapatkan semua percobaan untuk sebuah kuis
+func (s *QuizService) GetQuizAttempts(quizID uint) ([]models.QuizAttempt, error) {
+	return s.QuizRepo.FindAttemptsByQuiz(quizID)
+}
+
+// expireAttempt menutup percobaan yang kehabisan waktu dengan skor nol
+func (s *QuizService) expireAttempt(quiz *models.Quiz, attempt *models.QuizAttempt) error {
+	now := time.Now()
+	zero := 0.0
+
+	attempt.Answers = nil
+	attempt.Score = &zero
+	attempt.MaxScore = quiz.MaxScore()
+	attempt.Status = models.AttemptStatusExpired
+	attempt.SubmittedAt = &now
+
+	// Percobaan yang sudah dikirim oleh permintaan lain dibiarkan apa adanya
+	finished, err := s.QuizRepo.FinishAttempt(attempt)
+	if err != nil || !finished {
+		return err
+	}
+
+	return s.recordProgress(quiz, attempt.StudentID)
+}
+
+// recordProgress mencatat skor terbaik siswa melalui jalur yang sama dengan penilaian mentor
+func (s *QuizService) recordProgress(quiz *models.Quiz, studentID uint) error {
+	course, err := s.CourseRepo.FindByID(quiz.CourseID)
+	if err != nil {
+		return errors.New("course not found")
+	}
+
+	attempts, err := s.QuizRepo.FindAttemptsByQuizAndStudent(quiz.ID, studentID)
+	if err != nil {
+		return err
+	}
+
+	var best *models.QuizAttempt
+	for i := range attempts {
+		if attempts[i].Score == nil {
+			continue
+		}
+		if best == nil || *attempts[i].Score > *best.Score {
+			best = &attempts[i]
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	feedback := fmt.Sprintf("Auto-graded: best of %d attempt(s), attempt #%d", len(attempts), best.AttemptNumber)
+
+	return s.ProgressService.CreateOrUpdateGrade(
+		course.MentorID,
+		studentID,
+		quiz.CourseID,
+		models.ProgressTypeQuiz,
+		quiz.ID,
+		*best.Score,
+		quiz.MaxScore(),
+		feedback,
+		true,
+	)
+}
+
+// validateQuizSettings memvalidasi pengaturan batas waktu dan jumlah percobaan
+func validateQuizSettings(quiz *models.Quiz) error {
+	if quiz.TimeLimitMinutes != nil && *quiz.TimeLimitMinutes <= 0 {
+		return errors.New("time limit must be greater than zero")
+	}
+	if quiz.MaxAttempts != nil && *quiz.MaxAttempts <= 0 {
+		return errors.New("max attempts must be greater than zero")
+	}
+	return validateAvailability(quiz.Availability)
+}
+
+// validateQuestion memvalidasi kunci jawaban sesuai dengan jenis pertanyaan
+func validateQuestion(question *models.QuizQuestion) error {
+	if question.Points <= 0 {
+		return errors.New("points must be greater than zero")
+	}
+
+	switch question.Type {
+	case models.QuestionTypeMultipleChoice:
+		if len(question.Choices) < 2 {
+			return errors.New("multiple choice questions need at least two choices")
+		}
+		correct := 0
+		for _, choice := range question.Choices {
+			if choice.IsCorrect {
+				correct++
+			}
+		}
+		if correct != 1 {
+			return errors.New("multiple choice questions need exactly one correct choice")
+		}
+	case models.QuestionTypeTrueFalse:
+		if question.BoolAnswer == nil {
+			return errors.New("true/false questions need a bool_answer")
+		}
+		question.Choices = nil
+	case models.QuestionTypeNumeric:
+		if question.NumericAnswer == nil {
+			return errors.New("numeric questions need a numeric_answer")
+		}
+		if question.Tolerance < 0 {
+			return errors.New("tolerance cannot be negative")
+		}
+		question.Choices = nil
+	default:
+		return errors.New("invalid question type")
+	}
+
+	return nil
+}
+
+// gradeAnswers menilai jawaban terhadap kunci jawaban kuis
+func gradeAnswers(quiz *models.Quiz, answers []models.QuizAnswer) ([]models.QuizAnswer, error) {
+	submitted := make(map[uint]models.QuizAnswer, len(answers))
+	for _, answer := range answers {
+		submitted[answer.QuestionID] = answer
+	}
+
+	known := make(map[uint]bool, len(quiz.Questions))
+	graded := make([]models.QuizAnswer, 0, len(quiz.Questions))
+
+	for _, question := range quiz.Questions {
+		known[question.ID] = true
+
+		answer, ok := submitted[question.ID]
+		if !ok {
+			graded = append(graded, models.QuizAnswer{QuestionID: question.ID})
+			continue
+		}
+
+		result := models.QuizAnswer{QuestionID: question.ID}
+
+		switch question.Type {
+		case models.QuestionTypeMultipleChoice:
+			result.ChoiceID = answer.ChoiceID
+			if answer.ChoiceID != nil {
+				found := false
+				for _, choice := range question.Choices {
+					if choice.ID == *answer.ChoiceID {
+						found = true
+						result.IsCorrect = choice.IsCorrect
+						break
+					}
+				}
+				if !found {
+					return nil, fmt.Errorf("choice %d does not belong to question %d", *answer.ChoiceID, question.ID)
+				}
+			}
+		case models.QuestionTypeTrueFalse:
+			result.BoolAnswer = answer.BoolAnswer
+			result.IsCorrect = answer.BoolAnswer != nil && question.BoolAnswer != nil &&
+				*answer.BoolAnswer == *question.BoolAnswer
+		case models.QuestionTypeNumeric:
+			result.NumericAnswer = answer.NumericAnswer
+			result.IsCorrect = answer.NumericAnswer != nil && question.NumericAnswer != nil &&
+				math.Abs(*answer.NumericAnswer-*question.NumericAnswer) <= question.Tolerance
+		}
+
+		if result.IsCorrect {
+			result.PointsAwarded = question.Points
+		}
+
+		graded = append(graded, result)
+	}
+
+	for questionID := range submitted {
+		if !known[questionID] {
+			return nil, fmt.Errorf("question %d does not belong to this quiz", questionID)
+		}
+	}
+
+	return graded, nil
+}
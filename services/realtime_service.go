@@ -0,0 +1,117 @@
+package services
+
+import (
+	"LMS/events"
+	"LMS/realtime"
+	"LMS/repositories"
+
+)
+
+// RealtimeService meneruskan kejadian dari bus ke topik realtime yang diikuti klien
+type RealtimeService struct {
+	Broker         realtime.Broker
+	UserRepo       *repositories.UserRepository
+	DiscussionRepo *repositories.DiscussionRepository
+	CommentRepo    *repositories.CommentRepository
+	AssessmentRepo *repositories.AssessmentRepository
+	SubmissionRepo *repositories.SubmissionRepository
+}
+
+// NewRealtimeService membuat layanan realtime baru
+func NewRealtimeService(
+	broker realtime.Broker,
+	userRepo *repositories.UserRepository,
+	discussionRepo *repositories.DiscussionRepository,
+	commentRepo *repositories.CommentRepository,
+	assessmentRepo *repositories.AssessmentRepository,
+	submissionRepo *repositories.SubmissionRepository,
+) *RealtimeService {
+	return &RealtimeService{
+		Broker:         broker,
+		UserRepo:       userRepo,
+		DiscussionRepo: discussionRepo,
+		CommentRepo:    commentRepo,
+		AssessmentRepo: assessmentRepo,
+		SubmissionRepo: submissionRepo,
+	}
+}
+
+// MaterialUpdate adalah isi pesan materi baru. Judul sengaja tidak dikirim karena materi
+// mungkin masih terkunci bagi sebagian siswa; klien mengambil materi lewat API biasa.
+type MaterialUpdate struct {
+	ID       uint `json:"id"`
+	CourseID uint `json:"course_id"`
+}
+
+// GradeUpdate adalah isi pesan nilai yang dikirim hanya ke siswa pemilik kiriman
+type GradeUpdate struct {
+	AssessmentID uint `json:"assessment_id"`
+	SubmissionID uint `json:"submission_id"`
+	AssignmentID uint `json:"assignment_id"`
+	CourseID     uint `json:"course_id"`
+	Score        *int `json:"score"`
+}
+
+// HandleEvent menerjemahkan kejadian menjadi pesan realtime:
+// komentar ke topik diskusi, diskusi dan materi ke topik kursus, dan nilai ke topik pribadi siswa
+func (s *RealtimeService) HandleEvent(event events.Event) error {
+	messageType := string(event.Type)
+
+	switch event.Type {
+	case events.CommentCreated:
+		comment, err := s.CommentRepo.FindByID(event.ResourceID)
+		if err != nil {
+			return err
+		}
+		if user, err := s.UserRepo.FindByID(comment.UserID); err == nil {
+			comment.User = *user
+		}
+		s.publish(realtime.Topic(realtime.TopicDiscussion, comment.DiscussionID), messageType, comment)
+
+	case events.DiscussionCreated, events.DiscussionUpdated:
+		discussion, err := s.DiscussionRepo.FindByID(event.ResourceID)
+		if err != nil {
+			return err
+		}
+		if user, err := s.UserRepo.FindByID(discussion.UserID); err == nil {
+			discussion.User = *user
+		}
+		s.publish(realtime.Topic(realtime.TopicCourse, discussion.CourseID), messageType, discussion)
+		if event.Type == events.DiscussionUpdated {
+			s.publish(realtime.Topic(realtime.TopicDiscussion, discussion.ID), messageType, discussion)
+		}
+
+	case events.MaterialCreated:
+		s.publish(realtime.Topic(realtime.TopicCourse, event.CourseID), messageType, MaterialUpdate{
+			ID:       event.ResourceID,
+			CourseID: event.CourseID,
+		})
+
+	case events.AssessmentCreated, events.AssessmentUpdated:
+		assessment, err := s.AssessmentRepo.FindByID(event.ResourceID)
+		if err != nil {
+			return err
+		}
+		submission, err := s.SubmissionRepo.FindByID(assessment.SubmissionID)
+		if err != nil {
+			return err
+		}
+		s.publish(realtime.Topic(realtime.TopicUser, submission.StudentID), messageType, GradeUpdate{
+			AssessmentID: assessment.ID,
+			SubmissionID: submission.ID,
+			AssignmentID: submission.AssignmentID,
+			CourseID:     event.CourseID,
+			Score:        assessment.Score,
+		})
+	}
+	return nil
+}
+
+// publish mengirim satu pesan ke broker
+func (s *RealtimeService) publish(topic, messageType string, data interface{}) {
+	s.Broker.Publish(realtime.Message{
+		Topic: topic,
+		Type:  messageType,
+		Data:  data,
+	})
+}
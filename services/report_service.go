@@ -0,0 +1,119 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"strings"
+
+)
+
+// ReportService menangani antrean laporan diskusi dan komentar untuk moderator kursus
+type ReportService struct {
+	ReportRepo     *repositories.ReportRepository
+	DiscussionRepo *repositories.DiscussionRepository
+	CommentRepo    *repositories.CommentRepository
+	CommentService *CommentService
+}
+
+// NewReportService membuat layanan laporan baru
+func NewReportService(
+	reportRepo *repositories.ReportRepository,
+	discussionRepo *repositories.DiscussionRepository,
+	commentRepo *repositories.CommentRepository,
+	commentService *CommentService,
+) *ReportService {
+	return &ReportService{
+		ReportRepo:     reportRepo,
+		DiscussionRepo: discussionRepo,
+		CommentRepo:    commentRepo,
+		CommentService: commentService,
+	}
+}
+
+// CreateReport membuat laporan atas diskusi atau salah satu komentarnya
+func (s *ReportService) CreateReport(report *models.ContentReport) error {
+	discussion, err := s.DiscussionRepo.FindByID(report.DiscussionID)
+	if err != nil {
+		return errors.New("discussion not found")
+	}
+
+	if report.CommentID != nil {
+		comment, err := s.CommentRepo.FindByID(*report.CommentID)
+		if err != nil {
+			return errors.New("comment not found")
+		}
+		if comment.DiscussionID != discussion.ID {
+			return errors.New("comment does not belong to this discussion")
+		}
+	}
+
+	report.Reason = strings.TrimSpace(report.Reason)
+	if report.Reason == "" {
+		return errors.New("a reason is required to report a post")
+	}
+
+	// Satu pengguna hanya memiliki satu laporan terbuka untuk setiap tulisan
+	if _, err := s.ReportRepo.FindOpenByReporter(report.ReporterID, report.DiscussionID, report.CommentID); err == nil {
+		return errors.New("you have already reported this post")
+	}
+
+	report.CourseID = discussion.CourseID
+	report.Status = models.ReportOpen
+	return s.ReportRepo.Create(report)
+}
+
+// GetReportByID mendapatkan laporan berdasarkan ID
+func (s *ReportService) GetReportByID(id uint) (*models.ContentReport, error) {
+	return s.ReportRepo.FindByID(id)
+}
+
+// GetReportsByCourse mendapatkan antrean laporan sebuah kursus menurut status
+func (s *ReportService) GetReportsByCourse(courseID uint, status models.ReportStatus) ([]models.ContentReport, error) {
+	if status != "" && status != models.ReportOpen && status != models.ReportResolved && status != models.ReportDismissed {
+		return nil, errors.New("status must be open, resolved or dismissed")
+	}
+	return s.ReportRepo.FindByCourse(courseID, status)
+}
+
+// ResolveReport menutup laporan sebagai ditindaklanjuti atau diabaikan.
+// Jika hideComment diisi, komentar yang dilaporkan ikut disembunyikan dengan catatan penyelesaian sebagai alasannya.
+func (s *ReportService) ResolveReport(id, moderatorID uint, status models.ReportStatus, resolution string, hideComment bool) error {
+	report, err := s.ReportRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if status != models.ReportResolved && status != models.ReportDismissed {
+		return errors.New("status must be resolved or dismissed")
+	}
+	if report.Status != models.ReportOpen {
+		return errors.New("report has already been resolved")
+	}
+
+	resolution = strings.TrimSpace(resolution)
+	if hideComment {
+		if status != models.ReportResolved {
+			return errors.New("only resolved reports can hide the comment")
+		}
+		if report.CommentID == nil {
+			return errors.New("only comments can be hidden")
+		}
+
+		comment, err := s.CommentRepo.FindByID(*report.CommentID)
+		if err != nil {
+			return err
+		}
+		if comment.HiddenAt == nil {
+			reason := resolution
+			if reason == "" {
+				reason = report.Reason
+			}
+			if err := s.CommentService.HideComment(comment.ID, moderatorID, reason); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.ReportRepo.Resolve(id, status, moderatorID, resolution)
+}
@@ -0,0 +1,447 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"LMS/utils"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+)
+
+// Batas ukuran potongan yang boleh dipilih klien
+const (
+	MinResumableChunkSize = 1 << 20
+	MaxResumableChunkSize = 64 << 20
+)
+
+// checksumPattern adalah format checksum SHA-256 dalam heksadesimal
+var checksumPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ErrChunkChecksumMismatch dikembalikan jika isi potongan tidak sesuai checksum yang dikirim klien
+var ErrChunkChecksumMismatch = errors.New("chunk checksum mismatch")
+
+// ResumableUploadService menangani unggahan berkas besar yang dikirim per potongan
+type ResumableUploadService struct {
+	UploadRepo      *repositories.UploadRepository
+	CourseRepo      *repositories.CourseRepository
+	MaterialRepo    *repositories.MaterialRepository
+	MaterialService *MaterialService
+	Limits          UploadLimits
+
+	locks uploadLocks
+}
+
+// uploadLocks menyerialkan penulisan potongan ke berkas sementara terhadap penyusunan berkas sesi yang sama.
+// Potongan berbeda boleh ditulis bersamaan; penyusunan menunggu sampai penulisan yang sedang berjalan selesai.
+type uploadLocks struct {
+	mu    sync.Mutex
+	locks map[string]*uploadLock
+}
+
+// uploadLock adalah kunci satu sesi beserta jumlah pemakainya
+type uploadLock struct {
+	sync.RWMutex
+	users int
+}
+
+// acquire mengambil kunci sesi; setiap acquire harus diikuti release
+func (l *uploadLocks) acquire(token string) *uploadLock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locks == nil {
+		l.locks = make(map[string]*uploadLock)
+	}
+	lock, ok := l.locks[token]
+	if !ok {
+		lock = &uploadLock{}
+		l.locks[token] = lock
+	}
+	lock.users++
+	return lock
+}
+
+// release melepas kunci sesi dan membuangnya jika tidak dipakai lagi
+func (l *uploadLocks) release(token string, lock *uploadLock) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock.users--
+	if lock.users == 0 {
+		delete(l.locks, token)
+	}
+}
+
+// NewResumableUploadService membuat layanan unggahan bertahap baru
+func NewResumableUploadService(
+	uploadRepo *repositories.UploadRepository,
+	courseRepo *repositories.CourseRepository,
+	materialRepo *repositories.MaterialRepository,
+	materialService *MaterialService,
+	limits UploadLimits,
+) *ResumableUploadService {
+	return &ResumableUploadService{
+		UploadRepo:      uploadRepo,
+		CourseRepo:      courseRepo,
+		MaterialRepo:    materialRepo,
+		MaterialService: materialService,
+		Limits:          limits,
+	}
+}
+
+// InitUpload memulai sesi unggahan untuk materi baru, atau untuk mengganti berkas materi jika MaterialID diisi
+func (s *ResumableUploadService) InitUpload(session *models.UploadSession) error {
+	if session.MaterialID != nil {
+		material, err := s.MaterialRepo.FindByID(*session.MaterialID)
+		if err != nil {
+			return err
+		}
+		if material.Type != models.MaterialTypeFile && material.Type != models.MaterialTypeBundle {
+			return errors.New("files can only be uploaded to file and bundle materials")
+		}
+		session.CourseID = material.CourseID
+	} else if session.Title == "" {
+		return errors.New("title is required")
+	}
+
+	course, err := s.CourseRepo.FindByID(session.CourseID)
+	if err != nil {
+		return errors.New("course not found")
+	}
+
+	session.FileName = cleanFileName(session.FileName)
+	if session.FileName == "" || session.FileName == "." {
+		return errors.New("file name is required")
+	}
+	if err := s.checkDeclaredFile(course, session); err != nil {
+		return err
+	}
+
+	if session.ChunkSize == 0 {
+		session.ChunkSize = s.Limits.ResumableChunkSize
+	}
+	if session.ChunkSize < MinResumableChunkSize || session.ChunkSize > MaxResumableChunkSize {
+		return fmt.Errorf("chunk size must be between %s and %s", formatBytes(MinResumableChunkSize), formatBytes(MaxResumableChunkSize))
+	}
+
+	session.Checksum = strings.ToLower(session.Checksum)
+	if session.Checksum != "" && !checksumPattern.MatchString(session.Checksum) {
+		return errors.New("checksum must be a hex encoded SHA-256 digest")
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return err
+	}
+	session.Token = hex.EncodeToString(token)
+	session.Status = models.UploadStatusPending
+	session.ExpiresAt = time.Now().Add(s.Limits.UploadSessionTTL)
+
+	// Siapkan berkas sementara seukuran berkas akhir agar potongan dapat ditulis dalam urutan apa pun
+	if err := os.MkdirAll(s.Limits.StagingDir, 0o700); err != nil {
+		return errors.New("failed to prepare upload")
+	}
+	staging, err := os.OpenFile(s.stagingPath(session), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.New("failed to prepare upload")
+	}
+	err = staging.Truncate(session.FileSize)
+	staging.Close()
+	if err != nil {
+		os.Remove(s.stagingPath(session))
+		return errors.New("failed to prepare upload")
+	}
+
+	if err := s.UploadRepo.Create(session); err != nil {
+		os.Remove(s.stagingPath(session))
+		return err
+	}
+	s.fillProgress(session)
+	return nil
+}
+
+// GetUpload mendapatkan sesi unggahan milik pengguna beserta kemajuannya
+func (s *ResumableUploadService) GetUpload(token string, userID uint) (*models.UploadSession, error) {
+	session, err := s.UploadRepo.FindByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	// Sesi pengguna lain diperlakukan seolah tidak ada
+	if session.UserID != userID {
+		return nil, errors.New("upload session not found")
+	}
+	if session.Status != models.UploadStatusCompleted && time.Now().After(session.ExpiresAt) {
+		return nil, errors.New("upload session has expired")
+	}
+	s.fillProgress(session)
+	return session, nil
+}
+
+// UploadChunk menulis satu potongan ke berkas sementara dan mencatatnya. Potongan yang sama boleh dikirim ulang.
+func (s *ResumableUploadService) UploadChunk(token string, userID uint, index int, body io.Reader, checksum string) (*models.UploadSession, error) {
+	// Status sesi dibaca setelah kunci diambil sehingga potongan tidak ditulis ketika berkas sedang disusun
+	lock := s.locks.acquire(token)
+	defer s.locks.release(token, lock)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	session, err := s.GetUpload(token, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.UploadStatusPending {
+		return nil, errors.New("upload is no longer accepting chunks")
+	}
+	if index < 0 || index >= session.TotalChunks {
+		return nil, fmt.Errorf("chunk index must be between 0 and %d", session.TotalChunks-1)
+	}
+	checksum = strings.ToLower(checksum)
+	if checksum != "" && !checksumPattern.MatchString(checksum) {
+		return nil, errors.New("checksum must be a hex encoded SHA-256 digest")
+	}
+
+	staging, err := os.OpenFile(s.stagingPath(session), os.O_WRONLY, 0)
+	if err != nil {
+		return nil, errors.New("failed to write chunk")
+	}
+	defer staging.Close()
+
+	// Baca paling banyak satu byte lebih dari ukuran potongan untuk mendeteksi badan yang terlalu panjang
+	expected := session.ChunkLength(index)
+	hasher := sha256.New()
+	writer := io.NewOffsetWriter(staging, int64(index)*session.ChunkSize)
+	written, err := io.Copy(io.MultiWriter(writer, hasher), io.LimitReader(body, expected+1))
+	if err != nil {
+		return nil, err
+	}
+	if written != expected {
+		return nil, fmt.Errorf("chunk %d must be exactly %d bytes, received %d", index, expected, written)
+	}
+
+	digest := hex.EncodeToString(hasher.Sum(nil))
+	if checksum != "" && checksum != digest {
+		return nil, ErrChunkChecksumMismatch
+	}
+	if err := staging.Sync(); err != nil {
+		return nil, errors.New("failed to write chunk")
+	}
+
+	// Setiap potongan yang diterima memperpanjang masa berlaku sesi; status diperiksa ulang saat mencatat
+	chunk := &models.UploadChunk{SessionID: session.ID, ChunkIndex: index, Size: written, Checksum: digest}
+	saved, err := s.UploadRepo.SaveChunk(chunk, time.Now().Add(s.Limits.UploadSessionTTL))
+	if err != nil {
+		return nil, err
+	}
+	if !saved {
+		return nil, errors.New("upload is no longer accepting chunks")
+	}
+
+	return s.GetUpload(token, userID)
+}
+
+// CompleteUpload menyusun berkas dari semua potongan, memeriksa checksum-nya lalu melampirkannya ke materi.
+// Memanggil ulang setelah berhasil mengembalikan materi yang sama.
+func (s *ResumableUploadService) CompleteUpload(token string, userID uint) (*models.Material, error) {
+	session, err := s.GetUpload(token, userID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status == models.UploadStatusCompleted && session.MaterialID != nil {
+		return s.MaterialRepo.FindByID(*session.MaterialID)
+	}
+	if missing := session.TotalChunks - len(session.ReceivedChunks); missing > 0 {
+		return nil, fmt.Errorf("upload is incomplete, %d of %d chunks are missing", missing, session.TotalChunks)
+	}
+
+	// Hanya satu permintaan yang boleh menyusun berkas; masa berlaku diperpanjang selama penyusunan.
+	// Kunci menunggu penulisan potongan yang sedang berjalan; setelah status berubah potongan baru ditolak.
+	lock := s.locks.acquire(token)
+	lock.Lock()
+	changed, err := s.UploadRepo.StartAssembling(session.ID, time.Now().Add(s.Limits.UploadSessionTTL))
+	lock.Unlock()
+	s.locks.release(token, lock)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, errors.New("upload is already being completed")
+	}
+
+	material, err := s.attach(session)
+	if err != nil {
+		// Kembalikan ke pending agar potongan yang rusak dapat dikirim ulang
+		s.UploadRepo.UpdateStatus(session.ID, models.UploadStatusAssembling, models.UploadStatusPending)
+		return nil, err
+	}
+
+	if err := s.UploadRepo.MarkCompleted(session.ID, material.ID); err != nil {
+		log.Printf("failed to mark upload %s as completed: %v", session.Token, err)
+	}
+	os.Remove(s.stagingPath(session))
+	return material, nil
+}
+
+// AbortUpload membatalkan sesi unggahan dan menghapus potongan yang sudah diterima
+func (s *ResumableUploadService) AbortUpload(token string, userID uint) error {
+	session, err := s.GetUpload(token, userID)
+	if err != nil {
+		return err
+	}
+	if session.Status == models.UploadStatusAssembling {
+		return errors.New("upload is already being completed")
+	}
+	return s.removeSession(session)
+}
+
+// CleanupExpired menghapus sesi yang sudah kedaluwarsa beserta berkas sementaranya
+func (s *ResumableUploadService) CleanupExpired() (int, error) {
+	sessions, err := s.UploadRepo.FindExpired(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for i := range sessions {
+		if err := s.removeSession(&sessions[i]); err != nil {
+			log.Printf("failed to remove expired upload %s: %v", sessions[i].Token, err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// RunCleanup menjalankan CleanupExpired secara berkala; dipanggil sebagai goroutine
+func (s *ResumableUploadService) RunCleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		removed, err := s.CleanupExpired()
+		if err != nil {
+			log.Printf("failed to clean up expired uploads: %v", err)
+			continue
+		}
+		if removed > 0 {
+			log.Printf("removed %d expired upload sessions", removed)
+		}
+	}
+}
+
+// attach memeriksa berkas yang sudah tersusun lalu membuat materi baru atau mengganti berkas materi sasaran
+func (s *ResumableUploadService) attach(session *models.UploadSession) (*models.Material, error) {
+	staging, err := os.Open(s.stagingPath(session))
+	if err != nil {
+		return nil, errors.New("failed to read uploaded file")
+	}
+	defer staging.Close()
+
+	info, err := staging.Stat()
+	if err != nil || info.Size() != session.FileSize {
+		return nil, errors.New("uploaded file is incomplete")
+	}
+
+	if session.Checksum != "" {
+		hasher := sha256.New()
+		if _, err := io.Copy(hasher, staging); err != nil {
+			return nil, errors.New("failed to read uploaded file")
+		}
+		if hex.EncodeToString(hasher.Sum(nil)) != session.Checksum {
+			return nil, errors.New("file checksum mismatch, upload the chunks again")
+		}
+	}
+
+	file := &uploadedFile{Name: session.FileName, Size: session.FileSize, Reader: staging}
+	if session.MaterialID != nil {
+		existing, err := s.MaterialRepo.FindByID(*session.MaterialID)
+		if err != nil {
+			return nil, err
+		}
+		// Unggahan hanya mengganti berkas; aturan ketersediaan materi tetap
+		material := &models.Material{Availability: existing.Availability}
+		material.ID = existing.ID
+		material.Title = session.Title
+		if material.Title == "" {
+			material.Title = existing.Title
+		}
+		if err := s.MaterialService.updateMaterial(material, []*uploadedFile{file}, s.Limits.MaxResumableSize); err != nil {
+			return nil, err
+		}
+		return material, nil
+	}
+
+	material := &models.Material{CourseID: session.CourseID, Title: session.Title}
+	if err := s.MaterialService.createMaterial(material, []*uploadedFile{file}, s.Limits.MaxResumableSize); err != nil {
+		return nil, err
+	}
+	return material, nil
+}
+
+// checkDeclaredFile memeriksa ukuran dan ekstensi yang dinyatakan klien sebelum potongan pertama dikirim;
+// isi sebenarnya tetap diperiksa saat unggahan diselesaikan
+func (s *ResumableUploadService) checkDeclaredFile(course *models.Course, session *models.UploadSession) error {
+	var problems []UploadError
+	addProblem := func(code, message string) {
+		problems = append(problems, UploadError{Field: "file", Code: code, Message: message})
+	}
+
+	if session.FileSize <= 0 {
+		addProblem(UploadErrorEmpty, "file is empty")
+	} else if s.Limits.MaxResumableSize > 0 && session.FileSize > s.Limits.MaxResumableSize {
+		addProblem(UploadErrorTooLarge, fmt.Sprintf("file is %s, the maximum size is %s", formatBytes(session.FileSize), formatBytes(s.Limits.MaxResumableSize)))
+	}
+
+	extension := strings.ToLower(filepath.Ext(session.FileName))
+	fileType, ok := utils.FileTypeByExtension(extension)
+	if !ok {
+		addProblem(UploadErrorUnknownType, fmt.Sprintf("file extension %q is not a supported file type", extension))
+	} else if course.AllowedFileTypes != "" && !containsFileType(course.AllowedFileTypes, fileType.Name) {
+		addProblem(UploadErrorTypeNotAllowed, fmt.Sprintf("file type %s is not allowed, accepted types are %s", fileType.Name, strings.ReplaceAll(course.AllowedFileTypes, ",", ", ")))
+	}
+
+	if len(problems) > 0 {
+		return &UploadValidationError{Errors: problems}
+	}
+	return nil
+}
+
+// removeSession menghapus berkas sementara dan catatan sesi
+func (s *ResumableUploadService) removeSession(session *models.UploadSession) error {
+	if err := os.Remove(s.stagingPath(session)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return s.UploadRepo.Delete(session.ID)
+}
+
+// stagingPath mengembalikan lokasi berkas sementara sebuah sesi
+func (s *ResumableUploadService) stagingPath(session *models.UploadSession) string {
+	return filepath.Join(s.Limits.StagingDir, session.Token+".part")
+}
+
+// fillProgress mengisi jumlah potongan dan potongan yang sudah diterima
+func (s *ResumableUploadService) fillProgress(session *models.UploadSession) {
+	session.TotalChunks = session.ChunkCount()
+	session.ReceivedChunks = []int{}
+	session.ReceivedBytes = 0
+	for _, chunk := range session.Chunks {
+		session.ReceivedChunks = append(session.ReceivedChunks, chunk.ChunkIndex)
+		session.ReceivedBytes += chunk.Size
+	}
+	// Catatan potongan dihapus setelah unggahan selesai
+	if session.Status == models.UploadStatusCompleted {
+		session.ReceivedChunks = session.ReceivedChunks[:0]
+		for i := 0; i < session.TotalChunks; i++ {
+			session.ReceivedChunks = append(session.ReceivedChunks, i)
+		}
+		session.ReceivedBytes = session.FileSize
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"LMS/models"
+	"LMS/repositories"
+	"errors"
+	"strings"
+
+)
+
+// RubricService menangani logika bisnis rubrik penilaian
+type RubricService struct {
+	RubricRepo *repositories.RubricRepository
+	CourseRepo *repositories.CourseRepository
+}
+
+// NewRubricService membuat layanan rubrik baru
+func NewRubricService(
+	rubricRepo *repositories.RubricRepository,
+	courseRepo *repositories.CourseRepository,
+) *RubricService {
+	return &RubricService{
+		RubricRepo: rubricRepo,
+		CourseRepo: courseRepo,
+	}
+}
+
+// CreateRubric membuat rubrik baru beserta kriteria dan tingkat capaiannya
+func (s *RubricService) CreateRubric(rubric *models.Rubric) error {
+	if _, err := s.CourseRepo.FindByID(rubric.CourseID); err != nil {
+		return errors.New("course not found")
+	}
+
+	if err := validateRubric(rubric); err != nil {
+		return err
+	}
+
+	if err := s.RubricRepo.Create(rubric); err != nil {
+		return err
+	}
+	rubric.CalculateTotalPoints()
+	return nil
+}
+
+// GetRubricByID mendapatkan rubrik berdasarkan ID
+func (s *RubricService) GetRubricByID(id uint) (*models.Rubric, error) {
+	return s.RubricRepo.FindByID(id)
+}
+
+// GetRubricsByCourse mendapatkan rubrik sebuah kursus
+func (s *RubricService) GetRubricsByCourse(courseID uint) ([]models.Rubric, error) {
+	return s.RubricRepo.FindByCourse(courseID)
+}
+
+// UpdateRubric memperbarui rubrik dan mengganti kriterianya.
+// Rubrik yang sudah dipakai dalam penilaian tidak dapat diubah agar rincian nilai tetap utuh.
+func (s *RubricService) UpdateRubric(rubric *models.Rubric) error {
+	existingRubric, err := s.RubricRepo.FindByID(rubric.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := validateRubric(rubric); err != nil {
+		return err
+	}
+
+	used, err := s.RubricRepo.CountCriterionScores(rubric.ID)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return errors.New("rubric is already used in assessments; duplicate it instead")
+	}
+
+	// Total poin harus tetap sama dengan skor maksimal tugas yang memakainya
+	assignments, err := s.RubricRepo.CountAssignments(rubric.ID)
+	if err != nil {
+		return err
+	}
+	if assignments > 0 && rubric.CalculateTotalPoints() != existingRubric.TotalPoints {
+		return errors.New("rubric total points cannot change while assignments use it")
+	}
+
+	rubric.CourseID = existingRubric.CourseID
+	rubric.CreatedByID = existingRubric.CreatedByID
+	return s.RubricRepo.Update(rubric)
+}
+
+// DuplicateRubric menyalin rubrik ke kursus tujuan agar dapat dipakai ulang
+func (s *RubricService) DuplicateRubric(id, courseID, createdByID uint) (*models.Rubric, error) {
+	source, err := s.RubricRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.CourseRepo.FindByID(courseID); err != nil {
+		return nil, errors.New("course not found")
+	}
+
+	rubric := &models.Rubric{
+		CourseID:    courseID,
+		Title:       source.Title,
+		Description: source.Description,
+		CreatedByID: createdByID,
+	}
+	for _, criterion := range source.Criteria {
+		copiedCriterion := models.RubricCriterion{
+			Title:       criterion.Title,
+			Description: criterion.Description,
+			Position:    criterion.Position,
+		}
+		for _, level := range criterion.Levels {
+			copiedCriterion.Levels = append(copiedCriterion.Levels, models.RubricLevel{
+				Title:       level.Title,
+				Description: level.Description,
+				Points:      level.Points,
+				Position:    level.Position,
+			})
+		}
+		rubric.Criteria = append(rubric.Criteria, copiedCriterion)
+	}
+
+	if err := s.RubricRepo.Create(rubric); err != nil {
+		return nil, err
+	}
+	rubric.CalculateTotalPoints()
+	return rubric, nil
+}
+
+// DeleteRubric menghapus rubrik yang tidak lagi dipakai tugas
+func (s *RubricService) DeleteRubric(id uint) error {
+	if _, err := s.RubricRepo.FindByID(id); err != nil {
+		return err
+	}
+
+	assignments, err := s.RubricRepo.CountAssignments(id)
+	if err != nil {
+		return err
+	}
+	if assignments > 0 {
+		return errors.New("rubric is attached to assignments")
+	}
+
+	used, err := s.RubricRepo.CountCriterionScores(id)
+	if err != nil {
+		return err
+	}
+	if used > 0 {
+		return errors.New("rubric is already used in assessments")
+	}
+
+	return s.RubricRepo.Delete(id)
+}
+
+// validateRubric memvalidasi kriteria dan tingkat capaian rubrik serta menetapkan urutannya
+func validateRubric(rubric *models.Rubric) error {
+	if strings.TrimSpace(rubric.Title) == "" {
+		return errors.New("rubric title is required")
+	}
+	if len(rubric.Criteria) == 0 {
+		return errors.New("rubric must have at least one criterion")
+	}
+
+	for i := range rubric.Criteria {
+		criterion := &rubric.Criteria[i]
+		if strings.TrimSpace(criterion.Title) == "" {
+			return errors.New("criterion title is required")
+		}
+		if len(criterion.Levels) == 0 {
+			return errors.New("each criterion must have at least one level")
+		}
+		criterion.Position = i + 1
+
+		points := make(map[int]bool, len(criterion.Levels))
+		for j := range criterion.Levels {
+			level := &criterion.Levels[j]
+			if strings.TrimSpace(level.Title) == "" {
+				return errors.New("level title is required")
+			}
+			if level.Points < 0 {
+				return errors.New("level points cannot be negative")
+			}
+			if points[level.Points] {
+				return errors.New("levels of a criterion must have different points")
+			}
+			points[level.Points] = true
+			level.Position = j + 1
+		}
+	}
+
+	if rubric.CalculateTotalPoints() <= 0 {
+		return errors.New("rubric total points must be greater than 0")
+	}
+
+	return nil
+}
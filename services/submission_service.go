@@ -3,17 +3,27 @@ package services
 import (
 	"LMS/models"
 	"LMS/repositories"
+	"LMS/storage"
+	"LMS/utils"
 	"errors"
+	"io"
+	"math"
+	"mime/multipart"
 	"time"
 
 )
 
 // SubmissionService menangani logika bisnis pengiriman tugas
 type SubmissionService struct {
-	SubmissionRepo *repositories.SubmissionRepository
-	AssignmentRepo *repositories.AssignmentRepository
-	EnrollmentRepo *repositories.EnrollmentRepository
-	UserRepo       *repositories.UserRepository
+	SubmissionRepo      *repositories.SubmissionRepository
+	AssignmentRepo      *repositories.AssignmentRepository
+	EnrollmentRepo      *repositories.EnrollmentRepository
+	UserRepo            *repositories.UserRepository
+	CourseRepo          *repositories.CourseRepository
+	ExtensionService    *ExtensionService
+	AvailabilityService *AvailabilityService
+	BlobService         *BlobService
+	Limits              UploadLimits
 }
 
 // NewSubmissionService membuat layanan pengiriman baru
@@ -22,17 +32,27 @@ func NewSubmissionService(
 	assignmentRepo *repositories.AssignmentRepository,
 	enrollmentRepo *repositories.EnrollmentRepository,
 	userRepo *repositories.UserRepository,
+	courseRepo *repositories.CourseRepository,
+	extensionService *ExtensionService,
+	availabilityService *AvailabilityService,
+	blobService *BlobService,
+	limits UploadLimits,
 ) *SubmissionService {
 	return &SubmissionService{
-		SubmissionRepo: submissionRepo,
-		AssignmentRepo: assignmentRepo,
-		EnrollmentRepo: enrollmentRepo,
-		UserRepo:       userRepo,
+		SubmissionRepo:      submissionRepo,
+		AssignmentRepo:      assignmentRepo,
+		EnrollmentRepo:      enrollmentRepo,
+		UserRepo:            userRepo,
+		CourseRepo:          courseRepo,
+		ExtensionService:    extensionService,
+		AvailabilityService: availabilityService,
+		BlobService:         blobService,
+		Limits:              limits,
 	}
 }
 
-// CreateSubmission membuat pengiriman baru untuk tugas
-func (s *SubmissionService) CreateSubmission(submission *models.Submission, filePath string) error {
+// CreateSubmission membuat pengiriman baru untuk tugas dan menyimpan berkasnya
+func (s *SubmissionService) CreateSubmission(submission *models.Submission, header *multipart.FileHeader) error {
 	// Verifikasi adanya penugasan
 	assignment, err := s.AssignmentRepo.FindByID(submission.AssignmentID)
 	if err != nil {
@@ -55,25 +75,61 @@ func (s *SubmissionService) CreateSubmission(submission *models.Submission, file
 		return errors.New("student is not enrolled in this course")
 	}
 
-	// Periksa tanggal jatuh tempo jika ditetapkan
-	if assignment.DueDate != nil && time.Now().After(*assignment.DueDate) {
-		return errors.New("assignment due date has passed")
+	// Tugas yang belum terbuka atau sudah ditarik tidak menerima kiriman
+	if err := s.AvailabilityService.CheckAccess(assignment.CourseID, submission.StudentID, models.ProgressTypeAssignment, assignment.ID, assignment.Availability); err != nil {
+		return err
 	}
 
-	// Periksa apakah siswa telah mengirimkan
-	existingSubmission, err := s.SubmissionRepo.FindByAssignmentAndStudent(submission.AssignmentID, submission.StudentID)
-	if err == nil && existingSubmission != nil {
-		// Jika ada kiriman, perbarui
-		existingSubmission.FilePath = filePath
-		existingSubmission.SubmittedAt = time.Now()
-		return s.SubmissionRepo.Update(existingSubmission)
+	// Terapkan kebijakan keterlambatan tugas dengan tenggat efektif siswa
+	s.ExtensionService.ApplyToAssignment(assignment, submission.StudentID)
+	now := time.Now()
+	isLate, daysLate, err := evaluateLateness(assignment, now)
+	if err != nil {
+		return err
+	}
+
+	// Setiap unggahan menjadi percobaan baru; percobaan lama tetap tersimpan
+	lastAttempt, err := s.SubmissionRepo.LastAttemptNumber(submission.AssignmentID, submission.StudentID)
+	if err != nil {
+		return err
+	}
+	if assignment.MaxAttempts != nil && lastAttempt >= *assignment.MaxAttempts {
+		return errors.New("maximum number of attempts reached")
+	}
+
+	// Periksa berkas terhadap aturan unggahan tugas
+	file, err := openUpload(header)
+	if err != nil {
+		return err
+	}
+	defer file.Reader.Close()
+
+	fileType, err := s.validateFile(assignment, file)
+	if err != nil {
+		return err
 	}
-	// Mengatur jalur file dan tanggal pengiriman
-	submission.FilePath = filePath
-	submission.SubmittedAt = time.Now()
 
-	// Buat kiriman baru
-	return s.SubmissionRepo.Create(submission)
+	// Simpan berkas setelah semua validasi lolos; isi yang sama dipakai bersama
+	blob, err := s.BlobService.Store(file.Reader, fileType.MIME)
+	if err != nil {
+		return errors.New("failed to save file")
+	}
+
+	// Mengatur nomor percobaan, jalur file dan tanggal pengiriman
+	submission.AttemptNumber = lastAttempt + 1
+	submission.FilePath = models.BlobKey(blob.Hash)
+	submission.FileHash = blob.Hash
+	submission.FileName = cleanFileName(file.Name)
+	submission.SubmittedAt = now
+	submission.IsLate = isLate
+	submission.DaysLate = daysLate
+
+	// Buat kiriman baru; lepaskan berkas jika gagal agar tidak tertinggal
+	if err := s.SubmissionRepo.Create(submission); err != nil {
+		s.BlobService.Release(blob.Hash, submission.FilePath)
+		return err
+	}
+	return nil
 }
 
 // GetSubmissionByID berdasrkan id
@@ -91,7 +147,92 @@ func (s *SubmissionService) GetSubmissionsByStudent(studentID uint) ([]models.Su
 	return s.SubmissionRepo.FindByStudent(studentID)
 }
 
-// DeleteSubmission untuk submission
+// GetSubmissionAttempts mendapatkan semua percobaan siswa untuk sebuah tugas
+func (s *SubmissionService) GetSubmissionAttempts(assignmentID, studentID uint) ([]models.Submission, error) {
+	if _, err := s.AssignmentRepo.FindByID(assignmentID); err != nil {
+		return nil, errors.New("assignment not found")
+	}
+	return s.SubmissionRepo.FindAttempts(assignmentID, studentID)
+}
+
+// DeleteSubmission untuk submission beserta berkasnya
 func (s *SubmissionService) DeleteSubmission(id uint) error {
-	return s.SubmissionRepo.Delete(id)
+	submission, err := s.SubmissionRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.SubmissionRepo.Delete(id); err != nil {
+		return err
+	}
+
+	s.BlobService.Release(submission.FileHash, submission.FilePath)
+	return nil
+}
+
+// OpenSubmissionFile membuka berkas kiriman dari penyimpanan
+func (s *SubmissionService) OpenSubmissionFile(submission *models.Submission) (io.ReadCloser, *storage.Object, error) {
+	return s.BlobService.Open(submission.FileHash, submission.FilePath)
+}
+
+// GetSubmissionDownloadURL membuat URL unduhan sementara untuk berkas kiriman
+func (s *SubmissionService) GetSubmissionDownloadURL(submission *models.Submission) (string, error) {
+	return s.BlobService.PresignedURL(submission.FileHash, submission.FilePath)
+}
+
+// validateFile memeriksa berkas kiriman; jenis berkas tugas menggantikan jenis berkas kursus
+// dan ukuran maksimal tugas hanya dapat memperketat batas aplikasi
+func (s *SubmissionService) validateFile(assignment *models.Assignment, file *uploadedFile) (*utils.FileType, error) {
+	rules := uploadRules{
+		MaxSize:      s.Limits.MaxSubmissionSize,
+		AllowedTypes: assignment.AllowedFileTypes,
+	}
+	if assignment.MaxFileSizeMB != nil {
+		if size := int64(*assignment.MaxFileSizeMB) << 20; rules.MaxSize <= 0 || size < rules.MaxSize {
+			rules.MaxSize = size
+		}
+	}
+	if rules.AllowedTypes == "" {
+		if course, err := s.CourseRepo.FindByID(assignment.CourseID); err == nil {
+			rules.AllowedTypes = course.AllowedFileTypes
+		}
+	}
+
+	return validateUpload(file, rules, s.Limits)
+}
+
+// evaluateLateness menentukan apakah kiriman terlambat dan berapa hari, atau menolaknya
+// jika keterlambatan tidak diizinkan atau batas akhir sudah lewat
+func evaluateLateness(assignment *models.Assignment, submittedAt time.Time) (bool, int, error) {
+	dueDate := assignment.DueDate
+	if assignment.EffectiveDueDate != nil {
+		dueDate = assignment.EffectiveDueDate
+	}
+	if dueDate == nil {
+		return false, 0, nil
+	}
+
+	// Masa tenggang tidak dihitung sebagai terlambat
+	grace := time.Duration(assignment.LateGracePeriodMinutes) * time.Minute
+	if !submittedAt.After(dueDate.Add(grace)) {
+		return false, 0, nil
+	}
+
+	if !assignment.AllowLateSubmissions {
+		return false, 0, errors.New("assignment due date has passed")
+	}
+	if assignment.LateCutoffDate != nil {
+		// Perpanjangan menggeser batas akhir sejauh tenggatnya digeser
+		cutoff := *assignment.LateCutoffDate
+		if assignment.DueDate != nil && dueDate.After(*assignment.DueDate) {
+			cutoff = cutoff.Add(dueDate.Sub(*assignment.DueDate))
+		}
+		if submittedAt.After(cutoff) {
+			return false, 0, errors.New("late submission cutoff has passed")
+		}
+	}
+
+	// Setiap hari yang dimulai setelah tanggal jatuh tempo dihitung sebagai satu hari terlambat
+	daysLate := int(math.Ceil(submittedAt.Sub(*dueDate).Hours() / 24))
+	return true, daysLate, nil
 }
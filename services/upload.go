@@ -0,0 +1,236 @@
+package services
+
+import (
+	"LMS/utils"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+)
+
+// DownloadURLExpiry adalah masa berlaku URL unduhan sementara
+const DownloadURLExpiry = 15 * time.Minute
+
+// UploadLimits adalah batas unggahan berkas yang berlaku untuk seluruh aplikasi
+type UploadLimits struct {
+	MaxMaterialSize            int64
+	MaxSubmissionSize          int64
+	MaxArchiveEntries          int
+	MaxArchiveUncompressedSize int64
+	MaxArchiveCompressionRatio int64
+	MaxHLSEntries              int
+	MaxResumableSize           int64
+	ResumableChunkSize         int64
+	UploadSessionTTL           time.Duration
+	StagingDir                 string
+}
+
+// DefaultUploadLimits mengembalikan batas unggahan bawaan
+func DefaultUploadLimits() UploadLimits {
+	return UploadLimits{
+		MaxMaterialSize:            100 << 20,
+		MaxSubmissionSize:          25 << 20,
+		MaxArchiveEntries:          1000,
+		MaxArchiveUncompressedSize: 500 << 20,
+		MaxArchiveCompressionRatio: 100,
+		MaxHLSEntries:              20000,
+		MaxResumableSize:           4 << 30,
+		ResumableChunkSize:         8 << 20,
+		UploadSessionTTL:           24 * time.Hour,
+		StagingDir:                 filepath.Join(os.TempDir(), "lms-uploads"),
+	}
+}
+
+// RequestSize mengembalikan ukuran badan permintaan terbesar untuk unggahan berkas berukuran maxFileSize,
+// ditambah ruang untuk kolom formulir dan pembatas multipart
+func (l UploadLimits) RequestSize(maxFileSize int64) int64 {
+	return maxFileSize + 1<<20
+}
+
+// Kode kesalahan validasi unggahan
+const (
+	UploadErrorTooLarge          = "file_too_large"
+	UploadErrorEmpty             = "file_empty"
+	UploadErrorUnknownType       = "file_type_unknown"
+	UploadErrorTypeNotAllowed    = "file_type_not_allowed"
+	UploadErrorExtensionMismatch = "file_extension_mismatch"
+	UploadErrorUnsafeArchive     = "archive_unsafe"
+)
+
+// UploadError adalah satu kesalahan validasi unggahan
+type UploadError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// UploadValidationError berisi semua kesalahan validasi sebuah unggahan
+type UploadValidationError struct {
+	Errors []UploadError
+}
+
+// Error mengembalikan pesan kesalahan pertama
+func (e *UploadValidationError) Error() string {
+	if len(e.Errors) == 0 {
+		return "file validation failed"
+	}
+	return e.Errors[0].Message
+}
+
+// uploadRules adalah aturan yang berlaku untuk satu unggahan
+type uploadRules struct {
+	MaxSize      int64
+	AllowedTypes string
+}
+
+// uploadReader adalah isi berkas unggahan yang dapat dibaca berulang dari posisi mana pun
+type uploadReader interface {
+	io.ReadSeekCloser
+	io.ReaderAt
+}
+
+// uploadedFile adalah berkas unggahan yang sudah dibuka, dari formulir multipart maupun unggahan bertahap
+type uploadedFile struct {
+	Name   string
+	Size   int64
+	Reader uploadReader
+}
+
+// openUpload membuka berkas dari formulir multipart; pemanggil wajib menutup Reader
+func openUpload(header *multipart.FileHeader) (*uploadedFile, error) {
+	reader, err := header.Open()
+	if err != nil {
+		return nil, errors.New("failed to read file")
+	}
+	return &uploadedFile{Name: header.Filename, Size: header.Size, Reader: reader}, nil
+}
+
+// openUploads membuka beberapa berkas dari formulir multipart; pemanggil wajib menutupnya dengan closeUploads
+func openUploads(headers []*multipart.FileHeader) ([]*uploadedFile, error) {
+	files := make([]*uploadedFile, 0, len(headers))
+	for _, header := range headers {
+		file, err := openUpload(header)
+		if err != nil {
+			closeUploads(files)
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// closeUploads menutup berkas-berkas unggahan
+func closeUploads(files []*uploadedFile) {
+	for _, file := range files {
+		file.Reader.Close()
+	}
+}
+
+// validateUpload memeriksa ukuran, jenis isi sebenarnya, ekstensi dan keamanan arsip sebuah unggahan
+func validateUpload(file *uploadedFile, rules uploadRules, limits UploadLimits) (*utils.FileType, error) {
+	var problems []UploadError
+	addProblem := func(code, message string) {
+		problems = append(problems, UploadError{Field: "file", Code: code, Message: message})
+	}
+
+	if file.Size == 0 {
+		addProblem(UploadErrorEmpty, "file is empty")
+		return nil, &UploadValidationError{Errors: problems}
+	}
+	if rules.MaxSize > 0 && file.Size > rules.MaxSize {
+		addProblem(UploadErrorTooLarge, fmt.Sprintf("file is %s, the maximum size is %s", formatBytes(file.Size), formatBytes(rules.MaxSize)))
+	}
+
+	reader := file.Reader
+	extension := strings.ToLower(filepath.Ext(file.Name))
+	fileType, err := utils.DetectFileType(reader, file.Size, extension)
+	if err != nil {
+		if errors.Is(err, utils.ErrUnknownFileType) {
+			addProblem(UploadErrorUnknownType, "file content does not match any supported file type")
+			return nil, &UploadValidationError{Errors: problems}
+		}
+		return nil, errors.New("failed to read file")
+	}
+
+	if rules.AllowedTypes != "" && !containsFileType(rules.AllowedTypes, fileType.Name) {
+		addProblem(UploadErrorTypeNotAllowed, fmt.Sprintf("file type %s is not allowed, accepted types are %s", fileType.Name, strings.ReplaceAll(rules.AllowedTypes, ",", ", ")))
+	}
+	if !fileType.HasExtension(extension) {
+		addProblem(UploadErrorExtensionMismatch, fmt.Sprintf("file extension %q does not match its %s content", extension, fileType.Name))
+	}
+
+	// Arsip hanya diperiksa jika lolos pemeriksaan lain karena dekompresinya mahal
+	if fileType.Archive && len(problems) == 0 {
+		err := utils.InspectArchive(reader, file.Size, limits.archiveLimits(fileType, file))
+		if err != nil {
+			addProblem(UploadErrorUnsafeArchive, err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &UploadValidationError{Errors: problems}
+	}
+	return fileType, nil
+}
+
+// archiveLimits mengembalikan batas pemeriksaan arsip untuk sebuah unggahan. Paket HLS berisi ribuan
+// segmen video yang biasanya disimpan tanpa kompresi, sehingga batasnya mengikuti unggahan bertahap.
+func (l UploadLimits) archiveLimits(fileType *utils.FileType, file *uploadedFile) utils.ArchiveLimits {
+	if fileType.Name == "zip" && utils.IsHLSPackage(file.Reader, file.Size) {
+		return utils.ArchiveLimits{
+			MaxEntries:          l.MaxHLSEntries,
+			MaxUncompressedSize: l.MaxResumableSize,
+			MaxCompressionRatio: l.MaxArchiveCompressionRatio,
+		}
+	}
+	return utils.ArchiveLimits{
+		MaxEntries:          l.MaxArchiveEntries,
+		MaxUncompressedSize: l.MaxArchiveUncompressedSize,
+		MaxCompressionRatio: l.MaxArchiveCompressionRatio,
+	}
+}
+
+// containsFileType memeriksa apakah daftar jenis berkas yang dipisahkan koma memuat nama tertentu
+func containsFileType(list, name string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+// formatBytes menampilkan ukuran berkas dalam satuan yang mudah dibaca
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
+
+// cleanFileName mengambil nama dasar berkas unggahan tanpa jalur dan karakter kontrol untuk ditampilkan kembali
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if len(name) > 255 {
+		extension := filepath.Ext(name)
+		if len(extension) > 16 {
+			extension = ""
+		}
+		name = strings.ToValidUTF8(name[:255-len(extension)], "") + extension
+	}
+	return name
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+)
+
+// memoryUpload adalah isi unggahan di memori yang memenuhi uploadReader
+type memoryUpload struct {
+	*bytes.Reader
+}
+
+func (memoryUpload) Close() error { return nil }
+
+// storedZip membuat arsip ZIP tanpa kompresi berisi segmen video sebanyak segments dengan ukuran segmentSize.
+// Jika withPlaylist, arsip diawali playlist HLS di akarnya.
+func storedZip(t *testing.T, withPlaylist bool, segments, segmentSize int) *uploadedFile {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	add := func(name string, content []byte) {
+		entry, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := entry.Write(content); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	if withPlaylist {
+		add("index.m3u8", []byte("#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-ENDLIST\n"))
+	}
+	segment := bytes.Repeat([]byte{0x47}, segmentSize)
+	for i := 0; i < segments; i++ {
+		add(fmt.Sprintf("segments/%05d.ts", i), segment)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	return &uploadedFile{
+		Name:   "lecture.zip",
+		Size:   int64(buffer.Len()),
+		Reader: memoryUpload{bytes.NewReader(buffer.Bytes())},
+	}
+}
+
+func TestValidateUploadLargeStoredHLSPackage(t *testing.T) {
+	manyEntries := DefaultUploadLimits()
+
+	// Batas arsip biasa diperkecil agar ukuran dekompresi terlampaui tanpa membuat arsip ratusan MB
+	smallArchives := DefaultUploadLimits()
+	smallArchives.MaxArchiveUncompressedSize = 1 << 20
+
+	tests := []struct {
+		name        string
+		limits      UploadLimits
+		segments    int
+		segmentSize int
+	}{
+		{"more entries than the archive limit", manyEntries, 1500, 1 << 10},
+		{"larger than the archive size limit", smallArchives, 48, 64 << 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := uploadRules{MaxSize: tt.limits.MaxResumableSize, AllowedTypes: "zip"}
+
+			hls := storedZip(t, true, tt.segments, tt.segmentSize)
+			fileType, err := validateUpload(hls, rules, tt.limits)
+			if err != nil {
+				t.Fatalf("HLS package rejected: %v", err)
+			}
+			if fileType.Name != "zip" {
+				t.Errorf("file type = %s, want zip", fileType.Name)
+			}
+
+			// Arsip biasa dengan isi yang sama tetap terkena batas arsip
+			plain := storedZip(t, false, tt.segments, tt.segmentSize)
+			_, err = validateUpload(plain, rules, tt.limits)
+			var validationErr *UploadValidationError
+			if !errors.As(err, &validationErr) || validationErr.Errors[0].Code != UploadErrorUnsafeArchive {
+				t.Fatalf("plain archive error = %v, want %s", err, UploadErrorUnsafeArchive)
+			}
+		})
+	}
+}
+
+func TestValidateUploadHLSPackageStillChecksPaths(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for _, name := range []string{"index.m3u8", "../escape.ts"} {
+		entry, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		entry.Write([]byte("#EXTM3U\n"))
+	}
+	writer.Close()
+
+	file := &uploadedFile{Name: "lecture.zip", Size: int64(buffer.Len()), Reader: memoryUpload{bytes.NewReader(buffer.Bytes())}}
+	_, err := validateUpload(file, uploadRules{AllowedTypes: "zip"}, DefaultUploadLimits())
+	var validationErr *UploadValidationError
+	if !errors.As(err, &validationErr) || validationErr.Errors[0].Code != UploadErrorUnsafeArchive {
+		t.Fatalf("error = %v, want %s", err, UploadErrorUnsafeArchive)
+	}
+}
@@ -0,0 +1,290 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+)
+
+// LocalBackend menyimpan berkas di sistem berkas lokal di bawah satu direktori akar.
+// URL sementara diarahkan ke rute unduhan aplikasi dan ditandatangani dengan HMAC.
+type LocalBackend struct {
+	Root       string
+	PublicURL  string
+	SigningKey []byte
+}
+
+// NewLocalBackend membuat penyimpanan lokal dan memastikan direktori akarnya ada
+func NewLocalBackend(root, publicURL string, signingKey []byte) (*LocalBackend, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalBackend{
+		Root:       root,
+		PublicURL:  strings.TrimRight(publicURL, "/"),
+		SigningKey: signingKey,
+	}, nil
+}
+
+// path mengubah kunci objek menjadi jalur berkas di bawah direktori akar
+func (b *LocalBackend) path(key string) (string, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(b.Root, filepath.FromSlash(cleaned)), nil
+}
+
+// Put menyimpan berkas melalui berkas sementara agar pembaca tidak melihat berkas setengah jadi
+func (b *LocalBackend) Put(key string, reader io.Reader, size int64, contentType string) error {
+	filePath, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	written, err := io.Copy(temp, reader)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("expected %d bytes but received %d", size, written)
+	}
+
+	return os.Rename(temp.Name(), filePath)
+}
+
+// Get membuka berkas untuk dibaca
+func (b *LocalBackend) Get(key string) (io.ReadCloser, *Object, error) {
+	filePath, err := b.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	return file, b.object(key, info), nil
+}
+
+// GetRange membuka sebagian isi berkas
+func (b *LocalBackend) GetRange(key string, offset, length int64) (io.ReadCloser, error) {
+	filePath, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &sectionReadCloser{Reader: io.NewSectionReader(file, offset, length), closer: file}, nil
+}
+
+// sectionReadCloser membaca sebagian berkas dan menutup berkasnya saat selesai
+type sectionReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+// Close menutup berkas
+func (r *sectionReadCloser) Close() error {
+	return r.closer.Close()
+}
+
+// Delete menghapus berkas
+func (b *LocalBackend) Delete(key string) error {
+	filePath, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Stat mengembalikan metadata berkas
+func (b *LocalBackend) Stat(key string) (*Object, error) {
+	filePath, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, ErrNotFound
+	}
+
+	return b.object(key, info), nil
+}
+
+// List mengembalikan semua berkas dengan awalan kunci tertentu, tanpa berkas sementara unggahan
+func (b *LocalBackend) List(prefix string) ([]Object, error) {
+	var objects []Object
+	err := filepath.WalkDir(b.Root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".upload-") {
+			return nil
+		}
+
+		relative, err := filepath.Rel(b.Root, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(relative)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *b.object(key, info))
+		return nil
+	})
+	return objects, err
+}
+
+// PresignedURL membuat URL unduhan bertanda tangan yang berlaku selama expiry
+func (b *LocalBackend) PresignedURL(key string, expiry time.Duration) (string, error) {
+	return b.PresignedURLWithParams(key, expiry, nil)
+}
+
+// PresignedURLWithParams membuat URL unduhan bertanda tangan dengan parameter tambahan yang ikut ditandatangani,
+// sehingga rute unduhan dapat mempercayai parameter itu setelah VerifySignature berhasil
+func (b *LocalBackend) PresignedURLWithParams(key string, expiry time.Duration, params url.Values) (string, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if _, err := b.Stat(cleaned); err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(expiry).Unix()
+	query := url.Values{}
+	for name, values := range params {
+		query[name] = values
+	}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", b.sign(cleaned, expires, params))
+
+	return fmt.Sprintf("%s/api/files/%s?%s", b.PublicURL, escapeKey(cleaned), query.Encode()), nil
+}
+
+// VerifySignature memeriksa tanda tangan dan masa berlaku URL unduhan lokal beserta parameter tambahannya
+func (b *LocalBackend) VerifySignature(key string, query url.Values) error {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil {
+		return errors.New("invalid expiry")
+	}
+	if time.Now().Unix() > expiresAt {
+		return errors.New("link has expired")
+	}
+
+	params := url.Values{}
+	for name, values := range query {
+		if name != "expires" && name != "signature" {
+			params[name] = values
+		}
+	}
+
+	if !hmac.Equal([]byte(b.sign(cleaned, expiresAt, params)), []byte(query.Get("signature"))) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// sign menandatangani kunci, waktu kedaluwarsa dan parameter tambahan; tanpa parameter hasilnya
+// sama dengan URL yang diterbitkan sebelum parameter tambahan ada
+func (b *LocalBackend) sign(key string, expires int64, params url.Values) string {
+	mac := hmac.New(sha256.New, b.SigningKey)
+	mac.Write([]byte(key + "\n" + strconv.FormatInt(expires, 10)))
+	if len(params) > 0 {
+		mac.Write([]byte("\n" + params.Encode()))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// object membuat metadata dari informasi berkas
+func (b *LocalBackend) object(key string, info os.FileInfo) *Object {
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	// ETag lemah dari ukuran dan waktu modifikasi, cukup untuk validasi cache
+	sum := md5.Sum([]byte(fmt.Sprintf("%s:%d:%d", key, info.Size(), info.ModTime().UnixNano())))
+
+	return &Object{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  contentType,
+		LastModified: info.ModTime(),
+		ETag:         hex.EncodeToString(sum[:]),
+	}
+}
+
+// escapeKey meloloskan setiap segmen kunci untuk dipakai dalam jalur URL
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
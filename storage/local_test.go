@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+)
+
+func TestLocalBackendSignedParams(t *testing.T) {
+	backend, err := NewLocalBackend(t.TempDir(), "http://localhost:8080", []byte("secret"))
+	if err != nil {
+		t.Fatalf("NewLocalBackend: %v", err)
+	}
+	if err := backend.Put("materials/a.pdf", strings.NewReader("pdf"), 3, "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	params := url.Values{}
+	params.Set("material", "5")
+	params.Set("student", "7")
+	signed, err := backend.PresignedURLWithParams("materials/a.pdf", time.Minute, params)
+	if err != nil {
+		t.Fatalf("PresignedURLWithParams: %v", err)
+	}
+	link, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("parse %s: %v", signed, err)
+	}
+	if link.Path != "/api/files/materials/a.pdf" {
+		t.Errorf("path = %q", link.Path)
+	}
+
+	query := link.Query()
+	if err := backend.VerifySignature("materials/a.pdf", query); err != nil {
+		t.Errorf("VerifySignature: %v", err)
+	}
+	if query.Get("material") != "5" || query.Get("student") != "7" {
+		t.Errorf("signed params = %v", query)
+	}
+
+	tampered := link.Query()
+	tampered.Set("student", "8")
+	if err := backend.VerifySignature("materials/a.pdf", tampered); err == nil {
+		t.Error("changed parameter must invalidate the signature")
+	}
+
+	added := link.Query()
+	added.Set("extra", "1")
+	if err := backend.VerifySignature("materials/a.pdf", added); err == nil {
+		t.Error("added parameter must invalidate the signature")
+	}
+
+	if err := backend.VerifySignature("materials/b.pdf", query); err == nil {
+		t.Error("signature must be bound to the key")
+	}
+
+	plain, err := backend.PresignedURL("materials/a.pdf", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignedURL: %v", err)
+	}
+	plainLink, _ := url.Parse(plain)
+	if err := backend.VerifySignature("materials/a.pdf", plainLink.Query()); err != nil {
+		t.Errorf("VerifySignature without params: %v", err)
+	}
+
+	expired := url.Values{}
+	expired.Set("expires", "1")
+	expired.Set("signature", backend.sign("materials/a.pdf", 1, nil))
+	if err := backend.VerifySignature("materials/a.pdf", expired); err == nil {
+		t.Error("expired link must be rejected")
+	}
+}
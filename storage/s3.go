@@ -0,0 +1,441 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+)
+
+// unsignedPayload dipakai agar isi unggahan dapat dialirkan tanpa dihitung hash-nya lebih dulu
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3Config adalah konfigurasi penyimpanan yang kompatibel dengan S3 (AWS S3, MinIO, dan sejenisnya)
+type S3Config struct {
+	Endpoint     string
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	UsePathStyle bool
+}
+
+// S3Backend menyimpan berkas di bucket yang kompatibel dengan S3 memakai permintaan bertanda tangan SigV4
+type S3Backend struct {
+	Config S3Config
+	Client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Backend membuat penyimpanan S3
+func NewS3Backend(config S3Config) (*S3Backend, error) {
+	if config.Endpoint == "" || config.Bucket == "" {
+		return nil, errors.New("S3 endpoint and bucket are required")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, errors.New("S3 access key and secret key are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if !strings.Contains(config.Endpoint, "://") {
+		config.Endpoint = "https://" + config.Endpoint
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+
+	return &S3Backend{
+		Config: config,
+		Client: &http.Client{},
+		now:    time.Now,
+	}, nil
+}
+
+// Put mengunggah objek
+func (b *S3Backend) Put(key string, reader io.Reader, size int64, contentType string) error {
+	request, err := b.newRequest(http.MethodPut, key, reader)
+	if err != nil {
+		return err
+	}
+	if size >= 0 {
+		request.ContentLength = size
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+
+	response, err := b.do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+// Get mengunduh objek
+func (b *S3Backend) Get(key string) (io.ReadCloser, *Object, error) {
+	request, err := b.newRequest(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	response, err := b.do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return response.Body, objectFromResponse(key, response), nil
+}
+
+// GetRange membuka sebagian isi objek dengan header Range
+func (b *S3Backend) GetRange(key string, offset, length int64) (io.ReadCloser, error) {
+	if offset < 0 || length <= 0 {
+		return nil, errors.New("invalid range")
+	}
+
+	request, err := b.newRequest(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	response, err := b.do(request)
+	if err != nil {
+		return nil, err
+	}
+	// Server yang mengabaikan Range mengirim seluruh objek; lewati bagian awalnya
+	if response.StatusCode != http.StatusPartialContent && offset > 0 {
+		if _, err := io.CopyN(io.Discard, response.Body, offset); err != nil {
+			response.Body.Close()
+			return nil, err
+		}
+	}
+
+	return &sectionReadCloser{Reader: io.LimitReader(response.Body, length), closer: response.Body}, nil
+}
+
+// Delete menghapus objek
+func (b *S3Backend) Delete(key string) error {
+	request, err := b.newRequest(http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := b.do(request)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+// Stat mengambil metadata objek dengan permintaan HEAD
+func (b *S3Backend) Stat(key string) (*Object, error) {
+	request, err := b.newRequest(http.MethodHead, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := b.do(request)
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+
+	return objectFromResponse(key, response), nil
+}
+
+// PresignedURL membuat URL GET bertanda tangan di query string yang berlaku selama expiry (maksimal 7 hari)
+func (b *S3Backend) PresignedURL(key string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > 7*24*time.Hour {
+		return "", errors.New("expiry must be between 1 second and 7 days")
+	}
+
+	objectURL, err := b.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+
+	now := b.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := b.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", b.Config.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+	objectURL.RawQuery = canonicalQuery(query)
+
+	canonicalRequest := strings.Join([]string{
+		http.MethodGet,
+		objectURL.EscapedPath(),
+		objectURL.RawQuery,
+		"host:" + objectURL.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	signature := b.signature(now, amzDate, scope, canonicalRequest)
+	objectURL.RawQuery += "&X-Amz-Signature=" + signature
+
+	return objectURL.String(), nil
+}
+
+// List mengembalikan semua objek dengan awalan kunci tertentu memakai ListObjectsV2
+func (b *S3Backend) List(prefix string) ([]Object, error) {
+	var objects []Object
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		bucketURL, err := b.bucketURL()
+		if err != nil {
+			return nil, err
+		}
+		bucketURL.RawQuery = canonicalQuery(query)
+
+		request, err := b.signedRequest(http.MethodGet, bucketURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		response, err := b.do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(response.Body).Decode(&result)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{
+				Key:          content.Key,
+				Size:         content.Size,
+				LastModified: content.LastModified,
+				ETag:         strings.Trim(content.ETag, `"`),
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// listBucketResult adalah respons XML ListObjectsV2
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// newRequest membuat permintaan ke objek yang ditandatangani dengan header Authorization SigV4
+func (b *S3Backend) newRequest(method, key string, body io.Reader) (*http.Request, error) {
+	objectURL, err := b.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	return b.signedRequest(method, objectURL, body)
+}
+
+// signedRequest membuat permintaan ke URL bucket atau objek dan menandatanganinya
+func (b *S3Backend) signedRequest(method string, objectURL *url.URL, body io.Reader) (*http.Request, error) {
+	request, err := http.NewRequest(method, objectURL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := b.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := b.scope(now)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + objectURL.Host + "\n" +
+		"x-amz-content-sha256:" + unsignedPayload + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		objectURL.EscapedPath(),
+		objectURL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		unsignedPayload,
+	}, "\n")
+
+	signature := b.signature(now, amzDate, scope, canonicalRequest)
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.Config.AccessKey, scope, signedHeaders, signature,
+	))
+
+	return request, nil
+}
+
+// do mengirim permintaan dan mengubah status kesalahan menjadi error
+func (b *S3Backend) do(request *http.Request) (*http.Response, error) {
+	response, err := b.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, ErrNotFound
+	}
+	if response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		response.Body.Close()
+		return nil, fmt.Errorf("storage request failed with status %d: %s", response.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return response, nil
+}
+
+// bucketURL membuat URL bucket untuk operasi daftar objek
+func (b *S3Backend) bucketURL() (*url.URL, error) {
+	endpoint, err := url.Parse(b.Config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Config.UsePathStyle {
+		endpoint.Path = "/" + b.Config.Bucket + "/"
+	} else {
+		endpoint.Host = b.Config.Bucket + "." + endpoint.Host
+		endpoint.Path = "/"
+	}
+	endpoint.RawPath = ""
+	return endpoint, nil
+}
+
+// objectURL membuat URL objek dengan gaya jalur (endpoint/bucket/key) atau gaya virtual host (bucket.endpoint/key)
+func (b *S3Backend) objectURL(key string) (*url.URL, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := url.Parse(b.Config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	objectPath := "/" + cleaned
+	if b.Config.UsePathStyle {
+		objectPath = "/" + b.Config.Bucket + objectPath
+	} else {
+		endpoint.Host = b.Config.Bucket + "." + endpoint.Host
+	}
+
+	endpoint.Path = objectPath
+	endpoint.RawPath = awsURIEncode(objectPath, false)
+	return endpoint, nil
+}
+
+// scope membuat cakupan kredensial SigV4 untuk tanggal tertentu
+func (b *S3Backend) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + b.Config.Region + "/s3/aws4_request"
+}
+
+// signature menghitung tanda tangan SigV4 dari permintaan kanonis
+func (b *S3Backend) signature(now time.Time, amzDate, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+b.Config.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, b.Config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// objectFromResponse membaca metadata objek dari header respons
+func objectFromResponse(key string, response *http.Response) *Object {
+	object := &Object{
+		Key:         key,
+		Size:        response.ContentLength,
+		ContentType: response.Header.Get("Content-Type"),
+		ETag:        strings.Trim(response.Header.Get("ETag"), `"`),
+	}
+	if lastModified, err := http.ParseTime(response.Header.Get("Last-Modified")); err == nil {
+		object.LastModified = lastModified
+	}
+	return object
+}
+
+// canonicalQuery menyusun query string terurut dengan pengkodean SigV4
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			pairs = append(pairs, awsURIEncode(key, true)+"="+awsURIEncode(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode meloloskan semua karakter kecuali karakter tanpa cadangan RFC 3986;
+// garis miring dipertahankan kecuali encodeSlash bernilai true
+func awsURIEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, c := range []byte(value) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			encoded.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
+
+// hmacSHA256 menghitung HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"io"
+
+)
+
+// RangeOpener membuka length byte isi sebuah objek mulai dari offset
+type RangeOpener func(offset, length int64) (io.ReadCloser, error)
+
+// ReadSeeker membaca objek penyimpanan dari posisi mana pun. Isi baru diambil saat dibaca,
+// sehingga berpindah posisi tidak mengunduh bagian yang dilewati.
+type ReadSeeker struct {
+	open   RangeOpener
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+// NewReadSeeker membuat ReadSeeker untuk objek berukuran size
+func NewReadSeeker(size int64, open RangeOpener) *ReadSeeker {
+	return &ReadSeeker{open: open, size: size}
+}
+
+// Size mengembalikan ukuran objek
+func (r *ReadSeeker) Size() int64 {
+	return r.size
+}
+
+// Read membaca dari posisi saat ini sampai akhir objek
+func (r *ReadSeeker) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body == nil {
+		body, err := r.open(r.offset, r.size-r.offset)
+		if err != nil {
+			return 0, err
+		}
+		r.body = body
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	if err == io.EOF && r.offset < r.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// Seek berpindah posisi baca; potongan yang sedang terbuka ditutup jika posisinya berubah
+func (r *ReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	if offset != r.offset && r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// ReadAt membaca len(p) byte mulai dari off tanpa mengubah posisi baca
+func (r *ReadSeeker) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	length := int64(len(p))
+	if off+length > r.size {
+		length = r.size - off
+	}
+
+	body, err := r.open(off, length)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	n, err := io.ReadFull(body, p[:length])
+	if err == nil && int64(n) < int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Close menutup potongan yang sedang terbuka
+func (r *ReadSeeker) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+)
+
+// ErrNotFound dikembalikan ketika objek tidak ada di penyimpanan
+var ErrNotFound = errors.New("file not found")
+
+// ErrInvalidKey dikembalikan ketika kunci objek kosong atau keluar dari akar penyimpanan
+var ErrInvalidKey = errors.New("invalid file key")
+
+// Object adalah metadata sebuah berkas di penyimpanan
+type Object struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+	ETag         string
+}
+
+// Backend adalah penyimpanan berkas untuk materi dan kiriman.
+// Kunci memakai garis miring sebagai pemisah, misalnya "materials/material_1.pdf".
+type Backend interface {
+	// Put menyimpan isi reader dengan kunci tertentu; size boleh -1 jika tidak diketahui
+	Put(key string, reader io.Reader, size int64, contentType string) error
+	// Get membuka objek untuk dibaca beserta metadatanya
+	Get(key string) (io.ReadCloser, *Object, error)
+	// GetRange membuka length byte isi objek mulai dari offset
+	GetRange(key string, offset, length int64) (io.ReadCloser, error)
+	// Delete menghapus objek; menghapus objek yang tidak ada bukan kesalahan
+	Delete(key string) error
+	// Stat mengembalikan metadata objek tanpa membaca isinya
+	Stat(key string) (*Object, error)
+	// PresignedURL membuat URL sementara untuk mengunduh objek tanpa token akses
+	PresignedURL(key string, expiry time.Duration) (string, error)
+	// List mengembalikan semua objek dengan awalan kunci tertentu
+	List(prefix string) ([]Object, error)
+}
+
+// CleanKey menormalkan kunci objek dan menolak kunci yang keluar dari akar penyimpanan
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	if key == "" || strings.HasPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+)
+
+// ErrUnsupportedVideoURL dikembalikan jika URL video tidak dapat disematkan
+var ErrUnsupportedVideoURL = errors.New("unsupported video URL, use a YouTube or Vimeo link or a direct .mp4, .webm or .m3u8 file")
+
+// Pola ID video
+var (
+	youTubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+	vimeoIDPattern   = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// directVideoExtensions adalah ekstensi berkas video yang dapat diputar langsung oleh pemutar peramban
+var directVideoExtensions = map[string]bool{".mp4": true, ".webm": true, ".ogv": true, ".m3u8": true}
+
+// VideoEmbedURL mengubah URL video menjadi URL yang dapat disematkan: halaman YouTube dan Vimeo
+// menjadi URL pemutar mereka, sedangkan berkas video langsung dikembalikan apa adanya
+func VideoEmbedURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", ErrUnsupportedVideoURL
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+
+	switch host {
+	case "youtube.com", "m.youtube.com", "youtube-nocookie.com":
+		id := parsed.Query().Get("v")
+		if len(segments) == 2 && (segments[0] == "embed" || segments[0] == "shorts" || segments[0] == "live") {
+			id = segments[1]
+		}
+		if youTubeIDPattern.MatchString(id) {
+			return "https://www.youtube-nocookie.com/embed/" + id, nil
+		}
+	case "youtu.be":
+		if len(segments) == 1 && youTubeIDPattern.MatchString(segments[0]) {
+			return "https://www.youtube-nocookie.com/embed/" + segments[0], nil
+		}
+	case "vimeo.com", "player.vimeo.com":
+		id := segments[len(segments)-1]
+		if vimeoIDPattern.MatchString(id) {
+			return "https://player.vimeo.com/video/" + id, nil
+		}
+	default:
+		if directVideoExtensions[strings.ToLower(path.Ext(parsed.Path))] {
+			return parsed.String(), nil
+		}
+	}
+	return "", ErrUnsupportedVideoURL
+}
@@ -0,0 +1,282 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+)
+
+// FileType adalah jenis berkas yang dikenali dari isinya
+type FileType struct {
+	Name       string
+	MIME       string
+	Extensions []string
+	Archive    bool
+}
+
+// fileTypes adalah daftar jenis berkas yang dikenali; ekstensi pertama adalah ekstensi baku
+var fileTypes = []FileType{
+	{Name: "pdf", MIME: "application/pdf", Extensions: []string{".pdf"}},
+	{Name: "zip", MIME: "application/zip", Extensions: []string{".zip"}, Archive: true},
+	{Name: "docx", MIME: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Extensions: []string{".docx"}, Archive: true},
+	{Name: "xlsx", MIME: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extensions: []string{".xlsx"}, Archive: true},
+	{Name: "pptx", MIME: "application/vnd.openxmlformats-officedocument.presentationml.presentation", Extensions: []string{".pptx"}, Archive: true},
+	{Name: "doc", MIME: "application/msword", Extensions: []string{".doc"}},
+	{Name: "xls", MIME: "application/vnd.ms-excel", Extensions: []string{".xls"}},
+	{Name: "ppt", MIME: "application/vnd.ms-powerpoint", Extensions: []string{".ppt"}},
+	{Name: "png", MIME: "image/png", Extensions: []string{".png"}},
+	{Name: "jpg", MIME: "image/jpeg", Extensions: []string{".jpg", ".jpeg"}},
+	{Name: "gif", MIME: "image/gif", Extensions: []string{".gif"}},
+	{Name: "webp", MIME: "image/webp", Extensions: []string{".webp"}},
+	{Name: "mp4", MIME: "video/mp4", Extensions: []string{".mp4", ".m4v"}},
+	{Name: "webm", MIME: "video/webm", Extensions: []string{".webm"}},
+	{Name: "mp3", MIME: "audio/mpeg", Extensions: []string{".mp3"}},
+	{Name: "txt", MIME: "text/plain; charset=utf-8", Extensions: []string{".txt"}},
+	{Name: "csv", MIME: "text/csv; charset=utf-8", Extensions: []string{".csv"}},
+	{Name: "md", MIME: "text/markdown; charset=utf-8", Extensions: []string{".md", ".markdown"}},
+}
+
+// ErrUnknownFileType dikembalikan ketika isi berkas tidak cocok dengan jenis yang dikenali
+var ErrUnknownFileType = errors.New("file type is not recognized")
+
+// LookupFileType mencari jenis berkas berdasarkan nama, misalnya "pdf"
+func LookupFileType(name string) (*FileType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i := range fileTypes {
+		if fileTypes[i].Name == name {
+			return &fileTypes[i], true
+		}
+	}
+	return nil, false
+}
+
+// FileTypeByExtension mencari jenis berkas berdasarkan ekstensi, misalnya ".pdf"
+func FileTypeByExtension(extension string) (*FileType, bool) {
+	for i := range fileTypes {
+		if fileTypes[i].HasExtension(extension) {
+			return &fileTypes[i], true
+		}
+	}
+	return nil, false
+}
+
+// FileTypeNames mengembalikan nama semua jenis berkas yang dikenali
+func FileTypeNames() []string {
+	names := make([]string, 0, len(fileTypes))
+	for _, fileType := range fileTypes {
+		names = append(names, fileType.Name)
+	}
+	return names
+}
+
+// ParseFileTypes menormalkan daftar jenis berkas yang dipisahkan koma, misalnya "PDF, zip" menjadi "pdf,zip"
+func ParseFileTypes(value string) (string, error) {
+	seen := map[string]bool{}
+	var names []string
+	for _, part := range strings.Split(value, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := LookupFileType(name); !ok {
+			return "", fmt.Errorf("unknown file type %q, allowed values are %s", name, strings.Join(FileTypeNames(), ", "))
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ","), nil
+}
+
+// HasExtension memeriksa apakah ekstensi termasuk ekstensi jenis berkas
+func (t *FileType) HasExtension(extension string) bool {
+	extension = strings.ToLower(extension)
+	for _, candidate := range t.Extensions {
+		if candidate == extension {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectFileType mengenali jenis berkas dari magic bytes di awal isinya.
+// Ekstensi hanya dipakai untuk membedakan jenis yang isinya serupa (teks biasa, CSV, markdown, dokumen Office lama).
+func DetectFileType(reader io.ReaderAt, size int64, extension string) (*FileType, error) {
+	header := make([]byte, 512)
+	n, err := reader.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	header = header[:n]
+	extension = strings.ToLower(extension)
+
+	name := ""
+	switch {
+	case bytes.HasPrefix(header, []byte("%PDF-")):
+		name = "pdf"
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		name = detectZipType(reader, size)
+	case bytes.HasPrefix(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}):
+		// Dokumen Office lama memakai wadah OLE yang sama
+		name = "doc"
+		for _, candidate := range []string{"doc", "xls", "ppt"} {
+			if fileType, _ := LookupFileType(candidate); fileType.HasExtension(extension) {
+				name = candidate
+			}
+		}
+	case bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")):
+		name = "png"
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}):
+		name = "jpg"
+	case bytes.HasPrefix(header, []byte("GIF87a")), bytes.HasPrefix(header, []byte("GIF89a")):
+		name = "gif"
+	case len(header) >= 12 && bytes.Equal(header[0:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WEBP")):
+		name = "webp"
+	case len(header) >= 12 && bytes.Equal(header[4:8], []byte("ftyp")):
+		name = "mp4"
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		name = "webm"
+	case bytes.HasPrefix(header, []byte("ID3")), len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		name = "mp3"
+	case isText(header):
+		name = "txt"
+		for _, candidate := range []string{"csv", "md"} {
+			if fileType, _ := LookupFileType(candidate); fileType.HasExtension(extension) {
+				name = candidate
+			}
+		}
+	}
+
+	fileType, ok := LookupFileType(name)
+	if !ok {
+		return nil, ErrUnknownFileType
+	}
+	return fileType, nil
+}
+
+// detectZipType membedakan arsip ZIP biasa dari dokumen Office Open XML berdasarkan isinya
+func detectZipType(reader io.ReaderAt, size int64) string {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return "zip"
+	}
+	for _, file := range archive.File {
+		switch {
+		case strings.HasPrefix(file.Name, "word/"):
+			return "docx"
+		case strings.HasPrefix(file.Name, "xl/"):
+			return "xlsx"
+		case strings.HasPrefix(file.Name, "ppt/"):
+			return "pptx"
+		}
+	}
+	return "zip"
+}
+
+// isText memeriksa apakah awal berkas adalah teks UTF-8 tanpa karakter kontrol biner
+func isText(header []byte) bool {
+	if len(header) == 0 {
+		return false
+	}
+	// Potongan terakhir bisa memotong karakter multibita; buang hanya karakter terakhir yang tidak lengkap
+	for i := 1; i < utf8.UTFMax && i <= len(header); i++ {
+		if utf8.RuneStart(header[len(header)-i]) {
+			if !utf8.FullRune(header[len(header)-i:]) {
+				header = header[:len(header)-i]
+			}
+			break
+		}
+	}
+	if len(header) == 0 || !utf8.Valid(header) {
+		return false
+	}
+	for _, c := range header {
+		if c < 0x20 && c != '\n' && c != '\r' && c != '\t' && c != '\f' {
+			return false
+		}
+	}
+	return true
+}
+
+// ArchiveLimits adalah batas pemeriksaan isi arsip
+type ArchiveLimits struct {
+	MaxEntries          int
+	MaxUncompressedSize int64
+	MaxCompressionRatio int64
+}
+
+// InspectArchive memeriksa arsip ZIP terhadap nama entri yang keluar dari folder tujuan dan bom ZIP.
+// Isi setiap entri benar-benar didekompresi (dengan batas) karena ukuran di header arsip bisa dipalsukan.
+func InspectArchive(reader io.ReaderAt, size int64, limits ArchiveLimits) error {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return errors.New("archive is corrupted or unreadable")
+	}
+
+	if limits.MaxEntries > 0 && len(archive.File) > limits.MaxEntries {
+		return fmt.Errorf("archive contains %d entries, the limit is %d", len(archive.File), limits.MaxEntries)
+	}
+
+	remaining := limits.MaxUncompressedSize
+	for _, file := range archive.File {
+		if unsafeArchivePath(file.Name) {
+			return fmt.Errorf("archive entry %q points outside the archive", file.Name)
+		}
+		if file.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("archive entry %q is a symbolic link", file.Name)
+		}
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		// Pemeriksaan cepat dari header sebelum dekompresi
+		if limits.MaxCompressionRatio > 0 && file.CompressedSize64 > 0 &&
+			file.UncompressedSize64/file.CompressedSize64 > uint64(limits.MaxCompressionRatio) && file.UncompressedSize64 > 1<<20 {
+			return fmt.Errorf("archive entry %q has a suspicious compression ratio", file.Name)
+		}
+		if file.UncompressedSize64 > uint64(remaining) {
+			return errors.New("archive uncompressed size exceeds the limit")
+		}
+
+		entry, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("archive entry %q cannot be read", file.Name)
+		}
+		written, err := io.Copy(io.Discard, io.LimitReader(entry, remaining+1))
+		entry.Close()
+		if err != nil {
+			return fmt.Errorf("archive entry %q is corrupted", file.Name)
+		}
+		if written > remaining {
+			return errors.New("archive uncompressed size exceeds the limit")
+		}
+		remaining -= written
+	}
+	return nil
+}
+
+// unsafeArchivePath memeriksa nama entri yang absolut atau naik keluar folder tujuan
+func unsafeArchivePath(name string) bool {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || strings.HasPrefix(name, "/") {
+		return true
+	}
+	// Huruf drive Windows, misalnya "C:/..."
+	if len(name) >= 2 && name[1] == ':' {
+		return true
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return true
+		}
+	}
+	return strings.HasPrefix(path.Clean(name), "..")
+}
@@ -0,0 +1,309 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"strings"
+	"testing"
+
+)
+
+// zipEntry adalah satu entri arsip uji
+type zipEntry struct {
+	name    string
+	content []byte
+	method  uint16
+	mode    os.FileMode
+}
+
+// buildZip membuat arsip ZIP dari entri-entri uji
+func buildZip(t *testing.T, entries ...zipEntry) []byte {
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: entry.method}
+		if entry.mode != 0 {
+			header.SetMode(entry.mode)
+		}
+		file, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("create %s: %v", entry.name, err)
+		}
+		if _, err := file.Write(entry.content); err != nil {
+			t.Fatalf("write %s: %v", entry.name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+// forgedSizeZip membuat arsip yang header-nya mengaku berukuran kecil padahal isinya mengembang menjadi size bita
+func forgedSizeZip(t *testing.T, size int) []byte {
+	content := make([]byte, size)
+	var compressed bytes.Buffer
+	compressor, _ := flate.NewWriter(&compressed, flate.BestCompression)
+	compressor.Write(content)
+	compressor.Close()
+
+	var buffer bytes.Buffer
+	writer := zip.NewWriter(&buffer)
+	file, err := writer.CreateRaw(&zip.FileHeader{
+		Name:               "small.txt",
+		Method:             zip.Deflate,
+		CRC32:              crc32.ChecksumIEEE(content),
+		CompressedSize64:   uint64(compressed.Len()),
+		UncompressedSize64: 10,
+	})
+	if err != nil {
+		t.Fatalf("create raw entry: %v", err)
+	}
+	file.Write(compressed.Bytes())
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func TestDetectFileType(t *testing.T) {
+	ole := []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1, 0, 0, 0, 0}
+	plainZip := buildZip(t, zipEntry{name: "notes/readme.txt", content: []byte("hello")})
+
+	tests := []struct {
+		name      string
+		content   []byte
+		extension string
+		want      string
+	}{
+		{"pdf", []byte("%PDF-1.7\n..."), ".pdf", "pdf"},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00"), ".png", "png"},
+		{"jpg", []byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 0x10}, ".jpg", "jpg"},
+		{"gif", []byte("GIF89a\x01\x00"), ".gif", "gif"},
+		{"webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), ".webp", "webp"},
+		{"mp4", []byte("\x00\x00\x00\x18ftypmp42"), ".mp4", "mp4"},
+		{"webm", []byte{0x1A, 0x45, 0xDF, 0xA3, 0x01}, ".webm", "webm"},
+		{"mp3 with id3 tag", []byte("ID3\x04\x00"), ".mp3", "mp3"},
+		{"mp3 frame sync", []byte{0xFF, 0xFB, 0x90, 0x00}, ".mp3", "mp3"},
+
+		// Wadah OLE yang sama dibedakan lewat ekstensi, dengan doc sebagai bawaan
+		{"legacy word", ole, ".doc", "doc"},
+		{"legacy excel", ole, ".xls", "xls"},
+		{"legacy powerpoint", ole, ".ppt", "ppt"},
+		{"legacy office with other extension", ole, ".bin", "doc"},
+
+		// Dokumen Office Open XML dikenali dari isi arsipnya, bukan ekstensinya
+		{"plain zip", plainZip, ".zip", "zip"},
+		{"docx", buildZip(t, zipEntry{name: "[Content_Types].xml"}, zipEntry{name: "word/document.xml"}), ".docx", "docx"},
+		{"xlsx", buildZip(t, zipEntry{name: "xl/workbook.xml"}), ".xlsx", "xlsx"},
+		{"pptx", buildZip(t, zipEntry{name: "ppt/presentation.xml"}), ".pptx", "pptx"},
+		{"docx renamed to zip", buildZip(t, zipEntry{name: "word/document.xml"}), ".zip", "docx"},
+		{"zip renamed to docx", plainZip, ".docx", "zip"},
+		{"office folder below the root", buildZip(t, zipEntry{name: "backup/word/document.xml"}), ".zip", "zip"},
+		{"empty zip", buildZip(t), ".zip", "zip"},
+		{"unreadable zip", []byte("PK\x03\x04garbage"), ".zip", "zip"},
+
+		// Teks dibedakan menjadi CSV atau markdown hanya lewat ekstensi
+		{"text", []byte("just some notes\n"), ".txt", "txt"},
+		{"csv", []byte("id,name\n1,Ana\n"), ".csv", "csv"},
+		{"csv upper-case extension", []byte("id,name\n"), ".CSV", "csv"},
+		{"markdown", []byte("# Title\n\n- item\n"), ".md", "md"},
+		{"markdown long extension", []byte("# Title\n"), ".markdown", "md"},
+		{"text with unknown extension", []byte("id,name\n"), ".dat", "txt"},
+		{"text posing as pdf", []byte("not really a pdf"), ".pdf", "txt"},
+		{"utf-8 text", []byte("Catatan: ringkasan materi ✓\n"), ".txt", "txt"},
+		{"utf-8 cut at the header boundary", append(bytes.Repeat([]byte("a"), 511), "✓"...), ".txt", "txt"},
+		{"text with tabs and form feed", []byte("a\tb\r\n\f"), ".txt", "txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileType, err := DetectFileType(bytes.NewReader(tt.content), int64(len(tt.content)), tt.extension)
+			if err != nil {
+				t.Fatalf("DetectFileType: %v", err)
+			}
+			if fileType.Name != tt.want {
+				t.Errorf("type = %s, want %s", fileType.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectFileTypeUnknown(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"empty", nil},
+		{"binary", []byte{0x00, 0x01, 0x02, 0x03}},
+		{"text with control characters", []byte("hello\x00world")},
+		{"invalid utf-8", []byte("abc\xff\xfe def")},
+		{"executable", []byte("MZ\x90\x00\x03\x00")},
+		{"lone multibyte lead", []byte{0xE2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DetectFileType(bytes.NewReader(tt.content), int64(len(tt.content)), ".txt")
+			if !errors.Is(err, ErrUnknownFileType) {
+				t.Errorf("error = %v, want ErrUnknownFileType", err)
+			}
+		})
+	}
+}
+
+func TestInspectArchive(t *testing.T) {
+	limits := ArchiveLimits{MaxEntries: 5, MaxUncompressedSize: 1 << 20, MaxCompressionRatio: 100}
+	manyEntries := make([]zipEntry, 6)
+	for i := range manyEntries {
+		manyEntries[i] = zipEntry{name: fmt.Sprintf("file%d.txt", i), content: []byte("x")}
+	}
+
+	tests := []struct {
+		name    string
+		archive []byte
+		limits  ArchiveLimits
+		wantErr string
+	}{
+		{
+			name:    "safe archive",
+			archive: buildZip(t, zipEntry{name: "docs/"}, zipEntry{name: "docs/a.txt", content: []byte("hello"), method: zip.Deflate}),
+			limits:  limits,
+		},
+		{
+			name:    "entry limit reached exactly",
+			archive: buildZip(t, manyEntries[:5]...),
+			limits:  limits,
+		},
+		{
+			name:    "no limits",
+			archive: buildZip(t, append(manyEntries, zipEntry{name: "zeros", content: make([]byte, 2<<20), method: zip.Deflate})...),
+			limits:  ArchiveLimits{MaxUncompressedSize: 4 << 20},
+		},
+		{
+			name:    "not an archive",
+			archive: []byte("PK\x03\x04garbage"),
+			limits:  limits,
+			wantErr: "archive is corrupted or unreadable",
+		},
+		{
+			name:    "parent directory traversal",
+			archive: buildZip(t, zipEntry{name: "../evil.sh", content: []byte("x")}),
+			limits:  limits,
+			wantErr: "points outside the archive",
+		},
+		{
+			name:    "nested traversal",
+			archive: buildZip(t, zipEntry{name: "docs/../../evil.sh", content: []byte("x")}),
+			limits:  limits,
+			wantErr: "points outside the archive",
+		},
+		{
+			name:    "backslash traversal",
+			archive: buildZip(t, zipEntry{name: "docs\\..\\..\\evil.sh", content: []byte("x")}),
+			limits:  limits,
+			wantErr: "points outside the archive",
+		},
+		{
+			name:    "absolute path",
+			archive: buildZip(t, zipEntry{name: "/etc/cron.d/evil", content: []byte("x")}),
+			limits:  limits,
+			wantErr: "points outside the archive",
+		},
+		{
+			name:    "windows drive path",
+			archive: buildZip(t, zipEntry{name: "C:/Windows/evil.dll", content: []byte("x")}),
+			limits:  limits,
+			wantErr: "points outside the archive",
+		},
+		{
+			name:    "symbolic link",
+			archive: buildZip(t, zipEntry{name: "link", content: []byte("/etc/passwd"), mode: os.ModeSymlink | 0o777}),
+			limits:  limits,
+			wantErr: "is a symbolic link",
+		},
+		{
+			name:    "too many entries",
+			archive: buildZip(t, manyEntries...),
+			limits:  limits,
+			wantErr: "archive contains 6 entries, the limit is 5",
+		},
+		{
+			name:    "suspicious compression ratio",
+			archive: buildZip(t, zipEntry{name: "bomb.txt", content: make([]byte, 4<<20), method: zip.Deflate}),
+			limits:  ArchiveLimits{MaxUncompressedSize: 8 << 20, MaxCompressionRatio: 100},
+			wantErr: "suspicious compression ratio",
+		},
+		{
+			name:    "uncompressed size in header exceeds limit",
+			archive: buildZip(t, zipEntry{name: "big.bin", content: make([]byte, 2<<20)}),
+			limits:  limits,
+			wantErr: "archive uncompressed size exceeds the limit",
+		},
+		{
+			name: "total uncompressed size exceeds limit",
+			archive: buildZip(t,
+				zipEntry{name: "a.bin", content: make([]byte, 600<<10)},
+				zipEntry{name: "b.bin", content: make([]byte, 600<<10)},
+			),
+			limits:  limits,
+			wantErr: "archive uncompressed size exceeds the limit",
+		},
+		{
+			name:    "header understates the real size",
+			archive: forgedSizeZip(t, 2<<20),
+			limits:  limits,
+			wantErr: "is corrupted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := InspectArchive(bytes.NewReader(tt.archive), int64(len(tt.archive)), tt.limits)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("InspectArchive: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnsafeArchivePath(t *testing.T) {
+	tests := []struct {
+		name   string
+		unsafe bool
+	}{
+		{"readme.txt", false},
+		{"docs/chapter1/notes.md", false},
+		{"docs/", false},
+		{"docs/./notes.md", false},
+		{"docs/v1..v2/notes.md", false},
+		{"docs\\notes.md", false},
+		{"", true},
+		{"/etc/passwd", true},
+		{"\\windows\\system32", true},
+		{"C:/Windows/evil.dll", true},
+		{"c:evil.dll", true},
+		{"..", true},
+		{"../evil.sh", true},
+		{"docs/../../evil.sh", true},
+		{"docs/..", true},
+		{"docs\\..\\..\\evil.sh", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unsafeArchivePath(tt.name); got != tt.unsafe {
+				t.Errorf("unsafeArchivePath(%q) = %v, want %v", tt.name, got, tt.unsafe)
+			}
+		})
+	}
+}
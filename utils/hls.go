@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"archive/zip"
+	"io"
+	"path"
+	"strings"
+
+)
+
+// HLSPlaylist adalah nama playlist utama yang wajib ada di akar paket HLS
+const HLSPlaylist = "index.m3u8"
+
+// hlsContentTypes adalah jenis isi untuk berkas yang lazim ada di paket HLS
+var hlsContentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".ts":   "video/mp2t",
+	".m4s":  "video/iso.segment",
+	".mp4":  "video/mp4",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".vtt":  "text/vtt",
+	".key":  "application/octet-stream",
+}
+
+// IsHLSPackage memeriksa apakah arsip ZIP adalah video HLS yang sudah dipotong-potong,
+// yaitu berisi HLSPlaylist di akarnya
+func IsHLSPackage(reader io.ReaderAt, size int64) bool {
+	archive, err := zip.NewReader(reader, size)
+	if err != nil {
+		return false
+	}
+	return FindHLSFile(archive, HLSPlaylist) != nil
+}
+
+// FindHLSFile mencari berkas di dalam paket HLS berdasarkan jalurnya; jalur yang keluar dari akar ditolak
+func FindHLSFile(archive *zip.Reader, name string) *zip.File {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" || strings.Contains(name, "\\") || path.Clean(name) != name || strings.HasPrefix(name, "../") || name == ".." {
+		return nil
+	}
+	for _, file := range archive.File {
+		if file.Name == name && !file.FileInfo().IsDir() {
+			return file
+		}
+	}
+	return nil
+}
+
+// HLSContentType mengembalikan jenis isi berkas paket HLS berdasarkan ekstensinya
+func HLSContentType(name string) (string, bool) {
+	contentType, ok := hlsContentTypes[strings.ToLower(path.Ext(name))]
+	return contentType, ok
+}
@@ -2,6 +2,10 @@ package utils
 
 import (
 	"LMS/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -12,27 +16,46 @@ import (
 // JWTClaims mewakili klaim yang disimpan dalam token JWT
 // Ini termasuk ID pengguna, email, dan peran pengguna
 type JWTClaims struct {
-	UserID uint        `json:"user_id"`
-	Email  string      `json:"email"`
-	Role   models.Role `json:"role"`
+	UserID       uint        `json:"user_id"`
+	Email        string      `json:"email"`
+	Role         models.Role `json:"role"`
+	TokenVersion uint        `json:"ver"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken menghasilkan token JWT baru untuk pengguna
+// AccessTokenTTL mengembalikan masa berlaku token akses (default 15 menit)
+func AccessTokenTTL() time.Duration {
+	return getDurationEnv("JWT_ACCESS_EXPIRES_IN", 15*time.Minute)
+}
+
+// RefreshTokenTTL mengembalikan masa berlaku refresh token (default 7 hari)
+func RefreshTokenTTL() time.Duration {
+	return getDurationEnv("JWT_REFRESH_EXPIRES_IN", 7*24*time.Hour)
+}
+
+// GenerateToken menghasilkan token akses JWT berumur pendek untuk pengguna
 func GenerateToken(user *models.User) (string, error) {
 	// dapatkan kunci rahasia dari variabel lingkungan atau gunakan default untuk pengembangan
 	secretKey := getEnv("JWT_SECRET_KEY", "your-256-bit-secret")
 
 	// Tetapkan waktu kedaluwarsa
-	expirationTime := time.Now().Add(24 * time.Hour)
+	expirationTime := time.Now().Add(AccessTokenTTL())
+
+	// jti unik memungkinkan token dicabut sebelum kedaluwarsa
+	jti, err := GenerateRandomString(16)
+	if err != nil {
+		return "", err
+	}
 
 	// membuat klaim dengan informasi pengguna
 	// dan waktu kedaluwarsa
 	claims := &JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:       user.ID,
+		Email:        user.Email,
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -89,6 +112,40 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 	return nil, errors.New("invalid token")
 }
 
+// GenerateRefreshToken menghasilkan refresh token acak yang tidak dapat ditebak
+func GenerateRefreshToken() (string, error) {
+	return GenerateRandomString(32)
+}
+
+// GenerateRandomString menghasilkan string acak aman URL dari n byte acak
+func GenerateRandomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashToken mengembalikan hash SHA-256 dari token agar token asli tidak disimpan di basis data
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// membantu fungsi untuk mendapatkan durasi dari variabel lingkungan
+// dengan nilai default jika tidak ditemukan atau tidak valid
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return fallback
+	}
+	return duration
+}
+
 // membantu fungsi untuk mendapatkan variabel lingkungan
 // dengan nilai default jika tidak ditemukan
 func getEnv(key, fallback string) string {
@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+)
+
+// Pola baris blok Markdown
+var (
+	headingPattern     = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)[ \t#]*$`)
+	fencePattern       = regexp.MustCompile("^(```+|~~~+)[ \t]*([A-Za-z0-9_+-]*)")
+	rulePattern        = regexp.MustCompile(`^[ ]{0,3}(-[ \t]*-[ \t]*-[- \t]*|\*[ \t]*\*[ \t]*\*[* \t]*|_[ \t]*_[ \t]*_[_ \t]*)$`)
+	bulletPattern      = regexp.MustCompile(`^[ ]{0,3}([-*+])[ \t]+(.*)$`)
+	orderedPattern     = regexp.MustCompile(`^[ ]{0,3}([0-9]{1,9})[.)][ \t]+(.*)$`)
+	blockquotePattern  = regexp.MustCompile(`^[ ]{0,3}>[ ]?(.*)$`)
+	linkPattern        = regexp.MustCompile(`^!?\[([^\]]*)\]\(\s*((?:[^\s()]|\([^\s()]*\))*)(?:\s+"([^"]*)")?\s*\)`)
+	autolinkPattern    = regexp.MustCompile(`^<((?:https?://|mailto:)[^\s<>]+)>`)
+	inlineCodePattern  = regexp.MustCompile("^(`+)(.+?)(`+)")
+	emphasisDelimiters = []string{"**", "__", "~~", "*", "_"}
+)
+
+// emphasisTags adalah tag HTML untuk setiap penanda penekanan
+var emphasisTags = map[string]string{"**": "strong", "__": "strong", "~~": "del", "*": "em", "_": "em"}
+
+// RenderMarkdown mengubah Markdown menjadi HTML. Yang didukung adalah judul, paragraf, penekanan,
+// kode, kutipan, daftar bertingkat, garis pemisah, tautan dan gambar. HTML mentah di dalam Markdown
+// diloloskan sebagai teks; hasilnya tetap perlu dibersihkan dengan SanitizeHTML sebelum ditampilkan.
+func RenderMarkdown(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\t", "    ")
+	var output strings.Builder
+	renderBlocks(&output, strings.Split(source, "\n"))
+	return strings.TrimSpace(output.String())
+}
+
+// renderBlocks menulis baris-baris Markdown sebagai elemen blok
+func renderBlocks(output *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case fencePattern.MatchString(trimmed):
+			match := fencePattern.FindStringSubmatch(trimmed)
+			fence := match[1]
+			var code []string
+			i++
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+				code = append(code, lines[i])
+				i++
+			}
+			i++
+			if match[2] != "" {
+				output.WriteString(`<pre><code class="language-` + match[2] + `">`)
+			} else {
+				output.WriteString("<pre><code>")
+			}
+			output.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			output.WriteString("</code></pre>\n")
+
+		case headingPattern.MatchString(trimmed):
+			match := headingPattern.FindStringSubmatch(trimmed)
+			level := strconv.Itoa(len(match[1]))
+			output.WriteString("<h" + level + ">" + renderInline(match[2]) + "</h" + level + ">\n")
+			i++
+
+		case rulePattern.MatchString(line):
+			output.WriteString("<hr>\n")
+			i++
+
+		case blockquotePattern.MatchString(line):
+			var quoted []string
+			for i < len(lines) && blockquotePattern.MatchString(lines[i]) {
+				quoted = append(quoted, blockquotePattern.FindStringSubmatch(lines[i])[1])
+				i++
+			}
+			output.WriteString("<blockquote>\n")
+			renderBlocks(output, quoted)
+			output.WriteString("</blockquote>\n")
+
+		case bulletPattern.MatchString(line), orderedPattern.MatchString(line):
+			i = renderList(output, lines, i)
+
+		default:
+			var paragraph []string
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsBlock(lines[i]) {
+				paragraph = append(paragraph, lines[i])
+				i++
+			}
+			output.WriteString("<p>" + renderParagraph(paragraph) + "</p>\n")
+		}
+	}
+}
+
+// renderList menulis daftar yang dimulai pada baris start dan mengembalikan baris setelah daftar
+func renderList(output *strings.Builder, lines []string, start int) int {
+	ordered := orderedPattern.MatchString(lines[start])
+	itemPattern := bulletPattern
+	tag := "ul"
+	if ordered {
+		itemPattern = orderedPattern
+		tag = "ol"
+		if number := orderedPattern.FindStringSubmatch(lines[start])[1]; number != "1" {
+			first, _ := strconv.Atoi(number)
+			output.WriteString(`<ol start="` + strconv.Itoa(first) + `">` + "\n")
+		} else {
+			output.WriteString("<ol>\n")
+		}
+	} else {
+		output.WriteString("<ul>\n")
+	}
+
+	i := start
+	for i < len(lines) && itemPattern.MatchString(lines[i]) {
+		item := []string{itemPattern.FindStringSubmatch(lines[i])[2]}
+		i++
+
+		// Baris lanjutan yang menjorok, termasuk daftar bertingkat, menjadi bagian dari butir
+		loose := false
+		for i < len(lines) {
+			if strings.TrimSpace(lines[i]) == "" {
+				if i+1 < len(lines) && leadingSpaces(lines[i+1]) >= 2 {
+					loose = true
+					item = append(item, "")
+					i++
+					continue
+				}
+				break
+			}
+			if leadingSpaces(lines[i]) < 2 && (itemPattern.MatchString(lines[i]) || startsBlock(lines[i])) {
+				break
+			}
+			item = append(item, dedent(lines[i], 4))
+			i++
+		}
+
+		var content strings.Builder
+		renderBlocks(&content, item)
+		rendered := strings.TrimSpace(content.String())
+		// Butir tanpa baris kosong ditulis tanpa paragraf
+		if !loose {
+			rendered = strings.Replace(rendered, "<p>", "", 1)
+			rendered = strings.Replace(rendered, "</p>", "", 1)
+		}
+		output.WriteString("<li>" + rendered + "</li>\n")
+
+		// Satu baris kosong di antara butir tidak mengakhiri daftar
+		if i+1 < len(lines) && strings.TrimSpace(lines[i]) == "" && itemPattern.MatchString(lines[i+1]) {
+			i++
+		}
+	}
+
+	output.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// startsBlock memeriksa apakah baris memulai elemen blok baru yang memutus paragraf
+func startsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return headingPattern.MatchString(trimmed) || fencePattern.MatchString(trimmed) ||
+		rulePattern.MatchString(line) || blockquotePattern.MatchString(line) ||
+		bulletPattern.MatchString(line) || orderedPattern.MatchString(line)
+}
+
+// leadingSpaces menghitung spasi di awal baris
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// dedent menghapus paling banyak n spasi di awal baris
+func dedent(line string, n int) string {
+	if spaces := leadingSpaces(line); spaces < n {
+		n = spaces
+	}
+	return line[n:]
+}
+
+// renderParagraph menulis baris paragraf; baris yang diakhiri dua spasi atau garis miring terbalik menjadi <br>
+func renderParagraph(lines []string) string {
+	parts := make([]string, len(lines))
+	for i, line := range lines {
+		hardBreak := i < len(lines)-1 && (strings.HasSuffix(line, "  ") || strings.HasSuffix(line, "\\"))
+		line = strings.TrimSpace(line)
+		if hardBreak {
+			line = strings.TrimSuffix(line, "\\")
+		}
+		parts[i] = renderInline(line)
+		if hardBreak {
+			parts[i] += "<br>"
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// renderInline menulis elemen sebaris: kode, tautan, gambar, penekanan dan karakter yang diloloskan
+func renderInline(text string) string {
+	var output strings.Builder
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		if rest[0] == '\\' && len(rest) > 1 && strings.ContainsRune("\\`*_{}[]()#+-.!~<>", rune(rest[1])) {
+			output.WriteString(html.EscapeString(rest[1:2]))
+			i += 2
+			continue
+		}
+
+		if match := inlineCodePattern.FindStringSubmatch(rest); match != nil && match[1] == match[3] {
+			output.WriteString("<code>" + html.EscapeString(strings.TrimSpace(match[2])) + "</code>")
+			i += len(match[0])
+			continue
+		}
+
+		if rest[0] == '[' || strings.HasPrefix(rest, "![") {
+			if match := linkPattern.FindStringSubmatch(rest); match != nil {
+				output.WriteString(renderLink(rest[0] == '!', match[1], match[2], match[3]))
+				i += len(match[0])
+				continue
+			}
+		}
+
+		if match := autolinkPattern.FindStringSubmatch(rest); match != nil {
+			output.WriteString(`<a href="` + html.EscapeString(match[1]) + `">` + html.EscapeString(match[1]) + "</a>")
+			i += len(match[0])
+			continue
+		}
+
+		// Garis bawah di tengah kata, misalnya nama_variabel, bukan penekanan
+		intraword := rest[0] == '_' && i > 0 && isWordByte(text[i-1])
+		if rendered, consumed := renderEmphasis(rest); consumed > 0 && !intraword {
+			output.WriteString(rendered)
+			i += consumed
+			continue
+		}
+
+		output.WriteString(html.EscapeString(rest[:1]))
+		i++
+	}
+	return output.String()
+}
+
+// renderEmphasis menulis teks yang diapit penanda penekanan dan mengembalikan jumlah karakter yang dipakai
+func renderEmphasis(text string) (string, int) {
+	for _, delimiter := range emphasisDelimiters {
+		if !strings.HasPrefix(text, delimiter) || len(text) <= len(delimiter) || text[len(delimiter)] == ' ' {
+			continue
+		}
+		end := strings.Index(text[len(delimiter):], delimiter)
+		if end <= 0 || text[len(delimiter)+end-1] == ' ' {
+			continue
+		}
+		inner := text[len(delimiter) : len(delimiter)+end]
+		tag := emphasisTags[delimiter]
+		return "<" + tag + ">" + renderInline(inner) + "</" + tag + ">", len(delimiter)*2 + end
+	}
+	return "", 0
+}
+
+// isWordByte memeriksa apakah karakter ASCII adalah huruf atau angka
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// renderLink menulis tautan atau gambar; alamat yang tidak aman ditulis sebagai teks biasa
+func renderLink(image bool, label, target, title string) string {
+	if !SafeURL(target, !image) {
+		return html.EscapeString(label)
+	}
+
+	attributes := ""
+	if title != "" {
+		attributes = ` title="` + html.EscapeString(title) + `"`
+	}
+	if image {
+		return `<img src="` + html.EscapeString(target) + `" alt="` + html.EscapeString(label) + `"` + attributes + ">"
+	}
+	return `<a href="` + html.EscapeString(target) + `"` + attributes + ">" + renderInline(label) + "</a>"
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@.])@([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// ParseMentions mengambil nama pengguna yang disebut dengan @nama di dalam teks.
+// Hasilnya berhuruf kecil dan tanpa duplikat; alamat email tidak dianggap sebutan.
+func ParseMentions(text string) []string {
+	seen := make(map[string]bool)
+	var handles []string
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		handle := strings.ToLower(strings.TrimRight(match[1], "._-"))
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+	}
+	return handles
+}
+
+// MentionHandles mengembalikan sebutan yang cocok untuk seorang pengguna:
+// namanya tanpa spasi dan bagian lokal alamat emailnya, berhuruf kecil
+func MentionHandles(name, email string) []string {
+	handles := []string{strings.ToLower(strings.Join(strings.Fields(name), ""))}
+	if at := strings.Index(email, "@"); at > 0 {
+		handles = append(handles, strings.ToLower(email[:at]))
+	}
+	return handles
+}
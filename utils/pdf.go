@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+)
+
+// PDFText adalah satu baris teks yang diletakkan di tengah halaman pada ketinggian Y
+type PDFText struct {
+	Text string
+	Size float64
+	Bold bool
+	Y    float64
+}
+
+// PDFPage adalah halaman PDF tunggal dengan bingkai opsional dan baris teks di tengah
+type PDFPage struct {
+	Width  float64
+	Height float64
+	Border bool
+	Lines  []PDFText
+}
+
+// Lebar glif Helvetica dan Helvetica-Bold (per 1000 unit) untuk karakter ASCII 32..126
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// PDFTextWidth menghitung lebar teks dalam poin untuk ukuran huruf tertentu
+func PDFTextWidth(text string, size float64, bold bool) float64 {
+	widths := helveticaWidths
+	if bold {
+		widths = helveticaBoldWidths
+	}
+
+	total := 0
+	for _, b := range pdfEncode(text) {
+		if b >= 32 && b <= 126 {
+			total += widths[b-32]
+		} else {
+			// Huruf Latin-1 beraksen kira-kira selebar huruf kecil biasa
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapPDFText memecah teks menjadi baris yang tidak lebih lebar dari maxWidth.
+// Baris baru pada teks asli tetap dipertahankan.
+func WrapPDFText(text string, size float64, bold bool, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := words[0]
+		for _, word := range words[1:] {
+			if PDFTextWidth(line+" "+word, size, bold) > maxWidth {
+				lines = append(lines, line)
+				line = word
+				continue
+			}
+			line += " " + word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// WritePDF menulis dokumen PDF satu halaman dengan huruf Helvetica bawaan.
+// Karakter di luar Latin-1 diganti tanda tanya karena huruf bawaan memakai WinAnsiEncoding.
+func WritePDF(w io.Writer, page PDFPage) error {
+	var content bytes.Buffer
+	if page.Border {
+		fmt.Fprintf(&content, "q 0.2 0.3 0.5 RG 3 w 24 24 %.2f %.2f re S Q\n", page.Width-48, page.Height-48)
+		fmt.Fprintf(&content, "q 0.2 0.3 0.5 RG 1 w 32 32 %.2f %.2f re S Q\n", page.Width-64, page.Height-64)
+	}
+	for _, line := range page.Lines {
+		font := "F1"
+		if line.Bold {
+			font = "F2"
+		}
+		x := (page.Width - PDFTextWidth(line.Text, line.Size, line.Bold)) / 2
+		fmt.Fprintf(&content, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, line.Size, x, line.Y, pdfEscape(line.Text))
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> /Contents 4 0 R >>", page.Width, page.Height),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(document.Bytes())
+	return err
+}
+
+// pdfEncode mengubah teks UTF-8 ke byte WinAnsi, mengganti karakter yang tidak didukung
+func pdfEncode(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= 32 && r <= 126, r >= 160 && r <= 255:
+			encoded = append(encoded, byte(r))
+		case r == '\t':
+			encoded = append(encoded, ' ')
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+// pdfEscape mengenkode teks dan meloloskan karakter khusus string literal PDF
+func pdfEscape(text string) string {
+	var escaped strings.Builder
+	for _, b := range pdfEncode(text) {
+		switch b {
+		case '(', ')', '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteByte(b)
+		default:
+			escaped.WriteByte(b)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+
+)
+
+// allowedTags adalah tag HTML yang boleh ada di konten materi beserta atributnya
+var allowedTags = map[string][]string{
+	"p": nil, "br": nil, "hr": nil, "div": {"class"}, "span": {"class"},
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"strong": nil, "b": nil, "em": nil, "i": nil, "u": nil, "s": nil, "del": nil, "ins": nil,
+	"mark": nil, "sub": nil, "sup": nil, "small": nil, "abbr": {"title"}, "kbd": nil,
+	"blockquote": nil, "pre": {"class"}, "code": {"class"},
+	"ul": nil, "ol": {"start"}, "li": nil, "dl": nil, "dt": nil, "dd": nil,
+	"table": nil, "thead": nil, "tbody": nil, "tfoot": nil, "tr": nil, "caption": nil,
+	"th": {"colspan", "rowspan", "align"}, "td": {"colspan", "rowspan", "align"},
+	"figure": nil, "figcaption": nil,
+	"a":   {"href", "title"},
+	"img": {"src", "alt", "title", "width", "height"},
+}
+
+// voidTags adalah tag tanpa penutup
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+// droppedTags adalah tag yang dibuang beserta seluruh isinya
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "noscript": true,
+	"template": true, "textarea": true, "select": true, "title": true, "svg": true, "math": true, "head": true,
+}
+
+// Pola nilai atribut yang aman
+var (
+	classPattern  = regexp.MustCompile(`^(language-[A-Za-z0-9_+-]+|task-list-item)$`)
+	numberPattern = regexp.MustCompile(`^[0-9]{1,4}$`)
+	alignPattern  = regexp.MustCompile(`^(left|right|center)$`)
+)
+
+// SanitizeHTML membersihkan HTML dari pengguna dengan daftar tag dan atribut yang diizinkan.
+// Tag lain dibuang tetapi teksnya dipertahankan, kecuali tag seperti script yang dibuang beserta isinya.
+// Tautan hanya boleh memakai http, https, mailto atau alamat relatif dan gambar hanya http atau https.
+func SanitizeHTML(input string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+	var output strings.Builder
+	var open []string
+	skipping := ""
+	skipDepth := 0
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+
+		// Lewati isi tag yang dibuang sampai penutupnya
+		if skipping != "" {
+			switch {
+			case tokenType == html.StartTagToken && token.Data == skipping:
+				skipDepth++
+			case tokenType == html.EndTagToken && token.Data == skipping:
+				skipDepth--
+				if skipDepth == 0 {
+					skipping = ""
+				}
+			}
+			continue
+		}
+
+		switch tokenType {
+		case html.TextToken:
+			output.WriteString(html.EscapeString(token.Data))
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if droppedTags[token.Data] {
+				if tokenType == html.StartTagToken {
+					skipping = token.Data
+					skipDepth = 1
+				}
+				continue
+			}
+			attributes, ok := allowedTags[token.Data]
+			if !ok {
+				continue
+			}
+			output.WriteString("<" + token.Data)
+			for _, attribute := range token.Attr {
+				if value, ok := sanitizeAttribute(token.Data, attribute, attributes); ok {
+					output.WriteString(" " + attribute.Key + `="` + html.EscapeString(value) + `"`)
+				}
+			}
+			if token.Data == "a" {
+				output.WriteString(` rel="noopener noreferrer nofollow"`)
+			}
+			output.WriteString(">")
+			if !voidTags[token.Data] && tokenType == html.StartTagToken {
+				open = append(open, token.Data)
+			}
+
+		case html.EndTagToken:
+			// Tutup hanya tag yang sedang terbuka; tag di dalamnya ikut ditutup agar hasilnya seimbang
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != token.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					output.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		output.WriteString("</" + open[i] + ">")
+	}
+	return output.String()
+}
+
+// sanitizeAttribute memeriksa satu atribut dan mengembalikan nilainya jika aman
+func sanitizeAttribute(tag string, attribute html.Attribute, allowed []string) (string, bool) {
+	if attribute.Namespace != "" || !containsString(allowed, attribute.Key) {
+		return "", false
+	}
+	value := strings.TrimSpace(attribute.Val)
+
+	switch attribute.Key {
+	case "href":
+		return value, SafeURL(value, true)
+	case "src":
+		return value, SafeURL(value, false)
+	case "class":
+		return value, classPattern.MatchString(value)
+	case "width", "height", "colspan", "rowspan", "start":
+		return value, numberPattern.MatchString(value)
+	case "align":
+		return value, alignPattern.MatchString(value)
+	}
+	return value, true
+}
+
+// SafeURL memeriksa apakah URL aman dipakai di konten: http atau https, serta mailto
+// dan alamat relatif jika allowRelative bernilai true
+func SafeURL(value string, allowRelative bool) bool {
+	if value == "" || strings.ContainsAny(value, "\x00\r\n\t") {
+		return false
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+		return parsed.Host != ""
+	case "mailto":
+		return allowRelative
+	case "":
+		// Alamat tanpa skema seperti "//host" tetap menunjuk ke host lain; peramban
+		// memperlakukan \ sama dengan /, sehingga "/\host" dan "\\host" juga demikian
+		return allowRelative && !strings.HasPrefix(strings.ReplaceAll(value, "\\", "/"), "//")
+	}
+	return false
+}
+
+// containsString memeriksa apakah daftar memuat nilai tertentu
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+)
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		value         string
+		allowRelative bool
+		safe          bool
+	}{
+		{"https://example.com/a?b=c", false, true},
+		{"http://example.com", false, true},
+		{"HTTPS://example.com", false, true},
+		{"mailto:mentor@example.com", true, true},
+		{"mailto:mentor@example.com", false, false},
+		{"/materials/5", true, true},
+		{"notes.html#part-2", true, true},
+		{"#section", true, true},
+		{"/materials/5", false, false},
+		{"", true, false},
+		{"https://", false, false},
+		{"https:example.com", false, false},
+		{"javascript:alert(1)", true, false},
+		{"JavaScript:alert(1)", true, false},
+		{"java\tscript:alert(1)", true, false},
+		{"java\nscript:alert(1)", true, false},
+		{"javascript\x00:alert(1)", true, false},
+		{"vbscript:msgbox(1)", true, false},
+		{"data:text/html;base64,PHNjcmlwdD4=", true, false},
+		{"data:image/png;base64,iVBORw0KGgo=", false, false},
+		{"file:///etc/passwd", true, false},
+		{"//evil.example.com/x", true, false},
+		{"\\\\evil.example.com\\x", true, false},
+		{"/\\evil.example.com", true, false},
+		{"\\/evil.example.com", true, false},
+		{"http:\\\\evil.example.com", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := SafeURL(tt.value, tt.allowRelative); got != tt.safe {
+				t.Errorf("SafeURL(%q, %v) = %v, want %v", tt.value, tt.allowRelative, got, tt.safe)
+			}
+		})
+	}
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	const rel = ` rel="noopener noreferrer nofollow"`
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain text is escaped", `1 < 2 & "quotes"`, `1 &lt; 2 &amp; &#34;quotes&#34;`},
+		{"allowed formatting", `<p><strong>Bold</strong> <em>it</em></p>`, `<p><strong>Bold</strong> <em>it</em></p>`},
+		{"safe link", `<a href="https://example.com" title="Docs">docs</a>`, `<a href="https://example.com" title="Docs"` + rel + `>docs</a>`},
+		{"relative link", `<a href="/materials/5">next</a>`, `<a href="/materials/5"` + rel + `>next</a>`},
+		{"existing rel is replaced", `<a href="https://example.com" rel="opener">x</a>`, `<a href="https://example.com"` + rel + `>x</a>`},
+		{"image", `<img src="https://cdn.example.com/a.png" alt="diagram" width="300">`, `<img src="https://cdn.example.com/a.png" alt="diagram" width="300">`},
+
+		// Tautan berbahaya dibuang, termasuk yang disamarkan dengan entitas HTML
+		{"javascript href", `<a href="javascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"upper-case javascript href", `<a href="JAVASCRIPT:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"padded javascript href", `<a href="  javascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"decimal entity javascript href", `<a href="&#106;avascript:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"hex entity javascript href", `<a href="jav&#x61;script:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"entity tab inside scheme", `<a href="java&#x09;script:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"entity newline inside scheme", `<a href="java&#10;script:alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"named entity colon", `<a href="javascript&colon;alert(1)">x</a>`, `<a` + rel + `>x</a>`},
+		{"data href", `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`, `<a` + rel + `>x</a>`},
+		{"entity data href", `<a href="&#100;ata:text/html,<script>alert(1)</script>">x</a>`, `<a` + rel + `>x</a>`},
+		{"data image", `<img src="data:image/svg+xml;base64,PHN2Zz4=">`, `<img>`},
+		{"javascript image", `<img src="javascript:alert(1)">`, `<img>`},
+		{"protocol-relative link", `<a href="//evil.example.com">x</a>`, `<a` + rel + `>x</a>`},
+		{"backslash host link", `<a href="\\evil.example.com">x</a>`, `<a` + rel + `>x</a>`},
+		{"mixed slash host link", `<a href="/\evil.example.com">x</a>`, `<a` + rel + `>x</a>`},
+		{"protocol-relative image", `<img src="//evil.example.com/a.png">`, `<img>`},
+
+		// Atribut lain, termasuk penangan event, dibuang
+		{"event handler on link", `<a href="https://example.com" onclick="alert(1)">x</a>`, `<a href="https://example.com"` + rel + `>x</a>`},
+		{"event handler on image", `<img src=x onerror=alert(1)>`, `<img>`},
+		{"event handler on paragraph", `<p onmouseover="alert(1)" style="color:red">x</p>`, `<p>x</p>`},
+		{"upper-case event handler", `<p ONCLICK="alert(1)">x</p>`, `<p>x</p>`},
+		{"unsafe class", `<div class="modal-backdrop">x</div>`, `<div>x</div>`},
+		{"code language class", `<pre class="language-go">x</pre>`, `<pre class="language-go">x</pre>`},
+		{"invalid number", `<td colspan="2;">x</td>`, `<td>x</td>`},
+
+		// Tag yang dibuang beserta isinya, termasuk yang bersarang
+		{"script", `a<script>alert(1)</script>b`, `ab`},
+		{"nested script", `a<script><script>alert(1)</script>b</script>c`, `abc`},
+		{"nested svg", `a<svg><svg><a href="#">x</a></svg>hidden</svg>b`, `ab`},
+		{"style", `<style>body{display:none}</style>x`, `x`},
+		{"iframe", `<iframe src="https://evil.example.com"></iframe>x`, `x`},
+		{"self-closing dropped tag", `a<iframe/>b`, `ab`},
+		{"unclosed script", `a<script>alert(1)`, `a`},
+		{"unknown tag keeps text", `<section><blink>text</blink></section>`, `text`},
+
+		// Tag penutup yang tidak seimbang
+		{"stray closing tag", `</div>text</p>`, `text`},
+		{"misnested tags", `<b><i>x</b>y</i>`, `<b><i>x</i></b>y`},
+		{"unclosed tags", `<ul><li>one<li>two`, `<ul><li>one<li>two</li></li></ul>`},
+		{"closing a dropped tag", `<p>x</script>y</p>`, `<p>xy</p>`},
+		{"void tags stay open", `<p>a<br>b</br></p>`, `<p>a<br>b</p>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeHTML(tt.input); got != tt.want {
+				t.Errorf("SanitizeHTML(%q)\n got  %q\n want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeHTMLNeverKeepsScriptableMarkup(t *testing.T) {
+	inputs := []string{
+		`<scr<script>ipt>alert(1)</script>`,
+		`<<script>script>alert(1)<</script>/script>`,
+		`<a href="javascript:alert(1)"><img src=x onerror=alert(1)></a>`,
+		`<svg onload=alert(1)>`,
+		`<math><mi xlink:href="javascript:alert(1)">x</mi></math>`,
+		`<p title="x" onclick="alert(1)">y</p>`,
+	}
+
+	for _, input := range inputs {
+		output := strings.ToLower(SanitizeHTML(input))
+		for _, forbidden := range []string{"<script", "javascript:", "onerror", "onload", "onclick", "<svg"} {
+			if strings.Contains(output, forbidden) {
+				t.Errorf("SanitizeHTML(%q) = %q contains %q", input, output, forbidden)
+			}
+		}
+	}
+}
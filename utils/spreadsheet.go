@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+)
+
+// WriteCSV menulis baris ke CSV. Sel teks yang diawali karakter rumus diberi awalan
+// tanda kutip agar tidak dieksekusi ketika dibuka di aplikasi spreadsheet.
+func WriteCSV(w io.Writer, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	for _, row := range rows {
+		safeRow := make([]string, len(row))
+		for i, cell := range row {
+			safeRow[i] = escapeFormula(cell)
+		}
+		if err := writer.Write(safeRow); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadCSV membaca seluruh baris CSV dan mengabaikan BOM UTF-8 di awal berkas.
+// Awalan tanda kutip yang ditambahkan WriteCSV dibuang sehingga berkas ekspor
+// dapat diimpor kembali tanpa mengubah isi sel.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 && len(rows[0]) > 0 {
+		rows[0][0] = strings.TrimPrefix(rows[0][0], "\ufeff")
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = unescapeFormula(cell)
+		}
+	}
+	return rows, nil
+}
+
+// WriteXLSX menulis baris ke buku kerja XLSX minimal dengan satu lembar kerja.
+// Sel yang berupa angka disimpan sebagai angka, sisanya sebagai teks.
+func WriteXLSX(w io.Writer, sheetName string, rows [][]string) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(sheetName))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(rows)},
+	}
+
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(writer, file.content); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// xlsxSheet membuat XML lembar kerja dari baris
+func xlsxSheet(rows [][]string) string {
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	for r, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, r+1)
+		for c, cell := range row {
+			ref := xlsxColumn(c) + strconv.Itoa(r+1)
+			if isNumericCell(cell) {
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, cell)
+				continue
+			}
+			fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEscape(cell))
+		}
+		sheet.WriteString(`</row>`)
+	}
+
+	sheet.WriteString(`</sheetData></worksheet>`)
+	return sheet.String()
+}
+
+// xlsxColumn mengubah indeks kolom berbasis nol menjadi huruf kolom (A, B, ..., AA)
+func xlsxColumn(index int) string {
+	column := ""
+	for index >= 0 {
+		column = string(rune('A'+index%26)) + column
+		index = index/26 - 1
+	}
+	return column
+}
+
+// xmlEscape meloloskan teks untuk dimasukkan ke dalam XML
+func xmlEscape(value string) string {
+	var escaped bytes.Buffer
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
+
+// isNumericCell memeriksa apakah sel berisi angka desimal biasa
+func isNumericCell(cell string) bool {
+	return numericCellPattern.MatchString(cell)
+}
+
+// escapeFormula memberi awalan tanda kutip pada teks yang akan ditafsirkan sebagai rumus
+func escapeFormula(cell string) string {
+	if cell == "" {
+		return cell
+	}
+	if isNumericCell(cell) {
+		return cell
+	}
+	if isFormulaPrefix(cell[0]) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// unescapeFormula membuang awalan tanda kutip yang ditambahkan escapeFormula.
+// Tanda kutip di depan teks lain dibiarkan karena bukan berasal dari ekspor.
+func unescapeFormula(cell string) string {
+	if len(cell) >= 2 && cell[0] == '\'' && isFormulaPrefix(cell[1]) {
+		return cell[1:]
+	}
+	return cell
+}
+
+// isFormulaPrefix memeriksa apakah karakter pertama sel membuat spreadsheet menafsirkannya sebagai rumus
+func isFormulaPrefix(c byte) bool {
+	switch c {
+	case '=', '+', '-', '@', '\t', '\r':
+		return true
+	}
+	return false
+}
+
+var numericCellPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+const xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`
+
+const xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"Student ID", "Quiz 1 (max 10)", "Feedback"},
+		{"7", "-1.5", "=SUM(A1:A2)"},
+		{"8", "10", "- needs more detail"},
+		{"9", "", "@mention +plus 'quoted"},
+	}
+
+	var buffer bytes.Buffer
+	if err := WriteCSV(&buffer, rows); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+
+	exported := buffer.String()
+	for _, escaped := range []string{"'=SUM(A1:A2)", "'- needs more detail", "'@mention +plus 'quoted"} {
+		if !strings.Contains(exported, escaped) {
+			t.Errorf("exported CSV does not contain guarded cell %q:\n%s", escaped, exported)
+		}
+	}
+	if strings.Contains(exported, "'-1.5") {
+		t.Errorf("numeric cell must not be guarded:\n%s", exported)
+	}
+
+	imported, err := ReadCSV(&buffer)
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	if !reflect.DeepEqual(imported, rows) {
+		t.Errorf("round trip changed rows:\ngot  %q\nwant %q", imported, rows)
+	}
+}
+
+func TestReadCSVKeepsPlainQuotes(t *testing.T) {
+	rows, err := ReadCSV(strings.NewReader("\ufeffStudent ID,Feedback\n7,'tis fine\n"))
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	if rows[0][0] != "Student ID" {
+		t.Errorf("BOM not removed: %q", rows[0][0])
+	}
+	if rows[1][1] != "'tis fine" {
+		t.Errorf("quote not followed by a formula character must be kept, got %q", rows[1][1])
+	}
+}